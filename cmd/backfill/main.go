@@ -0,0 +1,174 @@
+// Command backfill seeds the price history with past prices from Internet
+// Archive (Wayback Machine) snapshots of vendor catalogs, so trends and
+// all-time lows have months of data from the first run.
+//
+// Usage:
+//
+//	go run ./cmd/backfill -vendor "Nutricost" -from 2026-01-01 -to 2026-06-30
+//
+// Shopify vendors are backfilled from archived products.json captures;
+// Magento and LD+JSON vendors from archived product pages, one per handle in
+// the vendor's cached data/<vendor>.json. Existing history points are never
+// overwritten. Every fetched snapshot is also kept under data/raw/, so
+// `go run cmd/main.go reanalyze` can re-derive its points without the network.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"longevity-ranker/internal/config"
+	"longevity-ranker/internal/history"
+	"longevity-ranker/internal/models"
+	"longevity-ranker/internal/rawdata"
+	"longevity-ranker/internal/rules"
+	"longevity-ranker/internal/scraper"
+	"longevity-ranker/internal/storage"
+)
+
+func main() {
+	now := time.Now().UTC()
+	vendorName := flag.String("vendor", "", "Backfill only this vendor (default: every archivable vendor)")
+	from := flag.String("from", now.AddDate(0, -6, 0).Format(history.DateLayout), "First snapshot date to use (YYYY-MM-DD)")
+	to := flag.String("to", now.AddDate(0, 0, -1).Format(history.DateLayout), "Last snapshot date to use (YYYY-MM-DD)")
+	maxSnapshots := flag.Int("max", 60, "Maximum snapshots (one per day) to fetch per URL")
+	dryRun := flag.Bool("dry-run", false, "Report what would be added without writing price_history.json")
+	flag.Parse()
+
+	fromStamp, err1 := cdxDate(*from)
+	toStamp, err2 := cdxDate(*to)
+	if err1 != nil || err2 != nil || *maxSnapshots <= 0 {
+		fmt.Fprintln(os.Stderr, "❌ -from and -to must be YYYY-MM-DD and -max positive")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	reg, err := rules.LoadRules(filepath.Join(storage.DataDir, "vendor_rules.json"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️ Could not load rules (%v). Backfilling without filters.\n", err)
+	}
+	store, err := history.Load(history.Filename)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Could not load price history: %v\n", err)
+		os.Exit(1)
+	}
+
+	vendors, err := config.Load(config.Filename)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	total, matched := 0, false
+	for _, v := range vendors {
+		if *vendorName != "" && v.Name != *vendorName {
+			continue
+		}
+		matched = true
+		links, err := archiveURLs(v)
+		if err != nil {
+			fmt.Printf("⏭️  %s: %v\n", v.Name, err)
+			continue
+		}
+
+		added, snapshots := 0, 0
+		for _, link := range links {
+			snaps, err := scraper.ListSnapshots(link, fromStamp, toStamp, *maxSnapshots)
+			if err != nil {
+				fmt.Printf("⚠️ %s: listing snapshots of %s: %v\n", v.Name, link, err)
+				continue
+			}
+			for _, snap := range snaps {
+				products, err := scraper.FetchSnapshotProducts(v, snap, link)
+				if err != nil {
+					fmt.Printf("⚠️ %s: snapshot %s of %s: %v\n", v.Name, snap.Timestamp, link, err)
+					continue
+				}
+				snapshots++
+				if !*dryRun {
+					raw := rawdata.Snapshot{Vendor: v.Name, Date: snap.Date(), Source: rawdata.SourceWayback, URL: snap.Original, Products: products}
+					if _, err := rawdata.Save(rawdata.Dir, raw); err != nil {
+						fmt.Printf("⚠️ %s: archiving snapshot %s: %v\n", v.Name, snap.Timestamp, err)
+					}
+				}
+				for _, p := range products {
+					if rules.ApplyRules(reg, v.Name, &p) {
+						added += history.Backfill(store, snap.Date(), v.Name, p)
+					}
+				}
+			}
+		}
+		fmt.Printf("🕰️  %s: %d snapshot(s) from %d URL(s), %d point(s) added\n", v.Name, snapshots, len(links), added)
+		total += added
+	}
+	if !matched {
+		fmt.Fprintf(os.Stderr, "❌ No configured vendor named %q\n", *vendorName)
+		os.Exit(1)
+	}
+
+	if *dryRun {
+		fmt.Printf("\n🧪 Dry run: %d point(s) would be added to %s\n", total, history.Filename)
+		return
+	}
+	if total == 0 {
+		fmt.Println("\nNothing to add.")
+		return
+	}
+	if err := storage.SaveJSON(history.Filename, store); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Could not save price history: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("\n✅ Added %d point(s) to %s\n", total, history.Filename)
+}
+
+// cdxDate converts a YYYY-MM-DD flag value to the CDX API's YYYYMMDD.
+func cdxDate(date string) (string, error) {
+	t, err := time.Parse(history.DateLayout, date)
+	if err != nil {
+		return "", err
+	}
+	return t.Format("20060102"), nil
+}
+
+// archiveURLs returns the live URLs whose archived captures hold the vendor's
+// catalog. Shopify collection URLs lose their query string (?limit=250):
+// archived captures are usually of the bare products.json. Generic HTML
+// vendors list their product pages; other page-per-product vendors need a
+// cached catalog to know their product URLs.
+func archiveURLs(v models.Vendor) ([]string, error) {
+	switch v.Type {
+	case "shopify":
+		var links []string
+		for _, raw := range append([]string{v.URL}, v.Collections...) {
+			u, err := url.Parse(raw)
+			if err != nil {
+				return nil, err
+			}
+			u.RawQuery = ""
+			links = append(links, u.String())
+		}
+		return links, nil
+	case "generic-html":
+		return append([]string{v.URL}, v.ProductPages...), nil
+	case "magento", "html-ldjson":
+		products, err := storage.LoadJSON[[]models.Product](storage.VendorFilename(v.Name))
+		if err != nil {
+			return nil, fmt.Errorf("no cached catalog to take product URLs from (run the scraper first): %v", err)
+		}
+		var links []string
+		seen := make(map[string]bool)
+		for _, p := range products {
+			if strings.HasPrefix(p.Handle, "http") && !seen[p.Handle] {
+				seen[p.Handle] = true
+				links = append(links, p.Handle)
+			}
+		}
+		return links, nil
+	}
+	return nil, fmt.Errorf("vendor type %q has no archived source", v.Type)
+}
@@ -0,0 +1,127 @@
+// Command golden snapshots the current analyzer output for one cached product
+// as a new case in internal/parser/testdata/golden/.
+//
+// Usage:
+//
+//	go run ./cmd/golden -vendor "Nutricost" -handle nutricost-nmn
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"longevity-ranker/internal/models"
+	"longevity-ranker/internal/parser"
+	"longevity-ranker/internal/rules"
+	"longevity-ranker/internal/storage"
+	"longevity-ranker/internal/taxonomy"
+)
+
+// goldenCase mirrors the case schema read by internal/parser/golden_test.go.
+type goldenCase struct {
+	Vendor      string              `json:"vendor"`
+	Supplements []string            `json:"supplements"`
+	Rules       *rules.VendorConfig `json:"rules,omitempty"`
+	Product     models.Product      `json:"product"`
+	Expected    []models.Analysis   `json:"expected"`
+}
+
+var reNonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+// caseName builds the fixture file name from the vendor and handle. URL handles
+// (Magento, LD+JSON) contribute only their last path segment.
+// Example: ("Do Not Age", "https://donotage.org/pure-nmn") → "do-not-age-pure-nmn"
+func caseName(vendor, handle string) string {
+	if u, err := url.Parse(handle); err == nil && u.Host != "" {
+		handle = path.Base(strings.TrimSuffix(u.Path, "/"))
+	}
+	slug := func(s string) string {
+		return strings.Trim(reNonSlug.ReplaceAllString(strings.ToLower(s), "-"), "-")
+	}
+	vendorSlug, handleSlug := slug(vendor), slug(handle)
+	if strings.HasPrefix(handleSlug, vendorSlug) {
+		return handleSlug
+	}
+	return vendorSlug + "-" + handleSlug
+}
+
+func main() {
+	vendor := flag.String("vendor", "", "Vendor name as configured (e.g. \"Nutricost\")")
+	handle := flag.String("handle", "", "Product handle to snapshot")
+	supplements := flag.String("supplements", "nmn,nad,tmg,resveratrol,creatine", "Comma-separated supplements from the built-in registry")
+	out := flag.String("out", filepath.Join("internal", "parser", "testdata", "golden"), "Golden corpus directory")
+	flag.Parse()
+
+	if *vendor == "" || *handle == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	products, err := storage.LoadJSON[[]models.Product](storage.VendorFilename(*vendor))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Could not load cached products for %s: %v\n", *vendor, err)
+		os.Exit(1)
+	}
+
+	var product *models.Product
+	for i := range products {
+		if products[i].Handle == *handle {
+			product = &products[i]
+			break
+		}
+	}
+	if product == nil {
+		fmt.Fprintf(os.Stderr, "❌ Handle %q not found in %s\n", *handle, storage.VendorFilename(*vendor))
+		os.Exit(1)
+	}
+
+	// Keep only the vendor config relevant to this handle
+	reg, err := rules.LoadRules(filepath.Join(storage.DataDir, "vendor_rules.json"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️ Could not load rules (%v). Snapshotting without rules.\n", err)
+	}
+	var caseRules *rules.VendorConfig
+	if cfg, ok := reg[*vendor]; ok {
+		trimmed := cfg
+		trimmed.Overrides = nil
+		if spec, ok := cfg.Overrides[*handle]; ok {
+			trimmed.Overrides = map[string]rules.ProductSpec{*handle: spec}
+		}
+		caseRules = &trimmed
+	}
+
+	// Anonymize: IDs and image URLs carry no extraction signal
+	anon := *product
+	anon.ID = ""
+	anon.ImageURL = ""
+	anon.Variants = append([]models.Variant(nil), product.Variants...)
+	for i := range anon.Variants {
+		anon.Variants[i].ImageURL = ""
+	}
+
+	gc := goldenCase{
+		Vendor:      *vendor,
+		Supplements: strings.Split(*supplements, ","),
+		Rules:       caseRules,
+		Product:     anon,
+	}
+
+	analyzer := &parser.Analyzer{Supplements: taxonomy.Defaults().Select(gc.Supplements)}
+	if caseRules != nil {
+		analyzer.Rules = rules.Registry{*vendor: *caseRules}
+	}
+	gc.Expected = analyzer.AnalyzeProduct(*vendor, anon)
+
+	path := filepath.Join(*out, caseName(*vendor, *handle)+".json")
+	if err := storage.SaveJSON(path, gc); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Could not write %s: %v\n", path, err)
+		os.Exit(1)
+	}
+	fmt.Printf("✅ Wrote golden case %s (%d expected entries)\n", path, len(gc.Expected))
+}
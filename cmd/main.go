@@ -1,35 +1,136 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
+	"math"
+	"net"
 	"net/http"
 	_ "net/http/pprof"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"runtime/pprof"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"text/tabwriter"
+	"time"
 
+	"longevity-ranker/internal/alerts"
+	"longevity-ranker/internal/bundle"
+	"longevity-ranker/internal/changes"
 	"longevity-ranker/internal/config"
+	"longevity-ranker/internal/delisting"
+	"longevity-ranker/internal/history"
+	"longevity-ranker/internal/locale"
+	"longevity-ranker/internal/manifest"
 	"longevity-ranker/internal/models"
+	"longevity-ranker/internal/pareto"
 	"longevity-ranker/internal/parser"
+	"longevity-ranker/internal/queue"
+	"longevity-ranker/internal/rawdata"
+	"longevity-ranker/internal/review"
 	"longevity-ranker/internal/rules"
+	"longevity-ranker/internal/runerrors"
+	"longevity-ranker/internal/runs"
+	"longevity-ranker/internal/scores"
 	"longevity-ranker/internal/scraper"
+	"longevity-ranker/internal/seed"
+	"longevity-ranker/internal/split"
+	"longevity-ranker/internal/spread"
 	"longevity-ranker/internal/storage"
+	"longevity-ranker/internal/summary"
+	"longevity-ranker/internal/taxonomy"
+	"longevity-ranker/internal/watchlist"
+	"longevity-ranker/internal/widget"
 )
 
+// reportPath is the ranked report every run writes and `best` reads.
+var reportPath = filepath.Join("data", "analysis_report.json")
+
+// extendedReportPath is the report with recent prices, written with -extended.
+var extendedReportPath = filepath.Join("data", "analysis_report_extended.json")
+
+// sparklineDays is how many daily prices a sparkline shows.
+const sparklineDays = 30
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "validate-vendor" {
+		os.Exit(runValidateVendor(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "best" {
+		os.Exit(runBest(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		os.Exit(runServe(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "compare" {
+		os.Exit(runCompare(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "explain" {
+		os.Exit(runExplain(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "export-history" {
+		os.Exit(runExportHistory(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "reanalyze" {
+		os.Exit(runReanalyze(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "worker" {
+		os.Exit(runWorker(os.Args[2:]))
+	}
+
 	refresh := flag.Bool("refresh", false, "Scrape websites to update local data")
 	cpuprofile := flag.String("cpuprofile", "", "Write cpu profile to `file`")
 	pprofFlag := flag.Bool("pprof", false, "Start pprof HTTP server on :6060")
 	audit := flag.Bool("audit", false, "Detect products that need manual overrides in vendor_rules.json")
-	supplements := flag.String("supplements", "nmn,nad,tmg,trimethylglycine,resveratrol,creatine", "Comma-separated list of supplement keywords to track")
+	supplements := flag.String("supplements", "", "Comma-separated supplements to track, by name or alias (default: all in data/supplements.json)")
+	verifyOverrides := flag.Bool("verify-overrides", false, "Re-scrape vendors and check overrides' expected mg/price against live data")
+	exclude := flag.String("exclude", "", "Comma-separated keywords; products matching any are dropped for every vendor (e.g. `\"gummies,topical\"`)")
+	widgetTop := flag.Int("widget-top", widget.DefaultTop, fmt.Sprintf("Products per supplement in data/widget.json (max %d; 0 = no widget)", widget.MaxTop))
+	strict := flag.Bool("strict", false, "Drop flagged and low-confidence entries from the ranking instead of listing them below the fold")
+	includeUnavailable := flag.Bool("include-unavailable", false, "Also rank out-of-stock variants, marked unavailable and listed below the fold, instead of skipping them")
+	testedOnly := flag.Bool("tested-only", false, "Rank only products with a third-party testing certification (certifications in vendor_rules.json)")
+	minCapsuleMg := flag.Float64("min-capsule-mg", 0, "Drop capsules and tablets with less active mg per unit than this (e.g. 250); powders and liquids stay")
+	paretoFlag := flag.Bool("pareto", false, "Also print each supplement's Pareto front: entries no other beats on both true cost and trust")
+	localeTag := flag.String("locale", "en", "Number, currency and unit format of the printed table: "+strings.Join(locale.Supported(), ", "))
+	watchlistFile := flag.String("watchlist", "", "Track only the products listed in `file` (vendor/handle entries, as in data/watchlist.json): scrape and analyze nothing else, update only the price history")
+	extended := flag.Bool("extended", false, fmt.Sprintf("Also write data/analysis_report_extended.json: the report plus each entry's last %d daily prices, for sparklines", sparklineDays))
+	mock := flag.String("mock", "", "Dry-run against a fixture instead of the configured vendors: `\"Vendor Name=path/or/url\"` (writes no files)")
+	browser := flag.Bool("browser", false, "Scrape Cloudflare-protected vendors through a headless Chrome instead of using their local JSON (needs Chrome or Chromium; $"+scraper.BrowserPathEnv+" picks the binary)")
+	alertMax := flag.Int("alert-max", alerts.DefaultMax, "Most alert webhook posts per run; past it, the remaining alerts share one digest post (0 = no cap)")
+	alertDigest := flag.Bool("alert-digest", false, "Post all of a run's alerts to the webhook as one digest")
+	httpCache := flag.String("http-cache", filepath.Join(storage.DataDir, "cache"), "Cache pages fetched with an ETag or Last-Modified in `dir` and re-download them only when changed (\"\" = off)")
+	ignoreRobots := flag.Bool("ignore-robots", false, "Fetch pages even when the vendor's robots.txt disallows them, and ignore its Crawl-delay")
+	distribute := flag.String("distribute", "", "With -refresh, hand live scrapes to `worker` processes polling `addr` (e.g. :9090) instead of scraping here (needs $"+serveTokenEnv+")")
+	distributeTimeout := flag.Duration("distribute-timeout", 30*time.Minute, "How long a distributed vendor waits for a worker's result before it counts as failed")
+	offline := flag.Bool("offline", false, "Never touch the network: rank local data, seeding missing vendor files, rules and lists from the built-in dataset")
+	resume := flag.Bool("resume", false, "With -refresh, continue the product page crawls an interrupted run left in data/.checkpoints instead of starting them over")
+	discover := flag.Bool("discover", false, "Only list the product URLs each vendor's scraper would crawl (entry pages, sitemaps and collections are fetched, product pages are not) and save them to data/discovered_links.json")
 	flag.Parse()
+	startedAt := time.Now().UTC()
+	runID := manifest.NewRunID(startedAt)
+	if *offline && (*refresh || *verifyOverrides || *discover) {
+		log.Fatal("-offline cannot be combined with -refresh, -verify-overrides or -discover")
+	}
+	if *resume && !*refresh {
+		log.Fatal("-resume needs -refresh")
+	}
+
+	loc, err := locale.Lookup(*localeTag)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	if *pprofFlag {
 		go func() {
@@ -55,6 +156,15 @@ func main() {
 	if err := storage.EnsureDataDir(); err != nil {
 		panic(err)
 	}
+	if *offline {
+		seeded, err := seed.Restore(storage.DataDir)
+		if err != nil {
+			log.Fatalf("could not seed %s: %v", storage.DataDir, err)
+		}
+		for _, name := range seeded {
+			fmt.Printf("📦 Seeded %s from the built-in dataset\n", filepath.Join(storage.DataDir, name))
+		}
+	}
 
 	// Load vendor rules (no global state — returned explicitly)
 	rulesPath := filepath.Join("data", "vendor_rules.json")
@@ -65,55 +175,406 @@ func main() {
 		fmt.Println("✅ Loaded vendor rules from JSON")
 	}
 
+	if excluded := parseKeywords(*exclude); len(excluded) > 0 {
+		reg = rules.WithExclusions(reg, excluded)
+		fmt.Printf("🚫 Excluding products matching: %s\n", strings.Join(excluded, ", "))
+	}
+
+	vendors, reg, err := loadVendors(reg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *offline {
+		vendors = offlineVendors(vendors)
+	}
+	if *browser {
+		vendors = withBrowser(vendors)
+		defer scraper.CloseBrowser()
+	}
+	scraper.CacheDir = *httpCache
+	scraper.IgnoreRobots = *ignoreRobots
+	if *refresh && *mock == "" {
+		scraper.CheckpointDir = filepath.Join(storage.DataDir, ".checkpoints")
+		scraper.Resume = *resume
+	}
+
+	if *verifyOverrides {
+		runVerifyOverrides(vendors, reg)
+		return
+	}
+
+	// Load price history (used to catch placeholder and anomalous prices)
+	priceHistory, err := history.Load(history.Filename)
+	if err != nil {
+		fmt.Printf("⚠️ Warning: Could not load price history (%v). Starting fresh.\n", err)
+		priceHistory = history.Store{}
+	}
+	today := time.Now().UTC().Format(history.DateLayout)
+
+	// Load operator review decisions (dismissed false positives stay dismissed)
+	decisions, err := review.Load(review.Filename)
+	if err != nil {
+		fmt.Printf("⚠️ Warning: Could not load review decisions (%v). Every flag will be queued.\n", err)
+		decisions = review.Decisions{}
+	}
+
+	// Load external quality scores (Labdoor/ConsumerLab exports)
+	qualityScores, err := scores.Load(scores.Filename)
+	if err != nil {
+		fmt.Printf("⚠️ Warning: Could not load quality scores (%v). No quality-adjusted costs.\n", err)
+	}
+
+	trackedSupplements, err := loadSupplements(*supplements, reg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	vendors = withTrackedCollections(vendors, trackedSupplements, reg)
+
+	if *discover {
+		runDiscover(vendors)
+		return
+	}
+
 	// Build analyzer with injected dependencies
 	analyzer := &parser.Analyzer{
 		Rules:       reg,
-		Supplements: parseSupplements(*supplements),
+		Supplements: trackedSupplements,
+		History:     priceHistory,
+		Today:       today,
+		Decisions:   decisions,
+		Scores:      qualityScores,
+
+		IncludeUnavailable: *includeUnavailable,
 	}
 
 	// Scrape or load all vendors concurrently
-	vendors := config.GetVendors()
-	vendorProducts := scrapeAll(vendors, reg, *refresh)
+	if *mock != "" {
+		mockVendor, err := parseMockVendor(*mock)
+		if err != nil {
+			log.Fatal(err)
+		}
+		vendors = []models.Vendor{mockVendor}
+	}
+	var tracked watchlist.Watchlist
+	if *watchlistFile != "" {
+		tracked, err = storage.LoadJSON[watchlist.Watchlist](*watchlistFile)
+		if err != nil {
+			log.Fatalf("could not load watchlist %s: %v", *watchlistFile, err)
+		}
+		if len(tracked) == 0 {
+			log.Fatalf("watchlist %s lists no products", *watchlistFile)
+		}
+		vendors = trackedVendors(vendors, tracked)
+		fmt.Printf("👀 Watchlist: tracking %d product(s) across %d vendor(s)\n", len(tracked), len(vendors))
+	}
+	fetch := liveFetch(fetchLocal)
+	stopCoordinator := func() {}
+	if *distribute != "" {
+		d, stop, err := startCoordinator(*distribute, os.Getenv(serveTokenEnv), runID, *distributeTimeout)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fetch, stopCoordinator = d.fetch, stop
+	}
+	vendorProducts, vendorStatuses, runErrors := scrapeAll(vendors, reg, *refresh, tracked, fetch)
+	stopCoordinator()
+	// Printed last, so errors are not lost in the progress output
+	defer func() { fmt.Fprint(os.Stderr, runerrors.Format(runErrors)) }()
+
+	for _, vp := range vendorProducts {
+		if vp.Product.MissingSince != "" {
+			continue // Not observed today: its prices are the last scraped ones
+		}
+		history.Record(priceHistory, today, vp.Vendor, vp.Product)
+	}
 
 	// Analyze and optionally audit
-	var report []models.Analysis
-	var auditResults []parser.AuditResult
+	analyzer.PriceSources = priceSources(vendors)
+	report, auditResults, quality := analyzeAll(analyzer, vendorProducts, *audit)
+	bundle.Apply(report)
+	printDearBundles(bundle.Dearer(report))
+	if *testedOnly {
+		report = filterTested(report)
+		fmt.Printf("🏅 Tested only: %d certified entries\n", len(report))
+	}
+	if *minCapsuleMg > 0 {
+		n := len(report)
+		report = filterCapsuleMg(report, *minCapsuleMg)
+		fmt.Printf("💊 Min capsule size %.0f mg: dropped %d entries\n", *minCapsuleMg, n-len(report))
+	}
+	// The review queue still sees the entries -strict drops
+	reviewed := report
+	if *strict {
+		report = filterStrict(report)
+		fmt.Printf("🛡️ Strict: dropped %d flagged/low-confidence entries\n", len(reviewed)-len(report))
+	}
+	fronts := pareto.Mark(report, trackedSupplements)
+	spread.Apply(report, trackedSupplements)
+	// Movement is measured against the last full report: mock and watchlist
+	// runs rank a different set of products
+	var previousReport []models.Analysis
+	if *mock == "" && tracked == nil {
+		previousReport = loadPreviousReport()
+	}
+	spread.Rank(report, previousReport)
+	analyzer.PrioritizeAudit(auditResults, report)
 
-	for _, vp := range vendorProducts {
-		if analyses := analyzer.AnalyzeProduct(vp.Vendor, vp.Product); analyses != nil {
-			report = append(report, analyses...)
+	if *mock != "" {
+		fmt.Println("🧪 Mock dry run: no files written.")
+		printTable(report, loc)
+		if *paretoFlag {
+			printPareto(report, fronts, loc)
 		}
+		fmt.Print(parser.FormatQualitySummary(quality))
 		if *audit {
-			if gap := analyzer.AuditProduct(vp.Vendor, vp.Product); gap != nil {
-				auditResults = append(auditResults, *gap)
-			}
+			fmt.Print(parser.FormatAuditReport(auditResults))
 		}
+		return
 	}
 
-	// Sort by effective cost (true value)
-	sort.Slice(report, func(i, j int) bool {
-		return report[i].EffectiveCost < report[j].EffectiveCost
-	})
+	// A watchlist run sees a sliver of each catalog: publishing it would
+	// empty the site and report everything else as delisted
+	if tracked != nil {
+		for _, e := range unmatchedWatches(tracked, vendorProducts) {
+			ref := e.Vendor + "/" + e.Handle
+			if e.Variant != "" {
+				ref += " (" + e.Variant + ")"
+			}
+			fmt.Printf("⚠️ Watched product not found (or blocked by rules): %s\n", ref)
+		}
+		if err := storage.SaveJSON(history.Filename, priceHistory); err != nil {
+			fmt.Printf("⚠️ Error saving price history: %v\n", err)
+		}
+		changeSet := changes.Compute(priceHistory, today, currentCatalog(vendorProducts, vendorStatuses))
+		printBackInStock(tracked.BackInStock(changeSet.AvailabilityChanges))
+		fmt.Println("👀 Watchlist run: price history updated; report, widget and change feed untouched.")
+		printTable(report, loc)
+		if *paretoFlag {
+			printPareto(report, fronts, loc)
+		}
+		return
+	}
 
-	if err := storage.SaveJSON(filepath.Join("data", "analysis_report.json"), report); err != nil {
+	// Sources are for the extended report; the published one stays lean
+	attributed := report
+	report = withoutAttribution(report)
+	var outputs []string
+	if err := storage.SaveJSON(reportPath, report); err != nil {
 		fmt.Printf("⚠️ Error saving analysis report: %v\n", err)
 	} else {
 		fmt.Printf("✅ Saved analysis report (%d products) to data/analysis_report.json\n", len(report))
+		outputs = append(outputs, reportPath)
+	}
+	if paths, ok := saveSplitReports(report, trackedSupplements, today, runID); ok {
+		outputs = append(outputs, paths...)
+	}
+	if path, ok := saveVendorSummary(report, quality, vendorStatuses, trackedSupplements, today, runID, startedAt); ok {
+		outputs = append(outputs, path)
+	}
+	// Archived for serve's /api/diff, like the raw data
+	if _, err := runs.Save(runs.Dir, runs.Run{RunID: runID, Date: today, Report: report}, runs.Keep); err != nil {
+		fmt.Printf("⚠️ Error archiving run %s: %v\n", runID, err)
+	}
+	if *extended {
+		if err := storage.SaveJSON(extendedReportPath, extendReport(attributed, priceHistory)); err != nil {
+			fmt.Printf("⚠️ Error saving extended report: %v\n", err)
+		} else {
+			fmt.Printf("📉 Saved extended report with recent prices to %s\n", extendedReportPath)
+			outputs = append(outputs, extendedReportPath)
+		}
+	}
+
+	if err := storage.SaveJSON(history.Filename, priceHistory); err != nil {
+		fmt.Printf("⚠️ Error saving price history: %v\n", err)
+	} else {
+		outputs = append(outputs, history.Filename)
 	}
 
-	saveReviewQueue(report)
-	printTable(report)
+	if path, ok := saveReviewQueue(reviewed, decisions); ok {
+		outputs = append(outputs, path)
+	}
+	changeSet := changes.Compute(priceHistory, today, currentCatalog(vendorProducts, vendorStatuses))
+	watched, err := watchlist.Load(watchlist.Filename)
+	if err != nil {
+		fmt.Printf("⚠️ Warning: Could not load watchlist (%v). No back-in-stock events.\n", err)
+	}
+	changeSet.BackInStock = watched.BackInStock(changeSet.AvailabilityChanges)
+	printBackInStock(changeSet.BackInStock)
+	if path, ok := saveChanges(changeSet); ok {
+		outputs = append(outputs, path)
+	}
+	if path, ok := saveErrors(today, runErrors); ok {
+		outputs = append(outputs, path)
+	}
+	if *widgetTop > 0 {
+		if path, ok := saveWidget(report, vendors, trackedSupplements, today, *widgetTop); ok {
+			outputs = append(outputs, path)
+		}
+	}
+	printTable(report, loc)
+	if *paretoFlag {
+		printPareto(report, fronts, loc)
+	}
+	fmt.Print(parser.FormatQualitySummary(quality))
 
 	if *audit {
 		fmt.Print(parser.FormatAuditReport(auditResults))
+		previous, ok := loadPreviousAudit()
+		if ok {
+			fmt.Print(parser.FormatAuditDiff(analyzer.DiffAudit(previous, auditResults, report)))
+		}
+		if !*offline {
+			notifyContenders(parser.NewContenders(previous, auditResults), alerts.Limits{Max: *alertMax, Digest: *alertDigest})
+		}
+		if path, ok := saveAuditReport(auditResults); ok {
+			outputs = append(outputs, path)
+		}
+	}
+
+	saveManifest(runID, startedAt, rulesPath, vendorStatuses, outputs)
+}
+
+// loadVendors reads the vendor list (data/vendors.json, written from the
+// built-in defaults on first use) and sets each vendor's currency on reg.
+func loadVendors(reg rules.Registry) ([]models.Vendor, rules.Registry, error) {
+	vendors, err := config.Load(config.Filename)
+	if err != nil {
+		return nil, reg, err
+	}
+	reg, err = rules.WithCurrencies(reg, vendors)
+	return vendors, reg, err
+}
+
+// saveManifest writes data/run_manifest.json for this run: the flags set on
+// the command line, the rules file hash, how each vendor was obtained, and
+// the hash of every output file written.
+func saveManifest(runID string, startedAt time.Time, rulesPath string, vendors []manifest.VendorStatus, outputs []string) {
+	m := manifest.Manifest{
+		RunID:      runID,
+		StartedAt:  startedAt,
+		FinishedAt: time.Now().UTC(),
+		Flags:      map[string]string{},
+		Vendors:    vendors,
+		Outputs:    map[string]string{},
+	}
+	flag.Visit(func(f *flag.Flag) {
+		m.Flags[f.Name] = f.Value.String()
+	})
+	if hash, err := manifest.HashFile(rulesPath); err == nil {
+		m.RulesHash = hash
+	}
+	for _, path := range outputs {
+		hash, err := manifest.HashFile(path)
+		if err != nil {
+			fmt.Printf("⚠️ Error hashing %s: %v\n", path, err)
+			continue
+		}
+		m.Outputs[filepath.ToSlash(path)] = hash
+	}
+
+	if err := storage.SaveJSON(manifest.Filename, m); err != nil {
+		fmt.Printf("⚠️ Error saving run manifest: %v\n", err)
+		return
+	}
+	fmt.Printf("🧾 Saved run manifest %s to data/run_manifest.json\n", m.RunID)
+}
+
+// withBrowser marks the Cloudflare-protected vendors for -browser: their
+// requests go through headless Chrome (see scraper.ClientFor).
+func withBrowser(vendors []models.Vendor) []models.Vendor {
+	for i := range vendors {
+		if vendors[i].Cloudflare {
+			vendors[i].Browser = true
+		}
+	}
+	return vendors
+}
+
+// withTrackedCollections sets, for each vendor with DiscoverTracked, its
+// DiscoverWords to the names and aliases of the supplements tracked for it
+// (its rules "supplements" scope, else all), so the scraper finds the
+// store's /collections/nad, /collections/tmg… without listing them. Short
+// names like "nad" are matched as whole words, not inside "canada".
+func withTrackedCollections(vendors []models.Vendor, tracked taxonomy.Registry, reg rules.Registry) []models.Vendor {
+	for i, v := range vendors {
+		if !v.DiscoverTracked {
+			continue
+		}
+		scope := tracked
+		if names := reg[v.Name].Supplements; len(names) > 0 {
+			scope = tracked.Select(names)
+		}
+		var words []string
+		for _, s := range scope {
+			words = append(words, s.Keywords()...)
+		}
+		vendors[i].DiscoverWords = words
+	}
+	return vendors
+}
+
+// mergeMagentoOptions merges a Magento vendor's cached products by handle
+// (see scraper.MergeByHandle): caches written before Magento options were
+// merged still hold one product per option. Other vendors' products are
+// returned as they are, since their handles need not be unique.
+func mergeMagentoOptions(v models.Vendor, products []models.Product) []models.Product {
+	if v.Type != "magento" {
+		return products
+	}
+	return scraper.MergeByHandle(products)
+}
+
+// offlineVendors drops the vendors an -offline run would have to fetch: those
+// without a local data/<vendor>.json, and CSV vendors whose file is a URL.
+func offlineVendors(vendors []models.Vendor) []models.Vendor {
+	var local []models.Vendor
+	for _, v := range vendors {
+		if v.Type == "csv" {
+			if !strings.HasPrefix(v.URL, "http://") && !strings.HasPrefix(v.URL, "https://") {
+				local = append(local, v)
+				continue
+			}
+		} else if _, err := os.Stat(storage.VendorFilename(v.Name)); err == nil {
+			local = append(local, v)
+			continue
+		}
+		fmt.Printf("📴 Skipping %s (offline, no local data)\n", v.Name)
 	}
+	return local
 }
 
-// parseSupplements splits a comma-separated string into a cleaned keyword list.
-func parseSupplements(raw string) []string {
-	if raw == "" {
-		return []string{"nmn", "nad", "tmg", "trimethylglycine", "resveratrol", "creatine"}
+// loadSupplements reads the supplement registry and returns the supplements
+// raw (the -supplements flag) names, or all of them when it is empty. Names
+// in the flag and in vendor "supplements" scopes must be registry names or
+// aliases.
+func loadSupplements(raw string, reg rules.Registry) (taxonomy.Registry, error) {
+	registry, err := taxonomy.Load(taxonomy.Filename)
+	if err != nil {
+		return nil, err
+	}
+	known := strings.Join(registry.Names(), ", ")
+	for vendor, cfg := range reg {
+		for _, name := range cfg.Supplements {
+			if _, ok := registry.Lookup(name); !ok {
+				return nil, fmt.Errorf("vendor %q: unknown supplement %q (want %s)", vendor, name, known)
+			}
+		}
+	}
+	names := parseKeywords(raw)
+	if len(names) == 0 {
+		return registry, nil
+	}
+	for _, name := range names {
+		if _, ok := registry.Lookup(name); !ok {
+			return nil, fmt.Errorf("unknown supplement %q (want %s, or add it to %s)", name, known, taxonomy.Filename)
+		}
 	}
+	return registry.Select(names), nil
+}
+
+// parseKeywords splits a comma-separated flag value into lowercased,
+// trimmed, non-empty keywords.
+func parseKeywords(raw string) []string {
 	var cleaned []string
 	for _, s := range strings.Split(raw, ",") {
 		s = strings.TrimSpace(strings.ToLower(s))
@@ -124,6 +585,117 @@ func parseSupplements(raw string) []string {
 	return cleaned
 }
 
+// runVerifyOverrides re-scrapes every vendor that has overrides and prints the
+// overrides whose stored expectations no longer match live data. Cloudflare
+// vendors are reported as skipped unless -browser can re-scrape them.
+func runVerifyOverrides(vendors []models.Vendor, reg rules.Registry) {
+	verifier := &parser.Analyzer{Rules: reg}
+	var mismatches []parser.OverrideMismatch
+
+	for _, v := range vendors {
+		if len(reg[v.Name].Overrides) == 0 {
+			continue
+		}
+		if v.Cloudflare && !v.Browser {
+			fmt.Printf("🛡️  Skipping %s (Cloudflare-protected; -browser scrapes it). Overrides not verified.\n", v.Name)
+			continue
+		}
+		products, err := scraper.FetchProducts(v)
+		if err != nil {
+			fmt.Printf("❌ Error for %s: %v\n", v.Name, err)
+			continue
+		}
+		mismatches = append(mismatches, verifier.VerifyOverrides(v.Name, products)...)
+	}
+
+	fmt.Print(parser.FormatVerifyReport(mismatches))
+}
+
+// discoverSample is how many of a vendor's discovered URLs -discover prints;
+// data/discovered_links.json has them all.
+const discoverSample = 10
+
+// runDiscover runs only the link-extraction phase of every vendor's scraper
+// and prints and saves the product URLs a crawl would fetch, so selectors and
+// URL filters can be checked before a new vendor's first full crawl.
+func runDiscover(vendors []models.Vendor) {
+	scraper.DiscoverOnly = true
+	found := map[string][]string{}
+
+	for _, v := range vendors {
+		if v.Cloudflare && !v.Browser {
+			fmt.Printf("🛡️  Skipping %s (Cloudflare-protected; -browser scrapes it).\n", v.Name)
+			continue
+		}
+		links, ok, err := scraper.DiscoverLinks(v)
+		if !ok {
+			fmt.Printf("⏭️  %s: %s vendors have no link discovery phase.\n", v.Name, v.Type)
+			continue
+		}
+		if err != nil {
+			fmt.Printf("❌ Error for %s: %v\n", v.Name, err)
+			continue
+		}
+		found[v.Name] = links
+		fmt.Printf("🔗 %s: %d URL(s)\n", v.Name, len(links))
+		for i, link := range links {
+			if i == discoverSample {
+				fmt.Printf("   … and %d more\n", len(links)-discoverSample)
+				break
+			}
+			fmt.Printf("   %s\n", link)
+		}
+	}
+
+	path := filepath.Join(storage.DataDir, "discovered_links.json")
+	if err := storage.SaveJSON(path, found); err != nil {
+		log.Fatalf("could not save %s: %v", path, err)
+	}
+	fmt.Printf("💾 Saved discovered URLs to %s\n", path)
+}
+
+// parseMockVendor parses a -mock value of the form "Vendor Name=path/or/url"
+// into a mock-type vendor, or a csv-type vendor when the source ends in
+// ".csv". The name selects which vendor_rules.json entry applies, so new
+// rules can be dry-run against a fixture.
+func parseMockVendor(raw string) (models.Vendor, error) {
+	name, source, ok := strings.Cut(raw, "=")
+	name, source = strings.TrimSpace(name), strings.TrimSpace(source)
+	if !ok || name == "" || source == "" {
+		return models.Vendor{}, fmt.Errorf("invalid -mock value %q: want \"Vendor Name=path/or/url\"", raw)
+	}
+	vendorType := "mock"
+	if strings.HasSuffix(strings.ToLower(source), ".csv") {
+		vendorType = "csv"
+	}
+	return models.Vendor{Name: name, URL: source, Type: vendorType}, nil
+}
+
+// analyzeAll runs the analyzer (and optionally the audit) over every product
+// and returns the report sorted by rank score (effective cost unless
+// rankWeights is configured), with entries below the fold (parser.BelowFold)
+// after all others and ties in a fixed order (parser.RankedBefore), the audit
+// gaps, and the per-vendor data quality summary.
+func analyzeAll(analyzer *parser.Analyzer, vendorProducts []vendorProduct, audit bool) ([]models.Analysis, []parser.AuditResult, []parser.VendorQuality) {
+	var report []models.Analysis
+	var auditResults []parser.AuditResult
+	quality := parser.QualityTracker{}
+
+	for _, vp := range vendorProducts {
+		analyses := analyzer.AnalyzeProduct(vp.Vendor, vp.Product)
+		report = append(report, analyses...)
+		analyzer.RecordQuality(quality, vp.Vendor, vp.Product, analyses)
+		if audit {
+			if gap := analyzer.AuditProduct(vp.Vendor, vp.Product); gap != nil {
+				auditResults = append(auditResults, *gap)
+			}
+		}
+	}
+
+	sort.SliceStable(report, func(i, j int) bool { return parser.RankedBefore(report[i], report[j]) })
+	return report, auditResults, quality.Summarize()
+}
+
 // vendorProduct pairs a vendor name with a single filtered product.
 type vendorProduct struct {
 	Vendor  string
@@ -131,105 +703,808 @@ type vendorProduct struct {
 }
 
 // scrapeAll fetches or loads products for all vendors concurrently, applies
-// blocklist rules, and returns the flattened list of vendor+product pairs.
-func scrapeAll(vendors []models.Vendor, reg rules.Registry, refresh bool) []vendorProduct {
+// blocklist rules, and returns the flattened list of vendor+product pairs
+// along with each vendor's status, sorted by vendor name, for the manifest,
+// and the run's errors: failed vendors plus every failed request. Results are
+// collected in vendor list order, whichever vendor finishes first, so the
+// products (and every file built from them) come out in the same order on
+// every run. A non-nil tracked watchlist narrows every vendor to its watched
+// products and variants. Live scrapes go through fetch.
+func scrapeAll(vendors []models.Vendor, reg rules.Registry, refresh bool, tracked watchlist.Watchlist, fetch liveFetch) ([]vendorProduct, []manifest.VendorStatus, []runerrors.Entry) {
 	type result struct {
 		VendorName string
+		URL        string
 		Products   []models.Product
+		Status     string
 		Err        error
+
+		// Inferred currency of a scraped vendor that has none configured
+		Currency, CurrencySource string
 	}
 
-	ch := make(chan result, len(vendors))
+	results := make([]result, len(vendors))
 	var wg sync.WaitGroup
 
-	for _, v := range vendors {
+	for i, v := range vendors {
 		wg.Add(1)
-		go func(v models.Vendor) {
+		go func(i int, v models.Vendor) {
 			defer wg.Done()
-			products, err := scrapeOrLoad(v, refresh)
-			ch <- result{VendorName: v.Name, Products: products, Err: err}
-		}(v)
+			products, status, err := scrapeOrLoad(v, refresh, tracked.Handles(v.Name), rules.DelistGraceDays(reg, v.Name), fetch)
+			results[i] = result{VendorName: v.Name, URL: v.URL, Products: products, Status: status, Err: err}
+			if err == nil && status == manifest.StatusScraped && v.Currency == "" && v.Type != "mock" && v.Type != "csv" {
+				results[i].Currency, results[i].CurrencySource = scraper.InferCurrency(v, products)
+			}
+		}(i, v)
 	}
-
-	go func() {
-		wg.Wait()
-		close(ch)
-	}()
+	wg.Wait()
 
 	var all []vendorProduct
-	for res := range ch {
+	var statuses []manifest.VendorStatus
+	var errs runerrors.Log
+	inferred := map[string]string{}
+	for _, res := range results {
+		m := scraper.VendorMetrics(res.VendorName)
+		if m.Throttled > 0 {
+			fmt.Printf("🐢 %s throttled %d time(s) (HTTP 429) over %d request(s); backed off %s, abandoned %d request(s)\n",
+				res.VendorName, m.Throttled, m.Requests, m.Waited.Round(time.Second), m.GaveUp)
+		}
+		if m.NotModified > 0 {
+			fmt.Printf("♻️  %s: %d of %d page(s) unchanged since the last scrape (HTTP 304), served from the cache\n",
+				res.VendorName, m.NotModified, m.Requests)
+		}
+		if m.Rotated > 0 {
+			fmt.Printf("🎭 %s answered 403; switched User-Agent %d time(s)\n", res.VendorName, m.Rotated)
+		}
+		if m.Disallowed > 0 {
+			fmt.Printf("🤖 %s: %d request(s) skipped, disallowed by robots.txt (listed in %s; -ignore-robots fetches them)\n",
+				res.VendorName, m.Disallowed, runerrors.Filename)
+		}
+		if m.Tripped {
+			fmt.Printf("⛔ %s: circuit breaker open after %d failed request(s) (%d retried); %d request(s) skipped, results may be partial\n",
+				res.VendorName, m.Failures, m.Retries, m.Skipped)
+		}
+		skipped := scraper.BudgetSkipped(res.VendorName)
+		if len(skipped) > 0 {
+			fmt.Printf("⏸️  %s: crawl budget spent; %d URL(s) skipped (listed in %s), known products first next run\n",
+				res.VendorName, len(skipped), manifest.Filename)
+		}
+		status := manifest.VendorStatus{Vendor: res.VendorName, Status: res.Status, Partial: m.Tripped || m.GaveUp > 0 || len(skipped) > 0, SkippedURLs: skipped}
 		if res.Err != nil {
-			fmt.Printf("❌ Error for %s: %v\n", res.VendorName, res.Err)
+			class := runerrors.Classify(res.Err, 0)
+			switch {
+			case errors.Is(res.Err, scraper.ErrCircuitOpen):
+				class = runerrors.ClassCircuitOpen
+			case errors.Is(res.Err, scraper.ErrBudgetExhausted):
+				class = runerrors.ClassOverBudget
+			case errors.Is(res.Err, scraper.ErrDisallowed):
+				class = runerrors.ClassDisallowed
+			}
+			errs.Add(runerrors.Entry{Vendor: res.VendorName, Scope: runerrors.ScopeVendor, URL: res.URL, Class: class, Message: res.Err.Error()})
+			status.Status = manifest.StatusFailed
+			status.Error = res.Err.Error()
+			statuses = append(statuses, status)
 			continue
 		}
+		currency := rules.Currency(reg, res.VendorName)
+		for _, e := range currencyMismatches(res.VendorName, currency, res.Products) {
+			errs.Add(e)
+		}
+		if res.Currency != "" {
+			if adopt, e := checkInferredCurrency(reg, res.VendorName, res.URL, res.Currency, res.CurrencySource); e != nil {
+				errs.Add(*e)
+			} else if adopt {
+				inferred[res.VendorName] = res.Currency
+			}
+		}
 		for _, p := range res.Products {
+			if tracked != nil {
+				var watched bool
+				if p, watched = tracked.Filter(res.VendorName, p); !watched {
+					continue
+				}
+			}
 			if rules.ApplyRules(reg, res.VendorName, &p) {
 				all = append(all, vendorProduct{Vendor: res.VendorName, Product: p})
+				status.Products++
 			}
 		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Vendor < statuses[j].Vendor })
+	if len(inferred) > 0 {
+		updated, err := config.SetCurrencies(config.Filename, inferred)
+		if err != nil {
+			fmt.Printf("⚠️ Error saving inferred currencies: %v\n", err)
+		}
+		for _, name := range updated {
+			fmt.Printf("💱 %s: recorded currency %s in %s\n", name, inferred[name], config.Filename)
+		}
+	}
+	for _, e := range scraper.PageErrors() {
+		errs.Add(e)
+	}
+	return all, statuses, errs.Entries()
+}
+
+// currencyMismatches reports, one entry per currency, the products whose
+// pages state another currency than the vendor's: their prices are read as
+// currency and would be ranked at the wrong exchange rate.
+func currencyMismatches(vendorName, currency string, products []models.Product) []runerrors.Entry {
+	var codes []string
+	counts := map[string]int{}
+	first := map[string]string{}
+	for _, p := range products {
+		if p.Currency == "" || strings.EqualFold(p.Currency, currency) {
+			continue
+		}
+		if counts[p.Currency] == 0 {
+			codes = append(codes, p.Currency)
+			first[p.Currency] = p.Handle
+		}
+		counts[p.Currency]++
+	}
+	sort.Strings(codes)
+	var entries []runerrors.Entry
+	for _, c := range codes {
+		entries = append(entries, runerrors.Entry{
+			Vendor: vendorName, Scope: runerrors.ScopePage, URL: first[c], Class: runerrors.ClassCurrency,
+			Message: fmt.Sprintf("%d product(s) priced in %s, but the vendor's currency is %s; set \"currency\" in %s", counts[c], c, currency, config.Filename),
+		})
+	}
+	return entries
+}
+
+// checkInferredCurrency decides what to do with the currency inferred for a
+// vendor that has none in the vendor list. It is recorded (adopt) when it
+// agrees with the rules, or when the rules set no currency and it has an
+// exchange rate, so later runs convert it. A disagreement that cannot be
+// recorded is returned as an error entry, since this run read the prices in
+// the wrong currency.
+func checkInferredCurrency(reg rules.Registry, vendorName, vendorURL, inferred, source string) (adopt bool, e *runerrors.Entry) {
+	current := rules.Currency(reg, vendorName)
+	if strings.EqualFold(inferred, current) {
+		return true, nil
+	}
+	_, hasRate := rules.ExchangeRate(reg, inferred)
+	if reg[vendorName].Currency == "" && hasRate {
+		fmt.Printf("💱 %s: prices look like %s (from the %s), not %s; they are converted from the next run\n", vendorName, inferred, source, current)
+		return true, nil
+	}
+	return false, &runerrors.Entry{
+		Vendor: vendorName, Scope: runerrors.ScopePage, URL: vendorURL, Class: runerrors.ClassCurrency,
+		Message: fmt.Sprintf("prices look like %s (from the %s) but are read as %s; add an exchangeRates %s rate and set \"currency\" in %s", inferred, source, current, inferred, config.Filename),
+	}
+}
+
+// filterTested keeps the entries carrying at least one third-party testing
+// certification, preserving order.
+func filterTested(report []models.Analysis) []models.Analysis {
+	tested := []models.Analysis{}
+	for _, a := range report {
+		if len(a.Certifications) > 0 {
+			tested = append(tested, a)
+		}
+	}
+	return tested
+}
+
+// filterCapsuleMg drops the capsule and tablet entries with less than minMg
+// of active per unit (UnitMg), preserving order. Entries without a unit
+// (powders, liquids, overrides) are kept.
+func filterCapsuleMg(report []models.Analysis, minMg float64) []models.Analysis {
+	kept := []models.Analysis{}
+	for _, a := range report {
+		if a.UnitMg == 0 || a.UnitMg >= minMg {
+			kept = append(kept, a)
+		}
+	}
+	return kept
+}
+
+// filterAvailable drops out-of-stock entries, preserving order.
+func filterAvailable(report []models.Analysis) []models.Analysis {
+	available := []models.Analysis{}
+	for _, a := range report {
+		if !a.Unavailable {
+			available = append(available, a)
+		}
+	}
+	return available
+}
+
+// filterStrict drops the entries below the fold (parser.BelowFold),
+// preserving order.
+func filterStrict(report []models.Analysis) []models.Analysis {
+	trusted := []models.Analysis{}
+	for _, a := range report {
+		if !parser.BelowFold(a) {
+			trusted = append(trusted, a)
+		}
 	}
-	return all
+	return trusted
 }
 
-// scrapeOrLoad either scrapes fresh data or loads from the local JSON cache.
-func scrapeOrLoad(v models.Vendor, refresh bool) ([]models.Product, error) {
+// scrapeOrLoad either scrapes fresh data or loads from the local JSON cache,
+// and reports which it did as a manifest status. A full scrape is also
+// archived under data/raw/ for reanalyze. Mock and CSV vendors always read
+// their source file and never touch the cache. A vendor whose schedule
+// excludes today, or whose blackout window the scrape would start in, is
+// loaded from cache, unless it has none yet; a live scrape first waits out
+// the vendor's random refresh jitter. With watched handles, a
+// page-per-product vendor fetches only those pages and merges them into the
+// cache; other vendors are fetched whole.
+func scrapeOrLoad(v models.Vendor, refresh bool, handles []string, graceDays int, fetch liveFetch) ([]models.Product, string, error) {
+	if v.Type == "mock" || v.Type == "csv" {
+		products, err := scraper.FetchProducts(v)
+		return products, manifest.StatusScraped, err
+	}
+
 	shouldScrape := refresh
+	start := time.Now().Add(config.Jitter(v))
+	if shouldScrape && !config.Due(v, start) {
+		fmt.Printf("📅 Skipping %s (schedule %q). Using local JSON.\n", v.Name, v.Schedule)
+		shouldScrape = false
+	} else if window, ok := config.InBlackout(v, start); shouldScrape && ok {
+		fmt.Printf("🌙 Skipping %s (blackout window %s UTC). Using local JSON.\n", v.Name, window)
+		shouldScrape = false
+	}
 	if !shouldScrape {
 		if _, err := os.Stat(storage.VendorFilename(v.Name)); os.IsNotExist(err) {
 			shouldScrape = true
 		}
 	}
 
-	// Cloudflare-blocked vendors rely on manually-maintained JSON
-	if shouldScrape && v.Cloudflare {
-		fmt.Printf("🛡️  Skipping %s (Cloudflare-protected). Using local JSON if available.\n", v.Name)
+	// Cloudflare-blocked vendors rely on manually-maintained JSON, unless
+	// -browser fetches them through headless Chrome
+	if shouldScrape && v.Cloudflare && !v.Browser {
+		fmt.Printf("🛡️  Skipping %s (Cloudflare-protected; -browser scrapes it). Using local JSON if available.\n", v.Name)
 		shouldScrape = false
 	}
 
 	if !shouldScrape {
-		return storage.LoadJSON[[]models.Product](storage.VendorFilename(v.Name))
+		products, err := storage.LoadJSON[[]models.Product](storage.VendorFilename(v.Name))
+		return mergeMagentoOptions(v, products), manifest.StatusCached, err
 	}
 
-	products, err := scraper.FetchProducts(v)
+	if wait := time.Until(start); wait > 0 {
+		fmt.Printf("⏳ Delaying %s by %s (refreshJitter %s)\n", v.Name, wait.Round(time.Second), v.RefreshJitter)
+		time.Sleep(wait)
+	}
+
+	products, pages, err := fetch(v, handles)
 	if err != nil {
-		return nil, fmt.Errorf("scraping: %w", err)
+		return nil, manifest.StatusScraped, fmt.Errorf("scraping: %w", err)
+	}
+	if pages {
+		saveProductPages(v, products)
+		return products, manifest.StatusScraped, nil
+	}
+	today := time.Now().UTC().Format(history.DateLayout)
+
+	// Products this scrape missed stay for the grace period, so one bad
+	// page does not drop them from the rankings
+	previous, _ := storage.LoadJSON[[]models.Product](storage.VendorFilename(v.Name))
+	carried := delisting.Carry(mergeMagentoOptions(v, previous), products, today, graceDays)
+	if carried.Kept > 0 || carried.Expired > 0 {
+		fmt.Printf("👻 %s: kept %d product(s) missing from this scrape as possibly delisted (grace %d day(s)), dropped %d\n", v.Name, carried.Kept, graceDays, carried.Expired)
 	}
 
-	if err := storage.SaveJSON(storage.VendorFilename(v.Name), products); err != nil {
+	if err := storage.SaveJSON(storage.VendorFilename(v.Name), carried.Products); err != nil {
 		fmt.Printf("⚠️ Error saving data for %s: %v\n", v.Name, err)
 	} else {
-		fmt.Printf("✅ Saved %d products for %s\n", len(products), v.Name)
+		fmt.Printf("✅ Saved %d products for %s\n", len(carried.Products), v.Name)
+	}
+	// Kept by date so reanalyze can re-derive this day's history later. The
+	// archive holds the scrape as fetched, without carried products
+	snapshot := rawdata.Snapshot{Vendor: v.Name, Date: today, Source: rawdata.SourceScrape, Products: products}
+	if _, err := rawdata.Save(rawdata.Dir, snapshot); err != nil {
+		fmt.Printf("⚠️ Error archiving raw data for %s: %v\n", v.Name, err)
 	}
 
-	return products, nil
+	return carried.Products, manifest.StatusScraped, nil
 }
 
-// saveReviewQueue extracts flagged products and persists them.
-func saveReviewQueue(report []models.Analysis) {
-	var queue []models.Analysis
-	for _, item := range report {
-		if item.NeedsReview {
-			queue = append(queue, item)
+// liveFetch scrapes a vendor live. With watched handles, a page-per-product
+// vendor fetches only those pages (pages is true); otherwise the whole
+// catalog is fetched. fetchLocal does it in this process, a dispatcher
+// through remote workers.
+type liveFetch func(v models.Vendor, handles []string) (products []models.Product, pages bool, err error)
+
+// fetchLocal scrapes the vendor from this process.
+func fetchLocal(v models.Vendor, handles []string) ([]models.Product, bool, error) {
+	if len(handles) > 0 {
+		if pages, ok, err := scraper.FetchProductPages(v, handles); ok {
+			return pages, true, err
 		}
 	}
-
+	products, err := scraper.FetchProducts(v)
+	return products, false, err
+}
+
+// workerRetry is how long a worker waits after failing to reach the
+// coordinator.
+const workerRetry = 10 * time.Second
+
+// dispatcher is the liveFetch of -distribute: it queues each vendor as a
+// job for the workers and waits for their result.
+type dispatcher struct {
+	q       queue.Queue
+	runID   string
+	timeout time.Duration
+
+	mu      sync.Mutex
+	waiting map[string]chan queue.Result // By job ID
+}
+
+// startCoordinator serves a job queue to workers on addr, behind the serve
+// API token, and returns its dispatcher and a function that stops serving.
+func startCoordinator(addr, token, runID string, timeout time.Duration) (*dispatcher, func(), error) {
+	if token == "" {
+		return nil, nil, fmt.Errorf("-distribute needs %s, the token workers authenticate with", serveTokenEnv)
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("-distribute: %w", err)
+	}
+	q := queue.NewMemory(0)
+	mux := http.NewServeMux()
+	mux.Handle("/queue/", requireToken(token, queue.Handler(q).ServeHTTP))
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go server.Serve(ln)
+	fmt.Printf("🛰️  Handing live scrapes to workers on %s (timeout %s per vendor)\n", ln.Addr(), timeout)
+
+	d := &dispatcher{q: q, runID: runID, timeout: timeout, waiting: map[string]chan queue.Result{}}
+	go d.route()
+	return d, func() { server.Close() }, nil
+}
+
+// route hands each result to the fetch waiting for it. A result nobody
+// waits for any more (the fetch timed out) is dropped.
+func (d *dispatcher) route() {
+	for r := range d.q.Results() {
+		d.mu.Lock()
+		ch := d.waiting[r.JobID]
+		delete(d.waiting, r.JobID)
+		d.mu.Unlock()
+		if ch != nil {
+			ch <- r
+		}
+	}
+}
+
+// fetch queues the vendor for a worker and merges the worker's metrics and
+// failed requests into this run's. Vendors a worker cannot scrape like this
+// process would are scraped here: Browser vendors need the local Chrome,
+// and API-backed ones (priceapi, amazon) are a few calls with this
+// machine's keys.
+func (d *dispatcher) fetch(v models.Vendor, handles []string) ([]models.Product, bool, error) {
+	if v.Browser || v.Type == "priceapi" || v.Type == "amazon" {
+		return fetchLocal(v, handles)
+	}
+	job := queue.Job{ID: d.runID + "/" + v.Name, Vendor: v, Handles: handles, DiscoverWords: v.DiscoverWords}
+	ch := make(chan queue.Result, 1)
+	d.mu.Lock()
+	d.waiting[job.ID] = ch
+	d.mu.Unlock()
+	if err := d.q.Push(job); err != nil {
+		return nil, false, err
+	}
+
+	select {
+	case r := <-ch:
+		scraper.RecordVendorRun(v.Name, r.Run)
+		if r.Error != "" {
+			fmt.Printf("🛰️  %s failed on worker %s\n", v.Name, r.Worker)
+			return nil, false, workerError(r.Error)
+		}
+		fmt.Printf("🛰️  %s scraped by worker %s (%d product(s))\n", v.Name, r.Worker, len(r.Products))
+		return r.Products, r.Pages, nil
+	case <-time.After(d.timeout):
+		d.mu.Lock()
+		delete(d.waiting, job.ID)
+		d.mu.Unlock()
+		return nil, false, fmt.Errorf("no worker finished %s within %s (-distribute-timeout)", v.Name, d.timeout)
+	}
+}
+
+// workerError is a scrape error sent back by a worker. Only its message
+// crosses the wire; it still matches the scraper's sentinel error it names,
+// so the vendor gets the same error class as a local failure.
+type workerError string
+
+func (e workerError) Error() string { return string(e) }
+
+func (e workerError) Unwrap() error {
+	for _, sentinel := range []error{scraper.ErrCircuitOpen, scraper.ErrBudgetExhausted, scraper.ErrDisallowed} {
+		if strings.Contains(string(e), sentinel.Error()) {
+			return sentinel
+		}
+	}
+	return nil
+}
+
+// runWorker implements `worker -coordinator URL [-name name] [-http-cache
+// dir] [-ignore-robots] [-once]`: it scrapes the vendors a run started with
+// -distribute hands out, until interrupted (or, with -once, until no job
+// comes within a claim). It authenticates with $SERVE_API_TOKEN and returns
+// the process exit code (2 for usage errors).
+func runWorker(args []string) int {
+	host, _ := os.Hostname()
+	fs := flag.NewFlagSet("worker", flag.ContinueOnError)
+	coordinator := fs.String("coordinator", "", "Base URL of the run started with -distribute, e.g. http://coordinator:9090")
+	name := fs.String("name", host, "Worker name shown in the coordinator's log")
+	httpCache := fs.String("http-cache", filepath.Join(storage.DataDir, "cache"), "Cache pages fetched with an ETag or Last-Modified in `dir` (\"\" = off)")
+	ignoreRobots := fs.Bool("ignore-robots", false, "Fetch pages even when the vendor's robots.txt disallows them, and ignore its Crawl-delay")
+	once := fs.Bool("once", false, "Exit when no job comes within one claim instead of waiting for the next run")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *coordinator == "" || fs.NArg() != 0 {
+		fmt.Println("usage: worker -coordinator URL [-name name] [-http-cache dir] [-ignore-robots] [-once]")
+		return 2
+	}
+	token := os.Getenv(serveTokenEnv)
+	if token == "" {
+		fmt.Printf("❌ set %s to the coordinator's token\n", serveTokenEnv)
+		return 2
+	}
+	scraper.CacheDir = *httpCache
+	scraper.IgnoreRobots = *ignoreRobots
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	client := queue.NewClient(*coordinator, token)
+	fmt.Printf("🛰️  Worker %s waiting for jobs from %s\n", *name, *coordinator)
+	for ctx.Err() == nil {
+		job, ok, err := client.Claim(ctx, *name)
+		switch {
+		case ctx.Err() != nil:
+		case err != nil:
+			fmt.Printf("⚠️ %v; retrying in %s\n", err, workerRetry)
+			select {
+			case <-ctx.Done():
+			case <-time.After(workerRetry):
+			}
+		case !ok && *once:
+			return 0
+		case ok:
+			work(client, *name, job)
+		}
+	}
+	return 0
+}
+
+// work scrapes one job and sends back its result. The vendor's per-run
+// scraper state is cleared first, so a vendor scraped again in a later run
+// starts fresh.
+func work(client *queue.Client, worker string, job queue.Job) {
+	job.Vendor.DiscoverWords = job.DiscoverWords
+	scraper.TakeVendorRun(job.Vendor.Name)
+	fmt.Printf("🔧 Scraping %s\n", job.Vendor.Name)
+	products, pages, err := fetchLocal(job.Vendor, job.Handles)
+	result := queue.Result{JobID: job.ID, Worker: worker, Products: products, Pages: pages, Run: scraper.TakeVendorRun(job.Vendor.Name)}
+	if err != nil {
+		fmt.Printf("❌ %s: %v\n", job.Vendor.Name, err)
+		result.Products, result.Error = nil, err.Error()
+	}
+	switch err := client.Complete(context.Background(), result); {
+	case errors.Is(err, queue.ErrUnknownJob):
+		fmt.Printf("   %s was already answered (another worker, or the run ended)\n", job.Vendor.Name)
+	case err != nil:
+		fmt.Printf("⚠️ Could not send %s's result: %v\n", job.Vendor.Name, err)
+	default:
+		fmt.Printf("✅ Sent %d product(s) for %s\n", len(products), job.Vendor.Name)
+	}
+}
+
+// saveProductPages merges freshly fetched product pages into the vendor's
+// cache: cached products with a fetched handle are replaced, the rest kept.
+func saveProductPages(v models.Vendor, pages []models.Product) {
+	fetched := make(map[string]bool, len(pages))
+	for _, p := range pages {
+		fetched[p.Handle] = true
+	}
+	cached, err := storage.LoadJSON[[]models.Product](storage.VendorFilename(v.Name))
+	if err != nil && !os.IsNotExist(err) {
+		fmt.Printf("⚠️ Could not read cached data for %s (%v); not updating it\n", v.Name, err)
+		return
+	}
+	merged := []models.Product{}
+	for _, p := range cached {
+		if !fetched[p.Handle] {
+			merged = append(merged, p)
+		}
+	}
+	merged = append(merged, pages...)
+	if err := storage.SaveJSON(storage.VendorFilename(v.Name), merged); err != nil {
+		fmt.Printf("⚠️ Error saving data for %s: %v\n", v.Name, err)
+	} else {
+		fmt.Printf("✅ Updated %d watched product(s) for %s\n", len(pages), v.Name)
+	}
+}
+
+// trackedVendors keeps the vendors the watchlist names, warning about
+// watched vendor names that are not configured.
+func trackedVendors(vendors []models.Vendor, tracked watchlist.Watchlist) []models.Vendor {
+	names := tracked.Vendors()
+	var kept []models.Vendor
+	for _, v := range vendors {
+		if slices.Contains(names, v.Name) {
+			kept = append(kept, v)
+		}
+	}
+	for _, name := range names {
+		if !slices.ContainsFunc(kept, func(v models.Vendor) bool { return v.Name == name }) {
+			fmt.Printf("⚠️ Watchlist vendor %q is not configured\n", name)
+		}
+	}
+	return kept
+}
+
+// unmatchedWatches returns the watchlist entries with no product (or no
+// variant, for variant entries) among this run's products.
+func unmatchedWatches(tracked watchlist.Watchlist, vendorProducts []vendorProduct) []watchlist.Entry {
+	var missing []watchlist.Entry
+	for _, e := range tracked {
+		found := false
+		for _, vp := range vendorProducts {
+			if vp.Vendor != e.Vendor || vp.Product.Handle != e.Handle {
+				continue
+			}
+			found = e.Variant == "" || slices.ContainsFunc(vp.Product.Variants, func(v models.Variant) bool { return v.Title == e.Variant })
+			if found {
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, e)
+		}
+	}
+	return missing
+}
+
+// saveReviewQueue extracts flagged products and persists them. Flags the
+// operator already confirmed in review_decisions.json are left out; dismissed
+// ones never reach the report flagged. It returns the path and whether the
+// file was written.
+func saveReviewQueue(report []models.Analysis, decisions review.Decisions) (string, bool) {
+	var queue []models.Analysis
+	confirmed := 0
+	for _, item := range report {
+		if !item.NeedsReview {
+			continue
+		}
+		if decisions.Lookup(item.Vendor, item.Handle, item.ReviewReason) == review.Confirm {
+			confirmed++
+			continue
+		}
+		queue = append(queue, item)
+	}
+
 	path := filepath.Join("data", "needs_review.json")
 	data, err := json.MarshalIndent(queue, "", "  ")
 	if err != nil {
 		fmt.Printf("⚠️ Error marshalling review queue: %v\n", err)
-		return
+		return path, false
 	}
 	if err := os.WriteFile(path, data, 0644); err != nil {
 		fmt.Printf("⚠️ Error saving review queue: %v\n", err)
-		return
+		return path, false
+	}
+	fmt.Printf("🔍 Saved review queue (%d flagged, %d already confirmed) to data/needs_review.json\n", len(queue), confirmed)
+	return path, true
+}
+
+// currentCatalog groups this run's products by vendor for changes.Compute.
+// Every vendor that did not fail gets an entry, even with no products, so a
+// catalog that emptied out is reported as delisted. Products carried through
+// the delisting grace period are left out: the feed reports what the
+// scrapes saw, so it announces them on the first scrape that missed them.
+func currentCatalog(vendorProducts []vendorProduct, statuses []manifest.VendorStatus) map[string][]models.Product {
+	catalog := make(map[string][]models.Product, len(statuses))
+	for _, s := range statuses {
+		if s.Status != manifest.StatusFailed {
+			catalog[s.Vendor] = nil
+		}
+	}
+	for _, vp := range vendorProducts {
+		if vp.Product.MissingSince != "" {
+			continue
+		}
+		catalog[vp.Vendor] = append(catalog[vp.Vendor], vp.Product)
+	}
+	return catalog
+}
+
+// printBackInStock announces restocks of watched variants.
+func printBackInStock(events []changes.AvailabilityChange) {
+	for _, e := range events {
+		line := fmt.Sprintf("🔔 Back in stock: %s — %s (%s)", e.Vendor, e.Title, e.Variant)
+		if e.OutOfStockSince != "" {
+			line += ", sold out since " + e.OutOfStockSince
+		}
+		fmt.Println(line)
+	}
+}
+
+// printDearBundles warns about multi-packs and bulk tiers that cost more
+// per gram than buying the single unit.
+func printDearBundles(dearer []models.Analysis) {
+	for _, a := range dearer {
+		fmt.Printf("📦 Bundle dearer than singles: %s — %s (%d-pack, %+.1f%% per gram, %+.2f per unit)\n",
+			a.Vendor, a.Name, a.PackSize, -a.BundleSavingPct, -a.BundleSaving)
+	}
+}
+
+// saveChanges persists the run's change set to data/changes.json. It returns
+// the path and whether the file was written.
+func saveChanges(cs changes.ChangeSet) (string, bool) {
+	if err := storage.SaveJSON(changes.Filename, cs); err != nil {
+		fmt.Printf("⚠️ Error saving changes: %v\n", err)
+		return changes.Filename, false
+	}
+	fmt.Printf("🆕 Saved changes (%d new, %d delisted, %d price, %d availability, %d watched restock(s)) to data/changes.json\n",
+		len(cs.NewProducts), len(cs.DelistedProducts), len(cs.PriceChanges), len(cs.AvailabilityChanges), len(cs.BackInStock))
+	return changes.Filename, true
+}
+
+// saveErrors writes the run's errors to data/errors.json (an empty list on a
+// clean run). It returns the path and whether the file was written.
+func saveErrors(today string, entries []runerrors.Entry) (string, bool) {
+	if err := storage.SaveJSON(runerrors.Filename, runerrors.Report{Date: today, Errors: entries}); err != nil {
+		fmt.Printf("⚠️ Error saving error report: %v\n", err)
+		return runerrors.Filename, false
+	}
+	fmt.Printf("🧯 Saved %d error(s) to data/errors.json\n", len(entries))
+	return runerrors.Filename, true
+}
+
+// saveWidget writes the compact top-N-per-supplement file for embeds to
+// data/widget.json. It returns the path and whether the file was written.
+func saveWidget(report []models.Analysis, vendors []models.Vendor, tracked taxonomy.Registry, today string, top int) (string, bool) {
+	w := widget.Build(report, vendors, tracked, today, top)
+	data, err := widget.Marshal(w, widget.MaxBytes)
+	if err == nil {
+		err = os.WriteFile(widget.Filename, data, 0644)
+	}
+	if err != nil {
+		fmt.Printf("⚠️ Error saving widget: %v\n", err)
+		return widget.Filename, false
+	}
+	fmt.Printf("🧩 Saved widget (%d bytes) to data/widget.json\n", len(data))
+	return widget.Filename, true
+}
+
+// saveSplitReports writes the report of every tracked supplement and the
+// index of those files (see split.Save). It returns the paths written and
+// whether all of them were.
+func saveSplitReports(report []models.Analysis, tracked taxonomy.Registry, today, runID string) ([]string, bool) {
+	paths, err := split.Save(split.Dir, report, tracked, today, runID)
+	if err != nil {
+		fmt.Printf("⚠️ Error saving per-supplement reports: %v\n", err)
+		return paths, false
+	}
+	fmt.Printf("🗂️  Saved %d per-supplement report(s) and their index to %s\n", len(tracked), filepath.Join(split.Dir, split.IndexName))
+	return paths, true
+}
+
+// saveVendorSummary writes data/vendor_summary.json, one card per vendor of
+// the run. Cached vendors keep the scrape time the previous summary gave
+// them. It returns the path and whether the file was written.
+func saveVendorSummary(report []models.Analysis, quality []parser.VendorQuality, statuses []manifest.VendorStatus, tracked taxonomy.Registry, today, runID string, startedAt time.Time) (string, bool) {
+	previous, err := summary.Load(summary.Filename)
+	if err != nil {
+		fmt.Printf("⚠️ Warning: %v. Cached vendors lose their last scrape time.\n", err)
+	}
+	s := summary.Summary{Date: today, RunID: runID, Vendors: summary.Build(report, quality, statuses, tracked, previous, startedAt)}
+	if err := storage.SaveJSON(summary.Filename, s); err != nil {
+		fmt.Printf("⚠️ Error saving vendor summary: %v\n", err)
+		return summary.Filename, false
+	}
+	fmt.Printf("🏪 Saved vendor summary (%d vendors) to data/vendor_summary.json\n", len(s.Vendors))
+	return summary.Filename, true
+}
+
+// loadPreviousReport reads the report written by the last run, or nil when
+// there is none or it cannot be read.
+func loadPreviousReport() []models.Analysis {
+	if _, err := os.Stat(reportPath); err != nil {
+		return nil
+	}
+	previous, err := storage.LoadJSON[[]models.Analysis](reportPath)
+	if err != nil {
+		fmt.Printf("⚠️ Error loading previous report (%v). No rank changes.\n", err)
+		return nil
+	}
+	return previous
+}
+
+// loadPreviousAudit reads the audit report written by the last -audit run.
+// ok is false when there is none (first run) or it cannot be read.
+func loadPreviousAudit() ([]parser.AuditResult, bool) {
+	path := filepath.Join("data", "audit_report.json")
+	if _, err := os.Stat(path); err != nil {
+		return nil, false
+	}
+	previous, err := storage.LoadJSON[[]parser.AuditResult](path)
+	if err != nil {
+		fmt.Printf("⚠️ Error loading previous audit report: %v\n", err)
+		return nil, false
+	}
+	return previous, true
+}
+
+// notifyContenders alerts the operator to audit gaps that could take first
+// place for their supplement once an override is written, posting them under
+// lim.
+func notifyContenders(gaps []parser.AuditResult, lim alerts.Limits) {
+	var pending []alerts.Alert
+	for _, g := range gaps {
+		pending = append(pending, alerts.Alert{
+			Kind:   alerts.KindAuditContender,
+			Vendor: g.Vendor,
+			Handle: g.Handle,
+			Message: fmt.Sprintf("Audit gap could beat #1: %s — %s at ~$%.2f/g vs %s at $%.2f/g. Add an override for %q.",
+				g.Vendor, g.Title, g.EstimatedCostPerGram, g.Leader, g.LeaderCostPerGram, g.Handle),
+		})
+	}
+	if err := alerts.Notify(pending, os.Getenv(alerts.WebhookEnv), lim); err != nil {
+		fmt.Printf("⚠️ Error sending alerts: %v\n", err)
+	}
+}
+
+// saveAuditReport persists audit gaps to data/audit_report.json. An empty run
+// writes [] so consumers can tell "no gaps" from "audit never ran". It returns
+// the path and whether the file was written.
+func saveAuditReport(results []parser.AuditResult) (string, bool) {
+	if results == nil {
+		results = []parser.AuditResult{}
 	}
-	fmt.Printf("🔍 Saved review queue (%d flagged) to data/needs_review.json\n", len(queue))
+	path := filepath.Join("data", "audit_report.json")
+	if err := storage.SaveJSON(path, results); err != nil {
+		fmt.Printf("⚠️ Error saving audit report: %v\n", err)
+		return path, false
+	}
+	fmt.Printf("🔍 Saved audit report (%d gap(s)) to data/audit_report.json\n", len(results))
+	return path, true
 }
 
-func printTable(data []models.Analysis) {
+func printTable(data []models.Analysis, loc locale.Locale) {
+	// The move column appears only when a previous report ranked something,
+	// the native price column only when a vendor is priced in another
+	// currency, the quality-adjusted column only when a score table is in
+	// use, the score column only when rankWeights reorders the report
+	moved, foreign, scored, ranked := false, false, false, false
+	for _, row := range data {
+		moved = moved || row.PreviousRank > 0
+		foreign = foreign || row.NativeCurrency != ""
+		scored = scored || row.QualityScore > 0
+		ranked = ranked || row.RankScore != row.EffectiveCost
+	}
+	header := "\nRANK\tVENDOR\tPRODUCT (Truncated)\tTYPE\tmg/CAP\tPRICE"
+	rule := "----\t------\t-------------------\t-----\t------\t-----"
+	if moved {
+		header = "\nRANK\tMOVE\tVENDOR\tPRODUCT (Truncated)\tTYPE\tmg/CAP\tPRICE"
+		rule = "----\t----\t------\t-------------------\t-----\t------\t-----"
+	}
+	if foreign {
+		header += "\tNATIVE PRICE"
+		rule += "\t------------"
+	}
+	header += "\tSALE\tACTIVE g\tGROSS g\t$/GRAM\tTRUE COST (Eff.)\tPCTL\t×CHEAPEST"
+	rule += "\t----\t--------\t-------\t------\t----------------\t----\t---------"
+	if scored {
+		header += "\tQUALITY-ADJ (score)"
+		rule += "\t-------------------"
+	}
+	if ranked {
+		header += "\tRANK SCORE"
+		rule += "\t----------"
+	}
+
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "\nRANK\tVENDOR\tPRODUCT (Truncated)\tTYPE\tPRICE\tACTIVE g\tGROSS g\t$/GRAM\tTRUE COST (Eff.)")
-	fmt.Fprintln(w, "----\t------\t-------------------\t-----\t-----\t--------\t-------\t------\t----------------")
+	fmt.Fprintln(w, header)
+	fmt.Fprintln(w, rule)
 
 	const (
 		reset = "\033[0m"
@@ -238,6 +1513,10 @@ func printTable(data []models.Analysis) {
 	)
 
 	for i, row := range data {
+		if parser.BelowFold(row) && (i == 0 || !parser.BelowFold(data[i-1])) {
+			// Every cell present, so the columns stay aligned across the fold
+			fmt.Fprintln(w, "~~~~\t\tBELOW THE FOLD: flagged, low-confidence or out of stock"+strings.Repeat("\t", strings.Count(header, "\t")-2))
+		}
 		color := reset
 		if row.EffectiveCost < 0.5 {
 			color = red
@@ -247,11 +1526,1394 @@ func printTable(data []models.Analysis) {
 
 		grossCol := "—"
 		if row.GrossGrams > 0 {
-			grossCol = fmt.Sprintf("%.1fg", row.GrossGrams)
+			grossCol = loc.Grams(row.GrossGrams)
+		}
+
+		// Active mg per capsule/tablet; "—" for powders and liquids
+		unitCol := "—"
+		if row.UnitMg > 0 {
+			unitCol = loc.Number(row.UnitMg, 0)
+		}
+
+		// A minimum order shows the real entry price, e.g. "$20.00 (3× = $60.00)"
+		priceCol := loc.Money(row.Price)
+		if row.MinOrderQty > 1 {
+			priceCol += fmt.Sprintf(" (%d× = %s)", row.MinOrderQty, loc.Money(row.EntryPrice))
+		}
+		// What checkout charges, e.g. "40.00 EUR"; "—" for report-currency vendors
+		if foreign {
+			priceCol += "\t" + nativePrice(row, loc)
+		}
+
+		// A trailing "*" marks a perpetual sale (compare-at price never charged)
+		saleCol := "—"
+		if row.DiscountPct > 0 {
+			saleCol = loc.Percent(-row.DiscountPct)
+			if row.PerpetualSale {
+				saleCol += "*"
+			}
+		}
+
+		// Spread within the supplement, e.g. "95" and "1.8×"; "—" outside any
+		spreadCol := "\t—\t—"
+		if row.Supplement != "" {
+			spreadCol = fmt.Sprintf("\t%s\t%s×", loc.Number(row.CostPercentile, 0), loc.Number(row.CostRatio, 1))
+		}
+
+		qualityCol := ""
+		if scored {
+			qualityCol = "\t—"
+			if row.QualityScore > 0 {
+				qualityCol = fmt.Sprintf("\t%s (%s)", loc.Money(row.QualityAdjustedCost), loc.Number(row.QualityScore, 0))
+			}
+		}
+
+		rankCol := ""
+		if ranked {
+			rankCol = "\t" + loc.Number(row.RankScore, 4)
+		}
+
+		posCol := strconv.Itoa(i + 1)
+		if moved {
+			posCol += "\t" + rankMove(row)
 		}
 
-		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t$%.2f\t%.1fg\t%s\t$%.2f\t%s$%.2f%s\n",
-			i+1, row.Vendor, row.Name, row.Type, row.Price, row.ActiveGrams, grossCol, row.CostPerGram, color, row.EffectiveCost, reset)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s%s%s%s%s%s\n",
+			posCol, vendorLabel(row), row.Name, loc.Type(row.Type), unitCol, priceCol, saleCol, loc.Grams(row.ActiveGrams), grossCol,
+			loc.Money(row.CostPerGram), color, loc.Money(row.EffectiveCost), reset, spreadCol, qualityCol, rankCol)
+	}
+	w.Flush()
+}
+
+// rankMove formats an entry's movement within its supplement since the
+// previous report: "▲3", "▼1", "=" when unchanged, "new" when the previous
+// report did not rank it, "—" when it is not ranked now.
+func rankMove(row models.Analysis) string {
+	switch {
+	case row.SupplementRank == 0:
+		return "—"
+	case row.PreviousRank == 0:
+		return "new"
+	case row.RankChange > 0:
+		return fmt.Sprintf("▲%d", row.RankChange)
+	case row.RankChange < 0:
+		return fmt.Sprintf("▼%d", -row.RankChange)
+	}
+	return "="
+}
+
+// vendorLabel names where an entry is sold: the vendor, or "Brand via
+// Vendor" for a retailer's listing of another brand (iHerb).
+func vendorLabel(row models.Analysis) string {
+	if row.Brand == "" {
+		return row.Vendor
+	}
+	return row.Brand + " via " + row.Vendor
+}
+
+// nativePrice formats an entry's checkout price in its vendor's currency,
+// e.g. "40.00 EUR", or "—" when it is priced in the report currency.
+func nativePrice(row models.Analysis, loc locale.Locale) string {
+	if row.NativeCurrency == "" {
+		return "—"
+	}
+	return loc.Number(row.NativePrice, 2) + " " + row.NativeCurrency
+}
+
+// printPareto prints each supplement's Pareto front, cheapest first: moving
+// down a section, every extra dollar per gram buys more trust.
+func printPareto(data []models.Analysis, fronts []pareto.Frontier, loc locale.Locale) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, f := range fronts {
+		fmt.Fprintf(w, "\n🎯 %s Pareto front (cost vs trust)\n", strings.ToUpper(f.Key))
+		fmt.Fprintln(w, "VENDOR\tPRODUCT\tCOST (Bioavail.)\tTRUST\tCERTIFICATIONS / SCORE")
+		for _, i := range f.Entries {
+			row := data[i]
+			basis := strings.Join(row.Certifications, ", ")
+			if row.QualityScore > 0 {
+				if basis != "" {
+					basis += "; "
+				}
+				basis += strings.TrimSpace(row.QualitySource + " " + loc.Number(row.QualityScore, 0))
+			}
+			if basis == "" {
+				basis = "—"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", vendorLabel(row), row.Name, loc.Money(pareto.Cost(row)),
+				loc.Number(parser.Trust(row), 2), basis)
+		}
 	}
 	w.Flush()
-}
\ No newline at end of file
+}
+
+// runBest implements `best <supplement> [-type t]`: one line naming the
+// top-ranked product of a supplement in the latest report, for shell aliases,
+// cron jobs and chat bots. Nothing is scraped or analyzed. Errors go to
+// stderr so stdout is only ever the answer. It returns the process exit
+// code (2 for usage errors, 1 when there is no report or no match).
+func runBest(args []string) int {
+	fs := flag.NewFlagSet("best", flag.ContinueOnError)
+	productType := fs.String("type", "", "Only products of this type (powder, capsules, tablets, liquid, gel, multi-pack)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	// Flags may follow the supplement (`best nmn --type powder`)
+	var positional []string
+	for fs.NArg() > 0 {
+		positional = append(positional, fs.Arg(0))
+		if err := fs.Parse(fs.Args()[1:]); err != nil {
+			return 2
+		}
+	}
+	if len(positional) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: best <supplement> [-type powder|capsules|tablets|liquid|gel|multi-pack]")
+		return 2
+	}
+	supplements, err := taxonomy.Load(taxonomy.Filename)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	supplement := supplementKey(supplements, positional[0])
+	if supplement == "" {
+		fmt.Fprintf(os.Stderr, "❌ Unknown supplement %q (want one of: %s)\n", positional[0], strings.Join(supplements.Names(), ", "))
+		return 2
+	}
+
+	report, err := storage.LoadJSON[[]models.Analysis](reportPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Could not load %s (run go run cmd/main.go first): %v\n", reportPath, err)
+		return 1
+	}
+	a, ok := bestEntry(report, supplements, supplement, *productType)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "❌ No %s %s above the fold in %s\n", supplement, strings.TrimSpace(*productType+" products"), reportPath)
+		return 1
+	}
+	vendors, err := config.Load(config.Filename)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️ %v (no product link)\n", err)
+	}
+	base := ""
+	for _, v := range vendors {
+		if v.Name == a.Vendor {
+			base = v.URL
+		}
+	}
+	fmt.Println(formatBest(a, widget.ProductURL(base, a.Handle)))
+	return 0
+}
+
+// supplementKey resolves a supplement name to its name in reg, by name or
+// alias ("trimethylglycine" → "tmg"), case-insensitively. "" if unknown.
+func supplementKey(reg taxonomy.Registry, name string) string {
+	if s, ok := reg.Lookup(name); ok {
+		return s.Name
+	}
+	return ""
+}
+
+// bestEntry returns the first one-time entry of the supplement above the
+// fold (the report is sorted by rank) whose type matches productType,
+// case-insensitively and ignoring a plural "s" ("capsule" = "Capsules").
+// An empty productType matches every type. Entries are assigned to one
+// supplement like the spread columns (a blend goes to the one it names
+// first); reports written before those carry no supplement and are grouped
+// the same way here, by reg.
+func bestEntry(report []models.Analysis, reg taxonomy.Registry, supplement, productType string) (models.Analysis, bool) {
+	for _, a := range report {
+		if canAnswer(reg, a, supplement, productType) {
+			return a, true
+		}
+	}
+	return models.Analysis{}, false
+}
+
+// canAnswer reports whether a may answer `best` or a badge for the
+// supplement and type (see bestEntry).
+func canAnswer(reg taxonomy.Registry, a models.Analysis, supplement, productType string) bool {
+	if a.IsSubscription || parser.BelowFold(a) {
+		return false
+	}
+	key := a.Supplement
+	if key == "" {
+		key = widget.GroupOf(reg, a)
+	}
+	singular := func(s string) string { return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(s)), "s") }
+	return key == supplement && (productType == "" || singular(a.Type) == singular(productType))
+}
+
+// formatBest renders the answer line, e.g. "NMN Powder (100 Grams) — Do Not
+// Age — $39.95 — $0.40/g — https://…". When bioavailability, certifications
+// or quality change the ranking cost, it follows the $/g as "(true $0.27/g)".
+func formatBest(a models.Analysis, url string) string {
+	perGram := fmt.Sprintf("$%.2f/g", a.CostPerGram)
+	if math.Abs(a.EffectiveCost-a.CostPerGram) >= 0.005 {
+		perGram += fmt.Sprintf(" (true $%.2f/g)", a.EffectiveCost)
+	}
+	line := fmt.Sprintf("%s — %s — $%.2f — %s", a.Name, a.Vendor, a.Price, perGram)
+	if url != "" {
+		line += " — " + url
+	}
+	return line
+}
+
+// serveTokenEnv names the environment variable holding serve's API token.
+// It is read from the environment rather than a flag so it stays out of
+// process listings and shell history.
+const serveTokenEnv = "SERVE_API_TOKEN"
+
+// serveOptions configures the serve endpoints beyond the report.
+type serveOptions struct {
+	Token   string // Bearer token of the protected endpoints; "" disables them
+	Webhook string // alerts.WebhookEnv, for POST /api/alerts/test
+
+	// Supplements resolves /badge/{supplement} by name or alias.
+	Supplements taxonomy.Registry
+
+	// /readyz reads the last run from Manifest and each vendor's last live
+	// scrape from Summary; data older than MaxAge is stale.
+	Manifest string
+	Summary  string
+	MaxAge   time.Duration
+}
+
+// defaultMaxAge is the -max-age default: the CI workflow runs daily, so a
+// report two days old means a run was missed.
+const defaultMaxAge = 48 * time.Hour
+
+// runServe implements `serve [-addr host:port] [-max-age duration]
+// [-cors-origins list]`: an HTTP server over the latest saved report, for
+// READMEs and dashboards. Nothing is scraped; the report is re-read whenever
+// the pipeline rewrites it. It returns the process exit code (2 for usage
+// errors, 1 when the server fails).
+func runServe(args []string) int {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := fs.String("addr", ":8080", "Listen address")
+	maxAge := fs.Duration("max-age", defaultMaxAge, "Age of the last run, or of a vendor's last live scrape, past which /readyz reports it stale")
+	corsFlag := fs.String("cors-origins", "", "Comma-separated origins allowed to call the API from a browser, e.g. https://example.com (* = any)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 0 {
+		fmt.Println("usage: serve [-addr host:port] [-max-age duration] [-cors-origins list]")
+		return 2
+	}
+	origins, err := parseOrigins(*corsFlag)
+	if err != nil {
+		fmt.Printf("❌ -cors-origins: %v\n", err)
+		return 2
+	}
+
+	supplements, err := taxonomy.Load(taxonomy.Filename)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return 1
+	}
+	opts := serveOptions{
+		Token: os.Getenv(serveTokenEnv), Webhook: os.Getenv(alerts.WebhookEnv), Supplements: supplements,
+		Manifest: manifest.Filename, Summary: summary.Filename, MaxAge: *maxAge,
+	}
+	cache := &reportCache{path: reportPath}
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           withCORS(newServeMux(cache.load, runs.Dir, opts), origins),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	fmt.Printf("🌐 Serving %s on %s (/badge/{supplement}, /api/report, /api/runs, /api/diff, /api/alerts/test, /healthz, /readyz)\n", reportPath, *addr)
+	if opts.Token == "" {
+		fmt.Printf("🔒 %s is not set: POST /api/alerts/test is disabled\n", serveTokenEnv)
+	}
+	if len(origins) > 0 {
+		fmt.Printf("🌍 CORS origins: %s\n", strings.Join(origins, ", "))
+	}
+	if err := server.ListenAndServe(); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// reportCache holds the decoded report, reloading it when the file's
+// modification time changes.
+type reportCache struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	report  []models.Analysis
+}
+
+// load returns the current report.
+func (c *reportCache) load() ([]models.Analysis, error) {
+	info, err := os.Stat(c.path)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.report == nil || !info.ModTime().Equal(c.modTime) {
+		report, err := storage.LoadJSON[[]models.Analysis](c.path)
+		if err != nil {
+			return nil, err
+		}
+		c.report, c.modTime = report, info.ModTime()
+	}
+	return c.report, nil
+}
+
+// shieldsBadge is the shields.io endpoint badge schema
+// (https://shields.io/badges/endpoint-badge).
+type shieldsBadge struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Label         string `json:"label"`
+	Message       string `json:"message"`
+	Color         string `json:"color,omitempty"`
+	IsError       bool   `json:"isError,omitempty"`
+	CacheSeconds  int    `json:"cacheSeconds,omitempty"`
+}
+
+// badgeCacheSeconds asks shields.io to cache a badge for an hour; the
+// report changes once a day.
+const badgeCacheSeconds = 3600
+
+// newServeMux routes the serve endpoints over the report returned by load
+// and the run archive under runsDir:
+//
+//	GET /badge/{supplement}[?type=powder]  shields.io JSON for the cheapest entry
+//	GET /api/report[?strict=true]          the report; strict drops entries below the fold
+//	    [&include_unavailable=true]         keeps out-of-stock entries (reports run with -include-unavailable)
+//	GET /api/runs                          the archived run IDs, oldest first
+//	GET /api/diff?from=<runID>&to=<runID>  the change set between two archived runs
+//	POST /api/alerts/test                  posts a test alert to opts.Webhook (needs opts.Token)
+//	GET /healthz                           liveness: 200 while the server answers
+//	GET /readyz                            readiness: report and per-vendor data freshness (see readiness)
+//
+// Endpoints that change state or send alerts go through requireToken.
+func newServeMux(load func() ([]models.Analysis, error), runsDir string, opts serveOptions) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /badge/{supplement}", func(w http.ResponseWriter, r *http.Request) {
+		supplement := supplementKey(opts.Supplements, r.PathValue("supplement"))
+		productType := r.URL.Query().Get("type")
+		label := strings.TrimSpace("cheapest " + supplementLabel(supplement) + " " + strings.ToLower(productType))
+		if supplement == "" {
+			writeJSON(w, http.StatusNotFound, shieldsBadge{SchemaVersion: 1, Label: "cheapest", Message: "unknown supplement", IsError: true})
+			return
+		}
+		report, err := load()
+		if err != nil {
+			writeJSON(w, http.StatusServiceUnavailable, shieldsBadge{SchemaVersion: 1, Label: label, Message: "no report", IsError: true})
+			return
+		}
+		writeJSON(w, http.StatusOK, priceBadge(report, opts.Supplements, supplement, productType, label))
+	})
+	mux.HandleFunc("GET /api/report", func(w http.ResponseWriter, r *http.Request) {
+		report, err := load()
+		if err != nil {
+			http.Error(w, "no report: run the pipeline first", http.StatusServiceUnavailable)
+			return
+		}
+		if include, _ := strconv.ParseBool(r.URL.Query().Get("include_unavailable")); !include {
+			report = filterAvailable(report)
+		}
+		if strict, _ := strconv.ParseBool(r.URL.Query().Get("strict")); strict {
+			report = filterStrict(report)
+		}
+		writeJSON(w, http.StatusOK, report)
+	})
+	mux.HandleFunc("GET /api/runs", func(w http.ResponseWriter, r *http.Request) {
+		ids, err := runs.IDs(runsDir)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, ids)
+	})
+	mux.HandleFunc("GET /api/diff", func(w http.ResponseWriter, r *http.Request) {
+		fromID, toID := r.URL.Query().Get("from"), r.URL.Query().Get("to")
+		if !runs.ValidID(fromID) || !runs.ValidID(toID) {
+			http.Error(w, "from and to must be run IDs (see /api/runs)", http.StatusBadRequest)
+			return
+		}
+		var pair [2]runs.Run
+		for i, id := range []string{fromID, toID} {
+			run, err := runs.Load(runsDir, id)
+			if errors.Is(err, runs.ErrNotFound) {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			} else if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			pair[i] = run
+		}
+		writeJSON(w, http.StatusOK, changes.Diff(pair[0].Date, pair[0].Report, pair[1].Date, pair[1].Report))
+	})
+	mux.Handle("POST /api/alerts/test", requireToken(opts.Token, func(w http.ResponseWriter, r *http.Request) {
+		if opts.Webhook == "" {
+			http.Error(w, alerts.WebhookEnv+" is not set", http.StatusServiceUnavailable)
+			return
+		}
+		test := alerts.Alert{Kind: alerts.KindTest, Message: "Test alert from longevity-rank serve"}
+		if err := alerts.Notify([]alerts.Alert{test}, opts.Webhook, alerts.Limits{}); err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		ready := readiness(load, opts, time.Now())
+		status := http.StatusOK
+		if ready.Status == readyUnavailable || ready.Status == readyStale {
+			status = http.StatusServiceUnavailable
+		}
+		writeJSON(w, status, ready)
+	})
+	return mux
+}
+
+// Readiness states. Unavailable and stale answer 503, so an orchestrator
+// stops routing to the server; degraded still serves.
+const (
+	readyOK          = "ready"
+	readyDegraded    = "degraded"    // Some vendors were not scraped live within MaxAge
+	readyStale       = "stale"       // The last run finished more than MaxAge ago
+	readyUnavailable = "unavailable" // No readable report or run manifest
+)
+
+// readyStatus is the /readyz body.
+type readyStatus struct {
+	Status        string        `json:"status"`
+	Error         string        `json:"error,omitempty"`
+	RunID         string        `json:"run_id,omitempty"`
+	FinishedAt    string        `json:"finished_at,omitempty"` // RFC 3339
+	AgeSeconds    int64         `json:"age_seconds"`
+	MaxAgeSeconds int64         `json:"max_age_seconds"`
+	Vendors       []readyVendor `json:"vendors"`
+}
+
+// readyVendor is one vendor's freshness.
+type readyVendor struct {
+	Vendor      string `json:"vendor"`
+	Status      string `json:"status"`                 // manifest.Status* of the last run
+	LastScraped string `json:"last_scraped,omitempty"` // Last live scrape, from the vendor summary
+	AgeSeconds  int64  `json:"age_seconds,omitempty"`
+	Stale       bool   `json:"stale"` // Never scraped live, or not within MaxAge
+}
+
+// readiness checks that the report loads and the last run is recent, and
+// lists every vendor of that run with its last successful live scrape. A
+// vendor that failed or was loaded from its cache keeps the time of its
+// last live scrape.
+func readiness(load func() ([]models.Analysis, error), opts serveOptions, now time.Time) readyStatus {
+	ready := readyStatus{Status: readyOK, MaxAgeSeconds: int64(opts.MaxAge / time.Second), Vendors: []readyVendor{}}
+	if _, err := load(); err != nil {
+		ready.Status, ready.Error = readyUnavailable, "no report: "+err.Error()
+		return ready
+	}
+	m, err := storage.LoadJSON[manifest.Manifest](opts.Manifest)
+	if err != nil {
+		ready.Status, ready.Error = readyUnavailable, "no run manifest: "+err.Error()
+		return ready
+	}
+	s, err := summary.Load(opts.Summary)
+	if err != nil {
+		ready.Error = err.Error() // Vendors then show as never scraped
+	}
+	lastScraped := make(map[string]string, len(s.Vendors))
+	for _, v := range s.Vendors {
+		lastScraped[v.Vendor] = v.LastScraped
+	}
+
+	ready.RunID, ready.FinishedAt = m.RunID, m.FinishedAt.UTC().Format(time.RFC3339)
+	ready.AgeSeconds = int64(now.Sub(m.FinishedAt) / time.Second)
+	for _, vs := range m.Vendors {
+		v := readyVendor{Vendor: vs.Vendor, Status: vs.Status, LastScraped: lastScraped[vs.Vendor], Stale: true}
+		if t, err := time.Parse(time.RFC3339, v.LastScraped); err == nil {
+			v.AgeSeconds = int64(now.Sub(t) / time.Second)
+			v.Stale = now.Sub(t) > opts.MaxAge
+		}
+		if v.Stale && ready.Status == readyOK {
+			ready.Status = readyDegraded
+		}
+		ready.Vendors = append(ready.Vendors, v)
+	}
+	if now.Sub(m.FinishedAt) > opts.MaxAge {
+		ready.Status = readyStale
+	}
+	return ready
+}
+
+// requireToken lets a request through to next only when it carries
+// "Authorization: Bearer <token>". Without a token configured, the endpoint
+// is disabled (403) rather than left open.
+func requireToken(token string, next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token == "" {
+			http.Error(w, "disabled: set "+serveTokenEnv+" to enable this endpoint", http.StatusForbidden)
+			return
+		}
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="longevity-rank"`)
+			http.Error(w, "missing or invalid bearer token", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	})
+}
+
+// parseOrigins splits the -cors-origins list. Each origin is a scheme and
+// host without a path ("https://example.com", "http://localhost:3000"), or
+// "*" for any origin.
+func parseOrigins(list string) ([]string, error) {
+	var origins []string
+	for _, o := range strings.Split(list, ",") {
+		o = strings.TrimSuffix(strings.TrimSpace(o), "/")
+		if o == "" {
+			continue
+		}
+		if o != "*" {
+			scheme, host, ok := strings.Cut(o, "://")
+			if !ok || (scheme != "http" && scheme != "https") || host == "" || strings.ContainsAny(host, "/?#") {
+				return nil, fmt.Errorf("%q is not an origin like https://example.com", o)
+			}
+		}
+		origins = append(origins, o)
+	}
+	return origins, nil
+}
+
+// withCORS lets browsers on the allowed origins call next: it sets
+// Access-Control-Allow-Origin on their requests and answers their preflight
+// OPTIONS requests itself. Requests from other origins, and requests without
+// an Origin header, pass through unchanged, so without origins the server
+// stays same-origin only.
+func withCORS(next http.Handler, origins []string) http.Handler {
+	if len(origins) == 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		w.Header().Add("Vary", "Origin")
+		if origin == "" || !slices.ContainsFunc(origins, func(o string) bool { return o == "*" || o == origin }) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST")
+			w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+			w.Header().Set("Access-Control-Max-Age", "86400")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// priceBadge builds the badge for the lowest true cost among the entries
+// that may answer for the supplement and type (see canAnswer), e.g.
+// "cheapest NMN | $0.43/g". Without a match the message is "n/a".
+func priceBadge(report []models.Analysis, reg taxonomy.Registry, supplement, productType, label string) shieldsBadge {
+	badge := shieldsBadge{SchemaVersion: 1, Label: label, Message: "n/a", Color: "lightgrey", CacheSeconds: badgeCacheSeconds}
+	cheapest := -1.0
+	for _, a := range report {
+		if canAnswer(reg, a, supplement, productType) && (cheapest < 0 || a.EffectiveCost < cheapest) {
+			cheapest = a.EffectiveCost
+		}
+	}
+	if cheapest >= 0 {
+		badge.Message = fmt.Sprintf("$%.2f/g", cheapest)
+		badge.Color = "brightgreen"
+	}
+	return badge
+}
+
+// supplementLabel is the display name of a supplement name: acronyms in
+// capitals ("NMN"), names capitalized ("Creatine").
+func supplementLabel(key string) string {
+	if len(key) <= 3 {
+		return strings.ToUpper(key)
+	}
+	return strings.ToUpper(key[:1]) + key[1:]
+}
+
+// writeJSON encodes v as the response body with the given status.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Could not write response: %v", err)
+	}
+}
+
+// compareTarget is one side of `compare`: a cached product, its one-time
+// analyses (in variant order) and the best-ranked of them.
+type compareTarget struct {
+	Vendor   string
+	Product  models.Product
+	Analyses []models.Analysis
+	Best     models.Analysis
+}
+
+// runExportHistory implements `export-history [-watchlist file] [-out dir]`:
+// it writes one CSV of price history per watched product (all its variants,
+// or the watched ones) for plotting in other tools. Nothing is scraped. It
+// returns the process exit code (2 for usage errors, 1 when the watchlist or
+// history cannot be read, lists nothing, or a file cannot be written).
+func runExportHistory(args []string) int {
+	fs := flag.NewFlagSet("export-history", flag.ContinueOnError)
+	watchFile := fs.String("watchlist", watchlist.Filename, "Export the products listed in `file`")
+	outDir := fs.String("out", filepath.Join(storage.DataDir, "history_csv"), "Write the CSV files to `dir`")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 0 {
+		fmt.Fprintln(os.Stderr, "usage: export-history [-watchlist file] [-out dir]")
+		return 2
+	}
+	watched, err := watchlist.Load(*watchFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Could not load watchlist %s: %v\n", *watchFile, err)
+		return 1
+	}
+	if len(watched) == 0 {
+		fmt.Fprintf(os.Stderr, "❌ %s lists no products\n", *watchFile)
+		return 1
+	}
+	store, err := history.Load(history.Filename)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Could not load price history: %v\n", err)
+		return 1
+	}
+	if err := os.MkdirAll(*outDir, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+
+	// One file per watched product: every variant when any of its entries
+	// watches the whole product, else the union of the watched variants
+	type product struct{ vendor, handle string }
+	var order []product
+	variants := map[product][]string{}
+	for _, e := range watched {
+		p := product{e.Vendor, e.Handle}
+		listed, seen := variants[p]
+		if !seen {
+			order = append(order, p)
+		}
+		switch {
+		case e.Variant == "":
+			variants[p] = nil
+		case !seen || listed != nil:
+			variants[p] = append(listed, e.Variant)
+		}
+	}
+
+	written, code := 0, 0
+	for _, p := range order {
+		var buf bytes.Buffer
+		rows, err := history.WriteCSV(&buf, store, p.vendor, p.handle, variants[p])
+		if err == nil && rows == 0 {
+			fmt.Printf("⚠️ No price history for %s/%s\n", p.vendor, p.handle)
+			continue
+		}
+		path := filepath.Join(*outDir, history.CSVName(p.vendor, p.handle))
+		if err == nil {
+			err = os.WriteFile(path, buf.Bytes(), 0644)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %s: %v\n", path, err)
+			code = 1
+			continue
+		}
+		fmt.Printf("📈 %s (%d row(s))\n", path, rows)
+		written++
+	}
+	fmt.Printf("Wrote %d CSV file(s) to %s\n", written, *outDir)
+	return code
+}
+
+// runReanalyze implements `reanalyze [-raw dir] [-supplements list]
+// [-dry-run]`. Without network access, it replays the raw data archive
+// (data/raw/, scrapes and Wayback snapshots) through the current rules to
+// rebuild the price history of every archived vendor and date, then
+// re-analyzes the cached vendor files and prints how the ranking changed.
+// Publishing the new report is left to a normal run without -refresh. It
+// returns the process exit code.
+func runReanalyze(args []string) int {
+	fs := flag.NewFlagSet("reanalyze", flag.ContinueOnError)
+	rawDir := fs.String("raw", rawdata.Dir, "Replay the raw snapshots under `dir`")
+	supplements := fs.String("supplements", "", "Comma-separated supplements to track, by name or alias (default: all in data/supplements.json)")
+	dryRun := fs.Bool("dry-run", false, "Report the changes without writing price_history.json")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 0 {
+		fmt.Fprintln(os.Stderr, "usage: reanalyze [-raw dir] [-supplements list] [-dry-run]")
+		return 2
+	}
+
+	// Replaying without rules would record products the rules drop
+	reg, err := rules.LoadRules(filepath.Join("data", "vendor_rules.json"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Could not load rules: %v\n", err)
+		return 1
+	}
+	vendors, reg, err := loadVendors(reg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	store, err := history.Load(history.Filename)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Could not load price history: %v\n", err)
+		return 1
+	}
+	snapshots, err := rawdata.Load(*rawDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Could not load raw data: %v\n", err)
+		return 1
+	}
+
+	stats := rawdata.Replay(store, snapshots, func(vendorName string, p *models.Product) bool {
+		return rules.ApplyRules(reg, vendorName, p)
+	})
+	fmt.Printf("♻️  Replayed %d raw snapshot(s) over %d vendor-day(s): %d history point(s) dropped, %d re-derived\n",
+		len(snapshots), stats.VendorDays, stats.Removed, stats.Written)
+
+	decisions, err := review.Load(review.Filename)
+	if err != nil {
+		fmt.Printf("⚠️ Warning: Could not load review decisions (%v). Every flag will be queued.\n", err)
+	}
+	qualityScores, err := scores.Load(scores.Filename)
+	if err != nil {
+		fmt.Printf("⚠️ Warning: Could not load quality scores (%v). No quality-adjusted costs.\n", err)
+	}
+	tracked, err := loadSupplements(*supplements, reg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	analyzer := &parser.Analyzer{
+		Rules:       reg,
+		Supplements: tracked,
+		History:     store,
+		Today:       time.Now().UTC().Format(history.DateLayout),
+		Decisions:   decisions,
+		Scores:      qualityScores,
+
+		PriceSources: priceSources(vendors),
+	}
+	var vendorProducts []vendorProduct
+	for _, v := range vendors {
+		products, err := storage.LoadJSON[[]models.Product](storage.VendorFilename(v.Name))
+		if err != nil {
+			fmt.Printf("⏭️  %s: no cached data (%v)\n", v.Name, err)
+			continue
+		}
+		for _, p := range products {
+			if rules.ApplyRules(reg, v.Name, &p) {
+				vendorProducts = append(vendorProducts, vendorProduct{Vendor: v.Name, Product: p})
+			}
+		}
+	}
+	report, _, _ := analyzeAll(analyzer, vendorProducts, false)
+	fmt.Print(formatReanalysis(loadPreviousReport(), report))
+
+	if *dryRun {
+		fmt.Println("🧪 Dry run: price history not written.")
+		return 0
+	}
+	if err := storage.SaveJSON(history.Filename, store); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Could not save price history: %v\n", err)
+		return 1
+	}
+	fmt.Printf("✅ Saved price history to %s. Run without -refresh to publish the reanalyzed report.\n", history.Filename)
+	return 0
+}
+
+// maxReanalysisLines caps the changed entries formatReanalysis lists.
+const maxReanalysisLines = 20
+
+// formatReanalysis compares the saved report with a fresh analysis of the
+// same cached data: entries added and removed, and entries whose cost per
+// gram, active grams or review flag changed, the first few listed.
+func formatReanalysis(previous, current []models.Analysis) string {
+	key := func(a models.Analysis) string {
+		return fmt.Sprintf("%s|%v", history.Key(a.Vendor, a.Handle, a.Variant), a.IsSubscription)
+	}
+	before := make(map[string]models.Analysis, len(previous))
+	for _, a := range previous {
+		before[key(a)] = a
+	}
+	var b strings.Builder
+	added, changed := 0, 0
+	seen := make(map[string]bool, len(current))
+	for _, a := range current {
+		k := key(a)
+		seen[k] = true
+		old, ok := before[k]
+		switch {
+		case !ok:
+			added++
+		case math.Abs(old.CostPerGram-a.CostPerGram) >= 0.005 || math.Abs(old.ActiveGrams-a.ActiveGrams) >= 0.005 || old.NeedsReview != a.NeedsReview:
+			changed++
+			if changed <= maxReanalysisLines {
+				fmt.Fprintf(&b, "  ~ %s (%s): $%.2f/g → $%.2f/g, %.1fg → %.1fg", a.Name, a.Vendor, old.CostPerGram, a.CostPerGram, old.ActiveGrams, a.ActiveGrams)
+				if old.NeedsReview != a.NeedsReview {
+					fmt.Fprintf(&b, ", review %v → %v", old.NeedsReview, a.NeedsReview)
+				}
+				b.WriteString("\n")
+			}
+		}
+	}
+	removed := 0
+	for k := range before {
+		if !seen[k] {
+			removed++
+		}
+	}
+	if changed > maxReanalysisLines {
+		fmt.Fprintf(&b, "  … and %d more\n", changed-maxReanalysisLines)
+	}
+	return fmt.Sprintf("🔬 Reanalyzed cached data: %d entries, %d changed, %d new, %d gone\n", len(current), changed, added, removed) + b.String()
+}
+
+// runCompare implements `compare [-supplements list] [-locale tag]
+// <vendor/handle> <vendor/handle>`: it analyzes two products from the local
+// vendor files and prints them side by side, to answer "A or B?". It
+// returns the process exit code (2 for usage errors, 1 for unknown products).
+func runCompare(args []string) int {
+	fs := flag.NewFlagSet("compare", flag.ContinueOnError)
+	supplements := fs.String("supplements", "", "Comma-separated supplements to track, by name or alias (default: all in data/supplements.json)")
+	localeTag := fs.String("locale", "en", "Number, currency and unit format: "+strings.Join(locale.Supported(), ", "))
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 2 {
+		fmt.Println("usage: compare [-supplements list] [-locale tag] \"Vendor Name/handle\" \"Vendor Name/handle\"")
+		return 2
+	}
+	loc, err := locale.Lookup(*localeTag)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return 2
+	}
+	analyzer, vendors, priceHistory, code := localAnalyzer(*supplements)
+	if code != 0 {
+		return code
+	}
+
+	var targets []compareTarget
+	for _, ref := range fs.Args() {
+		t, err := loadCompareTarget(analyzer, vendors, ref)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return 1
+		}
+		targets = append(targets, t)
+	}
+	printComparison(targets[0], targets[1], priceHistory, loc)
+	return 0
+}
+
+// localAnalyzer builds the analyzer compare and explain run over the local
+// vendor files, with the rules, price history and quality scores a full run
+// would use. A non-zero code is the exit code of a failed setup (2 for an
+// unknown supplement).
+func localAnalyzer(supplements string) (*parser.Analyzer, []models.Vendor, history.Store, int) {
+	reg, err := rules.LoadRules(filepath.Join("data", "vendor_rules.json"))
+	if err != nil {
+		fmt.Printf("⚠️ Warning: Could not load rules (%v). Analyzing without overrides.\n", err)
+	}
+	priceHistory, err := history.Load(history.Filename)
+	if err != nil {
+		fmt.Printf("⚠️ Warning: Could not load price history (%v). No sparklines.\n", err)
+		priceHistory = history.Store{}
+	}
+	qualityScores, err := scores.Load(scores.Filename)
+	if err != nil {
+		fmt.Printf("⚠️ Warning: Could not load quality scores (%v). No quality-adjusted costs.\n", err)
+	}
+	vendors, reg, err := loadVendors(reg)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return nil, nil, nil, 1
+	}
+	tracked, err := loadSupplements(supplements, reg)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return nil, nil, nil, 2
+	}
+	analyzer := &parser.Analyzer{
+		Rules:       reg,
+		Supplements: tracked,
+		History:     priceHistory,
+		Today:       time.Now().UTC().Format(history.DateLayout),
+		Scores:      qualityScores,
+
+		PriceSources: priceSources(vendors),
+	}
+	return analyzer, vendors, priceHistory, 0
+}
+
+// runExplain implements `explain [-supplements list] [-locale tag]
+// <vendor/handle>`: it analyzes one product from its vendor's local file
+// and prints where each variant's price, active grams and mg per unit came
+// from, to check a surprising ranking without reading the code. It returns
+// the process exit code (2 for usage errors, 1 for unknown products).
+func runExplain(args []string) int {
+	fs := flag.NewFlagSet("explain", flag.ContinueOnError)
+	supplements := fs.String("supplements", "", "Comma-separated supplements to track, by name or alias (default: all in data/supplements.json)")
+	localeTag := fs.String("locale", "en", "Number, currency and unit format: "+strings.Join(locale.Supported(), ", "))
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Println("usage: explain [-supplements list] [-locale tag] \"Vendor Name/handle\"")
+		return 2
+	}
+	loc, err := locale.Lookup(*localeTag)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return 2
+	}
+	analyzer, vendors, _, code := localAnalyzer(*supplements)
+	if code != 0 {
+		return code
+	}
+	t, err := loadCompareTarget(analyzer, vendors, fs.Arg(0))
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return 1
+	}
+	fmt.Printf("\n🔎 %s — %s\n", t.Vendor, t.Product.Title)
+	if len(t.Analyses) == 0 {
+		fmt.Printf("⚠️ %s/%s has no analyzable variant (not a tracked supplement, sold out, or no parseable mass)\n", t.Vendor, t.Product.Handle)
+		return 0
+	}
+	fmt.Print(formatExplain(t.Analyses, loc))
+	return 0
+}
+
+// formatExplain tabulates each analysis's price, active grams and mg per
+// unit next to their Attribution.
+func formatExplain(analyses []models.Analysis, loc locale.Locale) string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Variant\tPrice\tfrom\tActive g\tfrom\tmg/unit\tfrom")
+	for _, a := range analyses {
+		src := models.Attribution{}
+		if a.Attribution != nil {
+			src = *a.Attribution
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", a.Variant,
+			loc.Money(a.Price), orDash(src.Price != "", src.Price),
+			loc.Grams(a.ActiveGrams), orDash(src.Grams != "", src.Grams),
+			orDash(a.UnitMg > 0, loc.Number(a.UnitMg, 0)), orDash(src.Mg != "", src.Mg))
+	}
+	w.Flush()
+	return b.String()
+}
+
+// priceSources maps each vendor to where its prices come from, for the
+// analyzer's Attribution.
+func priceSources(vendors []models.Vendor) map[string]string {
+	sources := make(map[string]string, len(vendors))
+	for _, v := range vendors {
+		sources[v.Name] = scraper.PriceSource(v)
+	}
+	return sources
+}
+
+// loadCompareTarget resolves "Vendor Name/handle" (vendor matched
+// case-insensitively, split at the first "/" so URL handles work) against
+// the vendor's local JSON file and analyzes the product.
+func loadCompareTarget(analyzer *parser.Analyzer, vendors []models.Vendor, ref string) (compareTarget, error) {
+	name, handle, ok := strings.Cut(ref, "/")
+	name, handle = strings.TrimSpace(name), strings.TrimSpace(handle)
+	if !ok || name == "" || handle == "" {
+		return compareTarget{}, fmt.Errorf("invalid product %q: want \"Vendor Name/handle\"", ref)
+	}
+	vendor := ""
+	for _, v := range vendors {
+		if strings.EqualFold(v.Name, name) {
+			vendor = v.Name
+		}
+	}
+	if vendor == "" {
+		return compareTarget{}, fmt.Errorf("unknown vendor %q", name)
+	}
+	products, err := storage.LoadJSON[[]models.Product](storage.VendorFilename(vendor))
+	if err != nil {
+		return compareTarget{}, fmt.Errorf("no local data for %s (run with --refresh first): %v", vendor, err)
+	}
+
+	for _, p := range products {
+		if p.Handle != handle {
+			continue
+		}
+		t := compareTarget{Vendor: vendor, Product: p}
+		for _, a := range analyzer.AnalyzeProduct(vendor, p) {
+			if a.IsSubscription {
+				continue
+			}
+			if len(t.Analyses) == 0 || parser.RankedBefore(a, t.Best) {
+				t.Best = a
+			}
+			t.Analyses = append(t.Analyses, a)
+		}
+		return t, nil
+	}
+	return compareTarget{}, fmt.Errorf("%s has no product with handle %q", vendor, handle)
+}
+
+// printComparison prints two products side by side: the best-ranked
+// variant's extraction details and costs, every analyzed variant, the best
+// variant's price history, and which is cheaper per gram and per day.
+func printComparison(a, b compareTarget, store history.Store, loc locale.Locale) {
+	side := func(t compareTarget, field func(models.Analysis) string) string {
+		if len(t.Analyses) == 0 {
+			return "—"
+		}
+		return field(t.Best)
+	}
+	rows := []struct {
+		label string
+		field func(models.Analysis) string
+	}{
+		{"Best variant", func(x models.Analysis) string { return x.Variant }},
+		{"Type", func(x models.Analysis) string { return loc.Type(x.Type) }},
+		{"Price", func(x models.Analysis) string {
+			if x.NativeCurrency != "" {
+				return loc.Money(x.Price) + " (" + nativePrice(x, loc) + ")"
+			}
+			return loc.Money(x.Price)
+		}},
+		{"Active g", func(x models.Analysis) string { return loc.Grams(x.ActiveGrams) }},
+		{"Gross g", func(x models.Analysis) string { return orDash(x.GrossGrams > 0, loc.Grams(x.GrossGrams)) }},
+		{"Form", func(x models.Analysis) string {
+			if x.ActiveFraction > 0 {
+				return fmt.Sprintf("%s (%s active)", x.ActiveForm, loc.Percent(x.ActiveFraction*100))
+			}
+			return orDash(x.ActiveForm != "", x.ActiveForm)
+		}},
+		{"$/gram", func(x models.Analysis) string { return loc.Money(x.CostPerGram) }},
+		{"Bioavailability", func(x models.Analysis) string {
+			return orDash(x.Multiplier > 1, loc.Number(x.Multiplier, 2)+"× "+x.MultiplierLabel)
+		}},
+		{"True cost", func(x models.Analysis) string { return loc.Money(x.EffectiveCost) + "/g" }},
+		{"Certifications", func(x models.Analysis) string {
+			return orDash(len(x.Certifications) > 0, strings.Join(x.Certifications, ", "))
+		}},
+		{"Quality score", func(x models.Analysis) string {
+			return orDash(x.QualityScore > 0, strings.TrimSpace(x.QualitySource+" "+loc.Number(x.QualityScore, 0)))
+		}},
+		{"Cost per day", func(x models.Analysis) string {
+			if x.CostPerDay <= 0 {
+				return "—"
+			}
+			dose := loc.Number(x.DailyDoseMg, 0) + " mg"
+			if x.UnitsPerDay > 0 {
+				dose = fmt.Sprintf("%d units = %s", x.UnitsPerDay, dose)
+			}
+			return fmt.Sprintf("%s (%s)", loc.Money(x.CostPerDay), dose)
+		}},
+		{"Confidence", func(x models.Analysis) string { return loc.Number(x.Confidence, 2) }},
+		{"Review", func(x models.Analysis) string {
+			if x.ReviewDetail != "" {
+				return orDash(x.NeedsReview, x.ReviewReason+": "+x.ReviewDetail)
+			}
+			return orDash(x.NeedsReview, x.ReviewReason)
+		}},
+		{"History", func(x models.Analysis) string {
+			return priceSparkline(store[history.Key(x.Vendor, x.Handle, x.Variant)], loc)
+		}},
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "\n\tA\tB\n")
+	fmt.Fprintf(w, "Vendor\t%s\t%s\n", a.Vendor, b.Vendor)
+	fmt.Fprintf(w, "Product\t%s\t%s\n", truncateName(a.Product.Title), truncateName(b.Product.Title))
+	for _, r := range rows {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", r.label, side(a, r.field), side(b, r.field))
+	}
+	for i := 0; i < max(len(a.Analyses), len(b.Analyses)); i++ {
+		label := ""
+		if i == 0 {
+			label = "Variants"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", label, variantCell(a, i, loc), variantCell(b, i, loc))
+	}
+	w.Flush()
+
+	for _, t := range []compareTarget{a, b} {
+		if len(t.Analyses) == 0 {
+			fmt.Printf("⚠️ %s/%s has no analyzable variant (not a tracked supplement, sold out, or no parseable mass)\n", t.Vendor, t.Product.Handle)
+		}
+	}
+	if len(a.Analyses) == 0 || len(b.Analyses) == 0 {
+		return
+	}
+	fmt.Println()
+	fmt.Println(compareVerdict("per gram (true cost)", a, b, a.Best.EffectiveCost, b.Best.EffectiveCost, loc))
+	if a.Best.CostPerDay > 0 && b.Best.CostPerDay > 0 {
+		fmt.Println(compareVerdict("per day", a, b, a.Best.CostPerDay, b.Best.CostPerDay, loc))
+	}
+}
+
+// compareVerdict names the cheaper side on one cost, e.g.
+// "💡 Per day: A (Nutricost) is 1.8× cheaper ($0.25 vs $0.45)".
+func compareVerdict(what string, a, b compareTarget, costA, costB float64, loc locale.Locale) string {
+	if costA == costB {
+		return fmt.Sprintf("💡 %s: a tie at %s", what, loc.Money(costA))
+	}
+	winner, cheap, dear := "A ("+a.Vendor+")", costA, costB
+	if costB < costA {
+		winner, cheap, dear = "B ("+b.Vendor+")", costB, costA
+	}
+	return fmt.Sprintf("💡 Cheaper %s: %s is %s× cheaper (%s vs %s)", what, winner,
+		loc.Number(dear/cheap, 1), loc.Money(cheap), loc.Money(dear))
+}
+
+// variantCell formats the i-th analyzed variant: "1KG  $46.97  $0.05/g".
+func variantCell(t compareTarget, i int, loc locale.Locale) string {
+	if i >= len(t.Analyses) {
+		return ""
+	}
+	x := t.Analyses[i]
+	return fmt.Sprintf("%s  %s  %s/g", x.Variant, loc.Money(x.Price), loc.Money(x.EffectiveCost))
+}
+
+// withoutAttribution returns a copy of report with no Attribution, which
+// only the extended report publishes.
+func withoutAttribution(report []models.Analysis) []models.Analysis {
+	lean := make([]models.Analysis, len(report))
+	for i, a := range report {
+		a.Attribution = nil
+		lean[i] = a
+	}
+	return lean
+}
+
+// extendReport returns a copy of report whose entries carry the last
+// sparklineDays prices of their source variant. History keeps checkout
+// prices, so a vendor priced in another currency has them converted at the
+// entry's own rate (Price / NativePrice).
+func extendReport(report []models.Analysis, store history.Store) []models.Analysis {
+	extended := make([]models.Analysis, len(report))
+	for i, a := range report {
+		prices := history.Recent(store, history.Key(a.Vendor, a.Handle, a.Variant), sparklineDays)
+		if a.NativeCurrency != "" && a.NativePrice > 0 {
+			rate := a.Price / a.NativePrice
+			for j := range prices {
+				prices[j] = math.Round(prices[j]*rate*100) / 100
+			}
+		}
+		a.RecentPrices = prices
+		extended[i] = a
+	}
+	return extended
+}
+
+// priceSparkline draws up to the last sparklineDays observed prices of a
+// variant, e.g. "▃▃▁▁█ $40.00–$46.97 (5 days)". The lowest price is the
+// lowest bar.
+func priceSparkline(points []history.Point, loc locale.Locale) string {
+	if len(points) == 0 {
+		return "—"
+	}
+	if len(points) > sparklineDays {
+		points = points[len(points)-sparklineDays:]
+	}
+	low, high := points[0].Price, points[0].Price
+	for _, p := range points {
+		low, high = min(low, p.Price), max(high, p.Price)
+	}
+	bars := []rune("▁▂▃▄▅▆▇█")
+	var line strings.Builder
+	for _, p := range points {
+		level := len(bars) / 2
+		if high > low {
+			level = int((p.Price - low) / (high - low) * float64(len(bars)-1))
+		}
+		line.WriteRune(bars[level])
+	}
+	return fmt.Sprintf("%s %s–%s (%d days)", line.String(), loc.Money(low), loc.Money(high), len(points))
+}
+
+// orDash returns s, or "—" when ok is false.
+func orDash(ok bool, s string) string {
+	if !ok {
+		return "—"
+	}
+	return s
+}
+
+// truncateName shortens long product titles to keep the columns readable.
+func truncateName(s string) string {
+	const maxLen = 48
+	if r := []rune(s); len(r) > maxLen {
+		return string(r[:maxLen-1]) + "…"
+	}
+	return s
+}
+
+// runValidateVendor implements `validate-vendor [-vendor name] <file>`: it
+// checks a hand-maintained vendor JSON file (Cloudflare vendors) against the
+// Product schema, then runs a trial analysis with the vendor's rules so typos
+// surface before they reach the report. It writes no files and returns the
+// process exit code: 0 when the file is valid, 1 otherwise.
+func runValidateVendor(args []string) int {
+	fs := flag.NewFlagSet("validate-vendor", flag.ContinueOnError)
+	vendorName := fs.String("vendor", "", "Vendor whose vendor_rules.json entry applies (default: the configured vendor stored in this file)")
+	supplements := fs.String("supplements", "", "Comma-separated supplements to track, by name or alias (default: all in data/supplements.json)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Println("usage: validate-vendor [-vendor \"Vendor Name\"] <file>")
+		return 2
+	}
+	path := fs.Arg(0)
+
+	reg, err := rules.LoadRules(filepath.Join("data", "vendor_rules.json"))
+	if err != nil {
+		fmt.Printf("⚠️ Warning: Could not load rules (%v). Trial runs without filters.\n", err)
+	}
+	vendors, reg, err := loadVendors(reg)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return 1
+	}
+
+	name := *vendorName
+	if name == "" {
+		name = vendorForFile(vendors, path)
+	}
+	if name == "" {
+		fmt.Printf("❌ %s is not a configured vendor's file; pass -vendor\n", path)
+		return 2
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return 1
+	}
+	products, problems := validateVendorJSON(data)
+	for _, p := range problems {
+		fmt.Printf("❌ %s\n", p)
+	}
+	if products == nil {
+		return 1
+	}
+
+	tracked, err := loadSupplements(*supplements, reg)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return 1
+	}
+	analyzer := &parser.Analyzer{Rules: reg, Supplements: tracked}
+
+	var vendorProducts []vendorProduct
+	blocked := 0
+	for _, p := range products {
+		if rules.ApplyRules(reg, name, &p) {
+			vendorProducts = append(vendorProducts, vendorProduct{Vendor: name, Product: p})
+		} else {
+			blocked++
+		}
+	}
+	report, gaps, _ := analyzeAll(analyzer, vendorProducts, true)
+
+	fmt.Printf("\n🧪 Trial analysis for %s: %d products, %d blocked, %d entries, %d gap(s)\n",
+		name, len(products), blocked, len(report), len(gaps))
+	printTable(report, locale.Default)
+	if len(gaps) > 0 {
+		fmt.Print(parser.FormatAuditReport(gaps))
+	}
+
+	if len(problems) > 0 {
+		fmt.Printf("\n❌ %s: %d problem(s)\n", path, len(problems))
+		return 1
+	}
+	fmt.Printf("\n✅ %s is valid\n", path)
+	return 0
+}
+
+// vendorForFile returns the configured vendor whose cache file has path's
+// name (e.g. "jinfiniti.json" → "Jinfiniti"), or "".
+func vendorForFile(vendors []models.Vendor, path string) string {
+	for _, v := range vendors {
+		if filepath.Base(storage.VendorFilename(v.Name)) == filepath.Base(path) {
+			return v.Name
+		}
+	}
+	return ""
+}
+
+// validateVendorJSON decodes data as []Product, rejecting fields the schema
+// does not know (a misspelled "price" would otherwise decode as empty), and
+// checks every product and variant for the values the analyzer needs. It
+// returns the products (nil when the file cannot be decoded at all) and one
+// message per problem.
+func validateVendorJSON(data []byte) ([]models.Product, []string) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var products []models.Product
+	if err := dec.Decode(&products); err != nil {
+		return nil, []string{fmt.Sprintf("not a valid product list: %v", err)}
+	}
+	if products == nil {
+		return nil, []string{"file contains null, want a JSON array of products"}
+	}
+
+	var problems []string
+	seenIDs := make(map[string]bool)
+	for i, p := range products {
+		where := fmt.Sprintf("product[%d] %q", i, p.Handle)
+		if p.ID == "" {
+			problems = append(problems, where+": missing id")
+		} else if seenIDs[p.ID] {
+			problems = append(problems, fmt.Sprintf("%s: duplicate id %q", where, p.ID))
+		}
+		seenIDs[p.ID] = true
+		if p.Title == "" {
+			problems = append(problems, where+": missing title")
+		}
+		if p.Handle == "" {
+			problems = append(problems, where+": missing handle")
+		}
+		if len(p.Variants) == 0 {
+			problems = append(problems, where+": no variants")
+		}
+
+		for j, v := range p.Variants {
+			vwhere := fmt.Sprintf("%s variant[%d] %q", where, j, v.Title)
+			if v.Title == "" {
+				problems = append(problems, vwhere+": missing title")
+			}
+			price, err := strconv.ParseFloat(v.Price, 64)
+			switch {
+			case v.Price == "":
+				problems = append(problems, vwhere+": missing price")
+			case err != nil:
+				problems = append(problems, fmt.Sprintf("%s: price %q is not a number", vwhere, v.Price))
+			case price <= 0:
+				problems = append(problems, fmt.Sprintf("%s: price %q is not positive", vwhere, v.Price))
+			}
+			if v.CompareAtPrice != "" {
+				if _, err := strconv.ParseFloat(v.CompareAtPrice, 64); err != nil {
+					problems = append(problems, fmt.Sprintf("%s: compare_at_price %q is not a number", vwhere, v.CompareAtPrice))
+				}
+			}
+		}
+	}
+	return products, problems
+}
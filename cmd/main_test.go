@@ -0,0 +1,666 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"longevity-ranker/internal/changes"
+	"longevity-ranker/internal/history"
+	"longevity-ranker/internal/locale"
+	"longevity-ranker/internal/manifest"
+	"longevity-ranker/internal/models"
+	"longevity-ranker/internal/parser"
+	"longevity-ranker/internal/queue"
+	"longevity-ranker/internal/rules"
+	"longevity-ranker/internal/runerrors"
+	"longevity-ranker/internal/runs"
+	"longevity-ranker/internal/storage"
+	"longevity-ranker/internal/summary"
+	"longevity-ranker/internal/taxonomy"
+)
+
+var mockFixture = filepath.Join("testdata", "mock_products.json")
+
+// mockRules blocks the gummies product and overrides the capsule mass, so the
+// test covers blocklist filtering and the catalog override path end to end.
+var mockRules = rules.Registry{
+	"Mock Vendor": {
+		Blocklist: []string{"Gummies"},
+		Overrides: map[string]rules.ProductSpec{
+			"mock-nmn-capsules": {ForceType: "Capsules", ForceActiveGrams: 30},
+		},
+	},
+}
+
+func runMockPipeline(t *testing.T, source string) []models.Analysis {
+	t.Helper()
+	vendor, err := parseMockVendor("Mock Vendor=" + source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	analyzer := &parser.Analyzer{Rules: mockRules, Supplements: taxonomy.Defaults().Select([]string{"nmn"})}
+	vendorProducts, statuses, _ := scrapeAll([]models.Vendor{vendor}, mockRules, false, nil, fetchLocal)
+	// The blocklisted gummies are dropped before analysis
+	want := manifest.VendorStatus{Vendor: "Mock Vendor", Status: manifest.StatusScraped, Products: 3}
+	if len(statuses) != 1 || !reflect.DeepEqual(statuses[0], want) {
+		t.Errorf("vendor statuses = %+v, want [%+v]", statuses, want)
+	}
+	report, _, _ := analyzeAll(analyzer, vendorProducts, false)
+	return report
+}
+
+func assertMockReport(t *testing.T, report []models.Analysis) {
+	t.Helper()
+	// Expected, sorted by effective cost:
+	//   100 Grams   $100 / 100g = $1.00/g
+	//   50 Grams    $60  / 50g  = $1.20/g
+	//   60 Capsules $45  / 30g  = $1.50/g (override)
+	// Excluded: placeholder price, unavailable variant, blocklisted gummies,
+	// non-tracked fish oil.
+	want := []struct {
+		name        string
+		activeGrams float64
+		costPerGram float64
+	}{
+		{"Mock NMN Powder (100 Grams)", 100, 1.00},
+		{"Mock NMN Powder (50 Grams)", 50, 1.20},
+		{"Mock NMN Capsules (60 Capsules)", 30, 1.50},
+	}
+	if len(report) != len(want) {
+		t.Fatalf("report has %d entries, want %d: %+v", len(report), len(want), report)
+	}
+	for i, w := range want {
+		got := report[i]
+		if got.Name != w.name || got.ActiveGrams != w.activeGrams || got.CostPerGram != w.costPerGram {
+			t.Errorf("report[%d] = %q %.1fg $%.2f/g, want %q %.1fg $%.2f/g",
+				i, got.Name, got.ActiveGrams, got.CostPerGram, w.name, w.activeGrams, w.costPerGram)
+		}
+		if got.Vendor != "Mock Vendor" {
+			t.Errorf("report[%d].Vendor = %q", i, got.Vendor)
+		}
+	}
+}
+
+func TestPipelineWithMockFile(t *testing.T) {
+	assertMockReport(t, runMockPipeline(t, mockFixture))
+}
+
+func TestPipelineWithMockServer(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, mockFixture)
+	}))
+	defer srv.Close()
+
+	assertMockReport(t, runMockPipeline(t, srv.URL+"/products.json"))
+}
+
+func TestParseMockVendor(t *testing.T) {
+	for _, raw := range []string{"", "Mock Vendor", "=file.json", "Mock Vendor="} {
+		if _, err := parseMockVendor(raw); err == nil {
+			t.Errorf("parseMockVendor(%q) succeeded, want error", raw)
+		}
+	}
+}
+
+func TestBelowFold(t *testing.T) {
+	analyzer := &parser.Analyzer{Supplements: taxonomy.Defaults().Select([]string{"nmn"})}
+	product := func(handle, title, price string) vendorProduct {
+		return vendorProduct{Vendor: "Mock Vendor", Product: models.Product{
+			Handle:   handle,
+			Title:    title,
+			Variants: []models.Variant{{Price: price, Title: "100 Grams", Available: true}},
+		}}
+	}
+	// The flavored blend parses as ten times cheaper but is flagged
+	report, _, _ := analyzeAll(analyzer, []vendorProduct{
+		product("nmn-berry", "NMN Berry Blend Powder", "10.00"),
+		product("nmn", "NMN Powder", "100.00"),
+	}, false)
+	if len(report) != 2 || report[0].Handle != "nmn" || !parser.BelowFold(report[1]) {
+		t.Fatalf("report = %+v, want the clean powder first and the flagged blend below the fold", report)
+	}
+
+	strict := filterStrict(report)
+	if len(strict) != 1 || strict[0].Handle != "nmn" {
+		t.Errorf("filterStrict() = %+v, want only the clean powder", strict)
+	}
+	if got := filterStrict(report[1:]); got == nil || len(got) != 0 {
+		t.Errorf("filterStrict(flagged only) = %#v, want empty non-nil slice", got)
+	}
+}
+
+func TestFilterCapsuleMg(t *testing.T) {
+	report := []models.Analysis{
+		{Handle: "nmn-125", UnitMg: 125},
+		{Handle: "nmn-powder"},
+		{Handle: "nmn-250", UnitMg: 250},
+		{Handle: "nmn-500", UnitMg: 500},
+	}
+	var handles []string
+	for _, a := range filterCapsuleMg(report, 250) {
+		handles = append(handles, a.Handle)
+	}
+	if want := []string{"nmn-powder", "nmn-250", "nmn-500"}; !reflect.DeepEqual(handles, want) {
+		t.Errorf("filterCapsuleMg(250) = %v, want %v", handles, want)
+	}
+	if got := filterCapsuleMg(report[:1], 250); got == nil || len(got) != 0 {
+		t.Errorf("filterCapsuleMg(small only) = %#v, want empty non-nil slice", got)
+	}
+}
+
+func TestPriceSparkline(t *testing.T) {
+	loc, _ := locale.Lookup("en")
+	points := []history.Point{{Price: 46.97}, {Price: 40}, {Price: 40}, {Price: 46.97}, {Price: 43.485}}
+	if got, want := priceSparkline(points, loc), "█▁▁█▄ $40.00–$46.97 (5 days)"; got != want {
+		t.Errorf("priceSparkline() = %q, want %q", got, want)
+	}
+	if got, want := priceSparkline(points[1:3], loc), "▅▅ $40.00–$40.00 (2 days)"; got != want {
+		t.Errorf("priceSparkline(flat) = %q, want %q", got, want)
+	}
+	if got := priceSparkline(nil, loc); got != "—" {
+		t.Errorf("priceSparkline(nil) = %q, want —", got)
+	}
+}
+
+func TestBestEntry(t *testing.T) {
+	entry := func(name, typ string) models.Analysis {
+		return models.Analysis{Name: name, Type: typ, Confidence: 0.75}
+	}
+	flagged := entry("NMN Berry Flavor Powder", "Powder")
+	flagged.NeedsReview = true
+	sub := entry("NMN Capsules (Subscribe & Save)", "Capsules")
+	sub.IsSubscription = true
+	blend := entry("NAD+ Boost with NMN", "Capsules")
+	blend.Supplement = "nad"
+	report := []models.Analysis{flagged, sub, blend, entry("NMN Capsules", "Capsules"), entry("NMN Powder", "Powder")}
+
+	tests := []struct {
+		supplement, productType, want string
+	}{
+		{"nmn", "", "NMN Capsules"},
+		{"nmn", "powder", "NMN Powder"},
+		{"nmn", "Capsule", "NMN Capsules"},
+		{"nad", "", "NAD+ Boost with NMN"},
+		{"nmn", "gel", ""},
+		{"tmg", "", ""},
+	}
+	for _, tt := range tests {
+		got, ok := bestEntry(report, taxonomy.Defaults(), tt.supplement, tt.productType)
+		if got.Name != tt.want || ok != (tt.want != "") {
+			t.Errorf("bestEntry(%q, %q) = %q, %v; want %q", tt.supplement, tt.productType, got.Name, ok, tt.want)
+		}
+	}
+
+	if got := supplementKey(taxonomy.Defaults(), " Trimethylglycine"); got != "tmg" {
+		t.Errorf("supplementKey(Trimethylglycine) = %q, want tmg", got)
+	}
+	a := models.Analysis{Name: "NMN Powder", Vendor: "Do Not Age", Price: 39.95, CostPerGram: 0.3995, EffectiveCost: 0.2663}
+	want := "NMN Powder — Do Not Age — $39.95 — $0.40/g (true $0.27/g) — https://donotage.org/pure-nmn"
+	if got := formatBest(a, "https://donotage.org/pure-nmn"); got != want {
+		t.Errorf("formatBest() = %q, want %q", got, want)
+	}
+}
+
+func TestServeBadge(t *testing.T) {
+	entry := func(name, typ string, cost float64) models.Analysis {
+		return models.Analysis{Name: name, Type: typ, EffectiveCost: cost, Confidence: 0.75}
+	}
+	flagged := entry("NMN Berry Flavor Powder", "Powder", 0.10)
+	flagged.NeedsReview = true
+	report := []models.Analysis{flagged, entry("NMN Capsules", "Capsules", 0.80), entry("NMN Powder", "Powder", 0.43)}
+	srv := httptest.NewServer(newServeMux(func() ([]models.Analysis, error) { return report, nil }, t.TempDir(), serveOptions{Supplements: taxonomy.Defaults()}))
+	defer srv.Close()
+
+	tests := []struct {
+		path   string
+		status int
+		want   shieldsBadge
+	}{
+		{"/badge/nmn", http.StatusOK, shieldsBadge{SchemaVersion: 1, Label: "cheapest NMN", Message: "$0.43/g", Color: "brightgreen", CacheSeconds: badgeCacheSeconds}},
+		{"/badge/NMN?type=capsules", http.StatusOK, shieldsBadge{SchemaVersion: 1, Label: "cheapest NMN capsules", Message: "$0.80/g", Color: "brightgreen", CacheSeconds: badgeCacheSeconds}},
+		{"/badge/creatine", http.StatusOK, shieldsBadge{SchemaVersion: 1, Label: "cheapest Creatine", Message: "n/a", Color: "lightgrey", CacheSeconds: badgeCacheSeconds}},
+		{"/badge/fish-oil", http.StatusNotFound, shieldsBadge{SchemaVersion: 1, Label: "cheapest", Message: "unknown supplement", IsError: true}},
+	}
+	for _, tt := range tests {
+		resp, err := http.Get(srv.URL + tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got shieldsBadge
+		err = json.NewDecoder(resp.Body).Decode(&got)
+		resp.Body.Close()
+		if err != nil || resp.StatusCode != tt.status || got != tt.want {
+			t.Errorf("GET %s = %d %+v (%v), want %d %+v", tt.path, resp.StatusCode, got, err, tt.status, tt.want)
+		}
+	}
+
+	resp, err := http.Get(srv.URL + "/api/report?strict=true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var strict []models.Analysis
+	if err := json.NewDecoder(resp.Body).Decode(&strict); err != nil || len(strict) != 2 {
+		t.Errorf("GET /api/report?strict=true = %d entries (%v), want the 2 entries above the fold", len(strict), err)
+	}
+}
+
+func TestServeReportUnavailable(t *testing.T) {
+	report := []models.Analysis{
+		{Name: "NMN Powder", Confidence: 0.75},
+		{Name: "NMN Capsules", Confidence: 0.75, Unavailable: true},
+	}
+	srv := httptest.NewServer(newServeMux(func() ([]models.Analysis, error) { return report, nil }, t.TempDir(), serveOptions{}))
+	defer srv.Close()
+
+	for path, want := range map[string]int{
+		"/api/report":                                      1,
+		"/api/report?include_unavailable=true":             2,
+		"/api/report?include_unavailable=true&strict=true": 1,
+	} {
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []models.Analysis
+		err = json.NewDecoder(resp.Body).Decode(&got)
+		resp.Body.Close()
+		if err != nil || len(got) != want {
+			t.Errorf("GET %s = %d entries (%v), want %d", path, len(got), err, want)
+		}
+	}
+}
+
+func TestServeDiff(t *testing.T) {
+	dir := t.TempDir()
+	const fromID, toID = "20260101T060000Z-0123abcd", "20260108T060000Z-4567cdef"
+	from := []models.Analysis{{Vendor: "Vendor", Handle: "nmn-powder", Name: "NMN Powder", Variant: "100g", Price: 50}}
+	to := []models.Analysis{{Vendor: "Vendor", Handle: "nmn-powder", Name: "NMN Powder", Variant: "100g", Price: 45}}
+	for _, r := range []runs.Run{{RunID: fromID, Date: "2026-01-01", Report: from}, {RunID: toID, Date: "2026-01-08", Report: to}} {
+		if _, err := runs.Save(dir, r, runs.Keep); err != nil {
+			t.Fatal(err)
+		}
+	}
+	srv := httptest.NewServer(newServeMux(func() ([]models.Analysis, error) { return to, nil }, dir, serveOptions{}))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/api/runs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	err = json.NewDecoder(resp.Body).Decode(&ids)
+	resp.Body.Close()
+	if err != nil || !reflect.DeepEqual(ids, []string{fromID, toID}) {
+		t.Errorf("GET /api/runs = %v (%v), want [%s %s]", ids, err, fromID, toID)
+	}
+
+	resp, err = http.Get(srv.URL + "/api/diff?from=" + fromID + "&to=" + toID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got changes.ChangeSet
+	err = json.NewDecoder(resp.Body).Decode(&got)
+	resp.Body.Close()
+	want := changes.PriceChange{Vendor: "Vendor", Handle: "nmn-powder", Title: "NMN Powder", Variant: "100g", OldPrice: 50, NewPrice: 45, ChangePct: -10, Since: "2026-01-01"}
+	if err != nil || resp.StatusCode != http.StatusOK || got.Date != "2026-01-08" || len(got.PriceChanges) != 1 || got.PriceChanges[0] != want {
+		t.Errorf("GET /api/diff = %d %+v (%v), want the price drop %+v", resp.StatusCode, got, err, want)
+	}
+
+	for path, status := range map[string]int{
+		"/api/diff?from=" + fromID:                                   http.StatusBadRequest,
+		"/api/diff?from=../manifest&to=" + toID:                      http.StatusBadRequest,
+		"/api/diff?from=" + fromID + "&to=20260115T060000Z-89abcdef": http.StatusNotFound,
+	} {
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != status {
+			t.Errorf("GET %s = %d, want %d", path, resp.StatusCode, status)
+		}
+	}
+}
+
+func TestServeAuth(t *testing.T) {
+	var posted int
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { posted++ }))
+	defer webhook.Close()
+	load := func() ([]models.Analysis, error) { return nil, nil }
+	locked := httptest.NewServer(newServeMux(load, t.TempDir(), serveOptions{Webhook: webhook.URL}))
+	defer locked.Close()
+	srv := httptest.NewServer(newServeMux(load, t.TempDir(), serveOptions{Token: "s3cret", Webhook: webhook.URL}))
+	defer srv.Close()
+
+	tests := []struct {
+		url    string
+		auth   string
+		status int
+	}{
+		{locked.URL, "Bearer s3cret", http.StatusForbidden},
+		{srv.URL, "", http.StatusUnauthorized},
+		{srv.URL, "Bearer wrong", http.StatusUnauthorized},
+		{srv.URL, "s3cret", http.StatusUnauthorized},
+		{srv.URL, "Bearer s3cret", http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodPost, tt.url+"/api/alerts/test", nil)
+		if tt.auth != "" {
+			req.Header.Set("Authorization", tt.auth)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.status {
+			t.Errorf("POST /api/alerts/test with %q = %d, want %d", tt.auth, resp.StatusCode, tt.status)
+		}
+	}
+	if posted != 1 {
+		t.Errorf("webhook got %d posts, want 1", posted)
+	}
+}
+
+func TestServeCORS(t *testing.T) {
+	if _, err := parseOrigins("example.com"); err == nil {
+		t.Error("parseOrigins(example.com) = nil error, want one for the missing scheme")
+	}
+	origins, err := parseOrigins(" https://rank.example.com/, http://localhost:3000")
+	if err != nil || !reflect.DeepEqual(origins, []string{"https://rank.example.com", "http://localhost:3000"}) {
+		t.Fatalf("parseOrigins() = %v, %v", origins, err)
+	}
+	load := func() ([]models.Analysis, error) { return nil, nil }
+	srv := httptest.NewServer(withCORS(newServeMux(load, t.TempDir(), serveOptions{}), origins))
+	defer srv.Close()
+
+	tests := []struct {
+		method, origin string
+		status         int
+		allow          string
+	}{
+		{http.MethodGet, "https://rank.example.com", http.StatusOK, "https://rank.example.com"},
+		{http.MethodGet, "https://evil.example.com", http.StatusOK, ""},
+		{http.MethodOptions, "http://localhost:3000", http.StatusNoContent, "http://localhost:3000"},
+		{http.MethodOptions, "https://evil.example.com", http.StatusMethodNotAllowed, ""},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(tt.method, srv.URL+"/api/report", nil)
+		req.Header.Set("Origin", tt.origin)
+		req.Header.Set("Access-Control-Request-Method", "GET")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if got := resp.Header.Get("Access-Control-Allow-Origin"); resp.StatusCode != tt.status || got != tt.allow {
+			t.Errorf("%s from %s = %d, allow origin %q; want %d, %q", tt.method, tt.origin, resp.StatusCode, got, tt.status, tt.allow)
+		}
+	}
+}
+
+func TestServeReadyz(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now().UTC()
+	opts := serveOptions{Manifest: filepath.Join(dir, "manifest.json"), Summary: filepath.Join(dir, "summary.json"), MaxAge: 48 * time.Hour}
+	report := func() ([]models.Analysis, error) { return []models.Analysis{}, nil }
+	writeRun := func(finished time.Time) {
+		m := manifest.Manifest{RunID: "run", FinishedAt: finished, Vendors: []manifest.VendorStatus{
+			{Vendor: "Fresh", Status: manifest.StatusScraped},
+			{Vendor: "Blocked", Status: manifest.StatusFailed},
+		}}
+		s := summary.Summary{Vendors: []summary.Vendor{
+			{Vendor: "Fresh", LastScraped: now.Add(-time.Hour).Format(time.RFC3339)},
+			{Vendor: "Blocked", LastScraped: now.Add(-72 * time.Hour).Format(time.RFC3339)},
+		}}
+		if err := storage.SaveJSON(opts.Manifest, m); err != nil {
+			t.Fatal(err)
+		}
+		if err := storage.SaveJSON(opts.Summary, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := readiness(report, opts, now); got.Status != readyUnavailable {
+		t.Errorf("readiness() without a manifest = %q, want %q", got.Status, readyUnavailable)
+	}
+
+	writeRun(now.Add(-time.Hour))
+	srv := httptest.NewServer(newServeMux(report, dir, opts))
+	defer srv.Close()
+	resp, err := http.Get(srv.URL + "/readyz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got readyStatus
+	err = json.NewDecoder(resp.Body).Decode(&got)
+	resp.Body.Close()
+	want := []readyVendor{
+		{Vendor: "Fresh", Status: manifest.StatusScraped, LastScraped: now.Add(-time.Hour).Format(time.RFC3339), AgeSeconds: 3600},
+		{Vendor: "Blocked", Status: manifest.StatusFailed, LastScraped: now.Add(-72 * time.Hour).Format(time.RFC3339), AgeSeconds: 72 * 3600, Stale: true},
+	}
+	if err != nil || resp.StatusCode != http.StatusOK || got.Status != readyDegraded || len(got.Vendors) != 2 {
+		t.Errorf("GET /readyz = %d %+v (%v), want 200 degraded with 2 vendors", resp.StatusCode, got, err)
+	}
+	if got := readiness(report, opts, now); !reflect.DeepEqual(got.Vendors, want) {
+		t.Errorf("readiness() vendors = %+v, want %+v", got.Vendors, want)
+	}
+
+	writeRun(now.Add(-72 * time.Hour))
+	if got := readiness(report, opts, now); got.Status != readyStale {
+		t.Errorf("readiness() of a 3-day-old run = %q, want %q", got.Status, readyStale)
+	}
+	resp, err = http.Get(srv.URL + "/readyz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("GET /readyz of a stale run = %d, want 503", resp.StatusCode)
+	}
+
+	resp, err = http.Get(srv.URL + "/healthz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("GET /healthz = %d, want 200", resp.StatusCode)
+	}
+}
+
+func TestCurrencyChecks(t *testing.T) {
+	products := []models.Product{
+		{Handle: "a", Currency: "USD"},
+		{Handle: "b", Currency: "GBP"},
+		{Handle: "c", Currency: "GBP"},
+		{Handle: "d"},
+	}
+	mismatches := currencyMismatches("Shop", "USD", products)
+	if len(mismatches) != 1 || mismatches[0].URL != "b" || mismatches[0].Class != runerrors.ClassCurrency {
+		t.Errorf("currencyMismatches() = %+v, want one GBP entry for b", mismatches)
+	}
+
+	reg := rules.Registry{
+		rules.GlobalKey: {ExchangeRates: map[string]float64{"EUR": 1.08}},
+		"Pinned":        {Currency: "USD"},
+	}
+	tests := []struct {
+		vendor, inferred string
+		adopt, warn      bool
+	}{
+		{"Shop", "USD", true, false},   // Agrees with the default
+		{"Shop", "EUR", true, false},   // Has a rate: converted from the next run
+		{"Shop", "GBP", false, true},   // No rate
+		{"Pinned", "EUR", false, true}, // The rules set another currency
+	}
+	for _, tt := range tests {
+		adopt, e := checkInferredCurrency(reg, tt.vendor, "https://shop.example/", tt.inferred, "domain")
+		if adopt != tt.adopt || (e != nil) != tt.warn {
+			t.Errorf("checkInferredCurrency(%s, %s) = %v, %+v; want adopt %v, warning %v", tt.vendor, tt.inferred, adopt, e, tt.adopt, tt.warn)
+		}
+	}
+}
+
+func TestExtendReport(t *testing.T) {
+	var points []history.Point
+	for day := 1; day <= sparklineDays+5; day++ {
+		points = append(points, history.Point{Date: fmt.Sprintf("d%02d", day), Price: float64(day)})
+	}
+	store := history.Store{
+		history.Key("US", "nmn", "60ct"): points,
+		history.Key("EU", "nmn", "100g"): {{Price: 40}, {Price: 50}},
+	}
+	report := []models.Analysis{
+		{Vendor: "US", Handle: "nmn", Variant: "60ct", Price: 35},
+		{Vendor: "EU", Handle: "nmn", Variant: "100g", Price: 54, NativePrice: 50, NativeCurrency: "EUR"},
+		{Vendor: "New", Handle: "nmn", Variant: "60ct"},
+	}
+	extended := extendReport(report, store)
+
+	if got := extended[0].RecentPrices; len(got) != sparklineDays || got[0] != 6 || got[len(got)-1] != 35 {
+		t.Errorf("US prices = %v, want the last %d (6…35)", got, sparklineDays)
+	}
+	if got := extended[1].RecentPrices; !reflect.DeepEqual(got, []float64{43.2, 54}) {
+		t.Errorf("EU prices = %v, want [43.2 54] in USD", got)
+	}
+	if extended[2].RecentPrices != nil || report[0].RecentPrices != nil {
+		t.Errorf("RecentPrices set without history or on the input report")
+	}
+}
+
+func TestAttributionOnlyExtended(t *testing.T) {
+	report := []models.Analysis{{Vendor: "US", Handle: "nmn", Variant: "60ct", Price: 35,
+		Attribution: &models.Attribution{Price: "shopify-api", Grams: "override"}}}
+	lean := withoutAttribution(report)
+	if lean[0].Attribution != nil || report[0].Attribution == nil {
+		t.Errorf("withoutAttribution: lean %+v, input %+v; want only the copy stripped", lean[0].Attribution, report[0].Attribution)
+	}
+	if got := extendReport(report, history.Store{}); got[0].Attribution == nil || got[0].Attribution.Grams != "override" {
+		t.Errorf("extended attribution = %+v, want it kept", got[0].Attribution)
+	}
+
+	loc, _ := locale.Lookup("en")
+	out := formatExplain(report, loc)
+	for _, want := range []string{"shopify-api", "override", "—"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("formatExplain() = %q, missing %q", out, want)
+		}
+	}
+}
+
+func TestWithTrackedCollections(t *testing.T) {
+	vendors := []models.Vendor{
+		{Name: "Renue", Type: "shopify", DiscoverTracked: true, DiscoverCollections: []string{"longevity", "nmn"}},
+		{Name: "Scoped", Type: "shopify", DiscoverTracked: true},
+		{Name: "Plain", Type: "shopify", DiscoverCollections: []string{"nmn"}},
+	}
+	reg := rules.Registry{"Scoped": {Supplements: []string{"trimethylglycine"}}}
+	got := withTrackedCollections(vendors, taxonomy.Defaults().Select([]string{"nmn", "tmg"}), reg)
+
+	want := [][]string{
+		{"nmn", "tmg", "trimethylglycine"},
+		{"tmg", "trimethylglycine"},
+		nil,
+	}
+	for i, w := range want {
+		if !reflect.DeepEqual(got[i].DiscoverWords, w) {
+			t.Errorf("%s words = %v, want %v", got[i].Name, got[i].DiscoverWords, w)
+		}
+	}
+	if !reflect.DeepEqual(got[0].DiscoverCollections, []string{"longevity", "nmn"}) {
+		t.Errorf("Renue keywords = %v, want its own unchanged", got[0].DiscoverCollections)
+	}
+}
+
+func TestWorkerDiscoversTrackedCollections(t *testing.T) {
+	shop := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		first := r.URL.Query().Get("page") == "1"
+		switch {
+		case r.URL.Path == "/collections.json" && first:
+			w.Write([]byte(`{"collections": [{"title": "NMN Powders", "handle": "nmn-powders"}]}`))
+		case r.URL.Path == "/collections.json":
+			w.Write([]byte(`{"collections": []}`))
+		case r.URL.Path == "/collections/nmn-powders/products.json" && first:
+			w.Write([]byte(`{"products": [{"id": 1, "handle": "nmn", "title": "NMN Powder",
+				"variants": [{"id": 11, "title": "Default", "price": "50.00", "available": true}]}]}`))
+		case strings.HasSuffix(r.URL.Path, "products.json"):
+			w.Write([]byte(`{"products": []}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer shop.Close()
+
+	q := queue.NewMemory(0)
+	srv := httptest.NewServer(queue.Handler(q))
+	defer srv.Close()
+	d := &dispatcher{q: q, runID: "run", timeout: 10 * time.Second, waiting: map[string]chan queue.Result{}}
+	go d.route()
+
+	vendors := withTrackedCollections([]models.Vendor{{Name: "Tracked Shop", URL: shop.URL + "/products.json", Type: "shopify", DiscoverTracked: true}},
+		taxonomy.Defaults().Select([]string{"nmn"}), nil)
+	type fetched struct {
+		products []models.Product
+		err      error
+	}
+	done := make(chan fetched, 1)
+	go func() {
+		products, _, err := d.fetch(vendors[0], nil)
+		done <- fetched{products, err}
+	}()
+
+	// The job crosses the wire as JSON, like it does to a remote worker
+	client := queue.NewClient(srv.URL+"/", "")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	job, ok, err := client.Claim(ctx, "w1")
+	if !ok || err != nil {
+		t.Fatalf("Claim() = %v, %v; want the vendor's job", ok, err)
+	}
+	work(client, "w1", job)
+	got := <-done
+	if got.err != nil || len(got.products) != 1 || got.products[0].Handle != "nmn" {
+		t.Errorf("fetch() = %+v, %v; want the NMN found in the discovered collection", got.products, got.err)
+	}
+}
+
+func TestRankMove(t *testing.T) {
+	tests := []struct {
+		row  models.Analysis
+		want string
+	}{
+		{models.Analysis{SupplementRank: 2, PreviousRank: 5, RankChange: 3}, "▲3"},
+		{models.Analysis{SupplementRank: 4, PreviousRank: 3, RankChange: -1}, "▼1"},
+		{models.Analysis{SupplementRank: 1, PreviousRank: 1}, "="},
+		{models.Analysis{SupplementRank: 7}, "new"},
+		{models.Analysis{}, "—"},
+	}
+	for _, tt := range tests {
+		if got := rankMove(tt.row); got != tt.want {
+			t.Errorf("rankMove(%+v) = %q, want %q", tt.row, got, tt.want)
+		}
+	}
+}
+
+func TestMergeMagentoOptions(t *testing.T) {
+	products := []models.Product{
+		{ID: "1", Handle: "pure-nmn", Variants: []models.Variant{{Title: "30 Capsules"}}},
+		{ID: "2", Handle: "pure-nmn", Variants: []models.Variant{{Title: "60 Capsules"}}},
+	}
+	if got := mergeMagentoOptions(models.Vendor{Type: "magento"}, products); len(got) != 1 || len(got[0].Variants) != 2 {
+		t.Errorf("magento cache = %+v, want one product with both options", got)
+	}
+	if got := mergeMagentoOptions(models.Vendor{Type: "csv"}, products); !reflect.DeepEqual(got, products) {
+		t.Errorf("csv cache = %+v, want it unchanged", got)
+	}
+}
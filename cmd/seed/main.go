@@ -0,0 +1,60 @@
+// Command seed refreshes the dataset embedded by internal/seed from data/:
+// the vendor list, vendor rules, supplement registry and every vendor file
+// with products. Run it after a good scrape and commit internal/seed/data/.
+//
+// Usage:
+//
+//	go run ./cmd/seed
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"longevity-ranker/internal/config"
+	"longevity-ranker/internal/models"
+	"longevity-ranker/internal/seed"
+	"longevity-ranker/internal/storage"
+	"longevity-ranker/internal/taxonomy"
+)
+
+func main() {
+	vendors, err := config.Load(config.Filename)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	sources := []string{config.Filename, filepath.Join(storage.DataDir, "vendor_rules.json"), taxonomy.Filename}
+	for _, v := range vendors {
+		path := storage.VendorFilename(v.Name)
+		products, err := storage.LoadJSON[[]models.Product](path)
+		if err != nil || len(products) == 0 {
+			fmt.Printf("⏭️  %s: no cached products, not seeded\n", v.Name)
+			continue
+		}
+		sources = append(sources, path)
+	}
+
+	// Start clean, so vendors removed from the list leave the seed too
+	old, _ := filepath.Glob(filepath.Join(seed.Dir, "*.json"))
+	for _, path := range old {
+		if err := os.Remove(path); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(1)
+		}
+	}
+	for _, src := range sources {
+		data, err := os.ReadFile(src)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(1)
+		}
+		if err := os.WriteFile(filepath.Join(seed.Dir, filepath.Base(src)), data, 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(1)
+		}
+	}
+	fmt.Printf("✅ Wrote %d seed file(s) to %s\n", len(sources), seed.Dir)
+}
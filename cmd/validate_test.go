@@ -0,0 +1,40 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestValidateVendorJSON(t *testing.T) {
+	fixture, err := os.ReadFile(mockFixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if products, problems := validateVendorJSON(fixture); len(products) == 0 || len(problems) > 0 {
+		t.Errorf("mock fixture: %d products, problems %v", len(products), problems)
+	}
+
+	cases := []struct {
+		name string
+		json string
+		want string // substring of the single expected problem
+	}{
+		{"null", `null`, "contains null"},
+		{"not an array", `{"id": "1"}`, "not a valid product list"},
+		{"misspelled field", `[{"id": "1", "title": "NMN", "handle": "nmn", "variants": [{"title": "60 Capsules", "prise": "10"}]}]`, `unknown field "prise"`},
+		{"missing price", `[{"id": "1", "title": "NMN", "handle": "nmn", "variants": [{"title": "60 Capsules", "available": true}]}]`, "missing price"},
+		{"bad price", `[{"id": "1", "title": "NMN", "handle": "nmn", "variants": [{"title": "60 Capsules", "price": "$10"}]}]`, "not a number"},
+		{"no variants", `[{"id": "1", "title": "NMN", "handle": "nmn", "variants": []}]`, "no variants"},
+		{"duplicate id", `[{"id": "1", "title": "NMN", "handle": "nmn", "variants": [{"title": "A", "price": "10"}]},
+			{"id": "1", "title": "NMN 2", "handle": "nmn-2", "variants": [{"title": "A", "price": "10"}]}]`, "duplicate id"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, problems := validateVendorJSON([]byte(tc.json))
+			if len(problems) != 1 || !strings.Contains(problems[0], tc.want) {
+				t.Errorf("problems = %q, want one containing %q", problems, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package alerts
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// WebhookEnv names the environment variable holding the incoming-webhook URL
+// alerts are posted to. Unset, alerts are only printed.
+const WebhookEnv = "ALERT_WEBHOOK_URL"
+
+// Alert kinds.
+const (
+	KindAuditContender = "audit_contender" // An audit gap that could beat the #1 for its supplement
+	KindTest           = "test"            // Sent on request, to check the webhook (serve's POST /api/alerts/test)
+)
+
+// Alert is one notification for the operator.
+type Alert struct {
+	Kind    string `json:"kind"`
+	Vendor  string `json:"vendor"`
+	Handle  string `json:"handle"`
+	Message string `json:"message"`
+}
+
+// DefaultMax is the default cap on webhook posts per run: enough for a
+// real day's alerts, few enough that a parser regression alerting on every
+// product does not flood the channel.
+const DefaultMax = 5
+
+// digestLines is how many alerts a digest lists before it only counts the
+// rest; the run log prints them all.
+const digestLines = 20
+
+// Limits throttles the webhook posts of one run.
+type Limits struct {
+	Max    int  // Most posts; past it, the remaining alerts share the last post. 0 = no cap
+	Digest bool // Post all the run's alerts as one digest
+}
+
+// message is one webhook post and what it covers, for error reports.
+type message struct {
+	text  string
+	about string
+}
+
+// client bounds each webhook post, so an unreachable endpoint cannot stall
+// the end of a run.
+var client = &http.Client{Timeout: 10 * time.Second}
+
+// Notify prints every alert and, when webhook is set, posts them to it as
+// {"text": message}, the body Slack and Mattermost incoming webhooks accept:
+// one post per alert, batched under lim (see batch). A failed post does not
+// stop the others; their errors are joined.
+func Notify(alerts []Alert, webhook string, lim Limits) error {
+	for _, a := range alerts {
+		fmt.Printf("🚨 %s\n", a.Message)
+	}
+	if webhook == "" {
+		return nil
+	}
+	messages := batch(alerts, lim)
+	if len(messages) < len(alerts) {
+		fmt.Printf("📨 Posting %d alert(s) in %d message(s)\n", len(alerts), len(messages))
+	}
+	var errs []error
+	for _, m := range messages {
+		if err := post(webhook, m.text); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", m.about, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// batch groups alerts into webhook posts. In digest mode, or when there are
+// more alerts than lim.Max, every alert past the first Max-1 goes into one
+// digest post, so a run never posts more than Max messages.
+func batch(alerts []Alert, lim Limits) []message {
+	single := len(alerts)
+	if lim.Digest && len(alerts) > 1 {
+		single = 0
+	} else if lim.Max > 0 && len(alerts) > lim.Max {
+		single = lim.Max - 1
+	}
+	var messages []message
+	for _, a := range alerts[:single] {
+		messages = append(messages, message{text: a.Message, about: fmt.Sprintf("alert for %s/%s", a.Vendor, a.Handle)})
+	}
+	if rest := alerts[single:]; len(rest) > 0 {
+		messages = append(messages, digest(rest, single > 0))
+	}
+	return messages
+}
+
+// digest lists alerts in one post, the first digestLines of them in full.
+func digest(alerts []Alert, more bool) message {
+	var b strings.Builder
+	if more {
+		fmt.Fprintf(&b, "…and %d more alert(s):", len(alerts))
+	} else {
+		fmt.Fprintf(&b, "%d alert(s):", len(alerts))
+	}
+	for i, a := range alerts {
+		if i == digestLines {
+			fmt.Fprintf(&b, "\n…and %d more in the run log", len(alerts)-digestLines)
+			break
+		}
+		b.WriteString("\n• " + a.Message)
+	}
+	return message{text: b.String(), about: fmt.Sprintf("digest of %d alert(s)", len(alerts))}
+}
+
+// post sends one message to the webhook.
+func post(webhook, text string) error {
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return err
+	}
+	resp, err := client.Post(webhook, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
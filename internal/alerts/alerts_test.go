@@ -0,0 +1,88 @@
+package alerts
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestNotify(t *testing.T) {
+	var mu sync.Mutex
+	var texts []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct{ Text string }
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode webhook body: %v", err)
+		}
+		if strings.Contains(body.Text, "fail") {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		mu.Lock()
+		texts = append(texts, body.Text)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	alerts := []Alert{
+		{Kind: KindAuditContender, Vendor: "A", Handle: "one", Message: "first"},
+		{Kind: KindAuditContender, Vendor: "B", Handle: "two", Message: "fail"},
+		{Kind: KindAuditContender, Vendor: "C", Handle: "three", Message: "third"},
+	}
+	err := Notify(alerts, srv.URL, Limits{})
+	if err == nil || !strings.Contains(err.Error(), "B/two") || strings.Contains(err.Error(), "A/one") {
+		t.Errorf("Notify() error = %v, want only the B/two post to fail", err)
+	}
+	if strings.Join(texts, ",") != "first,third" {
+		t.Errorf("webhook received %q, want first and third", texts)
+	}
+
+	if err := Notify(alerts, "", Limits{}); err != nil {
+		t.Errorf("Notify(no webhook) = %v, want nil", err)
+	}
+
+	texts = nil
+	if err := Notify([]Alert{alerts[0], alerts[2]}, srv.URL, Limits{Digest: true}); err != nil {
+		t.Errorf("Notify(digest) = %v, want nil", err)
+	}
+	if want := "2 alert(s):\n• first\n• third"; len(texts) != 1 || texts[0] != want {
+		t.Errorf("digest posts = %q, want [%q]", texts, want)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	alerts := make([]Alert, 25)
+	for i := range alerts {
+		alerts[i] = Alert{Kind: KindAuditContender, Vendor: "V", Handle: fmt.Sprint("h", i), Message: fmt.Sprint("m", i)}
+	}
+	tests := []struct {
+		name  string
+		n     int
+		lim   Limits
+		posts int
+		last  string // Start of the last post
+	}{
+		{"no cap", 25, Limits{}, 25, "m24"},
+		{"under the cap", 3, Limits{Max: 5}, 3, "m2"},
+		{"at the cap", 5, Limits{Max: 5}, 5, "m4"},
+		{"over the cap", 25, Limits{Max: 5}, 5, "…and 21 more alert(s):\n• m4\n"},
+		{"cap of one", 3, Limits{Max: 1}, 1, "3 alert(s):\n• m0\n• m1\n• m2"},
+		{"digest", 25, Limits{Digest: true}, 1, "25 alert(s):\n• m0\n"},
+		{"digest of one", 1, Limits{Digest: true}, 1, "m0"},
+	}
+	for _, tt := range tests {
+		got := batch(alerts[:tt.n], tt.lim)
+		if len(got) != tt.posts || !strings.HasPrefix(got[len(got)-1].text, tt.last) {
+			t.Errorf("%s: batch() = %d posts, last %q; want %d, last starting %q", tt.name, len(got), got[len(got)-1].text, tt.posts, tt.last)
+		}
+	}
+
+	last := batch(alerts, Limits{Digest: true})[0].text
+	if !strings.HasSuffix(last, "• m19\n…and 5 more in the run log") || strings.Contains(last, "m20") {
+		t.Errorf("digest = %q, want the first 20 alerts and a count of the rest", last)
+	}
+}
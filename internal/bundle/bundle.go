@@ -0,0 +1,98 @@
+package bundle
+
+import (
+	"regexp"
+	"strings"
+
+	"longevity-ranker/internal/models"
+	"longevity-ranker/internal/parser"
+)
+
+// Pack phrases ("3 Pack", "6-Pack", "12 Bottles", "1 bottle") and the
+// punctuation around them, dropped from names to find a product's other
+// pack sizes.
+var (
+	rePackPhrase = regexp.MustCompile(`(?i)\b\d+\s*-?\s*(?:packs?|bottles?)\b`)
+	reNonWord    = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+)
+
+// massTolerance is how far a single's active grams may be from a bundle's
+// grams per pack and still be the unit it bundles.
+const massTolerance = 0.01
+
+// Apply compares every entry with a PackSize against the single unit it
+// bundles: an entry of the same vendor and purchase type, with no
+// PackSize, whose name differs only by the pack phrase and whose active
+// grams are the bundle's per pack. Shopify lists multi-packs as variants
+// or as products of their own, and Magento bulk tiers as "- 3 Pack"
+// options, so names are compared rather than handles. Only singles above
+// the fold count; among several (flavors), the cheapest per gram is the
+// one the bundle must beat. Entries without a single keep no saving.
+func Apply(report []models.Analysis) {
+	groups := make(map[string][]int)
+	for i := range report {
+		k := groupKey(report[i])
+		groups[k] = append(groups[k], i)
+	}
+
+	for _, members := range groups {
+		for _, i := range members {
+			b := &report[i]
+			b.BundleSaving, b.BundleSavingPct, b.BundleDearer = 0, 0, false
+			if b.PackSize < 2 || b.ActiveGrams <= 0 {
+				continue
+			}
+			perPack := b.ActiveGrams / float64(b.PackSize)
+			single, ok := cheapestSingle(report, members, perPack)
+			if !ok {
+				continue
+			}
+			b.BundleSaving = single.CostPerGram*perPack - b.Price/float64(b.PackSize)
+			b.BundleSavingPct = (single.CostPerGram - b.CostPerGram) / single.CostPerGram * 100
+			b.BundleDearer = b.CostPerGram > single.CostPerGram*(1+1e-9)
+		}
+	}
+}
+
+// Dearer returns the one-time entries that cost more per gram than buying
+// their single unit, in report order.
+func Dearer(report []models.Analysis) []models.Analysis {
+	var dearer []models.Analysis
+	for _, a := range report {
+		if a.BundleDearer && !a.IsSubscription {
+			dearer = append(dearer, a)
+		}
+	}
+	return dearer
+}
+
+// cheapestSingle returns the member with no PackSize above the fold whose
+// active grams are within massTolerance of grams, lowest cost per gram
+// first.
+func cheapestSingle(report []models.Analysis, members []int, grams float64) (models.Analysis, bool) {
+	var best models.Analysis
+	found := false
+	for _, i := range members {
+		s := report[i]
+		if s.PackSize != 0 || s.ActiveGrams <= 0 || s.CostPerGram <= 0 || parser.BelowFold(s) {
+			continue
+		}
+		if d := s.ActiveGrams - grams; d > grams*massTolerance || -d > grams*massTolerance {
+			continue
+		}
+		if !found || s.CostPerGram < best.CostPerGram {
+			best, found = s, true
+		}
+	}
+	return best, found
+}
+
+// groupKey is the vendor, purchase type and name without its pack phrase:
+// "Pure NMN (60 Capsules - 3 Pack)" and "Pure NMN (60 Capsules)" share one.
+func groupKey(a models.Analysis) string {
+	stem := reNonWord.ReplaceAllString(rePackPhrase.ReplaceAllString(strings.ToLower(a.Name), " "), " ")
+	if a.IsSubscription {
+		return a.Vendor + "|sub|" + strings.TrimSpace(stem)
+	}
+	return a.Vendor + "|" + strings.TrimSpace(stem)
+}
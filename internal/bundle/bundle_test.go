@@ -0,0 +1,56 @@
+package bundle
+
+import (
+	"math"
+	"testing"
+
+	"longevity-ranker/internal/models"
+)
+
+func TestApply(t *testing.T) {
+	entry := func(name string, price, grams float64, pack int) models.Analysis {
+		return models.Analysis{Vendor: "Shop", Name: name, Price: price, ActiveGrams: grams, CostPerGram: price / grams, PackSize: pack, Confidence: 0.75}
+	}
+	report := []models.Analysis{
+		entry("Pure NMN (60 Capsules)", 80, 30, 0),
+		entry("Pure NMN (60 Capsules - 3 Pack)", 216, 90, 3),
+		entry("Pure NMN (60 Capsules - 6 Pack)", 500, 180, 6), // Dearer than six singles
+		entry("NMN Pro 300, 30 capsules - 3-Pack", 72, 27, 3), // Its single is another product
+		entry("NMN Pro 300, 30 capsules", 27, 9, 0),
+		entry("TMG 90 Capsules (12 Bottles)", 480, 540, 12),    // The single is flagged
+		entry("Pure NMN (120 Capsules - 2 Pack)", 300, 120, 2), // No single of 60 g
+	}
+	flagged := entry("TMG 90 Capsules (1 Bottle)", 30, 45, 0)
+	flagged.NeedsReview = true
+	report = append(report, flagged)
+	sub := entry("Pure NMN (60 Capsules - 3 Pack) (Subscribe & Save)", 100, 90, 3)
+	sub.IsSubscription = true
+	report = append(report, sub) // No subscription single to compare with
+
+	Apply(report)
+
+	tests := []struct {
+		saving, pct float64
+		dearer      bool
+	}{
+		{0, 0, false},
+		{8, 10, false},
+		{-3.33, -4.17, true},
+		{3, 11.11, false},
+		{0, 0, false},
+		{0, 0, false},
+		{0, 0, false},
+		{0, 0, false},
+		{0, 0, false},
+	}
+	for i, tt := range tests {
+		a := report[i]
+		if math.Abs(a.BundleSaving-tt.saving) > 0.01 || math.Abs(a.BundleSavingPct-tt.pct) > 0.01 || a.BundleDearer != tt.dearer {
+			t.Errorf("%s: saving $%.2f (%.2f%%), dearer %v; want $%.2f (%.2f%%), %v",
+				a.Name, a.BundleSaving, a.BundleSavingPct, a.BundleDearer, tt.saving, tt.pct, tt.dearer)
+		}
+	}
+	if got := Dearer(report); len(got) != 1 || got[0].Name != "Pure NMN (60 Capsules - 6 Pack)" {
+		t.Errorf("Dearer() = %+v, want the 6 pack", got)
+	}
+}
@@ -0,0 +1,297 @@
+package changes
+
+import (
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"longevity-ranker/internal/history"
+	"longevity-ranker/internal/models"
+	"longevity-ranker/internal/storage"
+)
+
+// Filename is the change-set path, relative to the repo root. Each run
+// overwrites it.
+var Filename = filepath.Join(storage.DataDir, "changes.json")
+
+// ProductChange is a product that appeared in or disappeared from a vendor's
+// catalog. Title is empty for delisted products: only the handle is recorded
+// in the price history.
+type ProductChange struct {
+	Vendor string `json:"vendor"`
+	Handle string `json:"handle"`
+	Title  string `json:"title,omitempty"`
+}
+
+// PriceChange is a variant whose price differs from its last observation.
+type PriceChange struct {
+	Vendor    string  `json:"vendor"`
+	Handle    string  `json:"handle"`
+	Title     string  `json:"title"`
+	Variant   string  `json:"variant"`
+	OldPrice  float64 `json:"old_price"`
+	NewPrice  float64 `json:"new_price"`
+	ChangePct float64 `json:"change_pct"` // Negative for a price drop, rounded to 0.1
+	Since     string  `json:"since"`      // Date of the old price
+}
+
+// AvailabilityChange is a variant that went out of or came back into stock.
+type AvailabilityChange struct {
+	Vendor    string `json:"vendor"`
+	Handle    string `json:"handle"`
+	Title     string `json:"title"`
+	Variant   string `json:"variant"`
+	Available bool   `json:"available"` // true = back in stock
+	Since     string `json:"since"`     // Date of the previous observation
+	// OutOfStockSince is, for a restock, the first date of the unavailable
+	// streak that just ended.
+	OutOfStockSince string `json:"out_of_stock_since,omitempty"`
+}
+
+// ChangeSet is everything that changed in the catalogs since each variant was
+// last observed. Slices are never nil, so the file always lists every section.
+type ChangeSet struct {
+	Date                string               `json:"date"`
+	NewProducts         []ProductChange      `json:"new_products"`
+	DelistedProducts    []ProductChange      `json:"delisted_products"`
+	PriceChanges        []PriceChange        `json:"price_changes"`
+	AvailabilityChanges []AvailabilityChange `json:"availability_changes"`
+	// BackInStock holds the restocks of watched variants (see
+	// watchlist.BackInStock). Compute leaves it empty.
+	BackInStock []AvailabilityChange `json:"back_in_stock"`
+}
+
+// Empty reports whether nothing changed.
+func (c ChangeSet) Empty() bool {
+	return len(c.NewProducts)+len(c.DelistedProducts)+len(c.PriceChanges)+len(c.AvailabilityChanges) == 0
+}
+
+// Compute compares this run's products against the price history. It must run
+// after history.Record for today, so every current variant has a point dated
+// today. current maps each vendor observed this run (scraped or loaded from
+// cache) to its products; vendors that failed are left out so their catalog is
+// not reported as delisted.
+//
+// Each variant is compared with its last point before today. A product is new
+// when none of its variants has an earlier point, and delisted when it was
+// present on the vendor's previous observation date but not today. A vendor
+// with no earlier history reports no new products, so adding a vendor does
+// not flood the feed.
+func Compute(store history.Store, today string, current map[string][]models.Product) ChangeSet {
+	cs := ChangeSet{
+		Date:                today,
+		NewProducts:         []ProductChange{},
+		DelistedProducts:    []ProductChange{},
+		PriceChanges:        []PriceChange{},
+		AvailabilityChanges: []AvailabilityChange{},
+		BackInStock:         []AvailabilityChange{},
+	}
+
+	for vendorName, products := range current {
+		prevDate := previousDate(store, vendorName, today)
+		listed := make(map[string]bool, len(products))
+
+		for _, p := range products {
+			listed[p.Handle] = true
+			seenBefore := false
+			for _, v := range p.Variants {
+				points := store[history.Key(vendorName, p.Handle, v.Title)]
+				now, ok := pointOn(points, today)
+				if !ok {
+					continue
+				}
+				prior, ok := lastBefore(points, today)
+				if !ok {
+					continue
+				}
+				seenBefore = true
+
+				if prior.Price > 0 && math.Abs(now.Price-prior.Price) >= 0.01 {
+					cs.PriceChanges = append(cs.PriceChanges, PriceChange{
+						Vendor: vendorName, Handle: p.Handle, Title: p.Title, Variant: v.Title,
+						OldPrice: prior.Price, NewPrice: now.Price,
+						ChangePct: math.Round((now.Price-prior.Price)/prior.Price*1000) / 10,
+						Since:     prior.Date,
+					})
+				}
+				if now.Available != prior.Available {
+					change := AvailabilityChange{
+						Vendor: vendorName, Handle: p.Handle, Title: p.Title, Variant: v.Title,
+						Available: now.Available, Since: prior.Date,
+					}
+					if now.Available {
+						change.OutOfStockSince = outOfStockSince(points, today)
+					}
+					cs.AvailabilityChanges = append(cs.AvailabilityChanges, change)
+				}
+			}
+			if !seenBefore && prevDate != "" {
+				cs.NewProducts = append(cs.NewProducts, ProductChange{Vendor: vendorName, Handle: p.Handle, Title: p.Title})
+			}
+		}
+
+		if prevDate == "" {
+			continue
+		}
+		delisted := make(map[string]bool)
+		prefix := vendorName + "|"
+		for key, points := range store {
+			if !strings.HasPrefix(key, prefix) || len(points) == 0 {
+				continue
+			}
+			handle, _, _ := strings.Cut(strings.TrimPrefix(key, prefix), "|")
+			if listed[handle] || delisted[handle] || points[len(points)-1].Date != prevDate {
+				continue
+			}
+			delisted[handle] = true
+			cs.DelistedProducts = append(cs.DelistedProducts, ProductChange{Vendor: vendorName, Handle: handle})
+		}
+	}
+
+	sortChanges(&cs)
+	return cs
+}
+
+// Diff compares the reports of two runs, from (dated fromDate) and to
+// (dated toDate), and returns what changed between them as a change set
+// dated toDate. Only one-time entries count. A product (vendor and handle)
+// is new when to ranks it and from does not, and delisted the other way
+// round; the price of a variant ranked by both changed when its entry's
+// Price did, and its availability when Unavailable did (reports run with
+// -include-unavailable). Since is fromDate.
+func Diff(fromDate string, from []models.Analysis, toDate string, to []models.Analysis) ChangeSet {
+	cs := ChangeSet{
+		Date:                toDate,
+		NewProducts:         []ProductChange{},
+		DelistedProducts:    []ProductChange{},
+		PriceChanges:        []PriceChange{},
+		AvailabilityChanges: []AvailabilityChange{},
+		BackInStock:         []AvailabilityChange{},
+	}
+	before, beforeProducts := reportIndex(from)
+	after, afterProducts := reportIndex(to)
+
+	for key, a := range afterProducts {
+		if _, ok := beforeProducts[key]; !ok {
+			cs.NewProducts = append(cs.NewProducts, ProductChange{Vendor: a.Vendor, Handle: a.Handle, Title: a.Name})
+		}
+	}
+	for key, a := range beforeProducts {
+		if _, ok := afterProducts[key]; !ok {
+			cs.DelistedProducts = append(cs.DelistedProducts, ProductChange{Vendor: a.Vendor, Handle: a.Handle, Title: a.Name})
+		}
+	}
+	for key, now := range after {
+		prior, ok := before[key]
+		if !ok {
+			continue
+		}
+		if prior.Price > 0 && math.Abs(now.Price-prior.Price) >= 0.01 {
+			cs.PriceChanges = append(cs.PriceChanges, PriceChange{
+				Vendor: now.Vendor, Handle: now.Handle, Title: now.Name, Variant: now.Variant,
+				OldPrice: prior.Price, NewPrice: now.Price,
+				ChangePct: math.Round((now.Price-prior.Price)/prior.Price*1000) / 10,
+				Since:     fromDate,
+			})
+		}
+		if now.Unavailable != prior.Unavailable {
+			cs.AvailabilityChanges = append(cs.AvailabilityChanges, AvailabilityChange{
+				Vendor: now.Vendor, Handle: now.Handle, Title: now.Name, Variant: now.Variant,
+				Available: !now.Unavailable, Since: fromDate,
+			})
+		}
+	}
+
+	sortChanges(&cs)
+	return cs
+}
+
+// reportIndex returns a report's one-time entries by history.Key, and the
+// first entry of each product by vendor and handle.
+func reportIndex(report []models.Analysis) (variants, products map[string]models.Analysis) {
+	variants, products = map[string]models.Analysis{}, map[string]models.Analysis{}
+	for _, a := range report {
+		if a.IsSubscription {
+			continue
+		}
+		if _, ok := variants[history.Key(a.Vendor, a.Handle, a.Variant)]; !ok {
+			variants[history.Key(a.Vendor, a.Handle, a.Variant)] = a
+		}
+		if _, ok := products[a.Vendor+"|"+a.Handle]; !ok {
+			products[a.Vendor+"|"+a.Handle] = a
+		}
+	}
+	return variants, products
+}
+
+// previousDate returns the latest date before today on which any of the
+// vendor's variants was observed, or "" when there is none.
+func previousDate(store history.Store, vendorName, today string) string {
+	prefix := vendorName + "|"
+	latest := ""
+	for key, points := range store {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if pt, ok := lastBefore(points, today); ok && pt.Date > latest {
+			latest = pt.Date
+		}
+	}
+	return latest
+}
+
+// pointOn returns the point dated date, which Record keeps last.
+func pointOn(points []history.Point, date string) (history.Point, bool) {
+	if n := len(points); n > 0 && points[n-1].Date == date {
+		return points[n-1], true
+	}
+	return history.Point{}, false
+}
+
+// lastBefore returns the latest point dated before date.
+func lastBefore(points []history.Point, date string) (history.Point, bool) {
+	for i := len(points) - 1; i >= 0; i-- {
+		if points[i].Date < date {
+			return points[i], true
+		}
+	}
+	return history.Point{}, false
+}
+
+// outOfStockSince returns the date of the first point in the unavailable
+// streak that ends just before today.
+func outOfStockSince(points []history.Point, today string) string {
+	since := ""
+	for i := len(points) - 1; i >= 0; i-- {
+		if points[i].Date >= today {
+			continue
+		}
+		if points[i].Available {
+			break
+		}
+		since = points[i].Date
+	}
+	return since
+}
+
+// sortChanges orders every section by vendor, handle, then variant, so the
+// file diffs cleanly between runs.
+func sortChanges(cs *ChangeSet) {
+	for _, list := range [][]ProductChange{cs.NewProducts, cs.DelistedProducts} {
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Vendor != list[j].Vendor {
+				return list[i].Vendor < list[j].Vendor
+			}
+			return list[i].Handle < list[j].Handle
+		})
+	}
+	sort.Slice(cs.PriceChanges, func(i, j int) bool {
+		a, b := cs.PriceChanges[i], cs.PriceChanges[j]
+		return history.Key(a.Vendor, a.Handle, a.Variant) < history.Key(b.Vendor, b.Handle, b.Variant)
+	})
+	sort.Slice(cs.AvailabilityChanges, func(i, j int) bool {
+		a, b := cs.AvailabilityChanges[i], cs.AvailabilityChanges[j]
+		return history.Key(a.Vendor, a.Handle, a.Variant) < history.Key(b.Vendor, b.Handle, b.Variant)
+	})
+}
@@ -0,0 +1,122 @@
+package changes
+
+import (
+	"reflect"
+	"testing"
+
+	"longevity-ranker/internal/history"
+	"longevity-ranker/internal/models"
+)
+
+func product(handle, title string, variants ...models.Variant) models.Product {
+	return models.Product{Handle: handle, Title: title, Variants: variants}
+}
+
+func TestCompute(t *testing.T) {
+	const yesterday, today = "2026-01-01", "2026-01-02"
+	store := history.Store{}
+
+	// The 250g sold out three days ago
+	history.Record(store, "2025-12-29", "Vendor", product("nmn-powder", "NMN Powder",
+		models.Variant{Title: "100g", Price: "50.00", Available: true},
+		models.Variant{Title: "250g", Price: "100.00", Available: true}))
+	history.Record(store, "2025-12-30", "Vendor", product("nmn-powder", "NMN Powder",
+		models.Variant{Title: "100g", Price: "50.00", Available: true},
+		models.Variant{Title: "250g", Price: "100.00", Available: false}))
+
+	// Yesterday's catalog
+	history.Record(store, yesterday, "Vendor", product("nmn-powder", "NMN Powder",
+		models.Variant{Title: "100g", Price: "50.00", Available: true},
+		models.Variant{Title: "250g", Price: "100.00", Available: false}))
+	history.Record(store, yesterday, "Vendor", product("old-capsules", "Old Capsules",
+		models.Variant{Title: "Default", Price: "30.00", Available: true}))
+	// Delisted long ago: not reported again
+	history.Record(store, "2025-06-01", "Vendor", product("ancient", "Ancient",
+		models.Variant{Title: "Default", Price: "10.00", Available: true}))
+	// A failed vendor is absent from current and must not be reported
+	history.Record(store, yesterday, "Failed Vendor", product("tmg", "TMG",
+		models.Variant{Title: "Default", Price: "20.00", Available: true}))
+
+	// Today's catalog
+	current := map[string][]models.Product{
+		"Vendor": {
+			product("nmn-powder", "NMN Powder",
+				models.Variant{Title: "100g", Price: "45.00", Available: true},
+				models.Variant{Title: "250g", Price: "100.00", Available: true}),
+			product("new-tmg", "New TMG",
+				models.Variant{Title: "Default", Price: "25.00", Available: true}),
+		},
+		// First run for this vendor: nothing is "new"
+		"Fresh Vendor": {
+			product("resveratrol", "Resveratrol",
+				models.Variant{Title: "Default", Price: "40.00", Available: true}),
+		},
+	}
+	for vendorName, products := range current {
+		for _, p := range products {
+			history.Record(store, today, vendorName, p)
+		}
+	}
+
+	got := Compute(store, today, current)
+	want := ChangeSet{
+		Date:             today,
+		NewProducts:      []ProductChange{{Vendor: "Vendor", Handle: "new-tmg", Title: "New TMG"}},
+		DelistedProducts: []ProductChange{{Vendor: "Vendor", Handle: "old-capsules"}},
+		BackInStock:      []AvailabilityChange{},
+		PriceChanges: []PriceChange{{
+			Vendor: "Vendor", Handle: "nmn-powder", Title: "NMN Powder", Variant: "100g",
+			OldPrice: 50, NewPrice: 45, ChangePct: -10, Since: yesterday,
+		}},
+		AvailabilityChanges: []AvailabilityChange{{
+			Vendor: "Vendor", Handle: "nmn-powder", Title: "NMN Powder", Variant: "250g",
+			Available: true, Since: yesterday, OutOfStockSince: "2025-12-30",
+		}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Compute() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestComputeUnchanged(t *testing.T) {
+	store := history.Store{}
+	p := product("nmn", "NMN", models.Variant{Title: "Default", Price: "50.00", Available: true})
+	history.Record(store, "2026-01-01", "Vendor", p)
+	history.Record(store, "2026-01-02", "Vendor", p)
+
+	got := Compute(store, "2026-01-02", map[string][]models.Product{"Vendor": {p}})
+	if !got.Empty() {
+		t.Errorf("Compute() = %+v, want no changes", got)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	entry := func(handle, variant string, price float64) models.Analysis {
+		return models.Analysis{Vendor: "Vendor", Handle: handle, Name: "NMN " + variant, Variant: variant, Price: price}
+	}
+	sub := entry("nmn-powder", "100g", 40)
+	sub.IsSubscription = true
+	soldOut := entry("nmn-powder", "250g", 100)
+	soldOut.Unavailable = true
+	from := []models.Analysis{entry("nmn-powder", "100g", 50), soldOut, entry("nmn-caps", "60 Capsules", 30)}
+	to := []models.Analysis{entry("nmn-powder", "100g", 45), sub, entry("nmn-powder", "250g", 100), entry("nmn-tabs", "90 Tablets", 35)}
+
+	got := Diff("2026-01-01", from, "2026-01-08", to)
+	want := ChangeSet{
+		Date:             "2026-01-08",
+		NewProducts:      []ProductChange{{Vendor: "Vendor", Handle: "nmn-tabs", Title: "NMN 90 Tablets"}},
+		DelistedProducts: []ProductChange{{Vendor: "Vendor", Handle: "nmn-caps", Title: "NMN 60 Capsules"}},
+		PriceChanges: []PriceChange{{
+			Vendor: "Vendor", Handle: "nmn-powder", Title: "NMN 100g", Variant: "100g",
+			OldPrice: 50, NewPrice: 45, ChangePct: -10, Since: "2026-01-01",
+		}},
+		AvailabilityChanges: []AvailabilityChange{{
+			Vendor: "Vendor", Handle: "nmn-powder", Title: "NMN 250g", Variant: "250g",
+			Available: true, Since: "2026-01-01",
+		}},
+		BackInStock: []AvailabilityChange{},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() =\n%+v\nwant\n%+v", got, want)
+	}
+}
@@ -1,23 +1,68 @@
 package config
 
-import "longevity-ranker/internal/models"
+import (
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
 
-func GetVendors() []models.Vendor {
+	"longevity-ranker/internal/models"
+	"longevity-ranker/internal/storage"
+)
+
+// Filename is the vendor list path, relative to the repo root.
+var Filename = filepath.Join(storage.DataDir, "vendors.json")
+
+// Schedules other than a weekday list.
+const (
+	ScheduleDaily  = "daily"  // Scraped on every -refresh (the default)
+	ScheduleManual = "manual" // Never scraped; data/<vendor>.json is maintained by hand
+)
+
+// reASIN matches an Amazon Standard Identification Number (B0CXYZ1234).
+var reASIN = regexp.MustCompile(`^[A-Z0-9]{10}$`)
+
+// reMarket matches a Shopify Markets locale: a language, optionally with a
+// country (fr, en-gb).
+var reMarket = regexp.MustCompile(`^[a-z]{2}(-[a-z]{2})?$`)
+
+// weekdays maps the schedule's day abbreviations to time.Weekday.
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// window is a blackout window: minutes since UTC midnight, from start
+// (inclusive) to end (exclusive), wrapping past midnight when end < start.
+// days limits it to windows starting on those weekdays; nil = every day.
+type window struct {
+	days       []time.Weekday
+	start, end int
+}
+
+// Defaults is the built-in vendor list, written to Filename when it does
+// not exist yet.
+func Defaults() []models.Vendor {
 	return []models.Vendor{
 		{
-			Name:     "ProHealth",
-			URL:      "https://www.prohealth.com/collections/nmn-capsules/products.json",
-			Type:     "shopify",
+			Name: "ProHealth",
+			URL:  "https://www.prohealth.com/collections/nmn-capsules/products.json",
+			Type: "shopify",
 		},
 		{
-			Name:     "Renue By Science",
-			URL:      "https://renuebyscience.com/collections/nmn/products.json",
-			Type:     "shopify",
+			Name:            "Renue By Science",
+			URL:             "https://renuebyscience.com/collections/nmn/products.json",
+			Type:            "shopify",
+			DiscoverTracked: true, // NAD, TMG and resveratrol live in their own collections
 		},
 		{
-			Name:     "NMN Bio",
-			URL:      "https://nmnbio.co.uk/collections/all-products/products.json?currency=USD",
-			Type:     "shopify",
+			Name: "NMN Bio",
+			URL:  "https://nmnbio.co.uk/collections/all-products/products.json?currency=USD",
+			Type: "shopify",
 		},
 		{
 			Name:       "Jinfiniti",
@@ -26,14 +71,14 @@ func GetVendors() []models.Vendor {
 			Cloudflare: true,
 		},
 		{
-			Name:     "Do Not Age",
-			URL:      "https://donotage.org/products/",
-			Type:     "magento",
+			Name: "Do Not Age",
+			URL:  "https://donotage.org/products/",
+			Type: "magento",
 		},
 		{
-			Name:     "Nutricost",
-			URL:      "https://nutricost.com/collections/all-items/products.json",
-			Type:     "shopify",
+			Name: "Nutricost",
+			URL:  "https://nutricost.com/collections/all-items/products.json",
+			Type: "shopify",
 		},
 		{
 			Name:       "Wonderfeel",
@@ -42,9 +87,208 @@ func GetVendors() []models.Vendor {
 			Cloudflare: true,
 		},
 		{
-			Name:       "Blueprint",
-			URL:        "https://blueprint.bryanjohnson.com/collections/supplements/products.json",
-			Type:       "shopify",
+			Name: "Blueprint",
+			URL:  "https://blueprint.bryanjohnson.com/collections/supplements/products.json",
+			Type: "shopify",
 		},
 	}
 }
+
+// Load reads the vendor list from path. A missing file is created from
+// Defaults, so a fresh checkout works unchanged and the list can then be
+// edited without rebuilding. Vendors need a unique name, a URL and a type,
+// and a valid schedule; amazon vendors need valid ASINs, and only
+// generic-html vendors list productPages.
+func Load(path string) ([]models.Vendor, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		vendors := Defaults()
+		if err := storage.SaveJSON(path, vendors); err != nil {
+			return nil, fmt.Errorf("could not write default vendor list: %v", err)
+		}
+		fmt.Printf("📝 Wrote the default vendor list to %s\n", path)
+		return vendors, nil
+	}
+
+	vendors, err := storage.LoadJSON[[]models.Vendor](path)
+	if err != nil {
+		return nil, fmt.Errorf("could not load vendor list %s: %v", path, err)
+	}
+	var names []string
+	for i, v := range vendors {
+		switch {
+		case strings.TrimSpace(v.Name) == "":
+			return nil, fmt.Errorf("%s: vendor #%d has no name", path, i+1)
+		case slices.Contains(names, v.Name):
+			return nil, fmt.Errorf("%s: duplicate vendor %q", path, v.Name)
+		case v.URL == "" || v.Type == "":
+			return nil, fmt.Errorf("%s: vendor %q needs a url and a type", path, v.Name)
+		case v.CartPricing && v.Type != "shopify":
+			return nil, fmt.Errorf("%s: vendor %q: cartPricing needs a shopify vendor", path, v.Name)
+		case (len(v.DiscoverCollections) > 0 || v.DiscoverTracked) && v.Type != "shopify":
+			return nil, fmt.Errorf("%s: vendor %q: discoverCollections and discoverTracked need a shopify vendor", path, v.Name)
+		case v.Market != "" && v.Type != "shopify":
+			return nil, fmt.Errorf("%s: vendor %q: market needs a shopify vendor", path, v.Name)
+		case v.Market != "" && !reMarket.MatchString(v.Market):
+			return nil, fmt.Errorf("%s: vendor %q: market %q is not a lowercase locale like en-gb", path, v.Name, v.Market)
+		case v.Market != "" && v.Currency == "":
+			return nil, fmt.Errorf("%s: vendor %q: market needs the market's currency (e.g. GBP for en-gb)", path, v.Name)
+		case v.Sitemap != "" && v.Type != "magento" && v.Type != "html-ldjson":
+			return nil, fmt.Errorf("%s: vendor %q: sitemap needs a magento or html-ldjson vendor", path, v.Name)
+		case v.SitemapPattern != "" && v.Sitemap == "":
+			return nil, fmt.Errorf("%s: vendor %q: sitemapPattern needs a sitemap", path, v.Name)
+		case v.Sitemap != "" && v.Type == "magento" && v.SitemapPattern == "":
+			return nil, fmt.Errorf("%s: vendor %q: a magento sitemap needs a sitemapPattern (products and CMS pages share top-level URLs)", path, v.Name)
+		case v.Concurrency < 0 || v.RetryBackoff < 0 || v.RefreshJitter < 0 || v.RateLimit < 0:
+			return nil, fmt.Errorf("%s: vendor %q: concurrency, retryBackoff, refreshJitter and rateLimit cannot be negative", path, v.Name)
+		case (len(v.UserAgents) > 0 || v.RotateUserAgent) && v.Headers["User-Agent"] != "":
+			return nil, fmt.Errorf("%s: vendor %q: userAgents and rotateUserAgent conflict with a User-Agent header", path, v.Name)
+		case slices.Contains(v.UserAgents, ""):
+			return nil, fmt.Errorf("%s: vendor %q: userAgents cannot contain an empty string", path, v.Name)
+		case (v.Type == "amazon") != (len(v.ASINs) > 0):
+			return nil, fmt.Errorf("%s: vendor %q: asins are required for, and only for, amazon vendors", path, v.Name)
+		case len(v.ProductPages) > 0 && v.Type != "generic-html":
+			return nil, fmt.Errorf("%s: vendor %q: productPages needs a generic-html vendor", path, v.Name)
+		}
+		for _, asin := range v.ASINs {
+			if !reASIN.MatchString(asin) {
+				return nil, fmt.Errorf("%s: vendor %q: invalid ASIN %q", path, v.Name, asin)
+			}
+		}
+		if _, err := regexp.Compile(v.SitemapPattern); err != nil {
+			return nil, fmt.Errorf("%s: vendor %q: sitemapPattern: %v", path, v.Name, err)
+		}
+		if _, err := scheduledDays(v.Schedule); err != nil {
+			return nil, fmt.Errorf("%s: vendor %q: %v", path, v.Name, err)
+		}
+		for _, s := range v.Blackout {
+			if _, err := parseWindow(s); err != nil {
+				return nil, fmt.Errorf("%s: vendor %q: %v", path, v.Name, err)
+			}
+		}
+		names = append(names, v.Name)
+	}
+	return vendors, nil
+}
+
+// SetCurrencies records currencies (vendor name → ISO 4217 code) on the
+// vendors in the list at path that have none yet, leaving the rest of the
+// file as it is. It returns the names of the vendors it updated.
+func SetCurrencies(path string, currencies map[string]string) ([]string, error) {
+	vendors, err := storage.LoadJSON[[]models.Vendor](path)
+	if err != nil {
+		return nil, fmt.Errorf("could not load vendor list %s: %v", path, err)
+	}
+	var updated []string
+	for i, v := range vendors {
+		if c := currencies[v.Name]; c != "" && v.Currency == "" {
+			vendors[i].Currency = c
+			updated = append(updated, v.Name)
+		}
+	}
+	if len(updated) == 0 {
+		return nil, nil
+	}
+	if err := storage.SaveJSON(path, vendors); err != nil {
+		return nil, fmt.Errorf("could not update vendor list %s: %v", path, err)
+	}
+	return updated, nil
+}
+
+// Due reports whether the vendor's schedule allows scraping it on now's UTC
+// weekday. An invalid schedule (rejected by Load) is never due.
+func Due(v models.Vendor, now time.Time) bool {
+	days, err := scheduledDays(v.Schedule)
+	if err != nil {
+		return false
+	}
+	return days == nil || slices.Contains(days, now.UTC().Weekday())
+}
+
+// InBlackout returns the vendor's blackout window that contains now, if
+// any. Invalid windows (rejected by Load) are ignored.
+func InBlackout(v models.Vendor, now time.Time) (string, bool) {
+	for _, s := range v.Blackout {
+		if w, err := parseWindow(s); err == nil && w.contains(now) {
+			return s, true
+		}
+	}
+	return "", false
+}
+
+// Jitter returns a random delay for the vendor's next live scrape, from 0
+// to RefreshJitter.
+func Jitter(v models.Vendor) time.Duration {
+	if v.RefreshJitter <= 0 {
+		return 0
+	}
+	return rand.N(v.RefreshJitter + 1)
+}
+
+// parseWindow parses "HH:MM-HH:MM", optionally after weekdays as in a
+// schedule ("sat,sun 22:00-02:00").
+func parseWindow(s string) (window, error) {
+	invalid := fmt.Errorf("invalid blackout window %q (want \"HH:MM-HH:MM\" in UTC, optionally after weekdays like \"sun \")", s)
+	fields := strings.Fields(strings.ToLower(s))
+	var w window
+	switch len(fields) {
+	case 2:
+		days, err := scheduledDays(fields[0])
+		if err != nil || len(days) == 0 {
+			return window{}, invalid
+		}
+		w.days = days
+	case 1:
+	default:
+		return window{}, invalid
+	}
+	from, to, ok := strings.Cut(fields[len(fields)-1], "-")
+	start, err1 := time.Parse("15:04", from)
+	end, err2 := time.Parse("15:04", to)
+	if !ok || err1 != nil || err2 != nil || start.Equal(end) {
+		return window{}, invalid
+	}
+	w.start, w.end = start.Hour()*60+start.Minute(), end.Hour()*60+end.Minute()
+	return w, nil
+}
+
+// contains reports whether t falls in the window. The part of a window
+// wrapping past midnight belongs to the day it started on.
+func (w window) contains(t time.Time) bool {
+	t = t.UTC()
+	m, day := t.Hour()*60+t.Minute(), t.Weekday()
+	switch {
+	case w.start < w.end:
+		return m >= w.start && m < w.end && w.on(day)
+	case m >= w.start:
+		return w.on(day)
+	case m < w.end:
+		return w.on((day + 6) % 7)
+	}
+	return false
+}
+
+// on reports whether a window may start on day.
+func (w window) on(day time.Weekday) bool {
+	return w.days == nil || slices.Contains(w.days, day)
+}
+
+// scheduledDays parses a schedule: nil for daily, empty for manual, else
+// the listed weekdays.
+func scheduledDays(schedule string) ([]time.Weekday, error) {
+	schedule = strings.ToLower(strings.TrimSpace(schedule))
+	switch schedule {
+	case "", ScheduleDaily:
+		return nil, nil
+	case ScheduleManual:
+		return []time.Weekday{}, nil
+	}
+	var days []time.Weekday
+	for _, s := range strings.Split(schedule, ",") {
+		day, ok := weekdays[strings.TrimSpace(s)]
+		if !ok {
+			return nil, fmt.Errorf("invalid schedule %q (want daily, manual, or weekdays like \"mon,thu\")", schedule)
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
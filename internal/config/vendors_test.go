@@ -0,0 +1,167 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"longevity-ranker/internal/models"
+)
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vendors.json")
+
+	// A missing file is written from the defaults, which then load back unchanged
+	vendors, err := Load(path)
+	if err != nil || !reflect.DeepEqual(vendors, Defaults()) {
+		t.Fatalf("Load(missing) = %d vendors, %v; want the defaults", len(vendors), err)
+	}
+	if again, err := Load(path); err != nil || !reflect.DeepEqual(again, Defaults()) {
+		t.Errorf("Load(generated) = %+v, %v; want the defaults", again, err)
+	}
+
+	tests := []struct {
+		json    string
+		wantErr bool
+	}{
+		{`[{"name": "EU Shop", "url": "https://eu.example/products.json", "type": "shopify", "currency": "EUR", "schedule": "mon,thu", "timeout": "45s"}]`, false},
+		{`[{"name": "A", "url": "u", "type": "shopify"}, {"name": "A", "url": "u", "type": "shopify"}]`, true},
+		{`[{"name": "A", "type": "shopify"}]`, true},
+		{`[{"name": "A", "url": "u", "type": "shopify", "schedule": "weekly"}]`, true},
+		{`[{"name": "A", "url": "u", "type": "shopify", "timeout": "soon"}]`, true},
+		{`[{"name": "A", "url": "u", "type": "magento", "cartPricing": true}]`, true},
+		{`[{"name": "A", "url": "u", "type": "shopify", "discoverTracked": true}]`, false},
+		{`[{"name": "A", "url": "u", "type": "magento", "discoverTracked": true}]`, true},
+		{`[{"name": "A", "url": "u", "type": "html-ldjson", "discoverCollections": ["nmn"]}]`, true},
+		{`[{"name": "A", "url": "u", "type": "shopify", "market": "en-gb", "currency": "GBP"}]`, false},
+		{`[{"name": "A", "url": "u", "type": "shopify", "market": "en-gb"}]`, true},
+		{`[{"name": "A", "url": "u", "type": "shopify", "market": "en_GB", "currency": "GBP"}]`, true},
+		{`[{"name": "A", "url": "u", "type": "magento", "market": "fr", "currency": "EUR"}]`, true},
+		{`[{"name": "A", "url": "u", "type": "magento", "sitemap": "u/sitemap.xml", "sitemapPattern": "^/pure-"}]`, false},
+		{`[{"name": "A", "url": "u", "type": "magento", "sitemap": "u/sitemap.xml"}]`, true},
+		{`[{"name": "A", "url": "u", "type": "html-ldjson", "sitemap": "u/sitemap.xml"}]`, false},
+		{`[{"name": "A", "url": "u", "type": "shopify", "sitemap": "u/sitemap.xml"}]`, true},
+		{`[{"name": "A", "url": "u", "type": "html-ldjson", "sitemapPattern": "/product/"}]`, true},
+		{`[{"name": "A", "url": "u", "type": "html-ldjson", "sitemap": "u/sitemap.xml", "sitemapPattern": "("}]`, true},
+		{`[{"name": "A", "url": "https://www.amazon.com", "type": "amazon", "asins": ["B0CXYZ1234"]}]`, false},
+		{`[{"name": "A", "url": "https://www.amazon.com", "type": "amazon"}]`, true},
+		{`[{"name": "A", "url": "https://www.amazon.com", "type": "amazon", "asins": ["b0cxyz1234"]}]`, true},
+		{`[{"name": "A", "url": "u", "type": "shopify", "asins": ["B0CXYZ1234"]}]`, true},
+		{`[{"name": "A", "url": "u/nmn", "type": "generic-html", "productPages": ["u/tmg"]}]`, false},
+		{`[{"name": "A", "url": "u", "type": "magento", "productPages": ["u/tmg"]}]`, true},
+		{`[{"name": "A", "url": "u", "type": "magento", "concurrency": 4, "rateLimit": 10}]`, false},
+		{`[{"name": "A", "url": "u", "type": "magento", "concurrency": -1}]`, true},
+		{`[{"name": "A", "url": "u", "type": "shopify", "rateLimit": 0.5, "concurrency": 2}]`, false},
+		{`[{"name": "A", "url": "u", "type": "shopify", "rateLimit": -1}]`, true},
+		{`[{"name": "A", "url": "u", "type": "shopify", "maxRetries": -1, "retryBackoff": "2s"}]`, false},
+		{`[{"name": "A", "url": "u", "type": "shopify", "retryBackoff": "-2s"}]`, true},
+		{`[{"name": "A", "url": "u", "type": "shopify", "retryBackoff": "soon"}]`, true},
+		{`[{"name": "A", "url": "u", "type": "shopify", "proxyEnv": "A_PROXY", "userAgents": ["Mozilla/5.0 (X11)"]}]`, false},
+		{`[{"name": "A", "url": "u", "type": "shopify", "rotateUserAgent": true, "headers": {"User-Agent": "curl/8"}}]`, true},
+		{`[{"name": "A", "url": "u", "type": "shopify", "userAgents": [""]}]`, true},
+		{`[{"name": "A", "url": "u", "type": "shopify", "blackout": ["03:00-04:00", "sat,sun 22:00-02:00"], "refreshJitter": "20m"}]`, false},
+		{`[{"name": "A", "url": "u", "type": "shopify", "blackout": ["3am-4am"]}]`, true},
+		{`[{"name": "A", "url": "u", "type": "shopify", "blackout": ["manual 03:00-04:00"]}]`, true},
+		{`[{"name": "A", "url": "u", "type": "shopify", "blackout": ["03:00-03:00"]}]`, true},
+		{`[{"name": "A", "url": "u", "type": "shopify", "refreshJitter": "-1m"}]`, true},
+	}
+	for _, tt := range tests {
+		if err := os.WriteFile(path, []byte(tt.json), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(path); (err != nil) != tt.wantErr {
+			t.Errorf("Load(%s) error = %v, wantErr %v", tt.json, err, tt.wantErr)
+		}
+	}
+	if err := os.WriteFile(path, []byte(tests[0].json), 0644); err != nil {
+		t.Fatal(err)
+	}
+	vendors, _ = Load(path)
+	if len(vendors) != 1 || vendors[0].Timeout != 45*time.Second || vendors[0].Currency != "EUR" {
+		t.Errorf("Load() = %+v, want EU Shop with a 45s timeout", vendors)
+	}
+}
+
+func TestDefaultsDoNotAge(t *testing.T) {
+	// Do Not Age's one-time/subscription split and bulk tiers come from the
+	// live Magento pages, so it must stay a scraped magento vendor.
+	for _, v := range Defaults() {
+		if v.Name == "Do Not Age" {
+			if v.Type != "magento" || v.Cloudflare || !Due(v, time.Now()) {
+				t.Errorf("Do Not Age = %+v, want a daily magento vendor not behind Cloudflare", v)
+			}
+			return
+		}
+	}
+	t.Error("Defaults() has no Do Not Age vendor")
+}
+
+func TestDue(t *testing.T) {
+	monday := time.Date(2026, 10, 12, 8, 0, 0, 0, time.UTC)
+	tests := []struct {
+		schedule string
+		want     bool
+	}{
+		{"", true},
+		{"daily", true},
+		{"manual", false},
+		{"mon,thu", true},
+		{" Tue , Thu ", false},
+	}
+	for _, tt := range tests {
+		if got := Due(models.Vendor{Schedule: tt.schedule}, monday); got != tt.want {
+			t.Errorf("Due(%q, Monday) = %v, want %v", tt.schedule, got, tt.want)
+		}
+	}
+}
+
+func TestSetCurrencies(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vendors.json")
+	list := `[{"name": "UK", "url": "u", "type": "shopify"}, {"name": "EU", "url": "u", "type": "magento", "currency": "EUR"}, {"name": "US", "url": "u", "type": "shopify"}]`
+	if err := os.WriteFile(path, []byte(list), 0644); err != nil {
+		t.Fatal(err)
+	}
+	updated, err := SetCurrencies(path, map[string]string{"UK": "GBP", "EU": "USD", "Missing": "USD"})
+	if err != nil || !reflect.DeepEqual(updated, []string{"UK"}) {
+		t.Fatalf("SetCurrencies() = %v, %v; want only UK updated", updated, err)
+	}
+	vendors, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, v := range vendors {
+		got = append(got, v.Currency)
+	}
+	if !reflect.DeepEqual(got, []string{"GBP", "EUR", ""}) {
+		t.Errorf("currencies = %q, want GBP, EUR and none", got)
+	}
+}
+
+func TestInBlackout(t *testing.T) {
+	v := models.Vendor{Blackout: []string{"03:00-04:00", "sat 22:00-02:00"}}
+	at := func(day, hour, minute int) time.Time { return time.Date(2026, 10, day, hour, minute, 0, 0, time.UTC) } // Oct 10 is a Saturday
+	tests := []struct {
+		t    time.Time
+		want string
+	}{
+		{at(12, 3, 0), "03:00-04:00"},
+		{at(12, 3, 59), "03:00-04:00"},
+		{at(12, 4, 0), ""},
+		{at(10, 23, 0), "sat 22:00-02:00"},
+		{at(11, 1, 30), "sat 22:00-02:00"}, // Sunday morning, in Saturday's window
+		{at(11, 2, 0), ""},
+		{at(11, 23, 0), ""}, // Sunday evening
+		{at(12, 1, 0), ""},  // Monday morning
+	}
+	for _, tt := range tests {
+		if got, _ := InBlackout(v, tt.t); got != tt.want {
+			t.Errorf("InBlackout(%s) = %q, want %q", tt.t.Format("Mon 15:04"), got, tt.want)
+		}
+	}
+	if d := Jitter(models.Vendor{RefreshJitter: time.Minute}); d < 0 || d > time.Minute {
+		t.Errorf("Jitter(1m) = %s, want 0–1m", d)
+	}
+}
@@ -0,0 +1,57 @@
+package delisting
+
+import (
+	"time"
+
+	"longevity-ranker/internal/history"
+	"longevity-ranker/internal/models"
+)
+
+// Result is the outcome of Carry.
+type Result struct {
+	Products []models.Product // The fresh scrape, then the carried products
+	Kept     int              // Missing products carried through the grace period
+	Expired  int              // Missing products dropped: their grace period is over
+}
+
+// Carry returns the fresh scrape plus the products of the previous one
+// (matched by handle) that it no longer lists, so a product a single scrape
+// misses does not drop out of the rankings. Every product under a missing
+// handle is carried, since page-per-product vendors list one product per
+// variant under the page's handle; a repeated handle and ID is carried once.
+// A carried product keeps its last scraped variants and is marked
+// MissingSince today, or the date an earlier run first missed it. It is
+// dropped once graceDays have passed since that date; graceDays 0 drops every
+// missing product at once. A product that comes back is the fresh one,
+// unmarked.
+func Carry(previous, fresh []models.Product, today string, graceDays int) Result {
+	res := Result{Products: fresh}
+	listed := make(map[string]bool, len(fresh))
+	for _, p := range fresh {
+		listed[p.Handle] = true
+	}
+	now, err := time.Parse(history.DateLayout, today)
+	if err != nil {
+		return res
+	}
+
+	carried := make(map[[2]string]bool)
+	for _, p := range previous {
+		key := [2]string{p.Handle, p.ID}
+		if listed[p.Handle] || carried[key] {
+			continue
+		}
+		carried[key] = true
+		if p.MissingSince == "" {
+			p.MissingSince = today
+		}
+		since, err := time.Parse(history.DateLayout, p.MissingSince)
+		if err != nil || now.Sub(since) >= time.Duration(graceDays)*24*time.Hour {
+			res.Expired++
+			continue
+		}
+		res.Products = append(res.Products, p)
+		res.Kept++
+	}
+	return res
+}
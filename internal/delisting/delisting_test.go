@@ -0,0 +1,58 @@
+package delisting
+
+import (
+	"reflect"
+	"testing"
+
+	"longevity-ranker/internal/models"
+)
+
+func TestCarry(t *testing.T) {
+	product := func(handle, missingSince string) models.Product {
+		return models.Product{Handle: handle, Variants: []models.Variant{{Title: "60 Capsules", Price: "40"}}, MissingSince: missingSince}
+	}
+	previous := []models.Product{
+		product("nmn", ""),
+		product("tmg", ""),                   // Missed for the first time today
+		product("resveratrol", "2026-01-08"), // Missing for two days
+		product("creatine", "2026-01-07"),    // Missing for three days: expired
+		product("spermidine", "2026-01-09"),  // Back today
+		product("berberine", "not a date"),   // Unreadable: dropped
+	}
+	fresh := []models.Product{product("nmn", ""), product("spermidine", "")}
+
+	res := Carry(previous, fresh, "2026-01-10", 3)
+	want := []models.Product{
+		product("nmn", ""),
+		product("spermidine", ""),
+		product("tmg", "2026-01-10"),
+		product("resveratrol", "2026-01-08"),
+	}
+	if !reflect.DeepEqual(res.Products, want) {
+		t.Errorf("Carry() products = %+v, want %+v", res.Products, want)
+	}
+	if res.Kept != 2 || res.Expired != 2 {
+		t.Errorf("Carry() kept %d, expired %d; want 2 and 2", res.Kept, res.Expired)
+	}
+
+	if res := Carry(previous, fresh, "2026-01-10", 0); len(res.Products) != 2 || res.Expired != 4 {
+		t.Errorf("Carry(grace 0) = %d products, %d expired; want the fresh scrape only", len(res.Products), res.Expired)
+	}
+}
+
+func TestCarrySharedHandle(t *testing.T) {
+	// An LD+JSON page with two variants: one product per variant, same handle
+	variant := func(name string) models.Product {
+		return models.Product{ID: name, Title: name, Handle: "https://shop.example.com/nmn", Variants: []models.Variant{{Title: name, Price: "40"}}}
+	}
+	previous := []models.Product{variant("NMN 30g"), variant("NMN 60g"), variant("NMN 60g")}
+	fresh := []models.Product{{ID: "TMG", Handle: "https://shop.example.com/tmg"}}
+
+	res := Carry(previous, fresh, "2026-01-10", 3)
+	if res.Kept != 2 || len(res.Products) != 3 || res.Products[1].ID != "NMN 30g" || res.Products[2].ID != "NMN 60g" {
+		t.Errorf("Carry() = %+v, kept %d; want both variants carried once", res.Products, res.Kept)
+	}
+	if res := Carry(previous, fresh, "2026-01-10", 0); res.Expired != 2 {
+		t.Errorf("Carry(grace 0) expired %d, want both variants", res.Expired)
+	}
+}
@@ -0,0 +1,74 @@
+package history
+
+import (
+	"encoding/csv"
+	"io"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// reNonSlug matches the runs of characters CSVName replaces with "-".
+var reNonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+// CSVName returns the export file name for a product, such as
+// "do_not_age_pure-nmn.csv". A handle that is a product URL (Magento and
+// LD+JSON vendors) is named after its last path segment.
+func CSVName(vendorName, handle string) string {
+	if u, err := url.Parse(handle); err == nil && u.Host != "" {
+		handle = path.Base(strings.TrimSuffix(u.Path, "/"))
+	}
+	vendor := strings.ReplaceAll(strings.ToLower(vendorName), " ", "_")
+	return vendor + "_" + strings.Trim(reNonSlug.ReplaceAllString(strings.ToLower(handle), "-"), "-") + ".csv"
+}
+
+// WriteCSV writes the recorded points of a product's variants as CSV: a
+// header, then one date,variant,price,compare_at_price,available row per
+// point, by date and then variant. variants limits the export to those
+// variant titles; nil exports every variant. It returns the number of rows
+// after the header.
+func WriteCSV(w io.Writer, store Store, vendorName, handle string, variants []string) (int, error) {
+	type row struct {
+		variant string
+		point   Point
+	}
+	prefix := Key(vendorName, handle, "")
+	var rows []row
+	for key, points := range store {
+		variant, ok := strings.CutPrefix(key, prefix)
+		if !ok || (variants != nil && !slices.Contains(variants, variant)) {
+			continue
+		}
+		for _, pt := range points {
+			rows = append(rows, row{variant, pt})
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].point.Date != rows[j].point.Date {
+			return rows[i].point.Date < rows[j].point.Date
+		}
+		return rows[i].variant < rows[j].variant
+	})
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"date", "variant", "price", "compare_at_price", "available"})
+	for _, r := range rows {
+		compareAt := ""
+		if r.point.CompareAtPrice > 0 {
+			compareAt = strconv.FormatFloat(r.point.CompareAtPrice, 'f', 2, 64)
+		}
+		cw.Write([]string{
+			r.point.Date,
+			r.variant,
+			strconv.FormatFloat(r.point.Price, 'f', 2, 64),
+			compareAt,
+			strconv.FormatBool(r.point.Available),
+		})
+	}
+	cw.Flush()
+	return len(rows), cw.Error()
+}
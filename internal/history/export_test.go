@@ -0,0 +1,46 @@
+package history
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteCSV(t *testing.T) {
+	store := Store{
+		Key("Shop", "nmn", "60 Capsules"): {
+			{Date: "2026-01-02", Price: 40, CompareAtPrice: 50, Available: true},
+			{Date: "2026-01-01", Price: 45, Available: false},
+		},
+		Key("Shop", "nmn", "Powder, 100g"):  {{Date: "2026-01-01", Price: 80, Available: true}},
+		Key("Shop", "nmn-gummies", "30 ct"): {{Date: "2026-01-01", Price: 20}},
+		Key("Other", "nmn", "60 Capsules"):  {{Date: "2026-01-01", Price: 30}},
+	}
+
+	var b strings.Builder
+	rows, err := WriteCSV(&b, store, "Shop", "nmn", nil)
+	want := `date,variant,price,compare_at_price,available
+2026-01-01,60 Capsules,45.00,,false
+2026-01-01,"Powder, 100g",80.00,,true
+2026-01-02,60 Capsules,40.00,50.00,true
+`
+	if err != nil || rows != 3 || b.String() != want {
+		t.Errorf("WriteCSV(all) = %d rows, %v:\n%s\nwant 3 rows:\n%s", rows, err, b.String(), want)
+	}
+
+	b.Reset()
+	if rows, _ := WriteCSV(&b, store, "Shop", "nmn", []string{"Powder, 100g"}); rows != 1 {
+		t.Errorf("WriteCSV(one variant) = %d rows, want 1:\n%s", rows, b.String())
+	}
+}
+
+func TestCSVName(t *testing.T) {
+	tests := map[[2]string]string{
+		{"Do Not Age", "https://donotage.org/pure-nmn/"}: "do_not_age_pure-nmn.csv",
+		{"NMN Bio", "NMN Powder 100g"}:                   "nmn_bio_nmn-powder-100g.csv",
+	}
+	for in, want := range tests {
+		if got := CSVName(in[0], in[1]); got != want {
+			t.Errorf("CSVName(%q, %q) = %q, want %q", in[0], in[1], got, want)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"longevity-ranker/internal/models"
+	"longevity-ranker/internal/storage"
+)
+
+// DateLayout is the format of Point.Date.
+const DateLayout = "2006-01-02"
+
+// Filename is the price-history store path, relative to the repo root.
+var Filename = filepath.Join(storage.DataDir, "price_history.json")
+
+// Point is a single daily observation of a variant's listed price.
+type Point struct {
+	Date           string  `json:"date"`
+	Price          float64 `json:"price"`
+	CompareAtPrice float64 `json:"compare_at_price,omitempty"`
+	Available      bool    `json:"available"`
+}
+
+// MinPlausiblePrice is the lowest real price: below it, a price is a
+// vendor's placeholder ($0.00, $0.01) for an unreleased or misconfigured
+// variant. Such points are recorded but never serve as a variant's history.
+const MinPlausiblePrice = 1.0
+
+// Store maps a variant key (see Key) to its chronologically ordered points.
+type Store = map[string][]Point
+
+// Key identifies a single variant across runs.
+// Example: ("NMN Bio", "nmn-powder", "100g") → "NMN Bio|nmn-powder|100g"
+func Key(vendorName, handle, variantTitle string) string {
+	return vendorName + "|" + handle + "|" + variantTitle
+}
+
+// Load reads the history store from disk. A missing file yields an empty store.
+func Load(path string) (Store, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return Store{}, nil
+	}
+	store, err := storage.LoadJSON[Store](path)
+	if err != nil {
+		return nil, err
+	}
+	if store == nil {
+		store = Store{}
+	}
+	return store, nil
+}
+
+// Record appends one point per variant of p, dated date (YYYY-MM-DD).
+// A second observation on the same date replaces the first, so repeated
+// local runs do not inflate the history. Unparseable prices are skipped.
+func Record(store Store, date, vendorName string, p models.Product) {
+	for _, v := range p.Variants {
+		price, err := strconv.ParseFloat(v.Price, 64)
+		if err != nil {
+			continue
+		}
+		key := Key(vendorName, p.Handle, v.Title)
+		point := Point{Date: date, Price: price, Available: v.Available}
+		if compareAt, err := strconv.ParseFloat(v.CompareAtPrice, 64); err == nil {
+			point.CompareAtPrice = compareAt
+		}
+
+		points := store[key]
+		if n := len(points); n > 0 && points[n-1].Date == date {
+			points[n-1] = point
+		} else {
+			points = append(points, point)
+		}
+		store[key] = points
+	}
+}
+
+// Backfill inserts one point per variant of p dated date, keeping each
+// variant's points in date order. Dates that already have a point are left
+// untouched, so observed data always wins over backfilled data. Returns the
+// number of points added.
+func Backfill(store Store, date, vendorName string, p models.Product) int {
+	added := 0
+	for _, v := range p.Variants {
+		price, err := strconv.ParseFloat(v.Price, 64)
+		if err != nil {
+			continue
+		}
+		key := Key(vendorName, p.Handle, v.Title)
+		points := store[key]
+		i := sort.Search(len(points), func(i int) bool { return points[i].Date >= date })
+		if i < len(points) && points[i].Date == date {
+			continue
+		}
+		point := Point{Date: date, Price: price, Available: v.Available}
+		if compareAt, err := strconv.ParseFloat(v.CompareAtPrice, 64); err == nil {
+			point.CompareAtPrice = compareAt
+		}
+		points = append(points, Point{})
+		copy(points[i+1:], points[i:])
+		points[i] = point
+		store[key] = points
+		added++
+	}
+	return added
+}
+
+// PriorPrices returns the recorded prices for key, excluding any point dated
+// today and placeholder prices. Today's point is the observation under test,
+// not its history, and a placeholder would drag the median down.
+func PriorPrices(store Store, key, today string) []float64 {
+	var prices []float64
+	for _, pt := range store[key] {
+		if pt.Date == today || pt.Price < MinPlausiblePrice {
+			continue
+		}
+		prices = append(prices, pt.Price)
+	}
+	return prices
+}
+
+// Recent returns the last n recorded prices for key, oldest first,
+// placeholder prices left out.
+func Recent(store Store, key string, n int) []float64 {
+	points := store[key]
+	if len(points) > n {
+		points = points[len(points)-n:]
+	}
+	var prices []float64
+	for _, pt := range points {
+		if pt.Price >= MinPlausiblePrice {
+			prices = append(prices, pt.Price)
+		}
+	}
+	return prices
+}
+
+// PerpetualSale reports whether every recorded point for key shows a
+// compare-at price above the selling price, over a span of at least minDays.
+// A "sale" that never ends means the compare-at price is never charged.
+func PerpetualSale(store Store, key string, minDays int) bool {
+	points := store[key]
+	if len(points) < 2 {
+		return false
+	}
+	for _, pt := range points {
+		if pt.CompareAtPrice <= pt.Price {
+			return false
+		}
+	}
+	first, errFirst := time.Parse(DateLayout, points[0].Date)
+	last, errLast := time.Parse(DateLayout, points[len(points)-1].Date)
+	if errFirst != nil || errLast != nil {
+		return false
+	}
+	return last.Sub(first) >= time.Duration(minDays)*24*time.Hour
+}
+
+// Median returns the median of values, or 0 for an empty slice.
+func Median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
@@ -0,0 +1,23 @@
+package history
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPriorPricesSkipsPlaceholders(t *testing.T) {
+	key := Key("Shop", "nmn", "60 Capsules")
+	store := Store{key: {
+		{Date: "2026-10-01", Price: 50},
+		{Date: "2026-10-02", Price: 0.01}, // Placeholder
+		{Date: "2026-10-03", Price: 0},
+		{Date: "2026-10-04", Price: 52},
+		{Date: "2026-10-05", Price: 60}, // Today
+	}}
+	if got, want := PriorPrices(store, key, "2026-10-05"), []float64{50, 52}; !reflect.DeepEqual(got, want) {
+		t.Errorf("PriorPrices() = %v, want %v", got, want)
+	}
+	if got, want := Recent(store, key, 3), []float64{52, 60}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Recent() = %v, want %v", got, want)
+	}
+}
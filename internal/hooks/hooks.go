@@ -0,0 +1,74 @@
+package hooks
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"longevity-ranker/internal/models"
+)
+
+// Hook fixes a known quirk of one store's data, so the generic analyzer
+// needs no vendor conditionals. Hooks are registered below by name and
+// enabled per vendor with the "hooks" list in vendor_rules.json. Fix edits
+// the product in place; it runs before the exclusions, the blocklist and the
+// analyzer see the product.
+type Hook interface {
+	Fix(p *models.Product)
+}
+
+// Func adapts a plain function to Hook.
+type Func func(p *models.Product)
+
+// Fix calls f(p).
+func (f Func) Fix(p *models.Product) { f(p) }
+
+// registry maps hook names to their implementation.
+var registry = map[string]Hook{
+	"prohealth-titles": Func(proHealthTitles),
+}
+
+// Lookup returns the hook registered under name.
+func Lookup(name string) (Hook, bool) {
+	h, ok := registry[name]
+	return h, ok
+}
+
+// Names returns the registered hook names, sorted.
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Run applies the named hooks to p in order. Unknown names are skipped
+// (rules.LoadRules rejects them).
+func Run(names []string, p *models.Product) {
+	for _, name := range names {
+		if h, ok := registry[name]; ok {
+			h.Fix(p)
+		}
+	}
+}
+
+// reProHealthLine matches the product-line prefix ProHealth puts in front of
+// every title: "NMN Pro 300™ - ", "NMN Pro™ 500 - ".
+var reProHealthLine = regexp.MustCompile(`^NMN Pro\s*\d*\s*™?\s*\d*\s*-\s*`)
+
+// proHealthTitles drops the "NMN Pro <n>™" line name from ProHealth titles,
+// leaving the descriptive part ("Uthever® NMN - 300 mg, 30 capsules"). The
+// line number is the dose, which the rest of the title states again. When
+// the rest does not name NMN, "NMN " is put back in front.
+func proHealthTitles(p *models.Product) {
+	rest := reProHealthLine.ReplaceAllString(p.Title, "")
+	if rest == p.Title || strings.TrimSpace(rest) == "" {
+		return
+	}
+	if !strings.Contains(strings.ToLower(rest), "nmn") {
+		rest = "NMN " + rest
+	}
+	p.Title = rest
+}
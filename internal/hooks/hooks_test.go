@@ -0,0 +1,38 @@
+package hooks
+
+import (
+	"testing"
+
+	"longevity-ranker/internal/models"
+)
+
+func TestProHealthTitles(t *testing.T) {
+	tests := []struct{ title, want string }{
+		{"NMN Pro 300™ - Uthever® NMN - 300 mg, 30 capsules", "Uthever® NMN - 300 mg, 30 capsules"},
+		{"NMN Pro™ 500 - Uthever® NMN - 500 mg, 30 servings - 3-Pack", "Uthever® NMN - 500 mg, 30 servings - 3-Pack"},
+		{"NMN Pro 300™ - 300 mg, 90 capsules", "NMN 300 mg, 90 capsules"},
+		{"NAD Triple Boost™  with NMN - 90 capsules", "NAD Triple Boost™  with NMN - 90 capsules"},
+		{"NMN Pro 300™ - ", "NMN Pro 300™ - "},
+	}
+	for _, tt := range tests {
+		p := models.Product{Title: tt.title}
+		Run([]string{"prohealth-titles"}, &p)
+		if p.Title != tt.want {
+			t.Errorf("prohealth-titles(%q) = %q, want %q", tt.title, p.Title, tt.want)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	registry["test-bang"] = Func(func(p *models.Product) { p.Title += "!" })
+	defer delete(registry, "test-bang")
+
+	p := models.Product{Title: "NMN"}
+	Run([]string{"test-bang", "unknown", "test-bang"}, &p)
+	if p.Title != "NMN!!" {
+		t.Errorf("Title = %q, want both known hooks applied in order", p.Title)
+	}
+	if _, ok := Lookup("unknown"); ok {
+		t.Error("Lookup(unknown) ok = true")
+	}
+}
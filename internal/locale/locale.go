@@ -0,0 +1,104 @@
+package locale
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Locale formats numbers, prices and unit labels for human-readable output.
+// Amounts are always US dollars (every vendor is scraped in USD); a locale
+// changes how they are written, not their value. JSON outputs are never
+// localized.
+type Locale struct {
+	Tag      string
+	Decimal  string // Decimal separator; no thousands separator is written
+	Currency string // Currency symbol
+	// SuffixUnits writes the currency symbol after the amount and puts a
+	// space before unit and percent signs ("12,50 $", "3,5 g", "-15 %").
+	SuffixUnits bool
+	// Types translates the analyzer's product type labels.
+	Types map[string]string
+}
+
+// Default is the locale used when none is given: US English.
+var Default = locales["en"]
+
+var locales = map[string]Locale{
+	"en": {Tag: "en", Decimal: ".", Currency: "$"},
+	"de": {Tag: "de", Decimal: ",", Currency: "US$", SuffixUnits: true, Types: map[string]string{
+		"Capsules": "Kapseln", "Powder": "Pulver", "Multi-Pack": "Mehrfachpackung",
+	}},
+	"fr": {Tag: "fr", Decimal: ",", Currency: "$US", SuffixUnits: true, Types: map[string]string{
+		"Capsules": "Gélules", "Powder": "Poudre", "Multi-Pack": "Lot",
+	}},
+	"es": {Tag: "es", Decimal: ",", Currency: "US$", SuffixUnits: true, Types: map[string]string{
+		"Capsules": "Cápsulas", "Powder": "Polvo", "Multi-Pack": "Pack múltiple",
+	}},
+	"it": {Tag: "it", Decimal: ",", Currency: "USD", SuffixUnits: true, Types: map[string]string{
+		"Capsules": "Capsule", "Powder": "Polvere", "Multi-Pack": "Confezione multipla",
+	}},
+}
+
+// Lookup returns the locale for tag. Region subtags and case are ignored
+// ("de-AT", "DE_de" → de).
+func Lookup(tag string) (Locale, error) {
+	lang, _, _ := strings.Cut(strings.ToLower(strings.ReplaceAll(tag, "_", "-")), "-")
+	l, ok := locales[lang]
+	if !ok {
+		return Locale{}, fmt.Errorf("unsupported locale %q (supported: %s)", tag, strings.Join(Supported(), ", "))
+	}
+	return l, nil
+}
+
+// Supported lists the supported locale tags.
+func Supported() []string {
+	tags := make([]string, 0, len(locales))
+	for tag := range locales {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// Number formats v with places decimals and the locale's decimal separator.
+// Example (de): (1234.5, 2) → "1234,50"
+func (l Locale) Number(v float64, places int) string {
+	return strings.Replace(fmt.Sprintf("%.*f", places, v), ".", l.Decimal, 1)
+}
+
+// Money formats a dollar amount with two decimals.
+// Example: 1234.5 → "$1234.50" (en), "1234,50 US$" (de)
+func (l Locale) Money(v float64) string {
+	if l.SuffixUnits {
+		return l.Number(v, 2) + " " + l.Currency
+	}
+	return l.Currency + l.Number(v, 2)
+}
+
+// Grams formats a mass in grams with one decimal.
+// Example: 12.5 → "12.5g" (en), "12,5 g" (fr)
+func (l Locale) Grams(v float64) string {
+	return l.withUnit(l.Number(v, 1), "g")
+}
+
+// Percent formats v (already ×100) with no decimals.
+// Example: -15 → "-15%" (en), "-15 %" (fr)
+func (l Locale) Percent(v float64) string {
+	return l.withUnit(l.Number(v, 0), "%")
+}
+
+// Type translates a product type label, or returns it unchanged.
+func (l Locale) Type(label string) string {
+	if t, ok := l.Types[label]; ok {
+		return t
+	}
+	return label
+}
+
+func (l Locale) withUnit(number, unit string) string {
+	if l.SuffixUnits {
+		return number + " " + unit
+	}
+	return number + unit
+}
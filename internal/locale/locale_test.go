@@ -0,0 +1,35 @@
+package locale
+
+import "testing"
+
+func TestFormatting(t *testing.T) {
+	de, err := Lookup("de_AT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fr, _ := Lookup("fr-FR")
+
+	tests := []struct {
+		name, got, want string
+	}{
+		{"en money", Default.Money(1234.5), "$1234.50"},
+		{"de money", de.Money(1234.5), "1234,50 US$"},
+		{"fr money", fr.Money(0.027), "0,03 $US"},
+		{"en grams", Default.Grams(12.5), "12.5g"},
+		{"de grams", de.Grams(12.5), "12,5 g"},
+		{"en percent", Default.Percent(-15), "-15%"},
+		{"fr percent", fr.Percent(-15), "-15 %"},
+		{"de type", de.Type("Powder"), "Pulver"},
+		{"en type", Default.Type("Powder"), "Powder"},
+		{"unknown type", fr.Type("Liquid"), "Liquid"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+
+	if _, err := Lookup("pt-BR"); err == nil {
+		t.Error("Lookup(pt-BR): want an error for an unsupported locale")
+	}
+}
@@ -0,0 +1,71 @@
+package manifest
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"longevity-ranker/internal/storage"
+)
+
+// Filename is the run manifest path, relative to the repo root. Each run
+// overwrites it, so it always describes the run that produced the current
+// data files.
+var Filename = filepath.Join(storage.DataDir, "run_manifest.json")
+
+// Vendor status values.
+const (
+	StatusScraped = "scraped" // Fetched live this run
+	StatusCached  = "cached"  // Loaded from data/<vendor>.json
+	StatusFailed  = "failed"  // Neither; the vendor contributed no products
+)
+
+// VendorStatus records how one vendor's products were obtained.
+type VendorStatus struct {
+	Vendor   string `json:"vendor"`
+	Status   string `json:"status"`
+	Products int    `json:"products"`          // Products kept after blocklist and exclusions
+	Partial  bool   `json:"partial,omitempty"` // Circuit breaker opened, throttling gave up or crawl budget spent; results may be incomplete
+	Error    string `json:"error,omitempty"`
+
+	// URLs not requested because the vendor's crawl budget (maxRequests)
+	// was spent.
+	SkippedURLs []string `json:"skipped_urls,omitempty"`
+}
+
+// Manifest describes a single pipeline run.
+type Manifest struct {
+	RunID      string            `json:"run_id"`
+	StartedAt  time.Time         `json:"started_at"`
+	FinishedAt time.Time         `json:"finished_at"`
+	Flags      map[string]string `json:"flags"`      // Flags set on the command line, by name
+	RulesHash  string            `json:"rules_hash"` // HashFile of vendor_rules.json; "" when it could not be read
+	Vendors    []VendorStatus    `json:"vendors"`
+	Outputs    map[string]string `json:"outputs"` // HashFile of every file the run wrote, by path
+}
+
+// NewRunID returns an ID that sorts by start time and stays unique across
+// runs started in the same second, e.g. "20260114T080012Z-3fa9c2d1".
+func NewRunID(start time.Time) string {
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return start.UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(suffix)
+}
+
+// HashFile returns the file's SHA-256 as "sha256:<hex>".
+func HashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -1,10 +1,183 @@
 package models
 
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Vendor is one store, as configured in data/vendors.json (camelCase keys;
+// Timeout is written as a Go duration string such as "45s").
 type Vendor struct {
-	Name       string
-	URL        string
-	Type       string
-	Cloudflare bool
+	Name       string `json:"name"`
+	URL        string `json:"url"`
+	Type       string `json:"type"`
+	Cloudflare bool   `json:"cloudflare,omitempty"`
+
+	// ISO 4217 code of the vendor's prices ("" = USD), converted with the
+	// rules' exchangeRates. Schedule limits when -refresh scrapes the vendor:
+	// "" or "daily", "manual" (never), or UTC weekdays such as "mon,thu".
+	Currency string `json:"currency,omitempty"`
+	Schedule string `json:"schedule,omitempty"`
+
+	// Also for -refresh: UTC windows the vendor is never scraped in, such
+	// as its maintenance hour ("03:00-04:00", "sun 22:00-02:00"), and the
+	// most a live scrape is delayed by a random amount, so runs do not hit
+	// the store at the same minute every day.
+	Blackout      []string      `json:"blackout,omitempty"`
+	RefreshJitter time.Duration `json:"-"`
+
+	// Extra entry URLs fetched in parallel with URL: products.json
+	// collections for Shopify, category pages for Magento and LD+JSON
+	// vendors. DiscoverCollections (Shopify only) adds collections whose
+	// handle or title contains a keyword in /collections.json; DiscoverTracked
+	// has cmd/main.go set DiscoverWords, never read from the config, to the
+	// tracked supplements' names and aliases, which must match whole words
+	// of the handle or title ("nad" finds nad-plus, not canada). Products
+	// found under several entries are kept once.
+	Collections         []string `json:"collections,omitempty"`
+	DiscoverCollections []string `json:"discoverCollections,omitempty"`
+	DiscoverTracked     bool     `json:"discoverTracked,omitempty"`
+	DiscoverWords       []string `json:"-"`
+
+	// Magento and LD+JSON only: a sitemap.xml (or sitemap index) whose
+	// product pages are crawled along with the category pages', so products
+	// on later pages of a paginated category are not missed. SitemapPattern
+	// is a regexp over the URL path selecting the product pages; "" = the
+	// scraper's own rule (top-level URL keys for Magento, /product/ for
+	// LD+JSON).
+	Sitemap        string `json:"sitemap,omitempty"`
+	SitemapPattern string `json:"sitemapPattern,omitempty"`
+
+	// Sent on every request to the vendor (consent, currency, region).
+	// PersistCookies keeps cookies the vendor sets for the rest of the run.
+	Headers        map[string]string `json:"headers,omitempty"`
+	Cookies        map[string]string `json:"cookies,omitempty"`
+	PersistCookies bool              `json:"persistCookies,omitempty"`
+
+	// For vendors that soft-block the shared client: the environment
+	// variable holding a proxy URL (http://, https://, socks5:// or
+	// socks5h://, credentials included) to send the vendor's requests
+	// through, and a pool of User-Agent strings to pick one from per run,
+	// moving to the next after a 403. RotateUserAgent without UserAgents
+	// uses the built-in pool of desktop browsers.
+	ProxyEnv        string   `json:"proxyEnv,omitempty"`
+	UserAgents      []string `json:"userAgents,omitempty"`
+	RotateUserAgent bool     `json:"rotateUserAgent,omitempty"`
+
+	// Resilience: per-request timeout (0 = 30s default), retries after
+	// network errors and 5xx responses (0 = 2, negative = none) and the
+	// delay before the first one, doubled for each further retry (0 =
+	// 500ms), and consecutive failed requests before the vendor is skipped
+	// for the rest of the run (0 = 5). MaxRequests caps the requests sent to
+	// the vendor per run (0 = unlimited); past it the rest of the crawl is
+	// skipped.
+	Timeout          time.Duration `json:"-"`
+	MaxRetries       int           `json:"maxRetries,omitempty"`
+	RetryBackoff     time.Duration `json:"-"`
+	FailureThreshold int           `json:"failureThreshold,omitempty"`
+	MaxRequests      int           `json:"maxRequests,omitempty"`
+
+	// Every backend: the vendor's sustained requests per second, whatever
+	// the host (a token bucket holding one second's worth, so that many may
+	// go out back to back; 0 = unlimited, but product page crawls still
+	// default to about 3 a second), and the most requests awaiting a
+	// response at once (0 = no cap). Page-per-product vendors (Magento,
+	// LD+JSON, Amazon) fetch that many product pages at once (0 = 1, one
+	// after another).
+	RateLimit   float64 `json:"rateLimit,omitempty"`
+	Concurrency int     `json:"concurrency,omitempty"`
+
+	// Price API only ("priceapi" type): the response format ("keepa", or ""
+	// for the normalized offer list), the environment variable holding the
+	// API key, and the query parameter that carries it ("" = sent as an
+	// "Authorization: Bearer" header). Keys never live in the vendor config.
+	APIFormat   string `json:"apiFormat,omitempty"`
+	APIKeyEnv   string `json:"apiKeyEnv,omitempty"`
+	APIKeyParam string `json:"apiKeyParam,omitempty"`
+
+	// Amazon only ("amazon" type): the ASINs priced on the marketplace
+	// URL names (https://www.amazon.com), one product each.
+	ASINs []string `json:"asins,omitempty"`
+
+	// Generic HTML only ("generic-html" type): product pages scraped along
+	// with URL, for stores on none of the supported platforms. Each page is
+	// read from its schema.org markup or Open Graph product tags.
+	ProductPages []string `json:"productPages,omitempty"`
+
+	// Shopify only: after the crawl, put each available variant alone in a
+	// fresh cart (at its minimum order quantity) and record what the cart
+	// charges, so automatic and tiered cart discounts reach the ranking.
+	// Costs three requests per variant, counted against MaxRequests.
+	CartPricing bool `json:"cartPricing,omitempty"`
+
+	// Shopify only: a Shopify Markets locale ("en-gb", "de-de", "fr"). Every
+	// storefront path gets its subfolder (/en-gb/products.json) and requests
+	// carry the country (GB) in the localization cookie, so prices are the
+	// ones a shopper in that market is shown. Needs Currency, the market's.
+	Market string `json:"market,omitempty"`
+
+	// Set by --browser on Cloudflare vendors, never read from the config:
+	// fetch through a headless Chrome, which can pass the Cloudflare
+	// challenge, instead of skipping the scrape.
+	Browser bool `json:"-"`
+}
+
+// vendorJSON is Vendor with its durations (Timeout, RetryBackoff,
+// RefreshJitter) as duration strings.
+type vendorJSON struct {
+	vendorAlias
+	RefreshJitter string `json:"refreshJitter,omitempty"`
+	Timeout       string `json:"timeout,omitempty"`
+	RetryBackoff  string `json:"retryBackoff,omitempty"`
+}
+
+type vendorAlias Vendor
+
+// MarshalJSON writes the durations as duration strings ("45s", "250ms").
+func (v Vendor) MarshalJSON() ([]byte, error) {
+	out := vendorJSON{vendorAlias: vendorAlias(v)}
+	if v.RefreshJitter > 0 {
+		out.RefreshJitter = v.RefreshJitter.String()
+	}
+	if v.Timeout > 0 {
+		out.Timeout = v.Timeout.String()
+	}
+	if v.RetryBackoff > 0 {
+		out.RetryBackoff = v.RetryBackoff.String()
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON reads the durations as duration strings ("45s", "1m").
+func (v *Vendor) UnmarshalJSON(data []byte) error {
+	var in vendorJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	*v = Vendor(in.vendorAlias)
+	if in.RefreshJitter != "" {
+		d, err := time.ParseDuration(in.RefreshJitter)
+		if err != nil {
+			return fmt.Errorf("vendor %q: invalid refreshJitter: %v", v.Name, err)
+		}
+		v.RefreshJitter = d
+	}
+	if in.Timeout != "" {
+		d, err := time.ParseDuration(in.Timeout)
+		if err != nil {
+			return fmt.Errorf("vendor %q: invalid timeout: %v", v.Name, err)
+		}
+		v.Timeout = d
+	}
+	if in.RetryBackoff != "" {
+		d, err := time.ParseDuration(in.RetryBackoff)
+		if err != nil {
+			return fmt.Errorf("vendor %q: invalid retryBackoff: %v", v.Name, err)
+		}
+		v.RetryBackoff = d
+	}
+	return nil
 }
 
 type Product struct {
@@ -15,19 +188,61 @@ type Product struct {
 	BodyHTML string    `json:"body_html"`
 	ImageURL string    `json:"image_url"`
 	Variants []Variant `json:"variants"`
+
+	// ISO 4217 code the store states for the prices (LD+JSON priceCurrency,
+	// Magento product:price:currency); empty when the page does not say.
+	Currency string `json:"currency,omitempty"`
+
+	// Manufacturer of a product a multi-brand retailer (iHerb) lists, whose
+	// brand-level certifications and quality scores it carries; empty when
+	// the vendor is the brand.
+	Brand string `json:"brand,omitempty"`
+
+	// Date (YYYY-MM-DD, UTC) of the first scrape that no longer listed the
+	// product. Set only while it is kept through the delisting grace period
+	// (see internal/delisting); its prices are the last ones scraped.
+	MissingSince string `json:"missing_since,omitempty"`
 }
 
 type Variant struct {
-	Price     string `json:"price"`
-	Title     string `json:"title"`
-	Available bool   `json:"available"`
+	ID             string `json:"id,omitempty"` // Store variant ID, where the platform exposes one (Shopify)
+	Price          string `json:"price"`
+	CompareAtPrice string `json:"compare_at_price,omitempty"`
+	Title          string `json:"title"`
+	Available      bool   `json:"available"`
+	ImageURL       string `json:"image_url,omitempty"`
+	MinOrderQty    int    `json:"min_order_qty,omitempty"` // Minimum units per order; 0 = none
+
+	// Price per gram of product stated by the page's structured data
+	// (schema.org UnitPriceSpecification), in the vendor's currency; 0 = none
+	UnitPrice float64 `json:"unit_price,omitempty"`
+
+	// Payable price per unit once the variant sits in a cart, after the
+	// cart's automatic discounts (Vendor.CartPricing); empty when not
+	// simulated or when the simulation failed
+	CartPrice string `json:"cart_price,omitempty"`
+}
+
+// PriceSourceCart marks an Analysis whose Price comes from a simulated
+// cart (Variant.CartPrice) rather than the listed price.
+const PriceSourceCart = "cart"
+
+// Attribution names where an Analysis's key figures came from, such as
+// {"price": "shopify-api", "grams": "override", "mg": "body_html regex"}.
+type Attribution struct {
+	Price string `json:"price"`        // The vendor's feed or page (scraper.PriceSource), then cart, currency or subscription steps
+	Grams string `json:"grams"`        // The step of the mass pipeline that set ActiveGrams
+	Mg    string `json:"mg,omitempty"` // Where UnitMg was read; omitted for powders and overrides
 }
 
 type Analysis struct {
 	Vendor          string  `json:"vendor"`
+	Brand           string  `json:"brand,omitempty"` // Product.Brand: the manufacturer when Vendor is a retailer
 	Name            string  `json:"name"`
 	Handle          string  `json:"handle"`
+	Variant         string  `json:"variant,omitempty"` // Source variant title, for history lookups
 	Price           float64 `json:"price"`
+	PriceSource     string  `json:"price_source,omitempty"` // PriceSourceCart, or "" for the listed price
 	ActiveGrams     float64 `json:"active_grams"`
 	GrossGrams      float64 `json:"gross_grams"`
 	CostPerGram     float64 `json:"cost_per_gram"`
@@ -37,6 +252,109 @@ type Analysis struct {
 	Type            string  `json:"type"`
 	ImageURL        string  `json:"image_url"`
 	IsSubscription  bool    `json:"is_subscription"`
+	Unavailable     bool    `json:"unavailable,omitempty"` // Out of stock; only in reports run with -include-unavailable
 	NeedsReview     bool    `json:"needs_review"`
 	ReviewReason    string  `json:"review_reason,omitempty"`
-}
\ No newline at end of file
+	ReviewDetail    string  `json:"review_detail,omitempty"`
+	Caution         string  `json:"caution,omitempty"` // Caution keyword match: lowers Confidence, still ranks
+	Confidence      float64 `json:"confidence"`
+	CompareAtPrice  float64 `json:"compare_at_price,omitempty"`
+	DiscountPct     float64 `json:"discount_pct,omitempty"`
+	PerpetualSale   bool    `json:"perpetual_sale,omitempty"`
+	MinOrderQty     int     `json:"min_order_qty,omitempty"`
+	EntryPrice      float64 `json:"entry_price,omitempty"`   // Price × MinOrderQty: the real minimum spend
+	CostPerDay      float64 `json:"cost_per_day,omitempty"`  // Price of DailyDoseMg of active
+	UnitsPerDay     int     `json:"units_per_day,omitempty"` // Whole capsules/tablets to reach the target dose; 0 for powders
+	DailyDoseMg     float64 `json:"daily_dose_mg,omitempty"` // Target dose, rounded up to whole units
+	UnitMg          float64 `json:"unit_mg,omitempty"`       // Active mg per capsule/tablet; 0 for powders
+
+	// Powder in one labeled scoop ("1 scoop = 1g") and the scoops in the
+	// container; omitted when the description states no scoop size.
+	ScoopMg              float64 `json:"scoop_mg,omitempty"`
+	ServingsPerContainer int     `json:"servings_per_container,omitempty"`
+
+	// Price in the vendor's own currency (ISO 4217 code), before conversion
+	// to Price: what checkout charges. Omitted for report-currency vendors.
+	NativePrice    float64 `json:"native_price,omitempty"`
+	NativeCurrency string  `json:"native_currency,omitempty"`
+
+	// Labeled molecular form (e.g. "Creatine HCl") and the share of its weight
+	// that is the active moiety, already applied to ActiveGrams (omitted when 1).
+	ActiveForm     string  `json:"active_form,omitempty"`
+	ActiveFraction float64 `json:"active_fraction,omitempty"`
+
+	// Third-party testing marks, and the quality multiplier they earn (folded
+	// into EffectiveCost; omitted when 1).
+	Certifications    []string `json:"certifications,omitempty"`
+	QualityMultiplier float64  `json:"quality_multiplier,omitempty"`
+
+	// External 0–100 quality score (data/quality_scores.csv) and
+	// EffectiveCost / (QualityScore / 100); omitted without a score.
+	QualityScore        float64 `json:"quality_score,omitempty"`
+	QualitySource       string  `json:"quality_source,omitempty"`
+	QualityAdjustedCost float64 `json:"quality_adjusted_cost,omitempty"`
+
+	// Shipping fee for one minimum order (omitted when free or unknown), and
+	// the ranking formula's score: lower ranks higher. Equals EffectiveCost
+	// unless rankWeights is configured.
+	ShippingCost float64 `json:"shipping_cost,omitempty"`
+	RankScore    float64 `json:"rank_score"`
+
+	// Not beaten on both effective cost and trust by any other entry of the
+	// same supplement (see internal/pareto).
+	ParetoOptimal bool `json:"pareto_optimal,omitempty"`
+
+	// Spread within the supplement (internal/spread): the widget section the
+	// entry belongs to, the share of its trusted entries costing more (100 =
+	// cheapest), and EffectiveCost over the cheapest trusted one.
+	Supplement     string  `json:"supplement,omitempty"`
+	CostPercentile float64 `json:"cost_percentile,omitempty"`
+	CostRatio      float64 `json:"cost_ratio,omitempty"`
+
+	// Movement within the supplement (spread.Rank): the entry's place among
+	// its supplement's entries above the fold (1 = best), its place in the
+	// previous run's report, and the places gained (▲, positive) or lost
+	// since. Omitted below the fold, outside any supplement, and on entries
+	// the previous report did not rank.
+	SupplementRank int `json:"supplement_rank,omitempty"`
+	PreviousRank   int `json:"previous_rank,omitempty"`
+	RankChange     int `json:"rank_change,omitempty"`
+
+	// Packs in a multi-pack or bulk-tier entry ("3 Pack", "6 Bottles");
+	// omitted for single units. Against the vendor's single unit of the
+	// same product (internal/bundle): the saving per unit versus buying
+	// that many singles, in report currency, and its share of the single's
+	// cost per gram; both negative, and BundleDearer set, when the bundle
+	// costs more per gram. Omitted when no single unit is listed.
+	PackSize        int     `json:"pack_size,omitempty"`
+	BundleSaving    float64 `json:"bundle_saving,omitempty"`
+	BundleSavingPct float64 `json:"bundle_saving_pct,omitempty"`
+	BundleDearer    bool    `json:"bundle_dearer,omitempty"`
+
+	SubscriptionOptions []SubscriptionOption `json:"subscription_options,omitempty"`
+
+	// The vendor's subscription risk tags from the rules (hard-to-cancel,
+	// renewal-price-up); only on subscription entries.
+	SubscriptionRisks []string `json:"subscription_risks,omitempty"`
+
+	// The product was missing from the latest scrapes and is kept for the
+	// delisting grace period, at its last scraped price (Product.MissingSince).
+	PossiblyDelisted bool   `json:"possibly_delisted,omitempty"`
+	MissingSince     string `json:"missing_since,omitempty"`
+
+	// Last daily listed prices of the source variant, oldest first, in USD.
+	// Only set in the extended report (-extended), for sparklines.
+	RecentPrices []float64 `json:"recent_prices,omitempty"`
+
+	// Sources of the price, grams and mg. Set by the analyzer, and only
+	// published in the extended report and by explain.
+	Attribution *Attribution `json:"attribution,omitempty"`
+}
+
+// SubscriptionOption is the price of one delivery interval on a subscription
+// entry.
+type SubscriptionOption struct {
+	IntervalDays int     `json:"interval_days"`
+	Price        float64 `json:"price"`       // Per delivery
+	AnnualCost   float64 `json:"annual_cost"` // Price × 365 / IntervalDays
+}
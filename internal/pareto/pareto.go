@@ -0,0 +1,87 @@
+package pareto
+
+import (
+	"sort"
+	"strings"
+
+	"longevity-ranker/internal/models"
+	"longevity-ranker/internal/parser"
+	"longevity-ranker/internal/taxonomy"
+)
+
+// Frontier is the Pareto front of one supplement: report indices of the
+// entries no other entry beats on both cost (see Cost) and trust, cheapest
+// first (so trust rises along it).
+type Frontier struct {
+	Key     string // Supplement name in the registry, e.g. "nmn"
+	Entries []int
+}
+
+// Mark computes the front of every supplement of reg, in registry order, and
+// sets ParetoOptimal on its entries. An entry competes in every supplement
+// whose name or alias its name or handle contains. Only one-time entries
+// above the fold compete: subscription rows repeat their one-time row, and
+// flagged costs can't be trusted. An entry may sit on several fronts (an
+// NMN + resveratrol blend). Sections without candidates are left out.
+func Mark(report []models.Analysis, reg taxonomy.Registry) []Frontier {
+	var fronts []Frontier
+	for _, s := range reg {
+		var candidates []int
+		for i, a := range report {
+			if a.IsSubscription || parser.BelowFold(a) {
+				continue
+			}
+			name := strings.ToLower(a.Name + " " + a.Handle)
+			for _, kw := range s.Keywords() {
+				if strings.Contains(name, kw) {
+					candidates = append(candidates, i)
+					break
+				}
+			}
+		}
+		if len(candidates) == 0 {
+			continue
+		}
+		front := frontier(report, candidates)
+		for _, i := range front {
+			report[i].ParetoOptimal = true
+		}
+		fronts = append(fronts, Frontier{Key: s.Name, Entries: front})
+	}
+	return fronts
+}
+
+// frontier returns the non-dominated candidates, cheapest first. An entry is
+// dominated when another costs no more and is trusted no less, and is better
+// on at least one of the two; exact ties all stay on the front.
+func frontier(report []models.Analysis, candidates []int) []int {
+	sorted := append([]int(nil), candidates...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := report[sorted[i]], report[sorted[j]]
+		if Cost(a) != Cost(b) {
+			return Cost(a) < Cost(b)
+		}
+		return parser.Trust(a) > parser.Trust(b)
+	})
+
+	var front []int
+	bestTrust, bestCost := -1.0, 0.0
+	for _, i := range sorted {
+		c, trust := Cost(report[i]), parser.Trust(report[i])
+		if trust > bestTrust || (trust == bestTrust && c == bestCost) {
+			front = append(front, i)
+			bestTrust, bestCost = trust, c
+		}
+	}
+	return front
+}
+
+// Cost is an entry's cost axis: its cost per gram over the bioavailability
+// multiplier. Unlike EffectiveCost it leaves out the certification
+// multiplier, which the trust axis already counts.
+func Cost(a models.Analysis) float64 {
+	if a.Multiplier > 0 {
+		return a.CostPerGram / a.Multiplier
+	}
+	return a.CostPerGram
+}
@@ -0,0 +1,50 @@
+package pareto
+
+import (
+	"reflect"
+	"testing"
+
+	"longevity-ranker/internal/models"
+	"longevity-ranker/internal/taxonomy"
+)
+
+func TestMark(t *testing.T) {
+	entry := func(name string, cost, multiplier, score float64) models.Analysis {
+		effective := cost
+		if multiplier > 0 {
+			effective /= multiplier
+		}
+		return models.Analysis{Name: name, CostPerGram: cost, Multiplier: 1, EffectiveCost: effective, QualityMultiplier: multiplier, QualityScore: score, Confidence: 0.75}
+	}
+	report := []models.Analysis{
+		entry("NMN Cheap", 0.50, 0, 60),         // 0: cheapest
+		entry("NMN Tested", 0.60, 1.25, 0),      // 1: dearer, better trusted
+		entry("NMN Worse", 0.70, 0, 90),         // 2: dominated by Tested (1.25 > 0.9)
+		entry("NMN Tested Twin", 0.60, 1.25, 0), // 3: exact tie with Tested
+		entry("NMN Best", 0.90, 1.25, 0),        // 4: same trust as Tested, dearer
+		entry("Creatine", 0.05, 0, 0),           // 5: own section
+	}
+	flagged := entry("NMN Flavor", 0.10, 1.25, 0)
+	flagged.NeedsReview = true
+	sub := entry("NMN Cheap (Subscribe & Save)", 0.40, 0, 100)
+	sub.IsSubscription = true
+	// Tested's certification already lowers its EffectiveCost below Cheap's
+	// ($0.48/g); counted again as trust, it would push Cheap and Plain off
+	plain := entry("NMN Plain", 0.55, 0, 0)
+	report = append(report, flagged, sub, plain)
+
+	got := Mark(report, taxonomy.Defaults())
+	want := []Frontier{
+		{Key: "nmn", Entries: []int{0, 8, 1, 3}},
+		{Key: "creatine", Entries: []int{5}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Mark() = %+v, want %+v", got, want)
+	}
+	for i, a := range report {
+		onFront := i == 0 || i == 1 || i == 3 || i == 5 || i == 8
+		if a.ParetoOptimal != onFront {
+			t.Errorf("report[%d] %q ParetoOptimal = %v, want %v", i, a.Name, a.ParetoOptimal, onFront)
+		}
+	}
+}
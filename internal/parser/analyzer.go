@@ -1,48 +1,159 @@
 package parser
 
 import (
+	"fmt"
+	"math"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
+	"longevity-ranker/internal/history"
 	"longevity-ranker/internal/models"
+	"longevity-ranker/internal/review"
 	"longevity-ranker/internal/rules"
+	"longevity-ranker/internal/scores"
+	"longevity-ranker/internal/taxonomy"
 )
 
+// Count and mass units include the German, French, Spanish and Italian forms
+// seen on EU vendor sites (kapseln, gélules, comprimés, stück, grammes, …),
+// and kg values accept a decimal comma ("1,5 kg").
 var (
 	reMg      = regexp.MustCompile(`(?i)(\d+)\s*mg`)
-	reCount   = regexp.MustCompile(`(?i)(\d+)\s*(?:capsules|caps|servings|tabs|tablets|ct)`)
-	reGrams   = regexp.MustCompile(`(?i)(\d+)\s*(?:grams?|gms?|g)\b`)
-	reKg      = regexp.MustCompile(`(?i)(\d+(?:\.\d+)?)\s*kg\b`)
+	reCount   = regexp.MustCompile(`(?i)(\d+)\s*(?:capsules|caps|servings|tabs|tablets|ct|kapseln|tabletten|stück|stk|g[ée]lules|comprim[ée]s|c[áa]psulas|compresse)`)
+	reGrams   = regexp.MustCompile(`(?i)(\d+)\s*(?:grams?|grammes?|gramm|gramos|grammi|gms?|g)\b`)
+	reKg      = regexp.MustCompile(`(?i)(\d+(?:[.,]\d+)?)\s*kg\b`)
 	rePack    = regexp.MustCompile(`(?i)(\d+)\s*(?:Pack|Bottles?)`)
 	reServing = regexp.MustCompile(`(?i)(\d+)\s*(?:capsules|caps).*?per\s*serving`)
 
 	// reLabelGrams and reLabelKg scan only variant.Title and product.Title (label text)
 	// for Gross Grams extraction. Identical patterns to reGrams/reKg but kept separate
 	// for clarity of intent.
-	reLabelGrams = regexp.MustCompile(`(?i)(\d+)\s*(?:grams?|gms?|g)\b`)
-	reLabelKg    = regexp.MustCompile(`(?i)(\d+(?:\.\d+)?)\s*kg\b`)
+	reLabelGrams = regexp.MustCompile(`(?i)(\d+)\s*(?:grams?|grammes?|gramm|gramos|grammi|gms?|g)\b`)
+	reLabelKg    = regexp.MustCompile(`(?i)(\d+(?:[.,]\d+)?)\s*kg\b`)
+
+	// Liquids: concentration ("50 mg/ml", "250 mg per 5 ml") and bottle volume
+	// ("60 ml", "2 fl oz"). A stated ml volume wins over its fl oz equivalent.
+	reConcentration = regexp.MustCompile(`(?i)(\d+(?:\.\d+)?)\s*mg\s*(?:/|per|in)\s*(\d+(?:[.,]\d+)?)?\s*ml\b`)
+	reMl            = regexp.MustCompile(`(?i)(\d+(?:[.,]\d+)?)\s*ml\b`)
+	reFlOz          = regexp.MustCompile(`(?i)(\d+(?:\.\d+)?)\s*fl\.?\s*oz\b`)
+
+	// Powders: the powder in one scoop ("1 scoop = 1g", "scoop size: 5 g",
+	// "500 mg per scoop") and the servings in the container ("60 servings",
+	// "servings per container: 60").
+	reScoop       = regexp.MustCompile(`(?i)(?:\b(?:1|one)\s*(?:level\s+|heaping\s+)?scoop\s*(?:=|:|\(|is|equals|provides|contains|of)?|\bscoop\s*size\s*:?|\beach\s+scoop\s+(?:contains|provides|holds))\s*(?:approx(?:imately|\.)?\s*|about\s*|~\s*)?(\d+(?:[.,]\d+)?)\s*(mg|g|grams?)\b`)
+	reScoopAfter  = regexp.MustCompile(`(?i)(\d+(?:[.,]\d+)?)\s*(mg|g|grams?)\s*(?:per|/|a)\s*scoop\b`)
+	reServings    = regexp.MustCompile(`(?i)(\d+)\s*servings\b`)
+	reServingsPer = regexp.MustCompile(`(?i)servings\s*per\s*(?:container|bottle|bag|tub|jar|pouch)\s*:?\s*(?:approx(?:imately|\.)?\s*|about\s*)?(\d+)`)
+)
+
+// mlPerFlOz converts US fluid ounces to millilitres.
+const mlPerFlOz = 29.5735
+
+// Price sanity thresholds. Vendors publish placeholder prices for unreleased or
+// misconfigured variants; left unchecked, a $0.01 listing ranks #1.
+const (
+	minPlausiblePrice = history.MinPlausiblePrice // Below this, a price is a placeholder ($0.00, $0.01)
+	anomalyRatio      = 100.0                     // Max deviation from the reference price before a price is bogus
+	perpetualSaleDays = 30                        // Min span of uninterrupted "sale" history before it is a fake sale
+
+	// Max relative gap between a page's structured unit price and the price
+	// over the regex-derived grams before the entry is flagged
+	unitPriceTolerance = 0.15
+)
+
+// Confidence levels attached to every Analysis entry, describing how much the
+// computed ActiveGrams can be trusted.
+const (
+	ConfidenceOverride = 1.0  // Mass comes from a vendor_rules.json override
+	ConfidenceRegex    = 0.75 // Mass extracted by regex from clean text
+	ConfidenceSibling  = 0.6  // Count from the variant, mg per unit from a sibling variant
+	ConfidenceCaution  = 0.5  // Regex mass, but a caution keyword (flavor) matched
+	ConfidenceFlagged  = 0.25 // Triage flagged the entry for manual review
+)
+
+// Sources of an entry's active grams and unit mg (models.Attribution),
+// named after the step of the mass pipeline that read them.
+const (
+	sourceVariantOverride = "variant override"
+	sourceOverride        = "override"
+	sourceTitleRegex      = "title regex"
+	sourceBodyRegex       = "body_html regex"
+	sourceConcentration   = "mg/ml × volume regex"
+	sourceScoop           = "scoop × servings regex"
+	sourceCount           = "mg × count regex"
+	sourceSibling         = "sibling mg × count regex"
+	sourceUnitPrice       = "unit price"
+	sourceLabelWeight     = "label weight"
+	sourceExtractor       = "extractor:" // Followed by the extractor's name
 )
 
-// dirtyKeywords flags products whose regex-extracted mass is likely unreliable.
-var dirtyKeywords = []string{
-	"flavor", "island cooler", "coastal explosion", "watermelon", "berry", "punch",
-	"orange", "lemon", "mango", "grape", "apple", "blend", "complex", "with", "+",
-	"gumm", "chew", "bundle", "blue raspberry", "fruit punch", "sour watermelon",
-	"pineapple mango", "mandarin orange", "shaq's berry blast", "frozen lemonade",
+// BelowFold reports whether an entry is ranked below the fold: flagged for
+// review, parsed with less than caution confidence, or out of stock. A
+// mis-parsed blend can look absurdly cheap, so these never outrank a trusted
+// entry; flavored (caution) entries still rank. A price nobody can pay
+// (Unavailable, only with IncludeUnavailable) is shown but never wins.
+func BelowFold(a models.Analysis) bool {
+	return a.NeedsReview || a.Unavailable || a.Confidence < ConfidenceCaution
+}
+
+// RankedBefore reports whether a sorts before b in the report: entries above
+// the fold first, then by rank score. Ties are broken by vendor, handle,
+// variant, and one-time before subscription, so the order never depends on
+// which vendor finished scraping first and daily reports diff cleanly.
+func RankedBefore(a, b models.Analysis) bool {
+	if fa, fb := BelowFold(a), BelowFold(b); fa != fb {
+		return fb
+	}
+	if a.RankScore != b.RankScore {
+		return a.RankScore < b.RankScore
+	}
+	if a.Vendor != b.Vendor {
+		return a.Vendor < b.Vendor
+	}
+	if a.Handle != b.Handle {
+		return a.Handle < b.Handle
+	}
+	if a.Variant != b.Variant {
+		return a.Variant < b.Variant
+	}
+	return !a.IsSubscription && b.IsSubscription
 }
 
 // Analyzer holds the configuration needed by the analysis and audit pipelines.
 // There is no global mutable state — all dependencies are injected here.
 type Analyzer struct {
 	Rules       rules.Registry
-	Supplements []string
+	Supplements taxonomy.Registry // Tracked supplements; a product outside all of them is skipped
+	History     history.Store     // Prior price observations; nil disables the history check
+	Today       string            // Run date (YYYY-MM-DD); today's history point is ignored
+	Decisions   review.Decisions  // Operator verdicts on review flags; nil keeps every flag
+	Scores      scores.Table      // External quality scores (Labdoor, ConsumerLab); nil = none
+
+	// Analyze out-of-stock variants too, as Unavailable entries below the
+	// fold, instead of skipping them
+	IncludeUnavailable bool
+
+	// Where each vendor's prices come from (vendor name →
+	// scraper.PriceSource), for Attribution.Price; "listed" when unknown
+	PriceSources map[string]string
 }
 
-// matchesSupplement reports whether the product's identity string contains at
-// least one of the configured supplement keywords.
-func (a *Analyzer) matchesSupplement(identity string) bool {
-	return containsAny(identity, a.Supplements)
+// supplementsFor returns the supplements tracked for a vendor: the ones its
+// vendor_rules.json "supplements" scope names when set, else all of them.
+func (a *Analyzer) supplementsFor(vendorName string) taxonomy.Registry {
+	if cfg, ok := a.Rules[vendorName]; ok && len(cfg.Supplements) > 0 {
+		return a.Supplements.Select(cfg.Supplements)
+	}
+	return a.Supplements
+}
+
+// matchesSupplement reports whether the product's identity string contains a
+// keyword of a supplement tracked for the vendor.
+func (a *Analyzer) matchesSupplement(vendorName, identity string) bool {
+	_, ok := a.supplementsFor(vendorName).Match(identity)
+	return ok
 }
 
 // vendorConfig returns the VendorConfig for the given vendor name, plus the
@@ -59,33 +170,61 @@ func (a *Analyzer) vendorConfig(vendorName, handle string) (cfg rules.VendorConf
 	return
 }
 
-// AnalyzeProduct evaluates every available variant of a product and returns an
-// Analysis entry for each valid one. It implements a Hybrid Catalog/Regex Engine:
+// AnalyzeProduct evaluates every available variant of a product (every
+// variant with IncludeUnavailable) and returns an Analysis entry for each
+// valid one. It implements a Hybrid Catalog/Regex Engine:
 //
 //   - If the product handle has an override with ForceActiveGrams > 0, the regex
 //     mass-extraction pipeline is bypassed entirely.
 //   - The pack multiplier regex (rePack) always runs regardless of overrides.
-//   - When GlobalSubscriptionDiscount is configured, a synthetic "Subscribe & Save"
-//     entry is emitted for each variant.
+//   - A variant's structured UnitPrice supplies the mass when the regexes
+//     find none, and otherwise cross-checks it (unitPriceMismatch).
+//   - The product's supplement (the tracked one named first) supplies the
+//     target dose, the molecular forms, the purity and the plausible mg per
+//     capsule or tablet.
+//   - When GlobalSubscriptionDiscount or SubscriptionFrequencies is configured,
+//     a synthetic "Subscribe & Save" entry is emitted for each variant.
 //
-// Returns nil when the product has no variants, does not match any allowed
-// supplement keyword, or yields no valid analyses.
+// Returns nil when the product has no variants, does not match any tracked
+// supplement, or yields no valid analyses.
 func (a *Analyzer) AnalyzeProduct(vendorName string, p models.Product) []models.Analysis {
 	if len(p.Variants) == 0 {
 		return nil
 	}
 
 	identity := strings.ToLower(p.Title + " " + p.Context + " " + p.Handle)
-	if !a.matchesSupplement(identity) {
+	supplement, ok := a.supplementsFor(vendorName).Match(identity)
+	if !ok {
 		return nil
 	}
 
 	cfg, spec, hasOverride := a.vendorConfig(vendorName, p.Handle)
+	targetMg := supplement.TargetDoseMg
+	certs := rules.Certifications(a.Rules, vendorName, p.Handle)
+	brand := vendorName
+	if p.Brand != "" {
+		// A retailer's listing carries the marks and scores of its brand,
+		// configured under the brand's name as for the brand's own store
+		certs = rules.BrandCertifications(a.Rules, p.Brand, vendorName, p.Handle)
+		brand = p.Brand
+	}
+	qualityMultiplier := rules.CertificationMultiplier(a.Rules, certs)
+	score, hasScore := a.Scores.Lookup(brand, p.Handle, p.Title)
+	rankWeights := rules.RankWeights(a.Rules)
+	siblingMedian := history.Median(siblingPrices(p.Variants))
+	dirtyKeywords := rules.DirtyKeywords(a.Rules, vendorName)
+	cautionKeywords := rules.CautionKeywords(a.Rules, vendorName)
+	currency := rules.Currency(a.Rules, vendorName)
+	rate, ok := rules.ExchangeRate(a.Rules, currency)
+	if !ok {
+		return nil // LoadRules rejects this; hand-built registries get no prices in an unknown currency
+	}
+	siblingMg, siblingTitle := siblingUnitMg(p.Variants)
 
 	var results []models.Analysis
 
 	for _, v := range p.Variants {
-		if !v.Available {
+		if !v.Available && !a.IncludeUnavailable {
 			continue
 		}
 
@@ -95,10 +234,31 @@ func (a *Analyzer) AnalyzeProduct(vendorName string, p models.Product) []models.
 		}
 
 		price, err := strconv.ParseFloat(v.Price, 64)
-		if err != nil || price <= 0 {
+		if err != nil || price < minPlausiblePrice {
+			continue
+		}
+
+		// Bogus low prices are dropped; bogus high prices are flagged below
+		priceExcluded, priceDetail := a.checkPrice(vendorName, p.Handle, v.Title, price, siblingMedian)
+		if priceExcluded {
 			continue
 		}
 
+		// A simulated cart price is what checkout charges, so it ranks in
+		// place of the listed one. The checks above compare listed prices
+		// with listed history; structured unit prices and subscription
+		// discounts also refer to the listed price
+		listedPrice := price
+		priceSource := ""
+		if cart, err := strconv.ParseFloat(v.CartPrice, 64); err == nil && cart >= minPlausiblePrice && cart != price {
+			price, priceSource = cart, models.PriceSourceCart
+		}
+
+		// The price checks compare native prices with native history;
+		// everything below is in the report currency
+		nativePrice := price
+		price *= rate
+
 		// --- Search strings at different specificity levels ---
 		variantSearch := v.Title
 		cleanSearch := p.Title + " " + v.Title
@@ -107,7 +267,46 @@ func (a *Analyzer) AnalyzeProduct(vendorName string, p models.Product) []models.
 		// =================================================================
 		// ACTIVE GRAMS EXTRACTION — Hybrid Engine
 		// =================================================================
-		capsuleMass, powderMass, usedOverride := a.extractMass(spec, hasOverride, v.Title, cleanSearch, broadSearch, variantSearch)
+		// A scoop size describes a serving, never the container: the mass
+		// steps read the text without it
+		scoopMg, scooplessSearch := extractScoop(broadSearch)
+		capsuleMass, powderMass, unitMg, usedOverride, gramsSource := a.extractMass(spec, hasOverride, v.Title, cleanSearch, scooplessSearch, variantSearch, scoopMg)
+		mgSource := ""
+		if gramsSource == sourceCount {
+			mgSource = unitMgSource(p, v)
+		}
+
+		// Registered extractors (metafields, OCR, label databases) fill in
+		// where the regexes found nothing, or replace them when surer
+		massConfidence := 0.0
+		if !usedOverride {
+			if c, name, ok := bestCandidate(p, v, capsuleMass+powderMass > 0); ok {
+				capsuleMass, powderMass, unitMg, massConfidence = c.CapsuleMass, c.PowderMass, c.UnitMg, c.Confidence
+				if c.ServingMg > 0 {
+					scoopMg = c.ServingMg
+				}
+				gramsSource, mgSource = sourceExtractor+name, ""
+				if c.UnitMg > 0 {
+					mgSource = gramsSource
+				}
+			}
+		}
+
+		// No mass, but the variant states its count and a sibling the mg
+		// per unit ("500mg, 60 caps" next to "120 caps"): the same capsule
+		// in another bottle size
+		if capsuleMass+powderMass == 0 && !usedOverride && siblingMg > 0 {
+			if count, ok := extractFloat(reCount, variantSearch); ok {
+				servingSize := 1.0
+				if s, ok := extractFloat(reServing, broadSearch); ok {
+					servingSize = s
+				}
+				unitMg = finiteOrZero(siblingMg / servingSize)
+				capsuleMass = finiteOrZero(unitMg * count / 1000.0)
+				massConfidence = ConfidenceSibling
+				gramsSource, mgSource = sourceSibling, fmt.Sprintf("sibling variant %q", siblingTitle)
+			}
+		}
 
 		baseMass := capsuleMass + powderMass
 
@@ -117,9 +316,21 @@ func (a *Analyzer) AnalyzeProduct(vendorName string, p models.Product) []models.
 		packMultiplier := 1.0
 		if m, ok := extractFloatFrom(rePack, variantSearch, broadSearch); ok {
 			packMultiplier = m
+			gramsSource += fmt.Sprintf(" × %g-pack", m)
 		}
 
-		activeGrams := baseMass * packMultiplier
+		activeGrams := finiteOrZero(baseMass * packMultiplier)
+
+		// No mass in the text: a structured unit price (per gram of
+		// product) states how many grams the price buys
+		unitGrams := 0.0
+		if v.UnitPrice > 0 {
+			unitGrams = finiteOrZero(listedPrice / v.UnitPrice)
+		}
+		usedUnitPrice := false
+		if activeGrams <= 0 && !usedOverride && unitGrams > 0 {
+			activeGrams, usedUnitPrice, gramsSource = unitGrams, true, sourceUnitPrice
+		}
 		if activeGrams <= 0 {
 			continue
 		}
@@ -129,15 +340,42 @@ func (a *Analyzer) AnalyzeProduct(vendorName string, p models.Product) []models.
 		// =================================================================
 		isCapsuleProduct := capsuleMass > 0 && powderMass == 0
 		grossGrams := a.extractGrossGrams(spec, hasOverride, v.Title, p.Title, isCapsuleProduct, packMultiplier)
+		if usedUnitPrice && grossGrams == 0 {
+			grossGrams = unitGrams
+		}
 
 		// =================================================================
 		// PURE POWDER FALLBACK
 		// =================================================================
 		if !usedOverride && grossGrams > 0 && !isCapsuleProduct {
 			triageTarget := strings.ToLower(p.Title + " " + v.Title + " " + p.Handle)
-			if !containsAny(triageTarget, dirtyKeywords) {
+			if !containsAny(triageTarget, dirtyKeywords) && !containsAny(triageTarget, cautionKeywords) {
 				activeGrams = grossGrams
+				if !usedUnitPrice { // Else the "label" is the unit price's grams
+					gramsSource = sourceLabelWeight
+				}
+			}
+		}
+
+		// Scoops in the container, from the label weight (else the mass)
+		servings := 0
+		if scoopMg > 0 && !isCapsuleProduct {
+			containerGrams := grossGrams
+			if containerGrams == 0 {
+				containerGrams = activeGrams
+			}
+			servings = int(math.Floor(containerGrams*1000/scoopMg + 1e-9))
+		}
+
+		// Cross-check regex grams against the stated unit price: the label
+		// weight, or the mass itself when it is not capsule fill
+		unitDetail := ""
+		if !usedOverride && !usedUnitPrice {
+			labelGrams := grossGrams
+			if labelGrams == 0 && !isCapsuleProduct {
+				labelGrams = activeGrams
 			}
+			unitDetail = unitPriceMismatch(v.UnitPrice, listedPrice, labelGrams)
 		}
 
 		// =================================================================
@@ -149,67 +387,496 @@ func (a *Analyzer) AnalyzeProduct(vendorName string, p models.Product) []models.
 		// --- Bioavailability multiplier ---
 		multiplier, multiplierLabel := bioavailabilityMultiplier(typeSearch, productType)
 
+		// --- Molecular form (salt/ester weight → active moiety) and purity ---
+		activeForm, activeFraction := supplement.Form(typeSearch)
+		activeFraction *= supplement.PurityFraction()
+		if hasOverride && spec.ActiveFraction > 0 {
+			activeFraction = spec.ActiveFraction
+		}
+
 		// --- Display name ---
 		displayName := buildDisplayName(p.Title, v.Title, vendorName)
 
+		// Variant image (e.g. the actual "3 Pack" shot) over the base product image
+		imageURL := p.ImageURL
+		if v.ImageURL != "" {
+			imageURL = v.ImageURL
+		}
+
 		// =================================================================
 		// TRIAGE ENGINE — Dirty Data Detection
 		// =================================================================
-		needsReview, reviewReason := a.triageDirtyData(usedOverride, displayName, p.Handle, p.Title)
+		needsReview, reviewReason, caution := triageDirtyData(dirtyKeywords, cautionKeywords, usedOverride, displayName, p.Handle, p.Title)
+		reviewDetail := ""
+		if !needsReview && priceDetail != "" {
+			needsReview, reviewReason, reviewDetail = true, reasonAnomalousPrice, priceDetail
+		}
+		if !needsReview && unitDetail != "" {
+			needsReview, reviewReason, reviewDetail = true, reasonUnitPrice, unitDetail
+		}
+		if !needsReview && !usedOverride && unitMg > 0 && !supplement.PlausibleUnitMg(unitMg) {
+			needsReview, reviewReason, reviewDetail = true, reasonImplausibleUnit, implausibleUnitDetail(supplement, unitMg)
+		}
+		if costPerGram := price / (activeGrams * activeFraction); !needsReview && !usedOverride && !supplement.PlausibleCostPerGram(costPerGram) {
+			needsReview, reviewReason, reviewDetail = true, reasonImplausibleCost, implausibleCostDetail(supplement, costPerGram)
+		}
+		if needsReview && a.Decisions.Lookup(vendorName, p.Handle, reviewReason) == review.Dismiss {
+			needsReview, reviewReason, reviewDetail = false, "", ""
+		}
+		if caution != "" && a.Decisions.Lookup(vendorName, p.Handle, caution) == review.Dismiss {
+			caution = ""
+		}
+
+		confidence := entryConfidence(usedOverride, needsReview, caution != "", massConfidence)
 
 		// Pure powder gross fallback
 		if productType == "Powder" && grossGrams == 0 && !needsReview {
 			grossGrams = activeGrams
 		}
 
+		// Gross stays the labeled weight; active becomes the moiety
+		activeGrams *= activeFraction
+
+		priceFrom := a.PriceSources[vendorName]
+		if priceFrom == "" {
+			priceFrom = "listed"
+		}
+		if currency != rules.ReportCurrency {
+			priceFrom += " (" + currency + ")"
+		}
+		attribution := models.Attribution{Price: priceFrom, Grams: gramsSource, Mg: mgSource}
+		if unitMg == 0 {
+			attribution.Mg = ""
+		}
+
 		// --- One-time purchase entry ---
-		results = append(results, buildAnalysis(
-			vendorName, displayName, p.Handle, p.ImageURL, productType,
+		oneTime := buildAnalysis(
+			vendorName, displayName, p.Handle, imageURL, productType,
 			price, activeGrams, grossGrams, multiplier, multiplierLabel,
-			false, needsReview, reviewReason,
-		))
+			false, needsReview, reviewReason, confidence,
+		)
+		oneTime.Brand = p.Brand
+		oneTime.Variant = v.Title
+		oneTime.PriceSource = priceSource
+		oneTime.PackSize = packSize(packMultiplier)
+		oneTime.Attribution = &attribution
+		if priceSource == models.PriceSourceCart {
+			oneTime.Attribution = &models.Attribution{Price: priceFrom + " + cart", Grams: attribution.Grams, Mg: attribution.Mg}
+		}
+		oneTime.ReviewDetail = reviewDetail
+		oneTime.Unavailable = !v.Available
+		oneTime.Caution = caution
+		applyCurrency(&oneTime, currency, nativePrice)
+		a.applyCompareAt(&oneTime, vendorName, p.Handle, v, rate)
+		minQty := minOrderQty(spec, hasOverride, v)
+		applyMinOrder(&oneTime, minQty)
+		applyActiveForm(&oneTime, activeForm, activeFraction)
+		applyDailyCost(&oneTime, targetMg, unitMg*activeFraction)
+		applyScoop(&oneTime, scoopMg, servings)
+		applyCertifications(&oneTime, certs, qualityMultiplier)
+		if hasScore {
+			applyQualityScore(&oneTime, score)
+		}
+		a.applyRankScore(&oneTime, vendorName, rankWeights)
+		results = append(results, oneTime)
 
 		// --- Synthetic subscription entry ---
-		if cfg.GlobalSubscriptionDiscount > 0 {
-			subPrice := price * (1 - cfg.GlobalSubscriptionDiscount)
-			results = append(results, buildAnalysis(
-				vendorName, displayName+" (Subscribe & Save)", p.Handle, p.ImageURL, productType,
+		if subPrice, options := subscriptionPricing(cfg, listedPrice*rate); subPrice > 0 {
+			sub := buildAnalysis(
+				vendorName, displayName+" (Subscribe & Save)", p.Handle, imageURL, productType,
 				subPrice, activeGrams, grossGrams, multiplier, multiplierLabel,
-				true, needsReview, reviewReason,
-			))
+				true, needsReview, reviewReason, confidence,
+			)
+			sub.Brand = p.Brand
+			sub.Variant = v.Title
+			sub.PackSize = oneTime.PackSize
+			sub.ReviewDetail = reviewDetail
+			sub.Unavailable = !v.Available
+			sub.Caution = caution
+			sub.SubscriptionOptions = options
+			sub.SubscriptionRisks = rules.SubscriptionRisks(a.Rules, vendorName)
+			sub.Attribution = &models.Attribution{Price: priceFrom + " − subscription discount", Grams: attribution.Grams, Mg: attribution.Mg}
+			applyCurrency(&sub, currency, subPrice/rate)
+			applyMinOrder(&sub, minQty)
+			applyActiveForm(&sub, activeForm, activeFraction)
+			applyDailyCost(&sub, targetMg, unitMg*activeFraction)
+			applyScoop(&sub, scoopMg, servings)
+			applyCertifications(&sub, certs, qualityMultiplier)
+			if hasScore {
+				applyQualityScore(&sub, score)
+			}
+			a.applyRankScore(&sub, vendorName, rankWeights)
+			results = append(results, sub)
 		}
 	}
 
 	if len(results) == 0 {
 		return nil
 	}
+	if p.MissingSince != "" {
+		for i := range results {
+			results[i].PossiblyDelisted, results[i].MissingSince = true, p.MissingSince
+		}
+	}
 	return results
 }
 
+// siblingUnitMg returns the mg per unit the variant titles state, and the
+// first title stating it, when every variant that states one agrees. 0 when
+// none does or they disagree (a 250 mg and a 500 mg strength): a count-only
+// variant could be either.
+func siblingUnitMg(variants []models.Variant) (float64, string) {
+	mg, title := 0.0, ""
+	for _, v := range variants {
+		m, ok := extractFloat(reMg, v.Title)
+		if !ok || m <= 0 {
+			continue
+		}
+		if mg > 0 && m != mg {
+			return 0, ""
+		}
+		if mg == 0 {
+			mg, title = m, v.Title
+		}
+	}
+	return mg, title
+}
+
+// siblingPrices returns the parsed prices of every available variant with a
+// plausible price. Used as the reference when a variant has no history.
+func siblingPrices(variants []models.Variant) []float64 {
+	var prices []float64
+	for _, v := range variants {
+		if !v.Available {
+			continue
+		}
+		if price, err := strconv.ParseFloat(v.Price, 64); err == nil && price >= minPlausiblePrice {
+			prices = append(prices, price)
+		}
+	}
+	return prices
+}
+
+// Review reasons of the flags raised on live figures. The reason is fixed, so
+// an operator's decision on it (see review.Key) survives the next price
+// change; the figures go in the entry's ReviewDetail.
+const (
+	reasonAnomalousPrice  = "Anomalous price"
+	reasonUnitPrice       = "Unit price mismatch"
+	reasonImplausibleUnit = "Implausible unit dose"
+	reasonImplausibleCost = "Implausible price per gram"
+)
+
+// checkPrice compares a variant price against its reference: the median of the
+// variant's own recorded history, or the sibling median when no history exists.
+// A price anomalyRatio× below the reference is excluded (it would otherwise be
+// crowned #1). A price anomalyRatio× above it is kept but returns the detail of
+// a reasonAnomalousPrice flag.
+func (a *Analyzer) checkPrice(vendorName, handle, variantTitle string, price, siblingMedian float64) (exclude bool, detail string) {
+	reference, source := siblingMedian, "sibling variants"
+	if prior := history.PriorPrices(a.History, history.Key(vendorName, handle, variantTitle), a.Today); len(prior) > 0 {
+		reference, source = history.Median(prior), "price history"
+	}
+	if reference <= 0 {
+		return false, ""
+	}
+
+	switch {
+	case price*anomalyRatio <= reference:
+		return true, ""
+	case price >= reference*anomalyRatio:
+		return false, fmt.Sprintf("$%.2f is %.0fx the %s median ($%.2f)", price, price/reference, source, reference)
+	}
+	return false, ""
+}
+
+// unitPriceMismatch compares a stated unit price (per gram, native currency)
+// with price over grams and returns the detail of a reasonUnitPrice flag when
+// they differ by more than unitPriceTolerance. "" when either side is unknown.
+func unitPriceMismatch(unitPrice, price, grams float64) string {
+	if unitPrice <= 0 || grams <= 0 {
+		return ""
+	}
+	derived := price / grams
+	if math.Abs(derived/unitPrice-1) <= unitPriceTolerance {
+		return ""
+	}
+	return fmt.Sprintf("page states $%.2f/100g, label mass gives $%.2f/100g", unitPrice*100, derived*100)
+}
+
+// implausibleUnitDetail is the detail of a reasonImplausibleUnit flag: a label
+// mg per capsule or tablet outside the supplement's range, usually another
+// ingredient's dose or a per-serving figure read as the unit dose.
+func implausibleUnitDetail(s taxonomy.Supplement, unitMg float64) string {
+	bound := fmt.Sprintf("at least %.0f mg", s.MinUnitMg)
+	if s.MaxUnitMg > 0 {
+		bound = fmt.Sprintf("%.0f–%.0f mg", s.MinUnitMg, s.MaxUnitMg)
+	}
+	return fmt.Sprintf("%.0f mg per capsule/tablet, %s expects %s", unitMg, strings.ToUpper(s.Name), bound)
+}
+
+// implausibleCostDetail is the detail of a reasonImplausibleCost flag: a
+// price per active gram outside the supplement's cost range.
+func implausibleCostDetail(s taxonomy.Supplement, cost float64) string {
+	bound := fmt.Sprintf("at least $%.2f/g", s.MinCostPerGram)
+	if s.MaxCostPerGram > 0 {
+		bound = fmt.Sprintf("$%.2f–$%.2f/g", s.MinCostPerGram, s.MaxCostPerGram)
+	}
+	return fmt.Sprintf("$%.2f/g, %s expects %s", cost, strings.ToUpper(s.Name), bound)
+}
+
+// applyCompareAt records the advertised compare-at price and discount depth on
+// a one-time entry. A sale whose compare-at price has been above the selling
+// price for every recorded observation across perpetualSaleDays is marked
+// PerpetualSale — the "original" price is never actually charged. rate
+// converts the native compare-at price to the report currency.
+func (a *Analyzer) applyCompareAt(entry *models.Analysis, vendorName, handle string, v models.Variant, rate float64) {
+	compareAt, err := strconv.ParseFloat(v.CompareAtPrice, 64)
+	compareAt *= rate
+	if err != nil || compareAt <= entry.Price {
+		return
+	}
+	entry.CompareAtPrice = compareAt
+	entry.DiscountPct = (compareAt - entry.Price) / compareAt * 100
+	entry.PerpetualSale = history.PerpetualSale(a.History, history.Key(vendorName, handle, v.Title), perpetualSaleDays)
+}
+
+// applyCurrency records the checkout price of a vendor priced in another
+// currency than the report's.
+func applyCurrency(entry *models.Analysis, currency string, nativePrice float64) {
+	if currency == rules.ReportCurrency {
+		return
+	}
+	entry.NativePrice = nativePrice
+	entry.NativeCurrency = currency
+}
+
+// subscriptionPricing returns the synthetic subscription price for a one-time
+// price, or 0 when the vendor has no subscription. With SubscriptionFrequencies
+// it also returns one option per valid interval (Days > 0, 0 < Discount < 1),
+// shortest first, and the subscription price is the cheapest delivery.
+// Otherwise, or when no interval is valid, GlobalSubscriptionDiscount gives a
+// single price and no options.
+func subscriptionPricing(cfg rules.VendorConfig, price float64) (float64, []models.SubscriptionOption) {
+	var options []models.SubscriptionOption
+	best := 0.0
+	for _, f := range cfg.SubscriptionFrequencies {
+		if f.Days <= 0 || f.Discount <= 0 || f.Discount >= 1 {
+			continue
+		}
+		deliveryPrice := price * (1 - f.Discount)
+		options = append(options, models.SubscriptionOption{
+			IntervalDays: f.Days,
+			Price:        deliveryPrice,
+			AnnualCost:   deliveryPrice * 365 / float64(f.Days),
+		})
+		if best == 0 || deliveryPrice < best {
+			best = deliveryPrice
+		}
+	}
+	if len(options) == 0 {
+		if cfg.GlobalSubscriptionDiscount > 0 {
+			return price * (1 - cfg.GlobalSubscriptionDiscount), nil
+		}
+		return 0, nil
+	}
+	sort.Slice(options, func(i, j int) bool { return options[i].IntervalDays < options[j].IntervalDays })
+	return best, options
+}
+
+// minOrderQty resolves the variant's minimum order quantity: the override's
+// per-variant value, then its product-level value, then what the scraper
+// found. 0 means no minimum.
+func minOrderQty(spec rules.ProductSpec, hasOverride bool, v models.Variant) int {
+	if hasOverride {
+		if q, ok := spec.VariantMinOrderQty[v.Title]; ok {
+			return q
+		}
+		if spec.MinOrderQty > 0 {
+			return spec.MinOrderQty
+		}
+	}
+	return v.MinOrderQty
+}
+
+// applyMinOrder records a minimum order above one unit and the entry price it
+// implies. Per-gram costs are unchanged: buying the minimum buys that many
+// units' worth of grams.
+func applyMinOrder(entry *models.Analysis, minQty int) {
+	if minQty <= 1 {
+		return
+	}
+	entry.MinOrderQty = minQty
+	entry.EntryPrice = entry.Price * float64(minQty)
+}
+
+// applyDailyCost records what the target daily dose costs. Capsules and
+// tablets (unitMg > 0) can't be split, so the dose is rounded up to whole
+// units: with 400 mg capsules a 1000 mg target means 3 capsules (1200 mg) a
+// day. Powders, liquids and override-resolved products are dosed exactly.
+// The bioavailability multiplier is not applied; this is the sticker cost.
+// The unit's mg is recorded whatever the target, for -min-capsule-mg.
+func applyDailyCost(entry *models.Analysis, targetMg, unitMg float64) {
+	entry.UnitMg = unitMg
+	if targetMg <= 0 || entry.ActiveGrams <= 0 {
+		return
+	}
+	dose := targetMg
+	if unitMg > 0 {
+		entry.UnitsPerDay = int(math.Ceil(targetMg/unitMg - 1e-9))
+		dose = float64(entry.UnitsPerDay) * unitMg
+	}
+	entry.DailyDoseMg = dose
+	entry.CostPerDay = entry.Price * dose / (entry.ActiveGrams * 1000)
+}
+
+// applyScoop records a powder's labeled scoop and the servings it gives the
+// container; nothing when either is unknown.
+func applyScoop(entry *models.Analysis, scoopMg float64, servings int) {
+	if scoopMg <= 0 || servings <= 0 {
+		return
+	}
+	entry.ScoopMg = scoopMg
+	entry.ServingsPerContainer = servings
+}
+
+// applyCertifications attaches a product's third-party testing marks. A
+// quality multiplier above 1 lowers EffectiveCost the same way the
+// bioavailability multiplier does: a tested gram is worth more.
+func applyCertifications(entry *models.Analysis, certs []string, qualityMultiplier float64) {
+	entry.Certifications = certs
+	if qualityMultiplier > 1 {
+		entry.QualityMultiplier = qualityMultiplier
+		entry.EffectiveCost /= qualityMultiplier
+	}
+}
+
+// applyActiveForm records the labeled molecular form and, when below 1, the
+// active fraction already folded into ActiveGrams.
+func applyActiveForm(entry *models.Analysis, form string, fraction float64) {
+	entry.ActiveForm = form
+	if fraction != 1 {
+		entry.ActiveFraction = fraction
+	}
+}
+
+// applyQualityScore attaches an external quality score and the
+// quality-adjusted cost: EffectiveCost / (score / 100), so a product scoring
+// 50 costs twice as much per useful gram. Runs after applyCertifications.
+func applyQualityScore(entry *models.Analysis, s scores.Score) {
+	entry.QualityScore = s.Score
+	entry.QualitySource = s.Source
+	entry.QualityAdjustedCost = entry.EffectiveCost * 100 / s.Score
+}
+
+// Trust returns an entry's trust factor: its certification multiplier times
+// its external quality score / 100, each counting as 1 when absent. Higher is
+// better; only measured shortcomings lower it.
+func Trust(entry models.Analysis) float64 {
+	trust := 1.0
+	if entry.QualityMultiplier > 0 {
+		trust *= entry.QualityMultiplier
+	}
+	if entry.QualityScore > 0 {
+		trust *= entry.QualityScore / 100
+	}
+	return trust
+}
+
+// applyRankScore sets the shipping fee of one minimum order and the ranking
+// score. Without weights the score is EffectiveCost. Otherwise it is the
+// product of each factor raised to its weight, every factor a "lower is
+// better" ratio so the score stays a $/g-like number:
+//
+//	cost            CostPerGram
+//	bioavailability 1 / Multiplier
+//	trust           1 / Trust(entry)
+//	shipping        (order + shipping) / order
+//	deal            1 − DiscountPct/100; 1 for a perpetual sale
+//
+// {cost: 1, bioavailability: 1, trust: 1} ranks like EffectiveCost when no
+// quality scores are loaded. Runs last, after applyQualityScore.
+func (a *Analyzer) applyRankScore(entry *models.Analysis, vendorName string, weights map[string]float64) {
+	order := entry.Price
+	if entry.EntryPrice > 0 {
+		order = entry.EntryPrice
+	}
+	entry.ShippingCost = rules.Shipping(a.Rules, vendorName, order)
+	if entry.NativePrice > 0 {
+		// Shipping fees and thresholds are in the vendor's currency
+		nativeOrder := entry.NativePrice * float64(max(entry.MinOrderQty, 1))
+		entry.ShippingCost = rules.Shipping(a.Rules, vendorName, nativeOrder) * entry.Price / entry.NativePrice
+	}
+
+	if weights == nil {
+		entry.RankScore = entry.EffectiveCost
+		return
+	}
+
+	deal := 1.0
+	if entry.DiscountPct > 0 && !entry.PerpetualSale {
+		deal = 1 - entry.DiscountPct/100
+	}
+	factors := map[string]float64{
+		rules.RankCost:            entry.CostPerGram,
+		rules.RankBioavailability: 1 / entry.Multiplier,
+		rules.RankTrust:           1 / Trust(*entry),
+		rules.RankShipping:        (order + entry.ShippingCost) / order,
+		rules.RankDeal:            deal,
+	}
+
+	score := 1.0
+	for factor, w := range weights {
+		if w > 0 {
+			score *= math.Pow(factors[factor], w)
+		}
+	}
+	entry.RankScore = score
+}
+
 // extractMass implements the hybrid catalog/regex mass-extraction pipeline.
-// Returns capsuleMass, powderMass, and whether an override was used.
-func (a *Analyzer) extractMass(spec rules.ProductSpec, hasOverride bool, variantTitle, cleanSearch, broadSearch, variantSearch string) (capsuleMass, powderMass float64, usedOverride bool) {
+// Returns capsuleMass, powderMass, the mg of active per capsule/tablet (only
+// known on the mg × count path, else 0), whether an override was used, and
+// the step that found the mass ("" when none did).
+// scoopMg is the powder per scoop (extractScoop), 0 when not stated.
+func (a *Analyzer) extractMass(spec rules.ProductSpec, hasOverride bool, variantTitle, cleanSearch, broadSearch, variantSearch string, scoopMg float64) (capsuleMass, powderMass, unitMg float64, usedOverride bool, source string) {
 	// VARIANT CATALOG PATH
 	if hasOverride && spec.VariantOverrides != nil && spec.VariantOverrides[variantTitle] > 0 {
-		return 0, spec.VariantOverrides[variantTitle], true
+		return 0, spec.VariantOverrides[variantTitle], 0, true, sourceVariantOverride
 	}
 
 	// PRODUCT CATALOG PATH
 	if hasOverride && spec.ForceActiveGrams > 0 {
-		return 0, spec.ForceActiveGrams, true
+		return 0, spec.ForceActiveGrams, 0, true, sourceOverride
 	}
 
 	// REGEX PATH
 
 	// Step 1: Explicit grams or kg in clean title+variant
 	if g, ok := extractFloat(reGrams, cleanSearch); ok {
-		return 0, g, false
+		return 0, g, 0, false, sourceTitleRegex
 	}
 	if kg, ok := extractFloat(reKg, cleanSearch); ok {
-		return 0, kg * 1000.0, false
+		return 0, finiteOrZero(kg * 1000.0), 0, false, sourceTitleRegex
 	}
 
-	// Step 2: mg × count (capsules/tablets)
+	// Step 2: mg/ml concentration × bottle volume (liquids)
+	if g, ok := extractLiquidMass(cleanSearch, broadSearch); ok {
+		return g, 0, 0, false, sourceConcentration
+	}
+
+	// Step 3: scoop size × servings (powders labeled by serving)
+	if scoopMg > 0 {
+		servings, ok := extractFloat(reServingsPer, broadSearch)
+		if !ok {
+			servings, ok = extractFloat(reServings, broadSearch)
+		}
+		if ok {
+			return 0, finiteOrZero(scoopMg * servings / 1000.0), 0, false, sourceScoop
+		}
+	}
+
+	// Step 4: mg × count (capsules/tablets)
 	mg, mgOk := extractFloat(reMg, broadSearch)
 	count, countOk := extractFloatFrom(reCount, variantSearch, cleanSearch, broadSearch)
 	if mgOk && countOk {
@@ -217,16 +884,92 @@ func (a *Analyzer) extractMass(spec rules.ProductSpec, hasOverride bool, variant
 		if s, ok := extractFloat(reServing, broadSearch); ok {
 			servingSize = s
 		}
-		capsuleMass = (mg / servingSize * count) / 1000.0
-		return capsuleMass, 0, false
+		capsuleMass = finiteOrZero((mg / servingSize * count) / 1000.0)
+		return capsuleMass, 0, finiteOrZero(mg / servingSize), false, sourceCount
 	}
 
-	// Step 3: Fallback — grams in broad search
+	// Step 5: Fallback — grams in broad search
 	if g, ok := extractFloat(reGrams, broadSearch); ok {
-		return 0, g, false
+		return 0, g, 0, false, sourceBodyRegex
 	}
 
-	return 0, 0, false
+	return 0, 0, 0, false, ""
+}
+
+// unitMgSource says where the mg × count step read the mg: the product and
+// variant titles (with the context and handle), which lead the broad
+// search, or the description after them.
+func unitMgSource(p models.Product, v models.Variant) string {
+	if _, ok := extractFloat(reMg, p.Title+" "+p.Context+" "+v.Title+" "+strings.ReplaceAll(p.Handle, "-", " ")); ok {
+		return sourceTitleRegex
+	}
+	return sourceBodyRegex
+}
+
+// extractLiquidMass computes active grams for a liquid from a stated mg/ml
+// concentration and the bottle volume. The volume is read from cleanSearch
+// before broadSearch, with concentration phrases removed so "per 5 ml" is not
+// mistaken for the bottle size.
+func extractLiquidMass(cleanSearch, broadSearch string) (float64, bool) {
+	mgPerMl, ok := extractConcentration(broadSearch)
+	if !ok {
+		return 0, false
+	}
+	for _, s := range []string{cleanSearch, broadSearch} {
+		s = reConcentration.ReplaceAllString(s, "")
+		if ml, ok := extractFloat(reMl, s); ok {
+			return finiteOrZero(mgPerMl * ml / 1000.0), true
+		}
+		if oz, ok := extractFloat(reFlOz, s); ok {
+			return finiteOrZero(mgPerMl * oz * mlPerFlOz / 1000.0), true
+		}
+	}
+	return 0, false
+}
+
+// extractScoop returns the mg of powder in one scoop stated in s (0 when
+// none) and s without the scoop statements.
+func extractScoop(s string) (float64, string) {
+	mg := 0.0
+	for _, re := range []*regexp.Regexp{reScoop, reScoopAfter} {
+		m := re.FindStringSubmatch(s)
+		if m == nil {
+			continue
+		}
+		if mg == 0 {
+			v, err := strconv.ParseFloat(strings.Replace(m[1], ",", ".", 1), 64)
+			if err == nil && v > 0 {
+				if !strings.EqualFold(m[2], "mg") {
+					v *= 1000
+				}
+				mg = finiteOrZero(v)
+			}
+		}
+		s = re.ReplaceAllString(s, "")
+	}
+	return mg, s
+}
+
+// extractConcentration returns the mg per ml stated in s. "250 mg per 5 ml"
+// yields 50; a bare "50 mg/ml" yields 50.
+func extractConcentration(s string) (float64, bool) {
+	m := reConcentration.FindStringSubmatch(s)
+	if m == nil {
+		return 0, false
+	}
+	mg, err := strconv.ParseFloat(m[1], 64)
+	if err != nil || mg <= 0 {
+		return 0, false
+	}
+	perMl := 1.0
+	if m[2] != "" {
+		perMl, err = strconv.ParseFloat(strings.Replace(m[2], ",", ".", 1), 64)
+		if err != nil || perMl <= 0 {
+			return 0, false
+		}
+	}
+	v := finiteOrZero(mg / perMl)
+	return v, v > 0
 }
 
 // extractGrossGrams extracts the physical label weight from variant/product titles.
@@ -243,10 +986,10 @@ func (a *Analyzer) extractGrossGrams(spec rules.ProductSpec, hasOverride bool, v
 
 	labelSearch := productTitle + " " + variantTitle
 	if g, ok := extractFloat(reLabelGrams, labelSearch); ok {
-		return g * packMult
+		return finiteOrZero(g * packMult)
 	}
 	if kg, ok := extractFloat(reLabelKg, labelSearch); ok {
-		return kg * 1000.0 * packMult
+		return finiteOrZero(kg * 1000.0 * packMult)
 	}
 	return 0
 }
@@ -271,6 +1014,9 @@ func classifyType(typeSearch string, spec rules.ProductSpec, hasOverride, usedOv
 	if strings.Contains(typeSearch, "tab") {
 		return "Tablets"
 	}
+	if strings.Contains(typeSearch, "liquid") || strings.Contains(typeSearch, "fl oz") {
+		return "Liquid"
+	}
 	if strings.Contains(typeSearch, "powder") {
 		return "Powder"
 	}
@@ -311,34 +1057,75 @@ func buildDisplayName(productTitle, variantTitle, vendorName string) string {
 	return name
 }
 
-// triageDirtyData checks whether regex-extracted mass is likely unreliable.
-func (a *Analyzer) triageDirtyData(usedOverride bool, displayName, handle, title string) (bool, string) {
+// triageDirtyData checks whether regex-extracted mass is likely unreliable,
+// scanning for the vendor's resolved keyword tiers (see rules.DirtyKeywords
+// and rules.CautionKeywords). A dirty keyword flags the entry for review
+// (needsReview, reviewReason); failing that, a caution keyword only returns
+// a caution reason, which lowers the entry's confidence.
+func triageDirtyData(dirtyKeywords, cautionKeywords []string, usedOverride bool, displayName, handle, title string) (needsReview bool, reviewReason, caution string) {
 	if usedOverride {
-		return false, ""
+		return false, "", ""
 	}
 
 	triageTarget := strings.ToLower(displayName + " " + handle + " " + title)
-	for _, kw := range dirtyKeywords {
-		if !strings.Contains(triageTarget, strings.ToLower(kw)) {
-			continue
-		}
-		// "unflavored" contains substring "flavor" but is not a dirty signal
-		if kw == "flavor" && strings.Contains(triageTarget, "unflavored") {
-			if strings.Contains(triageTarget, "serv") {
-				return true, "Detected 'unflavored' but uses 'servings' (needs manual math check)"
+	tiers := []struct {
+		keywords []string
+		dirty    bool
+	}{{dirtyKeywords, true}, {cautionKeywords, false}}
+	for _, tier := range tiers {
+		for _, kw := range tier.keywords {
+			if !strings.Contains(triageTarget, strings.ToLower(kw)) {
+				continue
 			}
-			continue
+			// "unflavored" contains substring "flavor" but is not a dirty signal
+			if kw == "flavor" && strings.Contains(triageTarget, "unflavored") {
+				if strings.Contains(triageTarget, "serv") {
+					return true, "Detected 'unflavored' but uses 'servings' (needs manual math check)", ""
+				}
+				continue
+			}
+			if tier.dirty {
+				return true, "Detected dirty keyword: " + kw, ""
+			}
+			return false, "", "Detected caution keyword: " + kw
 		}
-		return true, "Detected dirty keyword: " + kw
 	}
-	return false, ""
+	return false, "", ""
+}
+
+// entryConfidence maps how an entry's mass was resolved to a confidence level.
+// A review flag outranks the mass source: a flagged override is still suspect.
+// massConfidence is the Confidence of the extractor Candidate that supplied
+// the mass, 0 for the regex engine.
+func entryConfidence(usedOverride, needsReview, caution bool, massConfidence float64) float64 {
+	switch {
+	case needsReview:
+		return ConfidenceFlagged
+	case caution:
+		return ConfidenceCaution
+	case usedOverride:
+		return ConfidenceOverride
+	case massConfidence > 0:
+		return massConfidence
+	default:
+		return ConfidenceRegex
+	}
+}
+
+// packSize is the Analysis.PackSize of a pack multiplier: 0 for a single
+// unit.
+func packSize(packMultiplier float64) int {
+	if packMultiplier < 2 {
+		return 0
+	}
+	return int(packMultiplier)
 }
 
 // buildAnalysis constructs a single Analysis entry with computed cost metrics.
 func buildAnalysis(
 	vendor, name, handle, imageURL, productType string,
 	price, activeGrams, grossGrams, multiplier float64, multiplierLabel string,
-	isSubscription, needsReview bool, reviewReason string,
+	isSubscription, needsReview bool, reviewReason string, confidence float64,
 ) models.Analysis {
 	costPerGram := price / activeGrams
 	return models.Analysis{
@@ -357,5 +1144,6 @@ func buildAnalysis(
 		IsSubscription:  isSubscription,
 		NeedsReview:     needsReview,
 		ReviewReason:    reviewReason,
+		Confidence:      confidence,
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,900 @@
+package parser
+
+import (
+	"math"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+
+	"longevity-ranker/internal/history"
+	"longevity-ranker/internal/models"
+	"longevity-ranker/internal/review"
+	"longevity-ranker/internal/rules"
+	"longevity-ranker/internal/scores"
+	"longevity-ranker/internal/taxonomy"
+)
+
+// tracked selects supplements from the built-in registry.
+func tracked(names ...string) taxonomy.Registry {
+	return taxonomy.Defaults().Select(names)
+}
+
+func TestReviewDecisions(t *testing.T) {
+	p := models.Product{
+		Handle: "nmn-with-tmg",
+		Title:  "NMN with TMG 500mg",
+		Variants: []models.Variant{
+			{Price: "50.00", Title: "60 Capsules", Available: true},
+		},
+	}
+	const reason = "Detected dirty keyword: with"
+
+	cases := []struct {
+		name       string
+		decision   string
+		reason     string
+		wantReview bool
+	}{
+		{"no decision", "", reason, true},
+		{"dismissed", review.Dismiss, reason, false},
+		{"confirmed", review.Confirm, reason, true},
+		{"dismissed for another reason", review.Dismiss, "Detected dirty keyword: blend", true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := &Analyzer{Supplements: tracked("nmn"), Decisions: review.Decisions{}}
+			if tc.decision != "" {
+				a.Decisions[review.Key("Vendor", p.Handle, tc.reason)] = review.Decision{Decision: tc.decision}
+			}
+			got := a.AnalyzeProduct("Vendor", p)
+			if len(got) != 1 {
+				t.Fatalf("got %d analyses, want 1", len(got))
+			}
+			if got[0].NeedsReview != tc.wantReview {
+				t.Errorf("NeedsReview = %v, want %v (reason %q)", got[0].NeedsReview, tc.wantReview, got[0].ReviewReason)
+			}
+			if !tc.wantReview && (got[0].ReviewReason != "" || got[0].Confidence != ConfidenceRegex) {
+				t.Errorf("dismissed entry kept reason %q / confidence %v", got[0].ReviewReason, got[0].Confidence)
+			}
+		})
+	}
+}
+
+func TestReviewDecisionSurvivesPriceChange(t *testing.T) {
+	a := &Analyzer{
+		Supplements: tracked("nmn"),
+		History:     history.Store{history.Key("Vendor", "nmn", "60 Capsules"): {{Date: "2026-10-01", Price: 50}}},
+		Today:       "2026-10-17",
+		Decisions:   review.Decisions{},
+	}
+	analyze := func(price string) models.Analysis {
+		t.Helper()
+		got := a.AnalyzeProduct("Vendor", models.Product{
+			Handle:   "nmn",
+			Title:    "NMN 500mg",
+			Variants: []models.Variant{{Price: price, Title: "60 Capsules", Available: true}},
+		})
+		if len(got) != 1 {
+			t.Fatalf("$%s: got %d analyses, want 1", price, len(got))
+		}
+		return got[0]
+	}
+
+	e := analyze("6000.00")
+	if !e.NeedsReview || e.ReviewReason != reasonAnomalousPrice || e.ReviewDetail != "$6000.00 is 120x the price history median ($50.00)" {
+		t.Fatalf("$6000: review = %v %q (%q)", e.NeedsReview, e.ReviewReason, e.ReviewDetail)
+	}
+
+	// The dismissal names the reason, not the figures, so a new price keeps it
+	a.Decisions[review.Key("Vendor", "nmn", e.ReviewReason)] = review.Decision{Decision: review.Dismiss}
+	for _, price := range []string{"6000.00", "7000.00"} {
+		if e := analyze(price); e.NeedsReview || e.ReviewReason != "" || e.ReviewDetail != "" {
+			t.Errorf("$%s after dismissal: review = %v %q (%q), want cleared", price, e.NeedsReview, e.ReviewReason, e.ReviewDetail)
+		}
+	}
+}
+
+func TestTriageTiers(t *testing.T) {
+	product := func(title string) models.Product {
+		return models.Product{
+			Handle:   "nmn",
+			Title:    title,
+			Variants: []models.Variant{{Price: "50.00", Title: "60 Capsules", Available: true}},
+		}
+	}
+
+	cases := []struct {
+		name           string
+		title          string
+		dismiss        string
+		wantReview     bool
+		wantCaution    string
+		wantConfidence float64
+	}{
+		{"clean", "NMN 500mg", "", false, "", ConfidenceRegex},
+		{"dirty", "NMN Gummies 500mg", "", true, "", ConfidenceFlagged},
+		{"caution", "NMN 500mg Berry", "", false, "Detected caution keyword: berry", ConfidenceCaution},
+		{"dirty outranks caution", "NMN Berry Chews 500mg", "", true, "", ConfidenceFlagged},
+		{"dismissed caution", "NMN 500mg Berry", "Detected caution keyword: berry", false, "", ConfidenceRegex},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := &Analyzer{Supplements: tracked("nmn"), Decisions: review.Decisions{}}
+			if tc.dismiss != "" {
+				a.Decisions[review.Key("Vendor", "nmn", tc.dismiss)] = review.Decision{Decision: review.Dismiss}
+			}
+			got := a.AnalyzeProduct("Vendor", product(tc.title))
+			if len(got) != 1 {
+				t.Fatalf("got %d analyses, want 1", len(got))
+			}
+			e := got[0]
+			if e.NeedsReview != tc.wantReview || e.Caution != tc.wantCaution || e.Confidence != tc.wantConfidence {
+				t.Errorf("review/caution/confidence = %v/%q/%v, want %v/%q/%v",
+					e.NeedsReview, e.Caution, e.Confidence, tc.wantReview, tc.wantCaution, tc.wantConfidence)
+			}
+			if BelowFold(e) != tc.wantReview {
+				t.Errorf("BelowFold() = %v, want %v: caution entries still rank", BelowFold(e), tc.wantReview)
+			}
+		})
+	}
+}
+
+func TestVendorSupplementScope(t *testing.T) {
+	a := &Analyzer{
+		Supplements: tracked("nmn", "creatine"),
+		Rules:       rules.Registry{"Creatine Shop": {Supplements: []string{"creatine"}}},
+	}
+	nmn := models.Product{
+		Handle:   "nmn-500",
+		Title:    "NMN 500mg",
+		Variants: []models.Variant{{Price: "50.00", Title: "60 Capsules", Available: true}},
+	}
+
+	if got := a.AnalyzeProduct("Creatine Shop", nmn); got != nil {
+		t.Errorf("scoped vendor analyzed out-of-scope product: %+v", got)
+	}
+	unparseable := models.Product{
+		Handle:   "nmn-mystery",
+		Title:    "NMN",
+		Variants: []models.Variant{{Price: "50.00", Title: "Bottle", Available: true}},
+	}
+	if gap := a.AuditProduct("Creatine Shop", unparseable); gap != nil {
+		t.Errorf("scoped vendor audited out-of-scope product: %+v", gap)
+	}
+	if gap := a.AuditProduct("Any Shop", unparseable); gap == nil {
+		t.Error("unscoped vendor: want an audit gap")
+	}
+	if got := a.AnalyzeProduct("Any Shop", nmn); len(got) != 1 {
+		t.Errorf("unscoped vendor: got %d analyses, want 1", len(got))
+	}
+}
+
+func TestLiquidMass(t *testing.T) {
+	cases := []struct {
+		name  string
+		clean string
+		broad string
+		want  float64
+		ok    bool
+	}{
+		{"ml volume", "Liposomal NMN 60 ml", "<p>50 mg/ml</p>", 3, true},
+		{"fl oz with ml equivalent", "Liposomal NMN 2 fl oz (60 ml)", "<p>Each 5 ml contains 250 mg per 5 ml</p>", 3, true},
+		{"fl oz only", "Liposomal NMN 2 fl oz", "50 mg per ml", 50 * 2 * mlPerFlOz / 1000, true},
+		{"volume only in body", "Liposomal NMN", "100 mg/ml. Bottle: 30 ml", 3, true},
+		{"no concentration", "Liposomal NMN 2 fl oz", "500 mg per serving", 0, false},
+		{"no volume", "Liposomal NMN", "50 mg/ml", 0, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := extractLiquidMass(tc.clean, tc.clean+" "+tc.broad)
+			if ok != tc.ok || math.Abs(got-tc.want) > 1e-9 {
+				t.Errorf("extractLiquidMass() = %v, %v; want %v, %v", got, ok, tc.want, tc.ok)
+			}
+		})
+	}
+}
+
+func TestSiblingUnitMg(t *testing.T) {
+	a := &Analyzer{Supplements: tracked("nmn")}
+	analyze := func(titles ...string) []models.Analysis {
+		t.Helper()
+		p := models.Product{Handle: "nmn-capsules", Title: "NMN Capsules"}
+		for _, title := range titles {
+			p.Variants = append(p.Variants, models.Variant{Price: "60.00", Title: title, Available: true})
+		}
+		return a.AnalyzeProduct("Vendor", p)
+	}
+
+	got := analyze("500mg / 60 Capsules", "120 Capsules")
+	if len(got) != 2 {
+		t.Fatalf("got %d entries, want both sizes", len(got))
+	}
+	own, inferred := got[0], got[1]
+	if own.ActiveGrams != 30 || own.Confidence != ConfidenceRegex {
+		t.Errorf("stated variant = %v g at %v, want 30 g at %v", own.ActiveGrams, own.Confidence, ConfidenceRegex)
+	}
+	if inferred.ActiveGrams != 60 || inferred.UnitMg != 500 || inferred.Confidence != ConfidenceSibling {
+		t.Errorf("count-only variant = %v g, %v mg at %v; want 60 g, 500 mg at %v", inferred.ActiveGrams, inferred.UnitMg, inferred.Confidence, ConfidenceSibling)
+	}
+	if want := (models.Attribution{Price: "listed", Grams: sourceSibling, Mg: `sibling variant "500mg / 60 Capsules"`}); *inferred.Attribution != want {
+		t.Errorf("attribution = %+v, want %+v", *inferred.Attribution, want)
+	}
+
+	// Two strengths: the count-only variant could be either
+	if got := analyze("250mg / 60 Capsules", "500mg / 60 Capsules", "120 Capsules"); len(got) != 2 {
+		t.Errorf("got %d entries with disagreeing siblings, want the count-only variant skipped", len(got))
+	}
+}
+
+func TestExtractScoop(t *testing.T) {
+	for s, want := range map[string]float64{
+		"1 scoop = 1g":                           1000,
+		"Serving Size: 1 Scoop (1.5g)":           1500,
+		"Scoop size: 5 grams":                    5000,
+		"Each scoop contains 500 mg of NMN.":     500,
+		"One level scoop provides approx. 250mg": 250,
+		"2,5 g per scoop":                        2500,
+		"Includes a scoop. 100 g":                0,
+	} {
+		got, rest := extractScoop(s)
+		if got != want {
+			t.Errorf("extractScoop(%q) = %v, want %v", s, got, want)
+		}
+		if want > 0 && strings.Contains(strings.ToLower(rest), "scoop") {
+			t.Errorf("extractScoop(%q) left %q, want the statement removed", s, rest)
+		}
+	}
+}
+
+func TestScoop(t *testing.T) {
+	a := &Analyzer{Supplements: taxonomy.Registry{{Name: "creatine", TargetDoseMg: 5000}}}
+	product := func(title, body string) models.Product {
+		return models.Product{
+			Handle:   "creatine",
+			Title:    title,
+			BodyHTML: body,
+			Variants: []models.Variant{{Price: "30.00", Title: "Default Title", Available: true}},
+		}
+	}
+
+	// No weight on the label: 60 scoops of 5 g make 300 g, and the day's 5 g
+	// costs a sixtieth of the tub
+	got := a.AnalyzeProduct("Vendor", product("Creatine Monohydrate Powder", "<p>1 scoop = 5 g. 60 servings per tub.</p>"))
+	if len(got) != 1 {
+		t.Fatalf("scoop × servings: got %d analyses, want 1", len(got))
+	}
+	e := got[0]
+	if e.ActiveGrams != 300 || e.Type != "Powder" || e.ScoopMg != 5000 || e.ServingsPerContainer != 60 || math.Abs(e.CostPerDay-0.5) > 1e-9 {
+		t.Errorf("active/type/scoop/servings/cost per day = %v/%q/%v/%d/%v, want 300/Powder/5000/60/0.5",
+			e.ActiveGrams, e.Type, e.ScoopMg, e.ServingsPerContainer, e.CostPerDay)
+	}
+
+	// A labeled weight stands; the scoop only counts the servings
+	got = a.AnalyzeProduct("Vendor", product("Creatine Monohydrate Powder 500g", "Each scoop contains 3 g."))
+	if len(got) != 1 || got[0].ActiveGrams != 500 || got[0].ScoopMg != 3000 || got[0].ServingsPerContainer != 166 {
+		t.Errorf("labeled weight: got %+v, want 500 g, 3000 mg scoop, 166 servings", got)
+	}
+
+	// A scoop size alone is not the container's weight
+	if got := a.AnalyzeProduct("Vendor", product("Creatine Monohydrate Powder", "1 scoop = 5g")); len(got) != 0 {
+		t.Errorf("scoop only: got %+v, want no analysis", got)
+	}
+}
+
+func TestMinOrderQty(t *testing.T) {
+	p := models.Product{
+		Handle: "nmn-powder",
+		Title:  "NMN Powder",
+		Variants: []models.Variant{
+			{Price: "20.00", Title: "100g", Available: true, MinOrderQty: 3},
+			{Price: "40.00", Title: "250g", Available: true},
+			{Price: "70.00", Title: "500g", Available: true},
+		},
+	}
+	a := &Analyzer{
+		Supplements: tracked("nmn"),
+		Rules: rules.Registry{"Vendor": {
+			GlobalSubscriptionDiscount: 0.1,
+			Overrides: map[string]rules.ProductSpec{
+				"nmn-powder": {VariantMinOrderQty: map[string]int{"500g": 2}},
+			},
+		}},
+	}
+
+	want := map[string]struct {
+		minQty     int
+		entryPrice float64
+	}{
+		"NMN Powder (100g)":                    {3, 60},
+		"NMN Powder (100g) (Subscribe & Save)": {3, 54},
+		"NMN Powder (250g)":                    {0, 0},
+		"NMN Powder (250g) (Subscribe & Save)": {0, 0},
+		"NMN Powder (500g)":                    {2, 140},
+		"NMN Powder (500g) (Subscribe & Save)": {2, 126},
+	}
+	got := a.AnalyzeProduct("Vendor", p)
+	if len(got) != len(want) {
+		t.Fatalf("got %d analyses, want %d", len(got), len(want))
+	}
+	for _, entry := range got {
+		w, ok := want[entry.Name]
+		if !ok {
+			t.Errorf("unexpected entry %q", entry.Name)
+			continue
+		}
+		if entry.MinOrderQty != w.minQty || math.Abs(entry.EntryPrice-w.entryPrice) > 1e-9 {
+			t.Errorf("%s: min %d entry $%.2f, want min %d entry $%.2f",
+				entry.Name, entry.MinOrderQty, entry.EntryPrice, w.minQty, w.entryPrice)
+		}
+	}
+}
+
+func TestSubscriptionFrequencies(t *testing.T) {
+	p := models.Product{
+		Handle:   "nmn-capsules",
+		Title:    "NMN 500mg",
+		Variants: []models.Variant{{Price: "100.00", Title: "60 Capsules", Available: true}},
+	}
+	a := &Analyzer{
+		Supplements: tracked("nmn"),
+		Rules: rules.Registry{"Vendor": {
+			GlobalSubscriptionDiscount: 0.5, // ignored when frequencies are set
+			SubscriptionRisks:          []string{rules.RiskHardToCancel},
+			SubscriptionFrequencies: []rules.SubscriptionFrequency{
+				{Days: 60, Discount: 0.10},
+				{Days: 30, Discount: 0.20},
+				{Days: 45, Discount: 0.15},
+				{Days: 90, Discount: 0}, // invalid: no discount
+			},
+		}},
+	}
+
+	got := a.AnalyzeProduct("Vendor", p)
+	if len(got) != 2 || !got[1].IsSubscription {
+		t.Fatalf("got %+v, want one-time and subscription entries", got)
+	}
+	if got[0].SubscriptionOptions != nil || got[0].SubscriptionRisks != nil {
+		t.Errorf("one-time entry has subscription options %+v, risks %v", got[0].SubscriptionOptions, got[0].SubscriptionRisks)
+	}
+	if len(got[1].SubscriptionRisks) != 1 || got[1].SubscriptionRisks[0] != rules.RiskHardToCancel {
+		t.Errorf("subscription risks = %v, want [%s]", got[1].SubscriptionRisks, rules.RiskHardToCancel)
+	}
+
+	sub := got[1]
+	if sub.Price != 80 {
+		t.Errorf("subscription price = %v, want cheapest delivery 80", sub.Price)
+	}
+	want := []models.SubscriptionOption{
+		{IntervalDays: 30, Price: 80, AnnualCost: 80 * 365 / 30.0},
+		{IntervalDays: 45, Price: 85, AnnualCost: 85 * 365 / 45.0},
+		{IntervalDays: 60, Price: 90, AnnualCost: 90 * 365 / 60.0},
+	}
+	if len(sub.SubscriptionOptions) != len(want) {
+		t.Fatalf("options = %+v, want %+v", sub.SubscriptionOptions, want)
+	}
+	for i, w := range want {
+		o := sub.SubscriptionOptions[i]
+		if o.IntervalDays != w.IntervalDays || math.Abs(o.Price-w.Price) > 1e-9 || math.Abs(o.AnnualCost-w.AnnualCost) > 1e-9 {
+			t.Errorf("option[%d] = %+v, want %+v", i, o, w)
+		}
+	}
+
+	// With no valid interval the global discount still applies
+	a.Rules["Vendor"] = rules.VendorConfig{
+		GlobalSubscriptionDiscount: 0.5,
+		SubscriptionFrequencies:    []rules.SubscriptionFrequency{{Days: 30, Discount: 1.5}},
+	}
+	got = a.AnalyzeProduct("Vendor", p)
+	if len(got) != 2 || got[1].Price != 50 || got[1].SubscriptionOptions != nil {
+		t.Errorf("invalid frequencies: got %+v, want a 50%% subscription entry without options", got)
+	}
+}
+
+func TestDailyCost(t *testing.T) {
+	a := &Analyzer{
+		Supplements: taxonomy.Registry{{Name: "nmn", TargetDoseMg: 1000}},
+	}
+	capsules := models.Product{
+		Handle:   "nmn-400",
+		Title:    "NMN 400mg",
+		Variants: []models.Variant{{Price: "30.00", Title: "60 Capsules", Available: true}},
+	}
+	powder := models.Product{
+		Handle:   "nmn-powder",
+		Title:    "NMN Powder",
+		Variants: []models.Variant{{Price: "50.00", Title: "100g", Available: true}},
+	}
+
+	tests := []struct {
+		product     models.Product
+		unitsPerDay int
+		doseMg      float64
+		costPerDay  float64
+		unitMg      float64
+	}{
+		// 1000 mg from 400 mg capsules: 3 capsules, 1200 mg of a 24 g bottle
+		{capsules, 3, 1200, 1.5, 400},
+		// Powder is dosed exactly: 1 g of 100 g
+		{powder, 0, 1000, 0.5, 0},
+	}
+	for _, tt := range tests {
+		got := a.AnalyzeProduct("Vendor", tt.product)
+		if len(got) != 1 {
+			t.Fatalf("%s: got %d analyses, want 1", tt.product.Handle, len(got))
+		}
+		e := got[0]
+		if e.UnitsPerDay != tt.unitsPerDay || e.DailyDoseMg != tt.doseMg || math.Abs(e.CostPerDay-tt.costPerDay) > 1e-9 {
+			t.Errorf("%s: units/dose/cost = %d/%.0f/%.4f, want %d/%.0f/%.4f", tt.product.Handle,
+				e.UnitsPerDay, e.DailyDoseMg, e.CostPerDay, tt.unitsPerDay, tt.doseMg, tt.costPerDay)
+		}
+		if e.UnitMg != tt.unitMg {
+			t.Errorf("%s: unit mg = %v, want %v", tt.product.Handle, e.UnitMg, tt.unitMg)
+		}
+	}
+}
+
+func TestActiveForm(t *testing.T) {
+	a := &Analyzer{
+		Supplements: tracked("creatine", "resveratrol"),
+		Rules: rules.Registry{"Brand": {Overrides: map[string]rules.ProductSpec{
+			"creatine-hcl-base": {ActiveFraction: 1},
+		}}},
+	}
+
+	tests := []struct {
+		handle, title, variant string
+		form                   string
+		fraction               float64 // Analysis.ActiveFraction; 0 when omitted
+		activeGrams            float64
+		grossGrams             float64
+	}{
+		{"creatine-hcl", "Creatine HCl 750mg", "120 Capsules", "Creatine HCl", 0.782, 90 * 0.782, 0},
+		{"creatine", "Creatine-Monohydrate Powder", "500g", "Creatine Monohydrate", 0.879, 500 * 0.879, 500},
+		{"creatine-powder", "Creatine Powder", "500g", "", 0, 500, 500},
+		// The override says the label already states creatine base
+		{"creatine-hcl-base", "Creatine HCl Powder", "100g", "Creatine HCl", 0, 100, 100},
+		// Not a resveratrol salt: labeled, never converted
+		{"pterostilbene", "Resveratrol & Pterostilbene 500mg", "60 Capsules", "Pterostilbene", 0, 30, 0},
+	}
+	for _, tt := range tests {
+		p := models.Product{
+			Handle:   tt.handle,
+			Title:    tt.title,
+			Variants: []models.Variant{{Price: "30.00", Title: tt.variant, Available: true}},
+		}
+		got := a.AnalyzeProduct("Brand", p)
+		if len(got) != 1 {
+			t.Fatalf("%s: got %d analyses, want 1", tt.handle, len(got))
+		}
+		e := got[0]
+		if e.ActiveForm != tt.form || e.ActiveFraction != tt.fraction ||
+			math.Abs(e.ActiveGrams-tt.activeGrams) > 1e-9 || e.GrossGrams != tt.grossGrams {
+			t.Errorf("%s: form/fraction/active/gross = %q/%v/%v/%v, want %q/%v/%v/%v", tt.handle,
+				e.ActiveForm, e.ActiveFraction, e.ActiveGrams, e.GrossGrams, tt.form, tt.fraction, tt.activeGrams, tt.grossGrams)
+		}
+	}
+}
+
+func TestSupplementPurityAndUnitRange(t *testing.T) {
+	a := &Analyzer{Supplements: taxonomy.Registry{{Name: "nmn", Purity: 0.98, MinUnitMg: 50, MaxUnitMg: 1500}}}
+	analyze := func(title string) models.Analysis {
+		t.Helper()
+		got := a.AnalyzeProduct("Brand", models.Product{
+			Handle:   "nmn",
+			Title:    title,
+			Variants: []models.Variant{{Price: "30.00", Title: "60 Capsules", Available: true}},
+		})
+		if len(got) != 1 {
+			t.Fatalf("%s: got %d analyses, want 1", title, len(got))
+		}
+		return got[0]
+	}
+
+	e := analyze("NMN 500mg")
+	if math.Abs(e.ActiveGrams-30*0.98) > 1e-9 || e.ActiveFraction != 0.98 || e.NeedsReview {
+		t.Errorf("98%% pure: active/fraction/review = %v/%v/%v, want %v/0.98/false", e.ActiveGrams, e.ActiveFraction, e.NeedsReview, 30*0.98)
+	}
+	// 5 mg is another ingredient's dose, not NMN per capsule
+	e = analyze("NMN & Zinc 5mg")
+	want := "5 mg per capsule/tablet, NMN expects 50–1500 mg"
+	if !e.NeedsReview || e.ReviewReason != reasonImplausibleUnit || e.ReviewDetail != want {
+		t.Errorf("5 mg capsules: review = %v %q (%q), want %q", e.NeedsReview, e.ReviewReason, e.ReviewDetail, want)
+	}
+}
+
+func TestSupplementCostRange(t *testing.T) {
+	a := &Analyzer{Supplements: taxonomy.Registry{{Name: "nmn", MinCostPerGram: 0.30, MaxCostPerGram: 20}}}
+	analyze := func(price string) models.Analysis {
+		t.Helper()
+		got := a.AnalyzeProduct("Brand", models.Product{
+			Handle:   "nmn",
+			Title:    "NMN 500mg",
+			Variants: []models.Variant{{Price: price, Title: "60 Capsules", Available: true}},
+		})
+		if len(got) != 1 {
+			t.Fatalf("$%s: got %d analyses, want 1", price, len(got))
+		}
+		return got[0]
+	}
+
+	if e := analyze("30.00"); e.NeedsReview || e.Confidence != ConfidenceRegex {
+		t.Errorf("$1/g: review = %v (%q), confidence %v; want a trusted entry", e.NeedsReview, e.ReviewReason, e.Confidence)
+	}
+	// 30 g for $6 is a mass read off by an order of magnitude, or a bad price
+	tests := []struct{ price, want string }{
+		{"6.00", "$0.20/g, NMN expects $0.30–$20.00/g"},
+		{"900.00", "$30.00/g, NMN expects $0.30–$20.00/g"},
+	}
+	for _, tt := range tests {
+		e := analyze(tt.price)
+		if !e.NeedsReview || e.ReviewReason != reasonImplausibleCost || e.ReviewDetail != tt.want || e.Confidence != ConfidenceFlagged {
+			t.Errorf("$%s: review = %v %q (%q), confidence %v; want %q", tt.price, e.NeedsReview, e.ReviewReason, e.ReviewDetail, e.Confidence, tt.want)
+		}
+	}
+}
+
+func TestRankScore(t *testing.T) {
+	// $20 for 100 g of liposomal NMN: $0.20/g, 1.5× bioavailability, NSF (1.25×),
+	// $5 shipping under $50, 20% off a $25 compare-at price
+	product := models.Product{
+		Handle:   "lipo-nmn",
+		Title:    "Liposomal NMN Powder",
+		Variants: []models.Variant{{Price: "20.00", CompareAtPrice: "25.00", Title: "100g", Available: true}},
+	}
+	reg := func(weights map[string]float64) rules.Registry {
+		return rules.Registry{
+			rules.GlobalKey: {CertificationMultipliers: map[string]float64{"NSF": 1.25}, RankWeights: weights},
+			"Brand":         {Certifications: []string{"NSF"}, ShippingCost: 5, FreeShippingOver: 50},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		weights map[string]float64
+		want    float64
+	}{
+		{"unconfigured", nil, 0.2 / 1.5 / 1.25},
+		{"effective cost", map[string]float64{"cost": 1, "bioavailability": 1, "trust": 1}, 0.2 / 1.5 / 1.25},
+		{"cost only", map[string]float64{"cost": 1}, 0.2},
+		{"shipping", map[string]float64{"cost": 1, "shipping": 1}, 0.2 * 25 / 20},
+		{"half-weight deal", map[string]float64{"cost": 1, "deal": 0.5}, 0.2 * math.Sqrt(0.8)},
+	}
+	for _, tt := range tests {
+		a := &Analyzer{Supplements: tracked("nmn"), Rules: reg(tt.weights)}
+		got := a.AnalyzeProduct("Brand", product)
+		if len(got) != 1 {
+			t.Fatalf("%s: got %d analyses, want 1", tt.name, len(got))
+		}
+		if e := got[0]; math.Abs(e.RankScore-tt.want) > 1e-9 || e.ShippingCost != 5 {
+			t.Errorf("%s: rank score/shipping = %v/%v, want %v/5", tt.name, e.RankScore, e.ShippingCost, tt.want)
+		}
+	}
+}
+
+func TestRankedBefore(t *testing.T) {
+	entry := func(vendor, handle, variant string, score float64, sub bool) models.Analysis {
+		return models.Analysis{Vendor: vendor, Handle: handle, Variant: variant, RankScore: score,
+			IsSubscription: sub, Confidence: ConfidenceRegex}
+	}
+	flagged := entry("A", "cheap", "", 0.01, false)
+	flagged.NeedsReview = true
+	want := []models.Analysis{
+		entry("B", "nmn", "", 0.10, false),
+		entry("A", "nmn", "500g", 0.20, false),
+		entry("A", "nmn", "500g", 0.20, true),
+		entry("A", "tmg", "", 0.20, false),
+		entry("B", "nmn", "100g", 0.20, false),
+		entry("B", "nmn", "250g", 0.20, false),
+		flagged,
+	}
+
+	// Every shuffle of the input sorts to the same order
+	for shift := range want {
+		report := append(append([]models.Analysis{}, want[shift:]...), want[:shift]...)
+		slices.Reverse(report[:len(report)/2])
+		sort.Slice(report, func(i, j int) bool { return RankedBefore(report[i], report[j]) })
+		if !reflect.DeepEqual(report, want) {
+			t.Errorf("shift %d: sorted = %+v, want %+v", shift, report, want)
+		}
+	}
+}
+
+func TestCurrency(t *testing.T) {
+	// €40 (compare-at €50) for 100 g at 1.10 $/€, €6 shipping under €45
+	a := &Analyzer{
+		Supplements: tracked("nmn"),
+		Rules: rules.Registry{
+			rules.GlobalKey: {ExchangeRates: map[string]float64{"EUR": 1.10}},
+			"EU Shop":       {Currency: "eur", ShippingCost: 6, FreeShippingOver: 45, GlobalSubscriptionDiscount: 0.1},
+		},
+	}
+	got := a.AnalyzeProduct("EU Shop", models.Product{
+		Handle:   "nmn",
+		Title:    "NMN Powder",
+		Variants: []models.Variant{{Price: "40.00", CompareAtPrice: "50.00", Title: "100g", Available: true}},
+	})
+	if len(got) != 2 {
+		t.Fatalf("got %d analyses, want one-time and subscription", len(got))
+	}
+	oneTime, sub := got[0], got[1]
+	near := func(x, y float64) bool { return math.Abs(x-y) < 1e-9 }
+	if !near(oneTime.Price, 44) || !near(oneTime.NativePrice, 40) || oneTime.NativeCurrency != "EUR" {
+		t.Errorf("price/native = %v/%v %s, want 44/40 EUR", oneTime.Price, oneTime.NativePrice, oneTime.NativeCurrency)
+	}
+	if !near(oneTime.CostPerGram, 0.44) || !near(oneTime.CompareAtPrice, 55) || !near(oneTime.DiscountPct, 20) {
+		t.Errorf("$/g/compare-at/discount = %v/%v/%v, want 0.44/55/20", oneTime.CostPerGram, oneTime.CompareAtPrice, oneTime.DiscountPct)
+	}
+	// €40 is under the €45 threshold, although $44 would not be
+	if !near(oneTime.ShippingCost, 6.6) {
+		t.Errorf("shipping = %v, want 6.60 (€6)", oneTime.ShippingCost)
+	}
+	if !near(sub.Price, 39.6) || !near(sub.NativePrice, 36) || sub.NativeCurrency != "EUR" {
+		t.Errorf("subscription price/native = %v/%v %s, want 39.6/36 EUR", sub.Price, sub.NativePrice, sub.NativeCurrency)
+	}
+
+	usd := &Analyzer{Supplements: tracked("nmn")}
+	if e := usd.AnalyzeProduct("US Shop", models.Product{Handle: "nmn", Title: "NMN Powder",
+		Variants: []models.Variant{{Price: "40.00", Title: "100g", Available: true}}}); len(e) != 1 || e[0].NativePrice != 0 || e[0].NativeCurrency != "" {
+		t.Errorf("USD vendor = %+v, want no native price", e)
+	}
+}
+
+func TestCertifications(t *testing.T) {
+	a := &Analyzer{
+		Supplements: tracked("creatine"),
+		Rules: rules.Registry{
+			rules.GlobalKey: {CertificationMultipliers: map[string]float64{"NSF Certified for Sport": 1.25, "informed sport": 1.1}},
+			"Brand": {
+				Certifications: []string{"Informed Sport"},
+				Overrides: map[string]rules.ProductSpec{
+					"creatine-nsf": {Certifications: []string{"NSF Certified for Sport", "informed sport"}},
+				},
+			},
+		},
+	}
+	product := func(handle string) models.Product {
+		return models.Product{
+			Handle:   handle,
+			Title:    "Creatine Powder",
+			Variants: []models.Variant{{Price: "50.00", Title: "500g", Available: true}},
+		}
+	}
+
+	tests := []struct {
+		vendor, handle string
+		certs          []string
+		multiplier     float64
+		effectiveCost  float64
+	}{
+		{"Brand", "creatine", []string{"Informed Sport"}, 1.1, 0.1 / 1.1},
+		// Marks don't compound: the best multiplier wins
+		{"Brand", "creatine-nsf", []string{"Informed Sport", "NSF Certified for Sport"}, 1.25, 0.08},
+		{"Other", "creatine", nil, 0, 0.1},
+	}
+	for _, tt := range tests {
+		got := a.AnalyzeProduct(tt.vendor, product(tt.handle))
+		if len(got) != 1 {
+			t.Fatalf("%s/%s: got %d analyses, want 1", tt.vendor, tt.handle, len(got))
+		}
+		e := got[0]
+		if !reflect.DeepEqual(e.Certifications, tt.certs) || e.QualityMultiplier != tt.multiplier || math.Abs(e.EffectiveCost-tt.effectiveCost) > 1e-9 {
+			t.Errorf("%s/%s: certs/multiplier/effective = %q/%v/%v, want %q/%v/%v", tt.vendor, tt.handle,
+				e.Certifications, e.QualityMultiplier, e.EffectiveCost, tt.certs, tt.multiplier, tt.effectiveCost)
+		}
+	}
+}
+
+func TestQualityScore(t *testing.T) {
+	a := &Analyzer{
+		Supplements: tracked("creatine"),
+		Rules:       rules.Registry{rules.GlobalKey: {CertificationMultipliers: map[string]float64{"NSF": 1.25}}, "Brand": {Certifications: []string{"NSF"}}},
+		Scores:      scores.Table{{Brand: "Brand", Score: 80, Source: "Labdoor"}},
+	}
+	p := models.Product{
+		Handle:   "creatine",
+		Title:    "Creatine Powder",
+		Variants: []models.Variant{{Price: "50.00", Title: "500g", Available: true}},
+	}
+
+	got := a.AnalyzeProduct("Brand", p)
+	if len(got) != 1 {
+		t.Fatalf("got %d analyses, want 1", len(got))
+	}
+	// $0.10/g, ÷1.25 certification multiplier = $0.08/g, ÷0.80 score = $0.10/g
+	e := got[0]
+	if e.QualityScore != 80 || e.QualitySource != "Labdoor" || math.Abs(e.QualityAdjustedCost-0.1) > 1e-9 {
+		t.Errorf("score/source/adjusted = %v/%q/%v, want 80/Labdoor/0.1", e.QualityScore, e.QualitySource, e.QualityAdjustedCost)
+	}
+
+	if got := a.AnalyzeProduct("Unscored", p); got[0].QualityScore != 0 || got[0].QualityAdjustedCost != 0 {
+		t.Errorf("unscored vendor got a quality score: %+v", got[0])
+	}
+}
+
+func TestBrandAttribution(t *testing.T) {
+	a := &Analyzer{
+		Supplements: tracked("creatine"),
+		Rules: rules.Registry{
+			"Brand":    {Certifications: []string{"NSF"}},
+			"Retailer": {Overrides: map[string]rules.ProductSpec{"creatine": {Certifications: []string{"Informed Sport"}}}},
+		},
+		Scores: scores.Table{{Brand: "Brand", Score: 80, Source: "Labdoor"}, {Brand: "Retailer", Score: 50}},
+	}
+	p := models.Product{
+		Handle:   "creatine",
+		Title:    "Creatine Powder",
+		Brand:    "Brand",
+		Variants: []models.Variant{{Price: "50.00", Title: "500g", Available: true}},
+	}
+
+	got := a.AnalyzeProduct("Retailer", p)
+	if len(got) != 1 {
+		t.Fatalf("got %d analyses, want 1", len(got))
+	}
+	// The brand's marks and score, plus the retailer's product override
+	e := got[0]
+	if e.Vendor != "Retailer" || e.Brand != "Brand" || !reflect.DeepEqual(e.Certifications, []string{"NSF", "Informed Sport"}) || e.QualityScore != 80 {
+		t.Errorf("vendor/brand/certs/score = %q/%q/%q/%v, want Retailer/Brand/[NSF Informed Sport]/80",
+			e.Vendor, e.Brand, e.Certifications, e.QualityScore)
+	}
+}
+
+func TestUnitPrice(t *testing.T) {
+	a := &Analyzer{Supplements: tracked("nmn")}
+	analyze := func(title string, unitPrice float64) models.Analysis {
+		t.Helper()
+		got := a.AnalyzeProduct("Vendor", models.Product{
+			Handle:   "nmn",
+			Title:    title,
+			Variants: []models.Variant{{Price: "30.00", Title: "Default", Available: true, UnitPrice: unitPrice}},
+		})
+		if len(got) != 1 {
+			t.Fatalf("%q: got %d analyses, want 1", title, len(got))
+		}
+		return got[0]
+	}
+
+	// No mass in the text: $0.60/g buys 50 g
+	if e := analyze("NMN Powder", 0.6); math.Abs(e.ActiveGrams-50) > 1e-9 || math.Abs(e.CostPerGram-0.6) > 1e-9 || e.NeedsReview {
+		t.Errorf("unit price only = %v g at $%v/g (review %v), want 50 g at $0.60/g", e.ActiveGrams, e.CostPerGram, e.NeedsReview)
+	}
+	// Label agrees within the tolerance
+	if e := analyze("NMN Powder 50g", 0.62); e.NeedsReview || e.ActiveGrams != 50 {
+		t.Errorf("agreeing unit price flagged: %q", e.ReviewReason)
+	}
+	// The label says 50 g, the page prices 100 g: the regex mass stays, flagged
+	e := analyze("NMN Powder 50g", 0.3)
+	if !e.NeedsReview || e.ActiveGrams != 50 || e.ReviewReason != reasonUnitPrice || e.ReviewDetail != "page states $30.00/100g, label mass gives $60.00/100g" {
+		t.Errorf("disagreeing unit price = %v g, review %v %q (%q)", e.ActiveGrams, e.NeedsReview, e.ReviewReason, e.ReviewDetail)
+	}
+	// Capsule fill is not the product weight; without a label weight there is nothing to compare
+	if e := analyze("NMN 500mg 60 Capsules", 0.3); e.NeedsReview {
+		t.Errorf("capsule product flagged: %q", e.ReviewReason)
+	}
+	// Neither text nor unit price: no entry
+	if got := a.AnalyzeProduct("Vendor", models.Product{Handle: "nmn", Title: "NMN",
+		Variants: []models.Variant{{Price: "30.00", Title: "Default", Available: true}}}); got != nil {
+		t.Errorf("no mass = %+v, want nil", got)
+	}
+}
+
+func TestCartPrice(t *testing.T) {
+	a := &Analyzer{
+		Supplements: tracked("nmn"),
+		Rules:       rules.Registry{"Vendor": {GlobalSubscriptionDiscount: 0.1}},
+	}
+	analyze := func(cartPrice string) []models.Analysis {
+		t.Helper()
+		got := a.AnalyzeProduct("Vendor", models.Product{
+			Handle:   "nmn",
+			Title:    "NMN 500mg",
+			Variants: []models.Variant{{Price: "100.00", Title: "60 Capsules", Available: true, CartPrice: cartPrice}},
+		})
+		if len(got) != 2 {
+			t.Fatalf("cart price %q: got %d analyses, want one-time and subscription", cartPrice, len(got))
+		}
+		return got
+	}
+
+	// The cart's tiered discount is what checkout charges
+	got := analyze("85.00")
+	if got[0].Price != 85 || got[0].PriceSource != models.PriceSourceCart {
+		t.Errorf("one-time = $%v from %q, want $85 from the cart", got[0].Price, got[0].PriceSource)
+	}
+	// Subscriptions are priced off the listed price
+	if got[1].Price != 90 || got[1].PriceSource != "" {
+		t.Errorf("subscription = $%v from %q, want $90 off the listed price", got[1].Price, got[1].PriceSource)
+	}
+	// A cart at the listed price, or a placeholder, leaves the listed price
+	for _, cart := range []string{"", "100.00", "0.00"} {
+		if got := analyze(cart); got[0].Price != 100 || got[0].PriceSource != "" {
+			t.Errorf("cart %q: one-time = $%v from %q, want the listed $100", cart, got[0].Price, got[0].PriceSource)
+		}
+	}
+}
+
+func TestAttribution(t *testing.T) {
+	a := &Analyzer{
+		Supplements: tracked("nmn"),
+		Rules: rules.Registry{"Vendor": {
+			GlobalSubscriptionDiscount: 0.1,
+			Overrides:                  map[string]rules.ProductSpec{"forced": {ForceActiveGrams: 30}},
+		}},
+		PriceSources: map[string]string{"Vendor": "shopify-api"},
+	}
+	analyze := func(handle, title, body string, v models.Variant) []models.Analysis {
+		t.Helper()
+		v.Available = true
+		got := a.AnalyzeProduct("Vendor", models.Product{Handle: handle, Title: title, BodyHTML: body, Variants: []models.Variant{v}})
+		if len(got) == 0 || got[0].Attribution == nil {
+			t.Fatalf("%q: got %+v, want an attributed analysis", title, got)
+		}
+		return got
+	}
+
+	got := analyze("nmn", "NMN 60 Capsules", "<p>Each capsule contains 500mg NMN</p>", models.Variant{Price: "40.00", Title: "Default Title", CartPrice: "36.00"})
+	if want := (models.Attribution{Price: "shopify-api + cart", Grams: "mg × count regex", Mg: "body_html regex"}); *got[0].Attribution != want {
+		t.Errorf("one-time attribution = %+v, want %+v", *got[0].Attribution, want)
+	}
+	if p := got[1].Attribution.Price; p != "shopify-api − subscription discount" {
+		t.Errorf("subscription price from %q", p)
+	}
+	if got := analyze("forced", "NMN Powder", "", models.Variant{Price: "40.00", Title: "Default Title"}); *got[0].Attribution != (models.Attribution{Price: "shopify-api", Grams: "override"}) {
+		t.Errorf("override attribution = %+v", *got[0].Attribution)
+	}
+	if got := analyze("nmn", "NMN Powder", "", models.Variant{Price: "30.00", Title: "Default Title", UnitPrice: 0.6}); got[0].Attribution.Grams != "unit price" {
+		t.Errorf("unit price grams from %q", got[0].Attribution.Grams)
+	}
+}
+
+func TestPossiblyDelisted(t *testing.T) {
+	a := &Analyzer{Supplements: tracked("nmn"), Rules: rules.Registry{"Vendor": {GlobalSubscriptionDiscount: 0.1}}}
+	p := models.Product{
+		Handle:       "nmn",
+		Title:        "NMN 500mg",
+		Variants:     []models.Variant{{Price: "40.00", Title: "60 Capsules", Available: true}},
+		MissingSince: "2026-01-10",
+	}
+	got := a.AnalyzeProduct("Vendor", p)
+	if len(got) != 2 {
+		t.Fatalf("got %d analyses, want one-time and subscription", len(got))
+	}
+	for _, e := range got {
+		if !e.PossiblyDelisted || e.MissingSince != "2026-01-10" {
+			t.Errorf("%s: possibly delisted %v since %q, want true since 2026-01-10", e.Name, e.PossiblyDelisted, e.MissingSince)
+		}
+	}
+
+	p.MissingSince = ""
+	if got := a.AnalyzeProduct("Vendor", p); got[0].PossiblyDelisted || got[0].MissingSince != "" {
+		t.Errorf("listed product marked possibly delisted: %+v", got[0])
+	}
+}
+
+func TestIncludeUnavailable(t *testing.T) {
+	a := &Analyzer{Supplements: tracked("nmn"), Rules: rules.Registry{"Vendor": {GlobalSubscriptionDiscount: 0.1}}}
+	p := models.Product{
+		Handle: "nmn",
+		Title:  "NMN 500mg",
+		Variants: []models.Variant{
+			{Price: "40.00", Title: "60 Capsules", Available: true},
+			{Price: "60.00", Title: "120 Capsules"},
+		},
+	}
+	if got := a.AnalyzeProduct("Vendor", p); len(got) != 2 || got[0].Variant != "60 Capsules" {
+		t.Fatalf("default: got %d analyses, want the in-stock variant's one-time and subscription", len(got))
+	}
+
+	a.IncludeUnavailable = true
+	got := a.AnalyzeProduct("Vendor", p)
+	if len(got) != 4 {
+		t.Fatalf("IncludeUnavailable: got %d analyses, want 4", len(got))
+	}
+	for _, e := range got {
+		if want := e.Variant == "120 Capsules"; e.Unavailable != want || BelowFold(e) != want {
+			t.Errorf("%s (subscription %v): unavailable %v, below fold %v; want %v", e.Variant, e.IsSubscription, e.Unavailable, BelowFold(e), want)
+		}
+	}
+}
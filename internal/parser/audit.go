@@ -1,32 +1,132 @@
 package parser
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
+	"reflect"
+	"sort"
 	"strings"
 
 	"longevity-ranker/internal/models"
+	"longevity-ranker/internal/rules"
 )
 
 // AuditResult describes a product that passes interest/blocklist filters but
 // lacks enough data for the analyzer to compute activeGrams. It reports what
 // data we DO have and what is MISSING so the operator can add an override
 // in data/vendor_rules.json.
+//
+// JSON tags define the schema of data/audit_report.json.
 type AuditResult struct {
-	Vendor     string
-	Title      string
-	Handle     string
-	BestPrice  float64
-	VariantCt  int
-	MgFound    bool
-	MgValue    float64
-	CountFound bool
-	CountValue float64
-	GramsFound bool
-	GramsValue float64
-	KgFound    bool
-	KgValue    float64
-	Missing    []string
+	Vendor            string            `json:"vendor"`
+	Title             string            `json:"title"`
+	Handle            string            `json:"handle"`
+	BestPrice         float64           `json:"best_price"`
+	VariantCt         int               `json:"variant_count"`
+	MgFound           bool              `json:"mg_found"`
+	MgValue           float64           `json:"mg_value"`
+	CountFound        bool              `json:"count_found"`
+	CountValue        float64           `json:"count_value"`
+	GramsFound        bool              `json:"grams_found"`
+	GramsValue        float64           `json:"grams_value"`
+	KgFound           bool              `json:"kg_found"`
+	KgValue           float64           `json:"kg_value"`
+	Missing           []string          `json:"missing"`
+	SuggestedOverride rules.ProductSpec `json:"suggested_override"`
+	UnknownKeys       []string          `json:"unknown_keys,omitempty"` // Keys of SuggestedOverride the audit could not infer
+
+	// Impact estimate, filled in by PrioritizeAudit once the report is known.
+	EstimatedCostPerGram float64 `json:"estimated_cost_per_gram,omitempty"`
+	EstimatedRank        int     `json:"estimated_rank,omitempty"`
+	Impact               string  `json:"impact"`
+
+	// The current #1 for the gap's supplement, and whether the estimate could
+	// plausibly beat it (see contenderMargin). Filled in by PrioritizeAudit.
+	Leader            string  `json:"leader,omitempty"`
+	LeaderCostPerGram float64 `json:"leader_cost_per_gram,omitempty"`
+	Contender         bool    `json:"contender,omitempty"`
+}
+
+// specKeys maps rules.ProductSpec field names to their vendor_rules.json
+// keys. Suggestions name keys through it, so they cannot drift from the
+// schema LoadRules reads.
+var specKeys = func() map[string]string {
+	t := reflect.TypeOf(rules.ProductSpec{})
+	keys := make(map[string]string, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		keys[f.Name] = name
+	}
+	return keys
+}()
+
+// suggestOverride derives the override from the probed values and returns
+// the keys of the ones it could not infer. variantGrams maps each available
+// variant title to its mg × count grams, or 0 when its title states no
+// count. Several variants become VariantOverrides when no product-wide
+// mass was found or their masses differ, so the keys are the exact titles
+// the analyzer matches; a 0 is left for the operator (and ignored until
+// then).
+func suggestOverride(r AuditResult, variantGrams map[string]float64) (rules.ProductSpec, []string) {
+	s := rules.ProductSpec{ForceType: "Capsules"}
+	switch {
+	case r.MgFound && r.CountFound:
+		s.ForceActiveGrams = roundGrams(r.MgValue * r.CountValue / 1000.0)
+		s.ForceServingMg = r.MgValue
+	case r.GramsFound:
+		s.ForceActiveGrams = r.GramsValue
+	case r.KgFound:
+		s.ForceActiveGrams = roundGrams(r.KgValue * 1000)
+	default:
+		if r.MgFound {
+			s.ForceServingMg = r.MgValue
+		}
+	}
+	if len(variantGrams) > 1 && (s.ForceActiveGrams == 0 || distinct(variantGrams) > 1) {
+		s.VariantOverrides = variantGrams
+	}
+
+	var unknown []string
+	if s.ForceActiveGrams == 0 {
+		unknown = append(unknown, specKeys["ForceActiveGrams"])
+	}
+	if s.ForceServingMg == 0 {
+		unknown = append(unknown, specKeys["ForceServingMg"])
+	}
+	for _, g := range s.VariantOverrides {
+		if g == 0 {
+			unknown = append(unknown, specKeys["VariantOverrides"])
+			break
+		}
+	}
+	return s, unknown
+}
+
+// distinct counts the different values in m.
+func distinct(m map[string]float64) int {
+	seen := map[float64]bool{}
+	for _, v := range m {
+		seen[v] = true
+	}
+	return len(seen)
+}
+
+// roundGrams drops float noise from a multiplied mass (0.1 × 300 is
+// 30.000000000000004), so the suggestion pastes as written.
+func roundGrams(g float64) float64 {
+	return math.Round(g*1000) / 1000
+}
+
+// OverrideSnippet renders a suggested override as the "handle": {...} entry
+// of a vendor's "overrides" object in data/vendor_rules.json. It marshals
+// the rules.ProductSpec itself, so every key is one LoadRules accepts and
+// values the audit could not infer are left out rather than invented.
+func OverrideSnippet(handle string, spec rules.ProductSpec) string {
+	key, _ := json.Marshal(handle)
+	body, _ := json.MarshalIndent(spec, "", "  ")
+	return string(key) + ": " + string(body)
 }
 
 // AuditProduct runs the same extraction pipeline as AnalyzeProduct but never
@@ -48,7 +148,7 @@ func (a *Analyzer) AuditProduct(vendorName string, p models.Product) *AuditResul
 
 	// Supplement keyword gate (same as AnalyzeProduct)
 	identity := strings.ToLower(p.Title + " " + p.Context + " " + p.Handle)
-	if !a.matchesSupplement(identity) {
+	if !a.matchesSupplement(vendorName, identity) {
 		return nil // Not a supplement we track — not a gap, just irrelevant
 	}
 
@@ -150,9 +250,114 @@ func (a *Analyzer) AuditProduct(vendorName string, p models.Product) *AuditResul
 		result.Missing = append(result.Missing, "data was partially found but activeGrams still computed to 0 (check overrides)")
 	}
 
+	// Per-variant grams, where the variant title states its own count.
+	// Pack variants ("3 Bottles") scale the product mass on their own
+	variantGrams := map[string]float64{}
+	for _, v := range p.Variants {
+		if _, isPack := extractFloat(rePack, v.Title); !v.Available || isPack {
+			continue
+		}
+		variantGrams[v.Title] = 0
+		if c, ok := extractFloat(reCount, v.Title); ok && result.MgFound {
+			variantGrams[v.Title] = roundGrams(result.MgValue * c / 1000.0)
+		}
+	}
+
+	result.SuggestedOverride, result.UnknownKeys = suggestOverride(*result, variantGrams)
 	return result
 }
 
+// Impact tiers assigned by PrioritizeAudit, in the order they are listed.
+const (
+	ImpactHigh    = "high"
+	ImpactMedium  = "medium"
+	ImpactUnknown = "unknown"
+	ImpactLow     = "low"
+)
+
+// highImpactRank is the leaderboard position at or above which a missing
+// product is considered high impact.
+const highImpactRank = 10
+
+// contenderMargin is how far above the #1's $/g an estimate may be and still
+// contend for first place. The estimate is the best price over the suggested
+// grams: it ignores bioavailability, quality and which variant the override
+// would make best, so it is only good to about 10%.
+const contenderMargin = 1.10
+
+var impactOrder = map[string]int{ImpactHigh: 0, ImpactMedium: 1, ImpactUnknown: 2, ImpactLow: 3}
+
+// PrioritizeAudit estimates where each audited product would land among the
+// report entries for the same supplement if it were analyzable, tags it with
+// an impact tier and sorts results so the highest-impact gaps come first.
+// The estimate uses the suggested forceActiveGrams and the best price;
+// products without a mass estimate are tagged unknown and listed after
+// medium-impact ones. A gap whose estimate is within contenderMargin of the
+// supplement's #1 entry above the fold is marked Contender.
+func (a *Analyzer) PrioritizeAudit(results []AuditResult, report []models.Analysis) {
+	for i := range results {
+		r := &results[i]
+		r.Impact = ImpactUnknown
+		r.EstimatedCostPerGram = 0
+		r.EstimatedRank = 0
+		r.Leader, r.LeaderCostPerGram, r.Contender = "", 0, false
+		grams := r.SuggestedOverride.ForceActiveGrams
+		if grams <= 0 || r.BestPrice <= 0 {
+			continue
+		}
+		r.EstimatedCostPerGram = r.BestPrice / grams
+
+		// Rank against peers of the same supplement: $/g is not comparable
+		// across supplements (creatine is orders of magnitude cheaper than NMN).
+		supplement, tracked := a.supplementsFor(r.Vendor).Match(strings.ToLower(r.Title + " " + r.Handle))
+		peers := 0
+		r.EstimatedRank = 1
+		var leader *models.Analysis
+		for j, entry := range report {
+			if tracked && !containsAny(strings.ToLower(entry.Name+" "+entry.Handle), supplement.Keywords()) {
+				continue
+			}
+			peers++
+			if entry.EffectiveCost < r.EstimatedCostPerGram {
+				r.EstimatedRank++
+			}
+			if !BelowFold(entry) && (leader == nil || RankedBefore(entry, *leader)) {
+				leader = &report[j]
+			}
+		}
+		if leader != nil {
+			r.Leader = fmt.Sprintf("%s (%s)", leader.Name, leader.Vendor)
+			r.LeaderCostPerGram = leader.EffectiveCost
+			r.Contender = r.EstimatedCostPerGram <= leader.EffectiveCost*contenderMargin
+		}
+		switch {
+		case r.EstimatedRank <= highImpactRank:
+			r.Impact = ImpactHigh
+		case r.EstimatedRank <= peers/2:
+			r.Impact = ImpactMedium
+		default:
+			r.Impact = ImpactLow
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		ri, rj := results[i], results[j]
+		if impactOrder[ri.Impact] != impactOrder[rj.Impact] {
+			return impactOrder[ri.Impact] < impactOrder[rj.Impact]
+		}
+		if ri.EstimatedRank != rj.EstimatedRank {
+			return ri.EstimatedRank < rj.EstimatedRank
+		}
+		if ri.BestPrice != rj.BestPrice {
+			return ri.BestPrice < rj.BestPrice
+		}
+		if ri.Vendor != rj.Vendor {
+			return ri.Vendor < rj.Vendor
+		}
+		return ri.Handle < rj.Handle
+	})
+}
+
 // FormatAuditReport produces a human-readable multi-line string from a slice
 // of AuditResults, suitable for printing to stdout.
 func FormatAuditReport(results []AuditResult) string {
@@ -164,79 +369,61 @@ func FormatAuditReport(results []AuditResult) string {
 	b.WriteString(fmt.Sprintf("\n🔍 AUDIT: %d product(s) need manual overrides in data/vendor_rules.json\n", len(results)))
 	b.WriteString(strings.Repeat("─", 80) + "\n")
 
-	// Group by vendor, preserving insertion order
-	grouped := make(map[string][]AuditResult)
-	var vendorOrder []string
-	for _, r := range results {
-		if _, seen := grouped[r.Vendor]; !seen {
-			vendorOrder = append(vendorOrder, r.Vendor)
-		}
-		grouped[r.Vendor] = append(grouped[r.Vendor], r)
-	}
-
-	for _, vendor := range vendorOrder {
-		items := grouped[vendor]
-		b.WriteString(fmt.Sprintf("\n📦 %s (%d item(s))\n", vendor, len(items)))
-		for _, r := range items {
-			b.WriteString(fmt.Sprintf("  ├─ Product: %s\n", r.Title))
-			b.WriteString(fmt.Sprintf("  │  Handle:  %s\n", r.Handle))
-			if r.VariantCt > 0 {
-				b.WriteString(fmt.Sprintf("  │  Variants: %d available, best price: $%.2f\n", r.VariantCt, r.BestPrice))
-			} else {
-				b.WriteString("  │  Variants: none available\n")
-			}
+	// Results arrive in priority order (see PrioritizeAudit), so the
+	// overrides that would move the leaderboard are listed first.
+	for i, r := range results {
+		tag := ""
+		if r.Impact != "" {
+			tag = "[" + strings.ToUpper(r.Impact) + "] "
+		}
+		b.WriteString(fmt.Sprintf("\n#%d %s📦 %s\n", i+1, tag, r.Vendor))
+		b.WriteString(fmt.Sprintf("  ├─ Product: %s\n", r.Title))
+		b.WriteString(fmt.Sprintf("  │  Handle:  %s\n", r.Handle))
+		if r.EstimatedRank > 0 {
+			b.WriteString(fmt.Sprintf("  │  Impact:  ~$%.2f/g if analyzable, would rank #%d\n", r.EstimatedCostPerGram, r.EstimatedRank))
+		}
+		if r.Contender {
+			b.WriteString(fmt.Sprintf("  │  🚨 Could beat #1: %s at $%.2f/g\n", r.Leader, r.LeaderCostPerGram))
+		}
+		if r.VariantCt > 0 {
+			b.WriteString(fmt.Sprintf("  │  Variants: %d available, best price: $%.2f\n", r.VariantCt, r.BestPrice))
+		} else {
+			b.WriteString("  │  Variants: none available\n")
+		}
 
-			// Show what we DO have
-			var found []string
-			if r.MgFound {
-				found = append(found, fmt.Sprintf("mg=%.0f", r.MgValue))
-			}
-			if r.CountFound {
-				found = append(found, fmt.Sprintf("count=%.0f", r.CountValue))
-			}
-			if r.GramsFound {
-				found = append(found, fmt.Sprintf("grams=%.1f", r.GramsValue))
-			}
-			if r.KgFound {
-				found = append(found, fmt.Sprintf("kg=%.2f", r.KgValue))
-			}
-			if len(found) > 0 {
-				b.WriteString(fmt.Sprintf("  │  Found:   %s\n", strings.Join(found, ", ")))
-			} else {
-				b.WriteString("  │  Found:   (nothing extractable)\n")
-			}
+		// Show what we DO have
+		var found []string
+		if r.MgFound {
+			found = append(found, fmt.Sprintf("mg=%.0f", r.MgValue))
+		}
+		if r.CountFound {
+			found = append(found, fmt.Sprintf("count=%.0f", r.CountValue))
+		}
+		if r.GramsFound {
+			found = append(found, fmt.Sprintf("grams=%.1f", r.GramsValue))
+		}
+		if r.KgFound {
+			found = append(found, fmt.Sprintf("kg=%.2f", r.KgValue))
+		}
+		if len(found) > 0 {
+			b.WriteString(fmt.Sprintf("  │  Found:   %s\n", strings.Join(found, ", ")))
+		} else {
+			b.WriteString("  │  Found:   (nothing extractable)\n")
+		}
 
-			// Show what's MISSING
-			b.WriteString(fmt.Sprintf("  │  Missing: %s\n", strings.Join(r.Missing, "; ")))
-
-			// Suggest override snippet
-			b.WriteString("  │  Suggested override:\n")
-			b.WriteString(fmt.Sprintf("  │    \"%s\": {\n", r.Handle))
-			b.WriteString("  │      \"forceType\": \"Capsules\",\n")
-
-			switch {
-			case r.MgFound && r.CountFound:
-				activeGrams := r.MgValue * r.CountValue / 1000.0
-				b.WriteString(fmt.Sprintf("  │      \"forceActiveGrams\": %.1f,\n", activeGrams))
-				b.WriteString(fmt.Sprintf("  │      \"forceServingMg\": %.0f\n", r.MgValue))
-			case r.GramsFound:
-				b.WriteString(fmt.Sprintf("  │      \"forceActiveGrams\": %.1f,\n", r.GramsValue))
-				b.WriteString("  │      \"forceServingMg\": ???\n")
-			case r.KgFound:
-				b.WriteString(fmt.Sprintf("  │      \"forceActiveGrams\": %.1f,\n", r.KgValue*1000))
-				b.WriteString("  │      \"forceServingMg\": ???\n")
-			default:
-				b.WriteString("  │      \"forceActiveGrams\": ???,\n")
-				if r.MgFound {
-					b.WriteString(fmt.Sprintf("  │      \"forceServingMg\": %.0f\n", r.MgValue))
-				} else {
-					b.WriteString("  │      \"forceServingMg\": ???\n")
-				}
-			}
-			b.WriteString("  │    }\n")
-			b.WriteString("  │\n")
+		// Show what's MISSING
+		b.WriteString(fmt.Sprintf("  │  Missing: %s\n", strings.Join(r.Missing, "; ")))
+
+		// Suggest override snippet
+		b.WriteString("  │  Suggested override:\n")
+		for _, line := range strings.Split(OverrideSnippet(r.Handle, r.SuggestedOverride), "\n") {
+			b.WriteString("  │    " + line + "\n")
 		}
+		if len(r.UnknownKeys) > 0 {
+			b.WriteString(fmt.Sprintf("  │  Not inferred, add by hand: %s\n", strings.Join(r.UnknownKeys, ", ")))
+		}
+		b.WriteString("  │\n")
 	}
 	b.WriteString(strings.Repeat("─", 80) + "\n")
 	return b.String()
-}
\ No newline at end of file
+}
@@ -0,0 +1,121 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"longevity-ranker/internal/models"
+)
+
+// Resolution values for a ResolvedGap.
+const (
+	ResolvedByOverride = "override"   // An override for the handle now exists in vendor_rules.json
+	ResolvedByParser   = "parsed"     // The analyzer now extracts the data without an override
+	ResolvedDelisted   = "not_listed" // The product no longer appears in the filtered vendor data
+)
+
+// ResolvedGap is an audit gap from the previous run that is absent from the
+// current one, with the reason it went away.
+type ResolvedGap struct {
+	Vendor     string `json:"vendor"`
+	Title      string `json:"title"`
+	Handle     string `json:"handle"`
+	Resolution string `json:"resolution"`
+}
+
+// AuditDiff compares the gaps of two audit runs, keyed by vendor and handle.
+type AuditDiff struct {
+	New        []AuditResult
+	Persisting []AuditResult
+	Resolved   []ResolvedGap
+}
+
+func auditKey(vendorName, handle string) string {
+	return vendorName + "|" + handle
+}
+
+// DiffAudit classifies current gaps as new or persisting relative to previous,
+// and attributes every previous gap that disappeared: to an override when one
+// now exists for the handle, to the parser when the product is in report
+// without one, and otherwise to the product no longer being listed.
+func (a *Analyzer) DiffAudit(previous, current []AuditResult, report []models.Analysis) AuditDiff {
+	var d AuditDiff
+
+	prevKeys := make(map[string]bool, len(previous))
+	for _, r := range previous {
+		prevKeys[auditKey(r.Vendor, r.Handle)] = true
+	}
+	currKeys := make(map[string]bool, len(current))
+	for _, r := range current {
+		key := auditKey(r.Vendor, r.Handle)
+		currKeys[key] = true
+		if prevKeys[key] {
+			d.Persisting = append(d.Persisting, r)
+		} else {
+			d.New = append(d.New, r)
+		}
+	}
+
+	analyzed := make(map[string]bool, len(report))
+	for _, entry := range report {
+		analyzed[auditKey(entry.Vendor, entry.Handle)] = true
+	}
+	for _, r := range previous {
+		key := auditKey(r.Vendor, r.Handle)
+		if currKeys[key] {
+			continue
+		}
+		resolution := ResolvedDelisted
+		if _, _, hasOverride := a.vendorConfig(r.Vendor, r.Handle); hasOverride {
+			resolution = ResolvedByOverride
+		} else if analyzed[key] {
+			resolution = ResolvedByParser
+		}
+		d.Resolved = append(d.Resolved, ResolvedGap{Vendor: r.Vendor, Title: r.Title, Handle: r.Handle, Resolution: resolution})
+	}
+	return d
+}
+
+// NewContenders returns the current gaps marked Contender that were not
+// contenders in the previous run, so each one is alerted once rather than
+// on every run until its override is written. With no previous run, every
+// contender is new.
+func NewContenders(previous, current []AuditResult) []AuditResult {
+	alerted := make(map[string]bool, len(previous))
+	for _, r := range previous {
+		if r.Contender {
+			alerted[auditKey(r.Vendor, r.Handle)] = true
+		}
+	}
+	var contenders []AuditResult
+	for _, r := range current {
+		if r.Contender && !alerted[auditKey(r.Vendor, r.Handle)] {
+			contenders = append(contenders, r)
+		}
+	}
+	return contenders
+}
+
+// FormatAuditDiff renders the run-over-run progress summary printed after the
+// audit report.
+func FormatAuditDiff(d AuditDiff) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("\n📈 AUDIT PROGRESS: %d new, %d persisting, %d resolved since last run\n",
+		len(d.New), len(d.Persisting), len(d.Resolved)))
+	for _, r := range d.New {
+		b.WriteString(fmt.Sprintf("  + %s / %s\n", r.Vendor, r.Handle))
+	}
+	for _, r := range d.Resolved {
+		var reason string
+		switch r.Resolution {
+		case ResolvedByOverride:
+			reason = fmt.Sprintf("resolved by override %q", r.Handle)
+		case ResolvedByParser:
+			reason = "now parsed without an override"
+		default:
+			reason = "no longer listed"
+		}
+		b.WriteString(fmt.Sprintf("  - %s / %s: %s\n", r.Vendor, r.Handle, reason))
+	}
+	return b.String()
+}
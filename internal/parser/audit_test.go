@@ -0,0 +1,165 @@
+package parser
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"longevity-ranker/internal/models"
+	"longevity-ranker/internal/rules"
+)
+
+func TestPrioritizeAudit(t *testing.T) {
+	a := &Analyzer{Supplements: tracked("nmn", "creatine")}
+	grams := func(g float64) rules.ProductSpec { return rules.ProductSpec{ForceActiveGrams: g} }
+
+	// 40 NMN entries at $1..$40/g and cheap creatine that must not count as peers.
+	var report []models.Analysis
+	for i := 1; i <= 40; i++ {
+		report = append(report, models.Analysis{Name: "NMN Capsules", EffectiveCost: float64(i)})
+	}
+	for i := 0; i < 50; i++ {
+		report = append(report, models.Analysis{Name: "Creatine Monohydrate", EffectiveCost: 0.05})
+	}
+
+	results := []AuditResult{
+		{Title: "NMN Expensive", BestPrice: 345, SuggestedOverride: grams(10)},
+		{Title: "NMN No Count", BestPrice: 30},
+		{Title: "NMN Mid", BestPrice: 125, SuggestedOverride: grams(10)},
+		{Title: "NMN Cheap", BestPrice: 25, SuggestedOverride: grams(10)},
+	}
+	a.PrioritizeAudit(results, report)
+
+	want := []struct {
+		title  string
+		impact string
+		rank   int
+	}{
+		{"NMN Cheap", ImpactHigh, 3},
+		{"NMN Mid", ImpactMedium, 13},
+		{"NMN No Count", ImpactUnknown, 0},
+		{"NMN Expensive", ImpactLow, 35},
+	}
+	for i, w := range want {
+		r := results[i]
+		if r.Title != w.title || r.Impact != w.impact || r.EstimatedRank != w.rank {
+			t.Errorf("results[%d] = %s (%s, rank %d), want %s (%s, rank %d)",
+				i, r.Title, r.Impact, r.EstimatedRank, w.title, w.impact, w.rank)
+		}
+	}
+}
+
+func TestAuditSuggestedOverride(t *testing.T) {
+	a := &Analyzer{Supplements: tracked("nmn")}
+	gap := a.AuditProduct("Vendor", models.Product{
+		Handle: "nmn-supply",
+		Title:  "NMN 500mg",
+		Variants: []models.Variant{
+			{Price: "40.00", Title: "1 Month Supply", Available: true},
+			{Price: "100.00", Title: "3 Month Supply", Available: true},
+		},
+	})
+	if gap == nil {
+		t.Fatal("want an audit gap")
+	}
+	want := rules.ProductSpec{
+		ForceType:        "Capsules",
+		ForceServingMg:   500,
+		VariantOverrides: map[string]float64{"1 Month Supply": 0, "3 Month Supply": 0},
+	}
+	if !reflect.DeepEqual(gap.SuggestedOverride, want) {
+		t.Errorf("SuggestedOverride = %+v, want %+v", gap.SuggestedOverride, want)
+	}
+	if want := []string{"forceActiveGrams", "variantOverrides"}; !reflect.DeepEqual(gap.UnknownKeys, want) {
+		t.Errorf("UnknownKeys = %v, want %v", gap.UnknownKeys, want)
+	}
+
+	// The snippet pastes into an "overrides" object as a valid ProductSpec
+	dec := json.NewDecoder(strings.NewReader("{" + OverrideSnippet(gap.Handle, gap.SuggestedOverride) + "}"))
+	dec.DisallowUnknownFields()
+	var pasted map[string]rules.ProductSpec
+	if err := dec.Decode(&pasted); err != nil || !reflect.DeepEqual(pasted["nmn-supply"], want) {
+		t.Errorf("pasted snippet = %+v (%v), want %+v", pasted, err, want)
+	}
+	if report := FormatAuditReport([]AuditResult{*gap}); strings.Contains(report, "???") {
+		t.Errorf("report has placeholder values:\n%s", report)
+	}
+}
+
+func TestDiffAudit(t *testing.T) {
+	a := &Analyzer{Rules: rules.Registry{
+		"Vendor": {Overrides: map[string]rules.ProductSpec{"fixed": {ForceActiveGrams: 30}}},
+	}}
+	previous := []AuditResult{
+		{Vendor: "Vendor", Handle: "fixed"},
+		{Vendor: "Vendor", Handle: "still-broken"},
+		{Vendor: "Vendor", Handle: "parser-improved"},
+		{Vendor: "Vendor", Handle: "delisted"},
+	}
+	current := []AuditResult{
+		{Vendor: "Vendor", Handle: "still-broken"},
+		{Vendor: "Other", Handle: "still-broken"},
+	}
+	report := []models.Analysis{
+		{Vendor: "Vendor", Handle: "fixed"},
+		{Vendor: "Vendor", Handle: "parser-improved"},
+	}
+
+	d := a.DiffAudit(previous, current, report)
+	if len(d.New) != 1 || d.New[0].Vendor != "Other" {
+		t.Errorf("New = %+v, want only Other/still-broken", d.New)
+	}
+	if len(d.Persisting) != 1 || d.Persisting[0].Vendor != "Vendor" {
+		t.Errorf("Persisting = %+v, want only Vendor/still-broken", d.Persisting)
+	}
+	want := []ResolvedGap{
+		{Vendor: "Vendor", Handle: "fixed", Resolution: ResolvedByOverride},
+		{Vendor: "Vendor", Handle: "parser-improved", Resolution: ResolvedByParser},
+		{Vendor: "Vendor", Handle: "delisted", Resolution: ResolvedDelisted},
+	}
+	if !reflect.DeepEqual(d.Resolved, want) {
+		t.Errorf("Resolved = %+v, want %+v", d.Resolved, want)
+	}
+}
+
+func TestAuditContenders(t *testing.T) {
+	a := &Analyzer{Supplements: tracked("nmn")}
+	grams := func(g float64) rules.ProductSpec { return rules.ProductSpec{ForceActiveGrams: g} }
+
+	// The flagged $0.10/g entry is below the fold and never the #1.
+	report := []models.Analysis{
+		{Name: "NMN Flagged", Vendor: "X", EffectiveCost: 0.10, Confidence: ConfidenceFlagged, NeedsReview: true},
+		{Name: "NMN Powder", Vendor: "Leader", EffectiveCost: 1.00, Confidence: ConfidenceRegex},
+		{Name: "NMN Capsules", Vendor: "Y", EffectiveCost: 2.00, Confidence: ConfidenceRegex},
+	}
+	results := []AuditResult{
+		{Vendor: "A", Handle: "cheaper", Title: "NMN Bulk", BestPrice: 9, SuggestedOverride: grams(10)},
+		{Vendor: "A", Handle: "close", Title: "NMN Jar", BestPrice: 10.5, SuggestedOverride: grams(10)},
+		{Vendor: "A", Handle: "behind", Title: "NMN Caps", BestPrice: 15, SuggestedOverride: grams(10)},
+		{Vendor: "A", Handle: "no-mass", Title: "NMN Mystery", BestPrice: 1},
+	}
+	a.PrioritizeAudit(results, report)
+
+	got := map[string]bool{}
+	for _, r := range results {
+		got[r.Handle] = r.Contender
+		if r.Contender && (r.Leader != "NMN Powder (Leader)" || r.LeaderCostPerGram != 1) {
+			t.Errorf("%s leader = %q at %v, want NMN Powder (Leader) at 1", r.Handle, r.Leader, r.LeaderCostPerGram)
+		}
+	}
+	want := map[string]bool{"cheaper": true, "close": true, "behind": false, "no-mass": false}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Contender = %v, want %v", got, want)
+	}
+
+	// A gap already contending last run is not alerted again.
+	previous := []AuditResult{{Vendor: "A", Handle: "cheaper", Contender: true}, {Vendor: "A", Handle: "close"}}
+	fresh := NewContenders(previous, results)
+	if len(fresh) != 1 || fresh[0].Handle != "close" {
+		t.Errorf("NewContenders() = %+v, want only close", fresh)
+	}
+	if all := NewContenders(nil, results); len(all) != 2 {
+		t.Errorf("NewContenders(no previous run) = %d gaps, want 2", len(all))
+	}
+}
@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"math"
 	"regexp"
 	"strconv"
 	"strings"
@@ -10,13 +11,14 @@ import (
 var rePriceFloat = regexp.MustCompile(`(\d+(?:\.\d+)?)`)
 
 // extractFloat returns the first captured group of re in s as a float64.
+// A decimal comma in the capture is read as a decimal point.
 // Returns (0, false) if there is no match or the value is <= 0.
 func extractFloat(re *regexp.Regexp, s string) (float64, bool) {
 	m := re.FindStringSubmatch(s)
 	if len(m) < 2 {
 		return 0, false
 	}
-	v, err := strconv.ParseFloat(m[1], 64)
+	v, err := strconv.ParseFloat(strings.Replace(m[1], ",", ".", 1), 64)
 	if err != nil || v <= 0 {
 		return 0, false
 	}
@@ -35,6 +37,16 @@ func extractFloatFrom(re *regexp.Regexp, sources ...string) (float64, bool) {
 	return 0, false
 }
 
+// finiteOrZero returns v, or 0 when v is ±Inf or NaN. Products of individually
+// valid captures (mg × count, grams × pack) can overflow float64 on adversarial
+// vendor text; 0 routes the product to the audit queue instead of the ranking.
+func finiteOrZero(v float64) float64 {
+	if math.IsInf(v, 0) || math.IsNaN(v) {
+		return 0
+	}
+	return v
+}
+
 // containsAny reports whether s contains any of the given substrings.
 func containsAny(s string, substrs []string) bool {
 	for _, sub := range substrs {
@@ -43,4 +55,4 @@ func containsAny(s string, substrs []string) bool {
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
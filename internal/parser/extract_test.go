@@ -0,0 +1,32 @@
+package parser
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestMultilingualUnits(t *testing.T) {
+	cases := []struct {
+		re   *regexp.Regexp
+		text string
+		want float64
+	}{
+		{reCount, "NMN 500 mg – 60 Kapseln", 60},
+		{reCount, "NMN 250 mg, 90 gélules végétales", 90},
+		{reCount, "Resvératrol 120 comprimés", 120},
+		{reCount, "NMN Pulver 30 Stück", 30},
+		{reCount, "NMN 60 cápsulas", 60},
+		{reCount, "Trans-Resveratrol 100 Tabletten", 100},
+		{reGrams, "Créatine monohydrate 500 grammes", 500},
+		{reGrams, "Kreatin Pulver 250 Gramm", 250},
+		{reLabelGrams, "Creatina 300 gramos", 300},
+		{reKg, "Kreatin 1,5 kg", 1.5},
+		{reLabelKg, "Creatine 2.5 kg", 2.5},
+	}
+	for _, tc := range cases {
+		got, ok := extractFloat(tc.re, tc.text)
+		if !ok || got != tc.want {
+			t.Errorf("extractFloat(%s, %q) = %v, %v; want %v", tc.re, tc.text, got, ok, tc.want)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"longevity-ranker/internal/models"
+)
+
+// Candidate is one extraction source's reading of a variant's active mass,
+// for one unit of the variant (the pack multiplier still applies). Exactly
+// one of CapsuleMass and PowderMass is normally set.
+type Candidate struct {
+	CapsuleMass float64 // Active grams in unit doses (capsules, tablets, liquid)
+	PowderMass  float64 // Active grams of loose powder
+	UnitMg      float64 // Active mg per capsule/tablet; 0 when unknown
+	ServingMg   float64 // Powder per scoop or serving; 0 when unknown
+
+	// How far the source trusts the reading, 0–1, on the entry Confidence
+	// scale: above ConfidenceRegex beats the built-in regex engine,
+	// anything lower only fills in when the regexes find no mass, and
+	// below ConfidenceCaution ranks the entry below the fold.
+	Confidence float64
+}
+
+func (c Candidate) mass() float64 {
+	return c.CapsuleMass + c.PowderMass
+}
+
+// Extractor is an extra source of active mass next to the analyzer's regex
+// engine: structured metafields, label OCR, a supplement label database.
+// Extract returns its readings of the variant, or nil when it has none; it
+// must not modify p. Extractors live in their own files and register
+// themselves from an init function:
+//
+//	func init() { parser.RegisterExtractor("dsld", dsldExtractor{}) }
+//
+// Vendor overrides still win over every extractor.
+type Extractor interface {
+	Extract(p models.Product, v models.Variant) []Candidate
+}
+
+// ExtractorFunc adapts a plain function to Extractor.
+type ExtractorFunc func(p models.Product, v models.Variant) []Candidate
+
+// Extract calls f(p, v).
+func (f ExtractorFunc) Extract(p models.Product, v models.Variant) []Candidate { return f(p, v) }
+
+// extractors maps extractor names to their implementation.
+var (
+	extractorsMu sync.RWMutex
+	extractors   = map[string]Extractor{}
+)
+
+// RegisterExtractor makes e available to every Analyzer under name. It
+// panics when name is empty or already taken, like a duplicate init.
+func RegisterExtractor(name string, e Extractor) {
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+	if name == "" || e == nil {
+		panic("parser: RegisterExtractor needs a name and an extractor")
+	}
+	if _, dup := extractors[name]; dup {
+		panic(fmt.Sprintf("parser: extractor %q registered twice", name))
+	}
+	extractors[name] = e
+}
+
+// ExtractorNames returns the registered extractor names, sorted.
+func ExtractorNames() []string {
+	extractorsMu.RLock()
+	defer extractorsMu.RUnlock()
+	names := make([]string, 0, len(extractors))
+	for name := range extractors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// bestCandidate asks every registered extractor, in name order, about the
+// variant and returns the most confident reading with a mass (the earliest
+// on ties), with its Confidence capped at ConfidenceOverride, and the name
+// of its extractor. When the regex engine already found a mass
+// (regexFound), only a reading more confident than ConfidenceRegex is
+// returned.
+func bestCandidate(p models.Product, v models.Variant, regexFound bool) (Candidate, string, bool) {
+	var best Candidate
+	var bestName string
+	found := false
+	for _, name := range ExtractorNames() {
+		extractorsMu.RLock()
+		e := extractors[name]
+		extractorsMu.RUnlock()
+		for _, c := range e.Extract(p, v) {
+			c.CapsuleMass, c.PowderMass = finiteOrZero(c.CapsuleMass), finiteOrZero(c.PowderMass)
+			if c.CapsuleMass < 0 || c.PowderMass < 0 || c.mass() <= 0 || c.Confidence <= 0 {
+				continue
+			}
+			c.Confidence = min(c.Confidence, ConfidenceOverride)
+			if !found || c.Confidence > best.Confidence {
+				best, bestName, found = c, name, true
+			}
+		}
+	}
+	if !found || (regexFound && best.Confidence <= ConfidenceRegex) {
+		return Candidate{}, "", false
+	}
+	return best, bestName, true
+}
@@ -0,0 +1,71 @@
+package parser
+
+import (
+	"testing"
+
+	"longevity-ranker/internal/models"
+)
+
+// registerTestExtractor registers e for the duration of the test.
+func registerTestExtractor(t *testing.T, name string, e Extractor) {
+	t.Helper()
+	RegisterExtractor(name, e)
+	t.Cleanup(func() {
+		extractorsMu.Lock()
+		delete(extractors, name)
+		extractorsMu.Unlock()
+	})
+}
+
+func TestExtractors(t *testing.T) {
+	a := &Analyzer{Supplements: tracked("nmn")}
+	product := func(title string) models.Product {
+		return models.Product{
+			Handle:   "nmn",
+			Title:    title,
+			Variants: []models.Variant{{Price: "60.00", Title: "Default Title", Available: true}},
+		}
+	}
+	// A metafield-like source: sure of itself, but only for "NMN Capsules"
+	registerTestExtractor(t, "test-metafields", ExtractorFunc(func(p models.Product, v models.Variant) []Candidate {
+		if p.Title != "NMN Capsules" {
+			return nil
+		}
+		return []Candidate{
+			{CapsuleMass: 30, UnitMg: 500, Confidence: 0.4},
+			{CapsuleMass: 15, UnitMg: 250, Confidence: 0.9},
+		}
+	}))
+	// An OCR-like source: a low-confidence guess for everything
+	registerTestExtractor(t, "test-ocr", ExtractorFunc(func(p models.Product, v models.Variant) []Candidate {
+		return []Candidate{{PowderMass: 10, Confidence: 0.6}, {PowderMass: -5, Confidence: 1}}
+	}))
+
+	// No mass in the text: the most confident reading fills in
+	got := a.AnalyzeProduct("Vendor", product("NMN Capsules"))
+	if len(got) != 1 || got[0].ActiveGrams != 15 || got[0].UnitMg != 250 || got[0].Confidence != 0.9 {
+		t.Errorf("no regex mass: got %+v, want 15 g at 250 mg/cap with confidence 0.9", got)
+	}
+
+	// The regexes found 50 g: a 0.6 guess does not replace them
+	got = a.AnalyzeProduct("Vendor", product("NMN Powder 50g"))
+	if len(got) != 1 || got[0].ActiveGrams != 50 || got[0].Confidence != ConfidenceRegex {
+		t.Errorf("regex mass: got %+v, want the regex's 50 g at ConfidenceRegex", got)
+	}
+
+	// Below ConfidenceRegex, a filled-in reading keeps its own confidence
+	got = a.AnalyzeProduct("Vendor", product("NMN Powder"))
+	if len(got) != 1 || got[0].ActiveGrams != 10 || got[0].Confidence != 0.6 || BelowFold(got[0]) {
+		t.Errorf("guess: got %+v, want 10 g at confidence 0.6, above the fold", got)
+	}
+}
+
+func TestRegisterExtractorTwice(t *testing.T) {
+	registerTestExtractor(t, "test-twice", ExtractorFunc(func(models.Product, models.Variant) []Candidate { return nil }))
+	defer func() {
+		if recover() == nil {
+			t.Error("RegisterExtractor(taken name) did not panic")
+		}
+	}()
+	RegisterExtractor("test-twice", ExtractorFunc(func(models.Product, models.Variant) []Candidate { return nil }))
+}
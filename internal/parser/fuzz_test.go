@@ -0,0 +1,114 @@
+package parser
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"testing"
+
+	"longevity-ranker/internal/rules"
+)
+
+// extractionRegexes are every pattern fed into extractFloat by the analyzer
+// and the audit probes.
+var extractionRegexes = []*regexp.Regexp{
+	reMg, reCount, reGrams, reKg, rePack, reServing, reLabelGrams, reLabelKg, rePriceFloat,
+	reConcentration, reMl, reFlOz,
+}
+
+// overflowText has mg and count values that each fit a float64 but whose
+// product does not.
+var overflowText = "1" + strings.Repeat("0", 200) + " mg 1" + strings.Repeat("0", 200) + " capsules"
+
+// seedTexts are real-world shaped vendor strings used to seed every fuzz target.
+var seedTexts = []string{
+	"NMN Pro 300™ - Uthever® NMN - 300 mg, 30 capsules",
+	"Creatine Monohydrate Powder (Fruit Punch / 500 GMS)",
+	"1 KG",
+	"2 capsules per serving, 500mg, 60 caps",
+	"Unflavored / 30 SERV",
+	"60 Capsules - 3 Pack",
+	"12 Bottles",
+	"<p>Each serving (2 capsules) contains 1000 mg NMN</p>",
+	"NMN 500 mg – 60 Kapseln",
+	"Créatine 1,5 kg (1500 grammes)",
+	"99999999999999999999999999999999999999 mg 99999999999999999999 caps",
+	overflowText,
+	"",
+}
+
+func assertSaneValue(t *testing.T, label string, v float64, ok bool) {
+	t.Helper()
+	if !ok {
+		if v != 0 {
+			t.Fatalf("%s: returned %v with ok=false", label, v)
+		}
+		return
+	}
+	if v <= 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+		t.Fatalf("%s: returned invalid value %v with ok=true", label, v)
+	}
+}
+
+func assertSaneMass(t *testing.T, label string, v float64) {
+	t.Helper()
+	if v < 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+		t.Fatalf("%s: invalid mass %v", label, v)
+	}
+}
+
+func FuzzExtractFloat(f *testing.F) {
+	for _, s := range seedTexts {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		for _, re := range extractionRegexes {
+			v, ok := extractFloat(re, s)
+			assertSaneValue(t, re.String(), v, ok)
+		}
+	})
+}
+
+// FuzzExtractCount exercises the capsule-count fallback chain
+// (variant title → clean title → broad search) used by extractMass.
+func FuzzExtractCount(f *testing.F) {
+	for _, s := range seedTexts {
+		f.Add(s, s, s)
+	}
+	f.Fuzz(func(t *testing.T, variant, clean, broad string) {
+		v, ok := extractFloatFrom(reCount, variant, clean, broad)
+		assertSaneValue(t, "count", v, ok)
+		if ok {
+			if want, wantOk := extractFloat(reCount, variant); wantOk && want != v {
+				t.Fatalf("count: variant title match %v not preferred, got %v", want, v)
+			}
+		}
+	})
+}
+
+func FuzzExtractMass(f *testing.F) {
+	for _, s := range seedTexts {
+		f.Add("NMN "+s, s, s+" 2 capsules per serving")
+	}
+	f.Add("NMN", overflowText, "")
+	a := &Analyzer{}
+	f.Fuzz(func(t *testing.T, productTitle, variantTitle, body string) {
+		cleanSearch := productTitle + " " + variantTitle
+		broadSearch := cleanSearch + " " + body
+
+		scoopMg, scooplessSearch := extractScoop(broadSearch)
+		assertSaneMass(t, "scoopMg", scoopMg)
+		capsuleMass, powderMass, _, usedOverride, _ := a.extractMass(rules.ProductSpec{}, false, variantTitle, cleanSearch, scooplessSearch, variantTitle, scoopMg)
+		assertSaneMass(t, "capsuleMass", capsuleMass)
+		assertSaneMass(t, "powderMass", powderMass)
+		if usedOverride {
+			t.Fatal("usedOverride reported without an override")
+		}
+		if capsuleMass > 0 && powderMass > 0 {
+			t.Fatalf("regex path returned both capsule (%v) and powder (%v) mass", capsuleMass, powderMass)
+		}
+
+		gross := a.extractGrossGrams(rules.ProductSpec{}, false, variantTitle, productTitle, false, 1)
+		assertSaneMass(t, "grossGrams", gross)
+	})
+}
@@ -0,0 +1,80 @@
+package parser
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"longevity-ranker/internal/models"
+	"longevity-ranker/internal/rules"
+	"longevity-ranker/internal/taxonomy"
+)
+
+var update = flag.Bool("update", false, "Rewrite golden expected outputs with the current analyzer output")
+
+// goldenCase is one fixture in testdata/golden/. New cases are generated from
+// cached vendor data by `go run ./cmd/golden -vendor <name> -handle <handle>`.
+type goldenCase struct {
+	Vendor      string              `json:"vendor"`
+	Supplements []string            `json:"supplements"`
+	Rules       *rules.VendorConfig `json:"rules,omitempty"`
+	Product     models.Product      `json:"product"`
+	Expected    []models.Analysis   `json:"expected"`
+}
+
+func (gc goldenCase) analyzer() *Analyzer {
+	a := &Analyzer{Supplements: taxonomy.Defaults().Select(gc.Supplements)}
+	if gc.Rules != nil {
+		a.Rules = rules.Registry{gc.Vendor: *gc.Rules}
+	}
+	return a
+}
+
+func TestGolden(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "golden", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no golden cases found in testdata/golden")
+	}
+
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".json")
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var gc goldenCase
+			if err := json.Unmarshal(data, &gc); err != nil {
+				t.Fatalf("parsing %s: %v", path, err)
+			}
+
+			got := gc.analyzer().AnalyzeProduct(gc.Vendor, gc.Product)
+
+			if *update {
+				gc.Expected = got
+				out, err := json.MarshalIndent(gc, "", "  ")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, out, 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			if !reflect.DeepEqual(got, gc.Expected) {
+				gotJSON, _ := json.MarshalIndent(got, "", "  ")
+				wantJSON, _ := json.MarshalIndent(gc.Expected, "", "  ")
+				t.Errorf("analysis mismatch for %s/%s\n--- got ---\n%s\n--- want ---\n%s",
+					gc.Vendor, gc.Product.Handle, gotJSON, wantJSON)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"longevity-ranker/internal/models"
+)
+
+// overrideProductQuality is the quality credited to a product that only
+// analyzes thanks to a manual override: the data is right, but the scraper and
+// parser needed help to get there.
+const overrideProductQuality = 0.5
+
+// VendorQuality summarizes how well the scraper and parser handle one vendor's
+// tracked catalog. Low scores show where scraper/parser effort should go next.
+type VendorQuality struct {
+	Vendor           string  `json:"vendor"`
+	TrackedProducts  int     `json:"tracked_products"`  // Products passing the supplement keyword gate
+	OverrideProducts int     `json:"override_products"` // Tracked products with a vendor_rules.json override
+	FailedProducts   int     `json:"failed_products"`   // Tracked products yielding no analysis
+	HighConfidence   int     `json:"high_confidence"`   // Entries with Confidence >= ConfidenceOverride
+	MediumConfidence int     `json:"medium_confidence"` // Entries with Confidence >= ConfidenceRegex
+	LowConfidence    int     `json:"low_confidence"`    // Remaining entries
+	OverrideShare    float64 `json:"override_share"`
+	FailureRate      float64 `json:"failure_rate"`
+	Score            float64 `json:"score"` // 0–100
+
+	qualitySum float64
+}
+
+// QualityTracker accumulates VendorQuality across a run.
+type QualityTracker map[string]*VendorQuality
+
+// RecordQuality adds one product and its analyses (nil when the analyzer
+// rejected it) to the tracker. Products outside the supplement scope are
+// ignored — they are not the parser's job.
+func (a *Analyzer) RecordQuality(qt QualityTracker, vendorName string, p models.Product, analyses []models.Analysis) {
+	identity := strings.ToLower(p.Title + " " + p.Context + " " + p.Handle)
+	if !a.matchesSupplement(vendorName, identity) {
+		return
+	}
+
+	q, ok := qt[vendorName]
+	if !ok {
+		q = &VendorQuality{Vendor: vendorName}
+		qt[vendorName] = q
+	}
+	q.TrackedProducts++
+
+	_, _, hasOverride := a.vendorConfig(vendorName, p.Handle)
+	if hasOverride {
+		q.OverrideProducts++
+	}
+
+	if len(analyses) == 0 {
+		q.FailedProducts++
+		return
+	}
+
+	confidenceSum := 0.0
+	for _, entry := range analyses {
+		switch {
+		case entry.Confidence >= ConfidenceOverride:
+			q.HighConfidence++
+		case entry.Confidence >= ConfidenceRegex:
+			q.MediumConfidence++
+		default:
+			q.LowConfidence++
+		}
+		confidenceSum += entry.Confidence
+	}
+
+	productQuality := confidenceSum / float64(len(analyses))
+	if hasOverride && productQuality > overrideProductQuality {
+		productQuality = overrideProductQuality
+	}
+	q.qualitySum += productQuality
+}
+
+// Summarize computes rates and scores and returns vendors ordered worst
+// score first, so the vendor most in need of work leads the summary.
+//
+// Score = 100 × mean product quality, where a failed product counts 0, an
+// override-resolved product at most overrideProductQuality, and any other
+// product the mean Confidence of its entries.
+func (qt QualityTracker) Summarize() []VendorQuality {
+	summary := make([]VendorQuality, 0, len(qt))
+	for _, q := range qt {
+		s := *q
+		if s.TrackedProducts > 0 {
+			tracked := float64(s.TrackedProducts)
+			s.OverrideShare = float64(s.OverrideProducts) / tracked
+			s.FailureRate = float64(s.FailedProducts) / tracked
+			s.Score = 100 * s.qualitySum / tracked
+		}
+		summary = append(summary, s)
+	}
+	sort.Slice(summary, func(i, j int) bool {
+		if summary[i].Score != summary[j].Score {
+			return summary[i].Score < summary[j].Score
+		}
+		return summary[i].Vendor < summary[j].Vendor
+	})
+	return summary
+}
+
+// FormatQualitySummary renders the per-vendor quality table for the run summary.
+func FormatQualitySummary(summary []VendorQuality) string {
+	if len(summary) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\n📐 DATA QUALITY (worst first)\n")
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VENDOR\tSCORE\tTRACKED\tOVERRIDES\tFAILED\tCONFIDENCE (high/med/low)")
+	for _, q := range summary {
+		fmt.Fprintf(w, "%s\t%.0f\t%d\t%d (%.0f%%)\t%d (%.0f%%)\t%d/%d/%d\n",
+			q.Vendor, q.Score, q.TrackedProducts,
+			q.OverrideProducts, q.OverrideShare*100,
+			q.FailedProducts, q.FailureRate*100,
+			q.HighConfidence, q.MediumConfidence, q.LowConfidence)
+	}
+	w.Flush()
+	return b.String()
+}
@@ -0,0 +1,156 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"longevity-ranker/internal/models"
+	"longevity-ranker/internal/rules"
+)
+
+// OverrideMismatch describes an override whose stored expectations no longer
+// match live vendor data. Hardcoded physics go stale when a vendor reformulates
+// (500mg → 250mg caps) or reprices a product.
+type OverrideMismatch struct {
+	Vendor string
+	Handle string
+	Issue  string
+}
+
+// VerifyOverrides checks every override of vendorName that stores an
+// expectation — ForceServingMg or ExpectedPriceMin/ExpectedPriceMax — against
+// freshly scraped products. Prices are compared in the vendor's currency, as
+// scraped. Overrides without expectations are not checked. Results are
+// ordered by handle.
+func (a *Analyzer) VerifyOverrides(vendorName string, products []models.Product) []OverrideMismatch {
+	cfg, exists := a.Rules[vendorName]
+	if !exists {
+		return nil
+	}
+
+	byHandle := make(map[string][]models.Product)
+	for _, p := range products {
+		byHandle[p.Handle] = append(byHandle[p.Handle], p)
+	}
+
+	handles := make([]string, 0, len(cfg.Overrides))
+	for handle := range cfg.Overrides {
+		handles = append(handles, handle)
+	}
+	sort.Strings(handles)
+
+	currency := rules.Currency(a.Rules, vendorName)
+	var mismatches []OverrideMismatch
+	for _, handle := range handles {
+		spec := cfg.Overrides[handle]
+		hasPriceRange := spec.ExpectedPriceMin > 0 || spec.ExpectedPriceMax > 0
+		if spec.ForceServingMg <= 0 && !hasPriceRange {
+			continue
+		}
+
+		report := func(format string, args ...any) {
+			mismatches = append(mismatches, OverrideMismatch{Vendor: vendorName, Handle: handle, Issue: fmt.Sprintf(format, args...)})
+		}
+
+		live, found := byHandle[handle]
+		if !found {
+			report("handle not found in live data (delisted or renamed?)")
+			continue
+		}
+
+		if spec.ForceServingMg > 0 {
+			if found := liveMgValues(live); !containsFloat(found, spec.ForceServingMg) {
+				if len(found) == 0 {
+					report("expected %.0f mg per serving, live page states no mg value", spec.ForceServingMg)
+				} else {
+					report("expected %.0f mg per serving, live page states %s", spec.ForceServingMg, formatMg(found))
+				}
+			}
+		}
+
+		if hasPriceRange {
+			for _, p := range live {
+				for _, v := range p.Variants {
+					price, err := strconv.ParseFloat(v.Price, 64)
+					if err != nil || !v.Available {
+						continue
+					}
+					if (spec.ExpectedPriceMin > 0 && price < spec.ExpectedPriceMin) ||
+						(spec.ExpectedPriceMax > 0 && price > spec.ExpectedPriceMax) {
+						report("variant %q priced %s, outside expected %s–%s", v.Title, formatAmount(price, currency),
+							formatAmount(spec.ExpectedPriceMin, currency), formatAmount(spec.ExpectedPriceMax, currency))
+					}
+				}
+			}
+		}
+	}
+	return mismatches
+}
+
+// formatAmount prints a price in its currency: "$40.00" in the report
+// currency, "40.00 EUR" in any other.
+func formatAmount(amount float64, currency string) string {
+	if currency == rules.ReportCurrency {
+		return fmt.Sprintf("$%.2f", amount)
+	}
+	return fmt.Sprintf("%.2f %s", amount, currency)
+}
+
+// liveMgValues returns every distinct mg value stated in the products' label
+// and description text, in order of first appearance.
+func liveMgValues(products []models.Product) []float64 {
+	var values []float64
+	for _, p := range products {
+		text := p.Title + " " + p.Context + " " + p.BodyHTML
+		for _, v := range p.Variants {
+			text += " " + v.Title
+		}
+		for _, m := range reMg.FindAllStringSubmatch(text, -1) {
+			if mg, err := strconv.ParseFloat(m[1], 64); err == nil && mg > 0 && !containsFloat(values, mg) {
+				values = append(values, mg)
+			}
+		}
+	}
+	return values
+}
+
+func containsFloat(values []float64, target float64) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
+
+func formatMg(values []float64) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = fmt.Sprintf("%.0f mg", v)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// FormatVerifyReport renders override mismatches grouped by vendor.
+func FormatVerifyReport(mismatches []OverrideMismatch) string {
+	if len(mismatches) == 0 {
+		return "✅ All verifiable overrides match live data.\n"
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("\n🔬 VERIFY: %d override mismatch(es) against live data\n", len(mismatches)))
+	b.WriteString(strings.Repeat("─", 80) + "\n")
+	lastVendor := ""
+	for _, m := range mismatches {
+		if m.Vendor != lastVendor {
+			b.WriteString(fmt.Sprintf("\n📦 %s\n", m.Vendor))
+			lastVendor = m.Vendor
+		}
+		b.WriteString(fmt.Sprintf("  ├─ %s\n", m.Handle))
+		b.WriteString(fmt.Sprintf("  │  %s\n", m.Issue))
+	}
+	b.WriteString(strings.Repeat("─", 80) + "\n")
+	return b.String()
+}
@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"longevity-ranker/internal/models"
+	"longevity-ranker/internal/rules"
+)
+
+func TestVerifyOverrides(t *testing.T) {
+	a := &Analyzer{Rules: rules.Registry{
+		"Vendor": {Overrides: map[string]rules.ProductSpec{
+			"reformulated":   {ForceActiveGrams: 30, ForceServingMg: 500},
+			"repriced":       {ForceActiveGrams: 30, ExpectedPriceMin: 40, ExpectedPriceMax: 60},
+			"delisted":       {ForceServingMg: 250},
+			"unchanged":      {ForceServingMg: 500, ExpectedPriceMin: 40, ExpectedPriceMax: 60},
+			"no-expectation": {ForceActiveGrams: 15},
+		}},
+	}}
+
+	live := []models.Product{
+		{Handle: "reformulated", Title: "NMN 250mg", Variants: []models.Variant{{Price: "50.00", Title: "60 Capsules", Available: true}}},
+		{Handle: "repriced", Title: "NMN", Variants: []models.Variant{
+			{Price: "75.00", Title: "60 Capsules", Available: true},
+			{Price: "10.00", Title: "Sold out", Available: false},
+		}},
+		{Handle: "unchanged", Title: "NMN", BodyHTML: "<p>500mg per capsule</p>", Variants: []models.Variant{{Price: "50.00", Title: "60 Capsules", Available: true}}},
+	}
+
+	got := a.VerifyOverrides("Vendor", live)
+	want := []OverrideMismatch{
+		{Vendor: "Vendor", Handle: "delisted", Issue: "handle not found in live data (delisted or renamed?)"},
+		{Vendor: "Vendor", Handle: "reformulated", Issue: "expected 500 mg per serving, live page states 250 mg"},
+		{Vendor: "Vendor", Handle: "repriced", Issue: `variant "60 Capsules" priced $75.00, outside expected $40.00–$60.00`},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("VerifyOverrides() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestVerifyOverridesForeignCurrency(t *testing.T) {
+	a := &Analyzer{Rules: rules.Registry{
+		rules.GlobalKey: {ExchangeRates: map[string]float64{"EUR": 1.10}},
+		"EU Vendor": {Currency: "eur", Overrides: map[string]rules.ProductSpec{
+			"nmn": {ForceActiveGrams: 30, ExpectedPriceMin: 40, ExpectedPriceMax: 60},
+		}},
+	}}
+	live := []models.Product{{Handle: "nmn", Title: "NMN", Variants: []models.Variant{
+		{Price: "58.00", Title: "60 Capsules", Available: true}, // $63.80, but in range in euros
+		{Price: "65.00", Title: "90 Capsules", Available: true},
+	}}}
+
+	got := a.VerifyOverrides("EU Vendor", live)
+	want := []OverrideMismatch{
+		{Vendor: "EU Vendor", Handle: "nmn", Issue: `variant "90 Capsules" priced 65.00 EUR, outside expected 40.00 EUR–60.00 EUR`},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("VerifyOverrides() =\n%+v\nwant\n%+v", got, want)
+	}
+}
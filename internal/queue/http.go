@@ -0,0 +1,132 @@
+package queue
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ClaimWait is how long a claim request waits for a job before answering
+// 204, so an idle worker long-polls instead of hammering the coordinator.
+var ClaimWait = 25 * time.Second
+
+// Handler serves q to remote workers:
+//
+//	POST /queue/claim?worker=<name>  a Job as JSON, or 204 when none came within ClaimWait
+//	POST /queue/results              a Result as JSON; 204, or 409 for an unknown or finished job
+//
+// It does no authentication; the caller wraps it (cmd/main.go requires the
+// serve API token).
+func Handler(q Queue) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /queue/claim", func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), ClaimWait)
+		defer cancel()
+		job, ok, err := q.Claim(ctx, r.URL.Query().Get("worker"))
+		switch {
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		case !ok:
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(job)
+		}
+	})
+	mux.HandleFunc("POST /queue/results", func(w http.ResponseWriter, r *http.Request) {
+		var result Result
+		if err := json.NewDecoder(r.Body).Decode(&result); err != nil {
+			http.Error(w, "invalid result: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		err := q.Complete(result)
+		switch {
+		case errors.Is(err, ErrUnknownJob):
+			http.Error(w, err.Error(), http.StatusConflict)
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	return mux
+}
+
+// Client is a worker's side of Handler.
+type Client struct {
+	URL   string // The coordinator's base URL, e.g. http://coordinator:9090
+	Token string // Sent as "Authorization: Bearer <token>"
+
+	// HTTP sends the requests. Its timeout must exceed ClaimWait.
+	HTTP *http.Client
+}
+
+// NewClient returns a client of the coordinator at base.
+func NewClient(base, token string) *Client {
+	return &Client{URL: strings.TrimSuffix(base, "/"), Token: token, HTTP: &http.Client{Timeout: ClaimWait + 30*time.Second}}
+}
+
+// Claim asks the coordinator for a job; ok is false when none came within
+// ClaimWait.
+func (c *Client) Claim(ctx context.Context, worker string) (Job, bool, error) {
+	resp, err := c.post(ctx, "/queue/claim?worker="+url.QueryEscape(worker), nil)
+	if err != nil {
+		return Job{}, false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNoContent {
+		return Job{}, false, nil
+	}
+	var job Job
+	if err := json.NewDecoder(resp.Body).Decode(&job); err != nil {
+		return Job{}, false, fmt.Errorf("invalid job: %v", err)
+	}
+	return job, true, nil
+}
+
+// Complete sends a job's result. A 409 (another worker answered first, or
+// the run ended) is ErrUnknownJob.
+func (c *Client) Complete(ctx context.Context, r Result) error {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	resp, err := c.post(ctx, "/queue/results", body)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// post sends one request and turns error statuses into errors.
+func (c *Client) post(ctx context.Context, path string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 && resp.StatusCode != http.StatusNoContent {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		if resp.StatusCode == http.StatusConflict {
+			return nil, ErrUnknownJob
+		}
+		return nil, fmt.Errorf("coordinator returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return resp, nil
+}
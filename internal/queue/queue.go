@@ -0,0 +1,180 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"longevity-ranker/internal/models"
+	"longevity-ranker/internal/scraper"
+)
+
+// Job is one vendor for a worker to scrape live. DiscoverWords carries
+// Vendor.DiscoverWords, which the vendor's own JSON leaves out.
+type Job struct {
+	ID            string        `json:"id"`
+	Vendor        models.Vendor `json:"vendor"`
+	Handles       []string      `json:"handles,omitempty"` // Watched products: fetch only their pages when the vendor can
+	DiscoverWords []string      `json:"discoverWords,omitempty"`
+}
+
+// Result is a worker's answer to a Job.
+type Result struct {
+	JobID    string            `json:"job_id"`
+	Worker   string            `json:"worker"`
+	Products []models.Product  `json:"products"`
+	Pages    bool              `json:"pages,omitempty"` // Products are the watched product pages, not the whole catalog
+	Error    string            `json:"error,omitempty"` // The scrape failed; Products is empty
+	Run      scraper.VendorRun `json:"run"`
+}
+
+// ErrUnknownJob is returned for a result of a job the queue is not waiting
+// on: never pushed, or already answered by another worker.
+var ErrUnknownJob = errors.New("unknown or finished job")
+
+// Queue hands jobs to workers and their results back to the coordinator.
+// Memory is the built-in implementation, served to workers over HTTP by
+// Handler; a broker-backed one (NATS, Redis) only needs these methods.
+type Queue interface {
+	// Push adds a job for the next worker to claim.
+	Push(job Job) error
+	// Claim waits until a job is available or ctx is done; ok is false
+	// when ctx ended first.
+	Claim(ctx context.Context, worker string) (job Job, ok bool, err error)
+	// Complete delivers a job's result to Results.
+	Complete(r Result) error
+	// Results yields each pushed job's first result.
+	Results() <-chan Result
+}
+
+// DefaultLease is how long a worker may take over a job before it is
+// handed to another worker: a dead worker must not stall the run, and a
+// slow crawl of a thousand pages must not run twice.
+const DefaultLease = 20 * time.Minute
+
+// Memory is an in-process Queue. A claimed job is leased to its worker;
+// when the lease runs out without a result, the job goes back to the front
+// of the queue. The first result for a job wins; later ones are refused
+// with ErrUnknownJob.
+type Memory struct {
+	lease time.Duration
+
+	mu      sync.Mutex
+	pending []Job
+	leased  map[string]leasedJob
+	ready   chan struct{} // Closed and replaced whenever a job is pushed or requeued
+	results chan Result
+}
+
+// leasedJob is a claimed job and when its lease runs out.
+type leasedJob struct {
+	job     Job
+	expires time.Time
+}
+
+// NewMemory returns an empty queue with the given lease (0 = DefaultLease).
+func NewMemory(lease time.Duration) *Memory {
+	if lease <= 0 {
+		lease = DefaultLease
+	}
+	return &Memory{lease: lease, leased: map[string]leasedJob{}, ready: make(chan struct{}), results: make(chan Result, 64)}
+}
+
+// Push implements Queue.
+func (q *Memory) Push(job Job) error {
+	if job.ID == "" {
+		return fmt.Errorf("job for %s has no ID", job.Vendor.Name)
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.pending = append(q.pending, job)
+	q.wake()
+	return nil
+}
+
+// Claim implements Queue.
+func (q *Memory) Claim(ctx context.Context, worker string) (Job, bool, error) {
+	for {
+		q.mu.Lock()
+		q.requeueExpired(time.Now())
+		if len(q.pending) > 0 {
+			job := q.pending[0]
+			q.pending = q.pending[1:]
+			q.leased[job.ID] = leasedJob{job: job, expires: time.Now().Add(q.lease)}
+			q.mu.Unlock()
+			return job, true, nil
+		}
+		ready := q.ready
+		q.mu.Unlock()
+
+		select {
+		case <-ready:
+		case <-time.After(q.nextExpiry()):
+		case <-ctx.Done():
+			return Job{}, false, nil
+		}
+	}
+}
+
+// Complete implements Queue.
+func (q *Memory) Complete(r Result) error {
+	q.mu.Lock()
+	_, ok := q.leased[r.JobID]
+	if !ok {
+		// A job whose lease ran out may still be waiting for its next worker
+		for i, job := range q.pending {
+			if job.ID == r.JobID {
+				q.pending, ok = append(q.pending[:i], q.pending[i+1:]...), true
+				break
+			}
+		}
+	}
+	delete(q.leased, r.JobID)
+	q.mu.Unlock()
+	if !ok {
+		return ErrUnknownJob
+	}
+	q.results <- r
+	return nil
+}
+
+// Results implements Queue.
+func (q *Memory) Results() <-chan Result {
+	return q.results
+}
+
+// requeueExpired puts jobs whose lease ran out back at the front of the
+// queue. Callers hold q.mu.
+func (q *Memory) requeueExpired(now time.Time) {
+	var expired []Job
+	for id, l := range q.leased {
+		if now.After(l.expires) {
+			expired = append(expired, l.job)
+			delete(q.leased, id)
+		}
+	}
+	if len(expired) > 0 {
+		q.pending = append(expired, q.pending...)
+		q.wake()
+	}
+}
+
+// nextExpiry is how long until the first lease runs out (the lease length
+// when none is out), so a waiting Claim picks up an abandoned job.
+func (q *Memory) nextExpiry() time.Duration {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	wait := q.lease
+	for _, l := range q.leased {
+		wait = min(wait, time.Until(l.expires))
+	}
+	return max(wait, time.Millisecond)
+}
+
+// wake releases every waiting Claim. Callers hold q.mu.
+func (q *Memory) wake() {
+	close(q.ready)
+	q.ready = make(chan struct{})
+}
@@ -0,0 +1,79 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"longevity-ranker/internal/models"
+	"longevity-ranker/internal/scraper"
+)
+
+func TestHandlerRoundTrip(t *testing.T) {
+	defer func(wait time.Duration) { ClaimWait = wait }(ClaimWait)
+	ClaimWait = 50 * time.Millisecond
+
+	q := NewMemory(0)
+	srv := httptest.NewServer(Handler(q))
+	defer srv.Close()
+	client := NewClient(srv.URL+"/", "")
+	ctx := context.Background()
+
+	if _, ok, err := client.Claim(ctx, "w1"); ok || err != nil {
+		t.Fatalf("Claim(empty queue) = %v, %v; want no job", ok, err)
+	}
+
+	q.Push(Job{ID: "run/Shop", Vendor: models.Vendor{Name: "Shop", URL: "https://shop.example.com"}, Handles: []string{"nmn"}, DiscoverWords: []string{"nmn", "nad"}})
+	job, ok, err := client.Claim(ctx, "w1")
+	if !ok || err != nil {
+		t.Fatalf("Claim() = %v, %v; want the pushed job", ok, err)
+	}
+	if job.ID != "run/Shop" || job.Vendor.URL != "https://shop.example.com" || len(job.Handles) != 1 || len(job.DiscoverWords) != 2 {
+		t.Errorf("claimed job = %+v", job)
+	}
+
+	result := Result{
+		JobID: job.ID, Worker: "w1",
+		Products: []models.Product{{Handle: "nmn", Title: "NMN"}},
+		Run:      scraper.VendorRun{Metrics: scraper.Metrics{Requests: 3, Waited: 2 * time.Second}},
+	}
+	if err := client.Complete(ctx, result); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	got := <-q.Results()
+	if got.Worker != "w1" || len(got.Products) != 1 || got.Run.Metrics.Requests != 3 || got.Run.Metrics.Waited != 2*time.Second {
+		t.Errorf("result = %+v", got)
+	}
+
+	// A second answer for the same job is refused
+	if err := client.Complete(ctx, result); !errors.Is(err, ErrUnknownJob) {
+		t.Errorf("Complete(again) error = %v, want ErrUnknownJob", err)
+	}
+}
+
+func TestMemoryRequeuesExpiredLease(t *testing.T) {
+	q := NewMemory(20 * time.Millisecond)
+	q.Push(Job{ID: "a"})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if job, ok, _ := q.Claim(ctx, "dead"); !ok || job.ID != "a" {
+		t.Fatalf("first Claim() = %+v, %v; want job a", job, ok)
+	}
+	// The dead worker never answers; the job goes to the next one
+	job, ok, _ := q.Claim(ctx, "alive")
+	if !ok || job.ID != "a" {
+		t.Fatalf("Claim() after the lease ran out = %+v, %v; want job a again", job, ok)
+	}
+	if err := q.Complete(Result{JobID: "a", Worker: "alive"}); err != nil {
+		t.Errorf("Complete() error = %v", err)
+	}
+	if err := q.Complete(Result{JobID: "a", Worker: "dead"}); !errors.Is(err, ErrUnknownJob) {
+		t.Errorf("late Complete() error = %v, want ErrUnknownJob", err)
+	}
+	if err := q.Push(Job{}); err == nil {
+		t.Error("Push(job without ID) should fail")
+	}
+}
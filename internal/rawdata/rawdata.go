@@ -0,0 +1,151 @@
+package rawdata
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"longevity-ranker/internal/history"
+	"longevity-ranker/internal/models"
+	"longevity-ranker/internal/storage"
+)
+
+// Dir is the raw data archive, relative to the repo root: one directory per
+// vendor (named like its data/<vendor>.json) holding one file per snapshot.
+// The CI workflow commits only data/*.json, so the archive stays local.
+var Dir = filepath.Join(storage.DataDir, "raw")
+
+// Snapshot sources. A scrape is what a -refresh run saw that day; a Wayback
+// snapshot is one archived page or feed saved by cmd/backfill.
+const (
+	SourceScrape  = "scrape"
+	SourceWayback = "wayback"
+)
+
+// Snapshot is the scraped products of one vendor as listed on Date, before
+// any rules ran.
+type Snapshot struct {
+	Vendor   string           `json:"vendor"`
+	Date     string           `json:"date"` // YYYY-MM-DD
+	Source   string           `json:"source"`
+	URL      string           `json:"url,omitempty"` // Wayback only: the captured URL
+	Products []models.Product `json:"products"`
+}
+
+// filename is the snapshot's path under dir. A vendor has one scrape per
+// date (a later run that day replaces it) and one Wayback snapshot per date
+// and captured URL.
+func (s Snapshot) filename(dir string) string {
+	vendorDir := strings.TrimSuffix(filepath.Base(storage.VendorFilename(s.Vendor)), ".json")
+	name := s.Date
+	if s.Source != SourceScrape {
+		sum := sha256.Sum256([]byte(s.URL))
+		name += "-" + s.Source + "-" + hex.EncodeToString(sum[:4])
+	}
+	return filepath.Join(dir, vendorDir, name+".json")
+}
+
+// Save writes s under dir and returns its path.
+func Save(dir string, s Snapshot) (string, error) {
+	path := s.filename(dir)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	return path, storage.SaveJSON(path, s)
+}
+
+// Load reads every snapshot under dir, ordered by date, then vendor, with
+// scrapes before Wayback snapshots. A missing dir is an empty archive.
+func Load(dir string) ([]Snapshot, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*", "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var snapshots []Snapshot
+	for _, path := range paths {
+		s, err := storage.LoadJSON[Snapshot](path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		snapshots = append(snapshots, s)
+	}
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		a, b := snapshots[i], snapshots[j]
+		if a.Date != b.Date {
+			return a.Date < b.Date
+		}
+		if a.Vendor != b.Vendor {
+			return a.Vendor < b.Vendor
+		}
+		if a.Source != b.Source {
+			return a.Source == SourceScrape
+		}
+		return a.URL < b.URL
+	})
+	return snapshots, nil
+}
+
+// ReplayStats counts the history points Replay touched.
+type ReplayStats struct {
+	VendorDays int // Vendor and date pairs rebuilt
+	Removed    int // Points dropped before rebuilding
+	Written    int // Points re-derived from the snapshots
+}
+
+// Replay rebuilds the history points the snapshots cover. For every vendor
+// and date with a snapshot, the vendor's points on that date are dropped and
+// derived again from the snapshots' products that keep accepts (keep may fix
+// a product, like rules.ApplyRules). Scrapes are applied before Wayback
+// snapshots, which never replace an observed point. Points on other dates
+// are left alone.
+func Replay(store history.Store, snapshots []Snapshot, keep func(vendorName string, p *models.Product) bool) ReplayStats {
+	var stats ReplayStats
+	covered := map[string]map[string]bool{} // vendor → dates
+	for _, s := range snapshots {
+		if covered[s.Vendor] == nil {
+			covered[s.Vendor] = map[string]bool{}
+		}
+		if !covered[s.Vendor][s.Date] {
+			covered[s.Vendor][s.Date] = true
+			stats.VendorDays++
+		}
+	}
+
+	for key, points := range store {
+		vendor, _, _ := strings.Cut(key, "|")
+		dates := covered[vendor]
+		if dates == nil {
+			continue
+		}
+		kept := points[:0]
+		for _, pt := range points {
+			if dates[pt.Date] {
+				stats.Removed++
+				continue
+			}
+			kept = append(kept, pt)
+		}
+		if len(kept) == 0 {
+			delete(store, key)
+		} else {
+			store[key] = kept
+		}
+	}
+
+	ordered := append([]Snapshot(nil), snapshots...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Source == SourceScrape && ordered[j].Source != SourceScrape
+	})
+	for _, s := range ordered {
+		for _, p := range s.Products {
+			if keep(s.Vendor, &p) {
+				stats.Written += history.Backfill(store, s.Date, s.Vendor, p)
+			}
+		}
+	}
+	return stats
+}
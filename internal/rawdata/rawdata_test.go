@@ -0,0 +1,96 @@
+package rawdata
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"longevity-ranker/internal/history"
+	"longevity-ranker/internal/models"
+)
+
+func product(handle, price string) models.Product {
+	return models.Product{Handle: handle, Title: handle, Variants: []models.Variant{{Title: "60 Capsules", Price: price, Available: true}}}
+}
+
+func TestSaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	snapshots := []Snapshot{
+		{Vendor: "Shop", Date: "2026-01-02", Source: SourceScrape, Products: []models.Product{product("nmn", "40")}},
+		{Vendor: "Shop", Date: "2026-01-01", Source: SourceWayback, URL: "https://shop.test/b", Products: []models.Product{product("nmn", "44")}},
+		{Vendor: "Shop", Date: "2026-01-01", Source: SourceWayback, URL: "https://shop.test/a", Products: []models.Product{product("nmn", "45")}},
+		{Vendor: "Shop", Date: "2026-01-01", Source: SourceScrape, Products: []models.Product{product("nmn", "46")}},
+		{Vendor: "Do Not Age", Date: "2026-01-02", Source: SourceScrape},
+	}
+	for _, s := range snapshots {
+		if _, err := Save(dir, s); err != nil {
+			t.Fatalf("Save(%s %s) = %v", s.Vendor, s.Date, err)
+		}
+	}
+	path, _ := Save(dir, snapshots[0])
+	if want := filepath.Join(dir, "shop", "2026-01-02.json"); path != want {
+		t.Errorf("Save() path = %s, want %s", path, want)
+	}
+
+	loaded, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() = %v", err)
+	}
+	var got []string
+	for _, s := range loaded {
+		got = append(got, s.Date+" "+s.Vendor+" "+s.Source+" "+s.URL)
+	}
+	want := []string{
+		"2026-01-01 Shop scrape ",
+		"2026-01-01 Shop wayback https://shop.test/a",
+		"2026-01-01 Shop wayback https://shop.test/b",
+		"2026-01-02 Do Not Age scrape ",
+		"2026-01-02 Shop scrape ",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Load() order = %q, want %q", got, want)
+	}
+
+	if empty, err := Load(filepath.Join(dir, "missing")); err != nil || len(empty) != 0 {
+		t.Errorf("Load(missing dir) = %v, %v; want an empty archive", empty, err)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	key := history.Key("Shop", "nmn", "60 Capsules")
+	store := history.Store{
+		key: {
+			{Date: "2026-01-01", Price: 99, Available: true},
+			{Date: "2026-01-02", Price: 99, Available: true},
+			{Date: "2026-01-03", Price: 50, Available: true},
+		},
+		history.Key("Shop", "gummies", "60 Capsules"): {{Date: "2026-01-02", Price: 20}},
+		history.Key("Other", "nmn", "60 Capsules"):    {{Date: "2026-01-02", Price: 30}},
+	}
+	snapshots := []Snapshot{
+		{Vendor: "Shop", Date: "2026-01-01", Source: SourceWayback, URL: "https://shop.test/", Products: []models.Product{product("nmn", "45")}},
+		{Vendor: "Shop", Date: "2026-01-02", Source: SourceWayback, URL: "https://shop.test/", Products: []models.Product{product("nmn", "41")}},
+		{Vendor: "Shop", Date: "2026-01-02", Source: SourceScrape, Products: []models.Product{product("nmn", "40"), product("gummies", "20")}},
+	}
+	// The rules now drop gummies
+	keep := func(vendorName string, p *models.Product) bool { return p.Handle != "gummies" }
+
+	stats := Replay(store, snapshots, keep)
+	if want := (ReplayStats{VendorDays: 2, Removed: 3, Written: 2}); stats != want {
+		t.Errorf("Replay() stats = %+v, want %+v", stats, want)
+	}
+	wantPoints := []history.Point{
+		{Date: "2026-01-01", Price: 45, Available: true},
+		{Date: "2026-01-02", Price: 40, Available: true},
+		{Date: "2026-01-03", Price: 50, Available: true},
+	}
+	if got := store[key]; !reflect.DeepEqual(got, wantPoints) {
+		t.Errorf("replayed points = %+v, want %+v (scrape over Wayback, other dates untouched)", got, wantPoints)
+	}
+	if got, ok := store[history.Key("Shop", "gummies", "60 Capsules")]; ok {
+		t.Errorf("gummies points = %+v, want them dropped with the product", got)
+	}
+	if got := store[history.Key("Other", "nmn", "60 Capsules")]; len(got) != 1 {
+		t.Errorf("other vendor points = %+v, want them untouched", got)
+	}
+}
@@ -0,0 +1,62 @@
+package review
+
+import (
+	"os"
+	"path/filepath"
+
+	"longevity-ranker/internal/storage"
+)
+
+// Filename is the review-decision store path, relative to the repo root.
+var Filename = filepath.Join(storage.DataDir, "review_decisions.json")
+
+// Decision values. A dismissed flag was a false positive: the entry is ranked
+// as clean. A confirmed flag is a real problem the operator has already seen:
+// the entry stays flagged but leaves the review queue.
+const (
+	Dismiss = "dismiss"
+	Confirm = "confirm"
+)
+
+// Decision records an operator's verdict on one review flag. It applies to
+// every variant of the product flagged with exactly this reason, so a new
+// kind of problem on the same product is queued again. Reasons carry no live
+// figures (those are in review_detail), so a decision survives price changes.
+type Decision struct {
+	Vendor   string `json:"vendor"`
+	Handle   string `json:"handle"`
+	Reason   string `json:"reason"`   // review_reason as written to needs_review.json
+	Decision string `json:"decision"` // Dismiss or Confirm
+	Note     string `json:"note,omitempty"`
+	Date     string `json:"date,omitempty"` // YYYY-MM-DD the decision was made
+}
+
+// Decisions indexes decisions by Key.
+type Decisions map[string]Decision
+
+// Key identifies the flag a decision applies to.
+func Key(vendorName, handle, reason string) string {
+	return vendorName + "|" + handle + "|" + reason
+}
+
+// Load reads the decision list from disk. A missing file yields no decisions.
+// When a flag has several decisions, the last one in the file wins.
+func Load(path string) (Decisions, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return Decisions{}, nil
+	}
+	list, err := storage.LoadJSON[[]Decision](path)
+	if err != nil {
+		return nil, err
+	}
+	decisions := make(Decisions, len(list))
+	for _, d := range list {
+		decisions[Key(d.Vendor, d.Handle, d.Reason)] = d
+	}
+	return decisions, nil
+}
+
+// Lookup returns the decision recorded for a flag, or "" when there is none.
+func (d Decisions) Lookup(vendorName, handle, reason string) string {
+	return d[Key(vendorName, handle, reason)].Decision
+}
@@ -4,33 +4,341 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 
+	"longevity-ranker/internal/hooks"
 	"longevity-ranker/internal/models"
 )
 
 // ProductSpec defines immutable mathematical truths about a product that the
 // regex engine cannot reliably extract. When present, these values bypass
 // regex entirely — they are not hints, they are overrides.
+//
+// ExpectedPriceMin/ExpectedPriceMax (and ForceServingMg) are not consumed by
+// the analyzer; -verify-overrides checks them against live data. The price
+// range is in the vendor's Currency, not converted to the report's.
+//
+// MinOrderQty/VariantMinOrderQty set the minimum number of units a vendor
+// sells per order, replacing any minimum the scraper found.
+//
+// Certifications adds third-party testing marks held by this product only,
+// on top of the vendor's.
+//
+// ActiveFraction replaces the analyzer's molecular-form table for this
+// product: the share of the labeled weight that is the active compound (1 =
+// the label already states it). ForceActiveGrams/VariantOverrides are labeled
+// weights, so the form factor applies to them too.
 type ProductSpec struct {
 	ForceType             string             `json:"forceType,omitempty"`
 	ForceActiveGrams      float64            `json:"forceActiveGrams,omitempty"`
 	ForceServingMg        float64            `json:"forceServingMg,omitempty"`
 	VariantOverrides      map[string]float64 `json:"variantOverrides,omitempty"`
 	VariantGrossOverrides map[string]float64 `json:"variantGrossOverrides,omitempty"`
+	ExpectedPriceMin      float64            `json:"expectedPriceMin,omitempty"`
+	ExpectedPriceMax      float64            `json:"expectedPriceMax,omitempty"`
+	MinOrderQty           int                `json:"minOrderQty,omitempty"`
+	VariantMinOrderQty    map[string]int     `json:"variantMinOrderQty,omitempty"`
+	Certifications        []string           `json:"certifications,omitempty"`
+	ActiveFraction        float64            `json:"activeFraction,omitempty"`
+}
+
+// SubscriptionFrequency is one delivery interval a vendor's subscription
+// offers, with its discount off the one-time price (0.15 = 15% off).
+type SubscriptionFrequency struct {
+	Days     int     `json:"days"`
+	Discount float64 `json:"discount"`
 }
 
 // VendorConfig holds blocklist and override configuration for a single vendor.
+//
+// Supplements, when set, names the supplements (data/supplements.json names or
+// aliases) tracked for the vendor, out of those the -supplements flag selects,
+// so a creatine-only store is not gated and audited for NMN.
+//
+// Exclude is only read from the GlobalKey entry: product substrings rejected
+// for every vendor, on top of each vendor's own Blocklist.
+//
+// SubscriptionFrequencies, when set, replaces GlobalSubscriptionDiscount: the
+// synthetic subscription entry lists a price per delivery interval.
+//
+// DirtyKeywords/DirtyKeywordsRemove tune the Triage Engine: in the GlobalKey
+// entry DirtyKeywords is the base list; in a vendor entry the two fields add
+// to and remove from it for that vendor only. CautionKeywords is the milder
+// tier (flavor names), resolved the same way: a match costs confidence but
+// the entry still ranks. DirtyKeywordsRemove applies to both tiers.
+//
+// Certifications lists third-party testing marks (e.g. "NSF Certified for
+// Sport", "Informed Sport", "ConsumerLab Tested") held by every product of a
+// brand. CertificationMultipliers is only read from the GlobalKey entry: a
+// quality multiplier per certification name (see CertificationMultiplier).
+//
+// ShippingCost is the vendor's flat shipping fee per order, waived for orders
+// of at least FreeShippingOver (0 = never waived). RankWeights is only read
+// from the GlobalKey entry: the ranking formula (see RankWeights).
+//
+// Currency is the ISO 4217 code of the vendor's prices (empty = the report
+// currency, USD); ShippingCost and FreeShippingOver are in it too.
+// ExchangeRates is only read from the GlobalKey entry: report-currency units
+// per unit of each currency, e.g. {"EUR": 1.08} (see ExchangeRate).
+//
+// Hooks names vendor-specific fixes registered in internal/hooks (e.g.
+// "prohealth-titles"), run in order on each of the vendor's products by
+// ApplyRules.
+//
+// DelistGraceDays is how many days a product missing from the vendor's
+// scrapes stays in the report, marked possibly delisted (see
+// DelistGraceDays); a vendor entry overrides the GlobalKey one.
+//
+// SubscriptionRisks tags a vendor whose subscriptions are known to be a
+// trap (see SubscriptionRiskTags); the tags are copied onto its
+// subscription entries.
 type VendorConfig struct {
-	Blocklist                  []string              `json:"blocklist"`
-	VariantBlocklist           []string              `json:"variantBlocklist,omitempty"`
-	Overrides                  map[string]ProductSpec `json:"overrides"`
-	GlobalSubscriptionDiscount float64               `json:"globalSubscriptionDiscount,omitempty"`
+	Blocklist                  []string                `json:"blocklist"`
+	VariantBlocklist           []string                `json:"variantBlocklist,omitempty"`
+	Overrides                  map[string]ProductSpec  `json:"overrides"`
+	GlobalSubscriptionDiscount float64                 `json:"globalSubscriptionDiscount,omitempty"`
+	SubscriptionFrequencies    []SubscriptionFrequency `json:"subscriptionFrequencies,omitempty"`
+	DirtyKeywords              []string                `json:"dirtyKeywords,omitempty"`
+	DirtyKeywordsRemove        []string                `json:"dirtyKeywordsRemove,omitempty"`
+	CautionKeywords            []string                `json:"cautionKeywords,omitempty"`
+	Supplements                []string                `json:"supplements,omitempty"`
+	Exclude                    []string                `json:"exclude,omitempty"`
+	Certifications             []string                `json:"certifications,omitempty"`
+	CertificationMultipliers   map[string]float64      `json:"certificationMultipliers,omitempty"`
+	ShippingCost               float64                 `json:"shippingCost,omitempty"`
+	FreeShippingOver           float64                 `json:"freeShippingOver,omitempty"`
+	RankWeights                map[string]float64      `json:"rankWeights,omitempty"`
+	Currency                   string                  `json:"currency,omitempty"`
+	ExchangeRates              map[string]float64      `json:"exchangeRates,omitempty"`
+	Hooks                      []string                `json:"hooks,omitempty"`
+	DelistGraceDays            int                     `json:"delistGraceDays,omitempty"`
+	SubscriptionRisks          []string                `json:"subscriptionRisks,omitempty"`
 }
 
 // Registry is a map from vendor name to its configuration.
 type Registry = map[string]VendorConfig
 
+// GlobalKey is the reserved registry entry holding settings that apply to
+// every vendor. It never matches a real vendor name.
+const GlobalKey = "*"
+
+// DefaultDirtyKeywords is the block-worthy triage keyword list used when the
+// rules file has no global dirtyKeywords (or could not be loaded): blends,
+// combos and chewables whose label mass is not the active mass.
+var DefaultDirtyKeywords = []string{
+	"blend", "complex", "with", "+", "gumm", "chew", "bundle",
+}
+
+// DefaultCautionKeywords is the caution triage keyword list used when the
+// rules file has no global cautionKeywords: flavor names. Flavoring adds
+// weight, so the mass is less certain, but the product is what it says.
+var DefaultCautionKeywords = []string{
+	"flavor", "island cooler", "coastal explosion", "watermelon", "berry", "punch",
+	"orange", "lemon", "mango", "grape", "apple", "blue raspberry", "fruit punch",
+	"sour watermelon", "pineapple mango", "mandarin orange", "shaq's berry blast",
+	"frozen lemonade",
+}
+
+// Certifications returns the third-party testing marks of a product: the
+// vendor's, then the product override's, without duplicates (compared
+// case-insensitively, first spelling kept). nil when there are none.
+func Certifications(reg Registry, vendorName, handle string) []string {
+	cfg := reg[vendorName]
+	return mergeCertifications(cfg.Certifications, cfg.Overrides[handle].Certifications)
+}
+
+// BrandCertifications returns the marks of a product a retailer lists for
+// another brand: the brand's own entry's, then the retailer's and the
+// product override's, deduplicated like Certifications.
+func BrandCertifications(reg Registry, brand, vendorName, handle string) []string {
+	cfg := reg[vendorName]
+	return mergeCertifications(reg[brand].Certifications, cfg.Certifications, cfg.Overrides[handle].Certifications)
+}
+
+// mergeCertifications joins the lists, trimmed and without case-insensitive
+// duplicates (first spelling kept). nil when they are all empty.
+func mergeCertifications(lists ...[]string) []string {
+	var certs []string
+	seen := make(map[string]bool)
+	for _, list := range lists {
+		for _, c := range list {
+			c = strings.TrimSpace(c)
+			if c == "" || seen[strings.ToLower(c)] {
+				continue
+			}
+			seen[strings.ToLower(c)] = true
+			certs = append(certs, c)
+		}
+	}
+	return certs
+}
+
+// CertificationMultiplier returns the quality multiplier for a set of
+// certifications: the largest GlobalKey CertificationMultipliers value among
+// them (names compared case-insensitively), so marks do not compound. 1 when
+// none is configured.
+func CertificationMultiplier(reg Registry, certs []string) float64 {
+	best := 1.0
+	for name, m := range reg[GlobalKey].CertificationMultipliers {
+		for _, c := range certs {
+			if strings.EqualFold(name, c) && m > best {
+				best = m
+			}
+		}
+	}
+	return best
+}
+
+// Ranking formula factors, the valid rankWeights keys.
+const (
+	RankCost            = "cost"            // Sticker $/g
+	RankBioavailability = "bioavailability" // Delivery multiplier (liposomal, sublingual, ...)
+	RankTrust           = "trust"           // Certification multiplier × external quality score
+	RankShipping        = "shipping"        // Shipping fee share of the order
+	RankDeal            = "deal"            // Genuine discount off the compare-at price
+)
+
+// RankFactors lists every ranking factor in display order.
+var RankFactors = []string{RankCost, RankBioavailability, RankTrust, RankShipping, RankDeal}
+
+// RankWeights returns the GlobalKey ranking weights, or nil when none are
+// configured (the report is then ranked by effective cost).
+func RankWeights(reg Registry) map[string]float64 {
+	weights := reg[GlobalKey].RankWeights
+	if len(weights) == 0 {
+		return nil
+	}
+	return weights
+}
+
+// Shipping returns a vendor's shipping fee for an order of orderTotal: its
+// ShippingCost, or 0 once the order reaches FreeShippingOver.
+func Shipping(reg Registry, vendorName string, orderTotal float64) float64 {
+	cfg := reg[vendorName]
+	if cfg.FreeShippingOver > 0 && orderTotal >= cfg.FreeShippingOver {
+		return 0
+	}
+	return cfg.ShippingCost
+}
+
+// Subscription risk tags, the valid subscriptionRisks values.
+const (
+	RiskHardToCancel   = "hard-to-cancel"   // Cancelling takes a call, an email or a chat
+	RiskRenewalPriceUp = "renewal-price-up" // Renewals are billed above the advertised subscription price
+)
+
+// SubscriptionRiskTags lists every subscription risk tag in display order.
+var SubscriptionRiskTags = []string{RiskHardToCancel, RiskRenewalPriceUp}
+
+// SubscriptionRisks returns a vendor's subscription risk tags in
+// SubscriptionRiskTags order, lowercased and without duplicates. nil when
+// it has none.
+func SubscriptionRisks(reg Registry, vendorName string) []string {
+	var risks []string
+	for _, tag := range SubscriptionRiskTags {
+		if slices.ContainsFunc(reg[vendorName].SubscriptionRisks, func(s string) bool {
+			return strings.EqualFold(strings.TrimSpace(s), tag)
+		}) {
+			risks = append(risks, tag)
+		}
+	}
+	return risks
+}
+
+// DefaultDelistGraceDays is the delisting grace period when the rules set
+// none: a product missed by up to three daily scrapes in a row keeps its
+// place.
+const DefaultDelistGraceDays = 3
+
+// DelistGraceDays returns the vendor's delisting grace period in days: its
+// DelistGraceDays, else the GlobalKey one, else DefaultDelistGraceDays. A
+// negative setting turns the grace period off (0).
+func DelistGraceDays(reg Registry, vendorName string) int {
+	days := reg[vendorName].DelistGraceDays
+	if days == 0 {
+		days = reg[GlobalKey].DelistGraceDays
+	}
+	if days == 0 {
+		days = DefaultDelistGraceDays
+	}
+	return max(days, 0)
+}
+
+// ReportCurrency is the currency every report price is in.
+const ReportCurrency = "USD"
+
+// Currency returns the uppercased ISO 4217 code of a vendor's prices, or
+// ReportCurrency when unset.
+func Currency(reg Registry, vendorName string) string {
+	if c := strings.ToUpper(strings.TrimSpace(reg[vendorName].Currency)); c != "" {
+		return c
+	}
+	return ReportCurrency
+}
+
+// ExchangeRate returns the report-currency value of one unit of currency
+// from the GlobalKey entry's ExchangeRates (1 for ReportCurrency). ok is
+// false when no positive rate is configured; LoadRules rejects vendors
+// priced in such a currency.
+func ExchangeRate(reg Registry, currency string) (rate float64, ok bool) {
+	if currency == ReportCurrency {
+		return 1, true
+	}
+	for code, r := range reg[GlobalKey].ExchangeRates {
+		if strings.EqualFold(code, currency) && r > 0 {
+			return r, true
+		}
+	}
+	return 0, false
+}
+
+// DirtyKeywords resolves the block-worthy triage keyword list for a vendor:
+// the global list (or DefaultDirtyKeywords), plus the vendor's additions,
+// minus its removals. Keywords are lowercased; removals match
+// case-insensitively.
+func DirtyKeywords(reg Registry, vendorName string) []string {
+	base := DefaultDirtyKeywords
+	if global, ok := reg[GlobalKey]; ok && len(global.DirtyKeywords) > 0 {
+		base = global.DirtyKeywords
+	}
+	return resolveKeywords(base, reg[vendorName].DirtyKeywords, reg[vendorName].DirtyKeywordsRemove)
+}
+
+// CautionKeywords resolves the caution triage keyword list for a vendor like
+// DirtyKeywords: the global cautionKeywords (or DefaultCautionKeywords), plus
+// the vendor's cautionKeywords, minus its dirtyKeywordsRemove.
+func CautionKeywords(reg Registry, vendorName string) []string {
+	base := DefaultCautionKeywords
+	if global, ok := reg[GlobalKey]; ok && len(global.CautionKeywords) > 0 {
+		base = global.CautionKeywords
+	}
+	return resolveKeywords(base, reg[vendorName].CautionKeywords, reg[vendorName].DirtyKeywordsRemove)
+}
+
+// resolveKeywords returns base plus added minus removed, lowercased and
+// without duplicates.
+func resolveKeywords(base, added, removedList []string) []string {
+	removed := make(map[string]bool, len(removedList))
+	for _, kw := range removedList {
+		removed[strings.ToLower(kw)] = true
+	}
+
+	keywords := make([]string, 0, len(base)+len(added))
+	seen := make(map[string]bool, cap(keywords))
+	for _, list := range [][]string{base, added} {
+		for _, kw := range list {
+			kw = strings.ToLower(kw)
+			if kw == "" || removed[kw] || seen[kw] {
+				continue
+			}
+			seen[kw] = true
+			keywords = append(keywords, kw)
+		}
+	}
+	return keywords
+}
+
 // LoadRules reads the JSON configuration from disk and returns the registry.
 // The caller owns the returned map — there is no global mutable state.
 func LoadRules(path string) (Registry, error) {
@@ -43,24 +351,124 @@ func LoadRules(path string) (Registry, error) {
 	if err := json.Unmarshal(data, &reg); err != nil {
 		return nil, fmt.Errorf("could not parse rules file: %v", err)
 	}
+	// Daily doses moved to the supplement registry; a leftover would be
+	// silently ignored
+	var moved map[string]struct {
+		TargetDoseMg json.RawMessage `json:"targetDoseMg"`
+	}
+	if json.Unmarshal(data, &moved) == nil && moved[GlobalKey].TargetDoseMg != nil {
+		return nil, fmt.Errorf("targetDoseMg moved to data/supplements.json (one targetDoseMg per supplement); remove it from the rules file")
+	}
+
+	for factor, w := range reg[GlobalKey].RankWeights {
+		if !slices.Contains(RankFactors, factor) {
+			return nil, fmt.Errorf("unknown rankWeights factor %q (want %s)", factor, strings.Join(RankFactors, ", "))
+		}
+		if w < 0 {
+			return nil, fmt.Errorf("rankWeights %q is negative", factor)
+		}
+	}
+
+	if err := validateCurrencies(reg); err != nil {
+		return nil, err
+	}
+
+	for vendor, cfg := range reg {
+		for _, name := range cfg.Hooks {
+			if _, ok := hooks.Lookup(name); !ok {
+				return nil, fmt.Errorf("vendor %q: unknown hook %q (want %s)", vendor, name, strings.Join(hooks.Names(), ", "))
+			}
+		}
+		for _, risk := range cfg.SubscriptionRisks {
+			if !slices.Contains(SubscriptionRiskTags, strings.ToLower(strings.TrimSpace(risk))) {
+				return nil, fmt.Errorf("vendor %q: unknown subscriptionRisks tag %q (want %s)", vendor, risk, strings.Join(SubscriptionRiskTags, ", "))
+			}
+		}
+	}
+
+	// Supplement keywords are matched against lowercased product identities
+	for _, cfg := range reg {
+		for i, s := range cfg.Supplements {
+			cfg.Supplements[i] = strings.ToLower(strings.TrimSpace(s))
+		}
+	}
 
 	return reg, nil
 }
 
-// ApplyRules evaluates the vendor blocklist against the product. Returns false
-// if the product is blocked, true if it is allowed. This function performs NO
-// data enrichment — overrides are consumed directly by the analyzer.
+// WithExclusions returns reg with keywords added to the global Exclude list,
+// creating the registry or its GlobalKey entry when missing. Used by the
+// -exclude flag for ad-hoc filtering without editing vendor_rules.json.
+func WithExclusions(reg Registry, keywords []string) Registry {
+	if len(keywords) == 0 {
+		return reg
+	}
+	if reg == nil {
+		reg = Registry{}
+	}
+	global := reg[GlobalKey]
+	global.Exclude = append(global.Exclude, keywords...)
+	reg[GlobalKey] = global
+	return reg
+}
+
+// WithCurrencies returns reg with each vendor's currency from the vendor
+// list (data/vendors.json) set on its entry, creating the registry or entry
+// when missing. A vendor entry that already names a different currency, or
+// a currency without an exchange rate, is an error.
+func WithCurrencies(reg Registry, vendors []models.Vendor) (Registry, error) {
+	for _, v := range vendors {
+		if v.Currency == "" {
+			continue
+		}
+		if reg == nil {
+			reg = Registry{}
+		}
+		cfg := reg[v.Name]
+		if cfg.Currency != "" && !strings.EqualFold(cfg.Currency, v.Currency) {
+			return reg, fmt.Errorf("vendor %q is priced in %s in the vendor list but %s in the rules", v.Name, v.Currency, cfg.Currency)
+		}
+		cfg.Currency = v.Currency
+		reg[v.Name] = cfg
+	}
+	return reg, validateCurrencies(reg)
+}
+
+// validateCurrencies checks that every vendor's currency has a positive
+// exchange rate.
+func validateCurrencies(reg Registry) error {
+	for name := range reg {
+		currency := Currency(reg, name)
+		if _, ok := ExchangeRate(reg, currency); !ok && name != GlobalKey {
+			return fmt.Errorf("vendor %q is priced in %s but exchangeRates has no positive %s rate", name, currency, currency)
+		}
+	}
+	return nil
+}
+
+// ApplyRules runs the vendor's hooks on the product, then evaluates the
+// global exclusions and the vendor blocklist against it. Returns false if the
+// product is blocked, true if it is allowed. Beyond the hooks' fixes this
+// function performs NO data enrichment — overrides are consumed directly by
+// the analyzer.
 func ApplyRules(reg Registry, vendorName string, p *models.Product) bool {
 	if reg == nil {
 		return true
 	}
+	hooks.Run(reg[vendorName].Hooks, p)
+
+	identity := strings.ToLower(p.Title + " " + p.Handle + " " + p.Context)
+	for _, excluded := range reg[GlobalKey].Exclude {
+		if strings.Contains(identity, strings.ToLower(excluded)) {
+			return false
+		}
+	}
 
 	config, exists := reg[vendorName]
 	if !exists {
 		return true
 	}
 
-	identity := strings.ToLower(p.Title + " " + p.Handle + " " + p.Context)
 	for _, blocked := range config.Blocklist {
 		if strings.Contains(identity, strings.ToLower(blocked)) {
 			return false
@@ -68,4 +476,4 @@ func ApplyRules(reg Registry, vendorName string, p *models.Product) bool {
 	}
 
 	return true
-}
\ No newline at end of file
+}
@@ -0,0 +1,202 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"longevity-ranker/internal/models"
+)
+
+func TestDirtyKeywords(t *testing.T) {
+	reg := Registry{
+		GlobalKey: {DirtyKeywords: []string{"flavor", "with", "+", "Blend"}},
+		"Tuned":   {DirtyKeywords: []string{"Gummies", "blend"}, DirtyKeywordsRemove: []string{"WITH", "+"}},
+	}
+
+	cases := []struct {
+		name   string
+		reg    Registry
+		vendor string
+		want   []string
+	}{
+		{"global list", reg, "Other", []string{"flavor", "with", "+", "blend"}},
+		{"vendor additions and removals", reg, "Tuned", []string{"flavor", "blend", "gummies"}},
+		{"no rules falls back to defaults", nil, "Other", DefaultDirtyKeywords},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := DirtyKeywords(tc.reg, tc.vendor); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("DirtyKeywords() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCautionKeywords(t *testing.T) {
+	reg := Registry{
+		GlobalKey: {CautionKeywords: []string{"Berry", "lemon"}},
+		"Tuned":   {CautionKeywords: []string{"Mango"}, DirtyKeywordsRemove: []string{"LEMON"}},
+	}
+	if got, want := CautionKeywords(reg, "Other"), []string{"berry", "lemon"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("CautionKeywords(Other) = %q, want %q", got, want)
+	}
+	if got, want := CautionKeywords(reg, "Tuned"), []string{"berry", "mango"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("CautionKeywords(Tuned) = %q, want %q", got, want)
+	}
+	if got := CautionKeywords(nil, "Other"); !reflect.DeepEqual(got, DefaultCautionKeywords) {
+		t.Errorf("CautionKeywords(nil) = %q, want the defaults", got)
+	}
+}
+
+func TestDelistGraceDays(t *testing.T) {
+	reg := Registry{
+		GlobalKey: {DelistGraceDays: 5},
+		"Strict":  {DelistGraceDays: -1},
+		"Patient": {DelistGraceDays: 10},
+	}
+	for vendor, want := range map[string]int{"Other": 5, "Strict": 0, "Patient": 10} {
+		if got := DelistGraceDays(reg, vendor); got != want {
+			t.Errorf("DelistGraceDays(%s) = %d, want %d", vendor, got, want)
+		}
+	}
+	if got := DelistGraceDays(nil, "Other"); got != DefaultDelistGraceDays {
+		t.Errorf("DelistGraceDays(nil) = %d, want the default %d", got, DefaultDelistGraceDays)
+	}
+}
+
+func TestApplyRulesExclusions(t *testing.T) {
+	reg := WithExclusions(Registry{"Vendor": {Blocklist: []string{"Bundle"}}}, []string{"gummies"})
+
+	cases := []struct {
+		vendor string
+		title  string
+		want   bool
+	}{
+		{"Vendor", "NMN Gummies", false},
+		{"Unconfigured", "NMN Gummies", false},
+		{"Vendor", "NMN Bundle", false},
+		{"Unconfigured", "NMN Bundle", true},
+		{"Vendor", "NMN Capsules", true},
+	}
+	for _, tc := range cases {
+		p := models.Product{Title: tc.title}
+		if got := ApplyRules(reg, tc.vendor, &p); got != tc.want {
+			t.Errorf("ApplyRules(%s, %q) = %v, want %v", tc.vendor, tc.title, got, tc.want)
+		}
+	}
+
+	if got := WithExclusions(nil, []string{"topical"}); len(got[GlobalKey].Exclude) != 1 {
+		t.Errorf("WithExclusions(nil) = %+v, want a global entry with one exclusion", got)
+	}
+}
+
+func TestLoadRulesRankWeights(t *testing.T) {
+	tests := []struct {
+		json    string
+		wantErr bool
+	}{
+		{`{"*": {"rankWeights": {"cost": 1, "trust": 0.5}}}`, false},
+		{`{"*": {"rankWeights": {"price": 1}}}`, true},
+		{`{"*": {"rankWeights": {"deal": -1}}}`, true},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "rules.json")
+		if err := os.WriteFile(path, []byte(tt.json), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadRules(path); (err != nil) != tt.wantErr {
+			t.Errorf("LoadRules(%s) error = %v, wantErr %v", tt.json, err, tt.wantErr)
+		}
+	}
+}
+
+func TestLoadRulesCurrency(t *testing.T) {
+	tests := []struct {
+		json    string
+		wantErr bool
+	}{
+		{`{"*": {"exchangeRates": {"eur": 1.08}}, "EU Shop": {"currency": "EUR"}}`, false},
+		{`{"EU Shop": {"currency": "usd"}}`, false},
+		{`{"EU Shop": {"currency": "EUR"}}`, true},
+		{`{"*": {"exchangeRates": {"EUR": 0}}, "EU Shop": {"currency": "EUR"}}`, true},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "rules.json")
+		if err := os.WriteFile(path, []byte(tt.json), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadRules(path); (err != nil) != tt.wantErr {
+			t.Errorf("LoadRules(%s) error = %v, wantErr %v", tt.json, err, tt.wantErr)
+		}
+	}
+}
+
+func TestLoadRulesHooks(t *testing.T) {
+	tests := []struct {
+		json    string
+		wantErr bool
+	}{
+		{`{"ProHealth": {"hooks": ["prohealth-titles"]}}`, false},
+		{`{"ProHealth": {"hooks": ["strip-titles"]}}`, true},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "rules.json")
+		if err := os.WriteFile(path, []byte(tt.json), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadRules(path); (err != nil) != tt.wantErr {
+			t.Errorf("LoadRules(%s) error = %v, wantErr %v", tt.json, err, tt.wantErr)
+		}
+	}
+
+	// The hook runs before the blocklist, which sees the fixed title
+	reg := Registry{"ProHealth": {Hooks: []string{"prohealth-titles"}, Blocklist: []string{"NMN Pro"}}}
+	p := models.Product{Title: "NMN Pro 300™ - Uthever® NMN - 300 mg, 30 capsules"}
+	if !ApplyRules(reg, "ProHealth", &p) || p.Title != "Uthever® NMN - 300 mg, 30 capsules" {
+		t.Errorf("ApplyRules() blocked or left title %q", p.Title)
+	}
+}
+
+func TestSubscriptionRisks(t *testing.T) {
+	tests := []struct {
+		json    string
+		wantErr bool
+	}{
+		{`{"Shop": {"subscriptionRisks": ["hard-to-cancel", "Renewal-Price-Up"]}}`, false},
+		{`{"Shop": {"subscriptionRisks": ["sneaky"]}}`, true},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "rules.json")
+		if err := os.WriteFile(path, []byte(tt.json), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadRules(path); (err != nil) != tt.wantErr {
+			t.Errorf("LoadRules(%s) error = %v, wantErr %v", tt.json, err, tt.wantErr)
+		}
+	}
+
+	reg := Registry{"Shop": {SubscriptionRisks: []string{"Renewal-Price-Up", " hard-to-cancel", "hard-to-cancel"}}}
+	if got, want := SubscriptionRisks(reg, "Shop"), []string{RiskHardToCancel, RiskRenewalPriceUp}; !reflect.DeepEqual(got, want) {
+		t.Errorf("SubscriptionRisks() = %v, want %v", got, want)
+	}
+	if got := SubscriptionRisks(reg, "Other"); got != nil {
+		t.Errorf("SubscriptionRisks(untagged) = %v, want nil", got)
+	}
+}
+
+func TestWithCurrencies(t *testing.T) {
+	rates := Registry{GlobalKey: {ExchangeRates: map[string]float64{"EUR": 1.08}}}
+	reg, err := WithCurrencies(rates, []models.Vendor{{Name: "EU Shop", Currency: "EUR"}, {Name: "US Shop"}})
+	if err != nil || Currency(reg, "EU Shop") != "EUR" || Currency(reg, "US Shop") != ReportCurrency {
+		t.Errorf("WithCurrencies() = %+v, %v; want EU Shop in EUR", reg, err)
+	}
+	if _, err := WithCurrencies(nil, []models.Vendor{{Name: "UK Shop", Currency: "GBP"}}); err == nil {
+		t.Error("WithCurrencies(no GBP rate) error = nil, want error")
+	}
+	conflict := Registry{"EU Shop": {Currency: "GBP"}}
+	if _, err := WithCurrencies(conflict, []models.Vendor{{Name: "EU Shop", Currency: "EUR"}}); err == nil {
+		t.Error("WithCurrencies(conflicting currency) error = nil, want error")
+	}
+}
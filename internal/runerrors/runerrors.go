@@ -0,0 +1,164 @@
+package runerrors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"longevity-ranker/internal/storage"
+)
+
+// Filename is the error report path, relative to the repo root. Each run
+// overwrites it, so a clean run leaves an empty list.
+var Filename = filepath.Join(storage.DataDir, "errors.json")
+
+// Scopes: a vendor error means the vendor contributed no products; a page
+// error is one failed request, after which the scrape may have continued.
+const (
+	ScopeVendor = "vendor"
+	ScopePage   = "page"
+)
+
+// Error classes.
+const (
+	ClassNetwork     = "network"      // DNS, connection refused or reset
+	ClassTimeout     = "timeout"      // Client or dial timeout
+	ClassHTTP        = "http"         // 4xx/5xx response
+	ClassThrottled   = "throttled"    // Still HTTP 429 after every retry
+	ClassCircuitOpen = "circuit_open" // Skipped after the vendor's breaker opened
+	ClassOverBudget  = "over_budget"  // Skipped after the vendor's crawl budget was spent
+	ClassDisallowed  = "disallowed"   // Skipped because the host's robots.txt disallows it
+	ClassParse       = "parse"        // Response or file that does not decode
+	ClassMissingFile = "missing_file" // No cached data/<vendor>.json
+	ClassCurrency    = "currency"     // Prices stated in another currency than the vendor's
+	ClassOther       = "other"
+)
+
+// Entry is one error of the run.
+type Entry struct {
+	Vendor  string `json:"vendor"`
+	Scope   string `json:"scope"`
+	URL     string `json:"url,omitempty"`
+	Status  int    `json:"status,omitempty"` // HTTP status code, when a response arrived
+	Class   string `json:"class"`
+	Message string `json:"message"`
+}
+
+// Report is the contents of data/errors.json. Errors is never nil.
+type Report struct {
+	Date   string  `json:"date"`
+	Errors []Entry `json:"errors"`
+}
+
+// Log collects entries from concurrent scrapes.
+type Log struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// Add records e.
+func (l *Log) Add(e Entry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, e)
+}
+
+// Entries returns the recorded entries by vendor, vendor errors first, then
+// by URL. It never returns nil.
+func (l *Log) Entries() []Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entries := append([]Entry{}, l.entries...)
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Vendor != b.Vendor {
+			return a.Vendor < b.Vendor
+		}
+		if a.Scope != b.Scope {
+			return a.Scope == ScopeVendor
+		}
+		return a.URL < b.URL
+	})
+	return entries
+}
+
+// Classify maps an error, and the HTTP status when a response arrived
+// (0 otherwise), to its class. Circuit breaker refusals are classified by
+// the scraper, which owns the sentinel error.
+func Classify(err error, status int) string {
+	var netErr net.Error
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case status == http.StatusTooManyRequests:
+		return ClassThrottled
+	case status >= 400:
+		return ClassHTTP
+	case err == nil:
+		return ClassOther
+	case errors.Is(err, fs.ErrNotExist):
+		return ClassMissingFile
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return ClassTimeout
+	case errors.As(err, &netErr):
+		return ClassNetwork
+	case errors.As(err, &syntaxErr), errors.As(err, &typeErr):
+		return ClassParse
+	}
+	return ClassOther
+}
+
+// Format renders the end-of-run summary: one block per vendor, its vendor
+// error first, then its failed pages, except those whose message the vendor
+// error already quotes. "" when there are no entries.
+func Format(entries []Entry) string {
+	if len(entries) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n=== ERRORS (%d) — details in data/errors.json ===\n", len(entries))
+	vendor, vendorMessage := "", ""
+	for _, e := range entries {
+		if e.Vendor != vendor {
+			vendor, vendorMessage = e.Vendor, ""
+			fmt.Fprintf(&b, "%s:\n", vendor)
+		}
+		switch {
+		case e.Scope == ScopeVendor:
+			vendorMessage = e.Message
+			fmt.Fprintf(&b, "  ❌ [%s] %s\n", e.Class, e.Message)
+		case vendorMessage != "" && strings.Contains(vendorMessage, e.Message):
+			continue
+		case e.Status != 0:
+			fmt.Fprintf(&b, "  ⚠️  [%s %d] %s\n", e.Class, e.Status, e.URL)
+		default:
+			fmt.Fprintf(&b, "  ⚠️  [%s] %s: %s\n", e.Class, e.URL, e.Message)
+		}
+	}
+	return b.String()
+}
+
+// Take removes the vendor's entries from the log and returns them in the
+// order they were added.
+func (l *Log) Take(vendor string) []Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var taken []Entry
+	kept := l.entries[:0]
+	for _, e := range l.entries {
+		if e.Vendor == vendor {
+			taken = append(taken, e)
+		} else {
+			kept = append(kept, e)
+		}
+	}
+	l.entries = kept
+	return taken
+}
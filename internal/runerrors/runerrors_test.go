@@ -0,0 +1,81 @@
+package runerrors
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClassify(t *testing.T) {
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	_, timeoutErr := (&net.Dialer{}).DialContext(ctx, "tcp", "192.0.2.1:80")
+	_, missingErr := os.ReadFile("testdata/missing.json")
+	syntaxErr := json.Unmarshal([]byte("{"), &struct{}{})
+
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		want   string
+	}{
+		{"429", nil, 429, ClassThrottled},
+		{"404", nil, 404, ClassHTTP},
+		{"503", nil, 503, ClassHTTP},
+		{"dial", fmt.Errorf("failed fetching page 1: %w", dialErr), 0, ClassNetwork},
+		{"timeout", timeoutErr, 0, ClassTimeout},
+		{"missing cache", missingErr, 0, ClassMissingFile},
+		{"bad json", syntaxErr, 0, ClassParse},
+		{"other", errors.New("unknown vendor scraper type: ftp"), 0, ClassOther},
+	}
+	for _, tt := range tests {
+		if got := Classify(tt.err, tt.status); got != tt.want {
+			t.Errorf("%s: Classify(%v, %d) = %q, want %q", tt.name, tt.err, tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestEntriesAndFormat(t *testing.T) {
+	var l Log
+	if got := l.Entries(); got == nil || Format(got) != "" {
+		t.Errorf("empty log: Entries() = %#v, Format() = %q; want [] and \"\"", got, Format(got))
+	}
+
+	l.Add(Entry{Vendor: "B", Scope: ScopePage, URL: "https://b/2", Status: 503, Class: ClassHTTP, Message: "503 Service Unavailable"})
+	l.Add(Entry{Vendor: "B", Scope: ScopePage, URL: "https://b/1", Class: ClassNetwork, Message: "connection refused"})
+	l.Add(Entry{Vendor: "B", Scope: ScopePage, URL: "https://b/0", Class: ClassNetwork, Message: "no such host"})
+	l.Add(Entry{Vendor: "B", Scope: ScopeVendor, URL: "https://b", Class: ClassNetwork, Message: "scraping: failed fetching page 1: no such host"})
+	l.Add(Entry{Vendor: "A", Scope: ScopeVendor, Class: ClassMissingFile, Message: "no cached file"})
+
+	var order []string
+	for _, e := range l.Entries() {
+		order = append(order, e.Vendor+" "+e.Scope+" "+e.URL)
+	}
+	want := []string{"A vendor ", "B vendor https://b", "B page https://b/0", "B page https://b/1", "B page https://b/2"}
+	if strings.Join(order, "|") != strings.Join(want, "|") {
+		t.Errorf("order = %q, want %q", order, want)
+	}
+
+	out := Format(l.Entries())
+	for _, line := range []string{
+		"=== ERRORS (5)",
+		"A:\n  ❌ [missing_file] no cached file\n",
+		"B:\n  ❌ [network] scraping: failed fetching page 1: no such host\n",
+		"  ⚠️  [network] https://b/1: connection refused\n",
+		"  ⚠️  [http 503] https://b/2\n",
+	} {
+		if !strings.Contains(out, line) {
+			t.Errorf("Format() missing %q:\n%s", line, out)
+		}
+	}
+	if strings.Contains(out, "https://b/0") {
+		t.Errorf("Format() repeats the page error the vendor error quotes:\n%s", out)
+	}
+}
@@ -0,0 +1,101 @@
+package runs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"longevity-ranker/internal/models"
+	"longevity-ranker/internal/storage"
+)
+
+// Dir is the run archive, relative to the repo root: one file per full
+// pipeline run, named by its run ID. The CI workflow commits only
+// data/*.json, so the archive stays local.
+var Dir = filepath.Join(storage.DataDir, "runs")
+
+// Keep is how many runs Save leaves in the archive, newest first: two
+// months of daily runs.
+const Keep = 60
+
+// ErrNotFound is returned by Load for a run the archive does not hold.
+var ErrNotFound = errors.New("run not found")
+
+// reRunID matches manifest.NewRunID, which Load relies on to keep IDs
+// from naming a path outside the archive.
+var reRunID = regexp.MustCompile(`^\d{8}T\d{6}Z-[0-9a-f]{8}$`)
+
+// Run is the report one run published.
+type Run struct {
+	RunID  string            `json:"run_id"`
+	Date   string            `json:"date"` // YYYY-MM-DD
+	Report []models.Analysis `json:"report"`
+}
+
+// ValidID reports whether id has the form of a run ID.
+func ValidID(id string) bool {
+	return reRunID.MatchString(id)
+}
+
+// Save writes r under dir and returns its path, then deletes the oldest
+// runs beyond keep. Run IDs sort by start time, so the oldest sort first.
+func Save(dir string, r Run, keep int) (string, error) {
+	if !ValidID(r.RunID) {
+		return "", fmt.Errorf("invalid run ID %q", r.RunID)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, r.RunID+".json")
+	if err := storage.SaveJSON(path, r); err != nil {
+		return "", err
+	}
+	ids, err := IDs(dir)
+	if err != nil {
+		return path, err
+	}
+	for i := 0; i < len(ids)-keep; i++ {
+		if err := os.Remove(filepath.Join(dir, ids[i]+".json")); err != nil {
+			return path, err
+		}
+	}
+	return path, nil
+}
+
+// IDs returns the IDs of the runs under dir, oldest first. A missing dir
+// is an empty archive.
+func IDs(dir string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	ids := []string{}
+	for _, path := range paths {
+		if id := strings.TrimSuffix(filepath.Base(path), ".json"); ValidID(id) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// Load reads the run with the given ID from dir. An ID that is malformed
+// or not in the archive returns ErrNotFound.
+func Load(dir, id string) (Run, error) {
+	if !ValidID(id) {
+		return Run{}, fmt.Errorf("%w: %q", ErrNotFound, id)
+	}
+	path := filepath.Join(dir, id+".json")
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return Run{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	r, err := storage.LoadJSON[Run](path)
+	if err != nil {
+		return Run{}, fmt.Errorf("%s: %v", path, err)
+	}
+	return r, nil
+}
@@ -0,0 +1,43 @@
+package runs
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"longevity-ranker/internal/models"
+)
+
+func TestSaveLoadPrune(t *testing.T) {
+	dir := t.TempDir()
+	ids := []string{"20260101T080000Z-00000001", "20260102T080000Z-00000002", "20260103T080000Z-00000003"}
+	for i, id := range ids {
+		r := Run{RunID: id, Date: id[:4] + "-" + id[4:6] + "-" + id[6:8], Report: []models.Analysis{{Vendor: "V", Price: float64(i)}}}
+		if _, err := Save(dir, r, 2); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := IDs(dir)
+	if err != nil || !reflect.DeepEqual(got, ids[1:]) {
+		t.Errorf("IDs() = %v, %v; want the 2 newest %v", got, err, ids[1:])
+	}
+	r, err := Load(dir, ids[2])
+	if err != nil || r.Date != "2026-01-03" || len(r.Report) != 1 || r.Report[0].Price != 2 {
+		t.Errorf("Load(%s) = %+v, %v", ids[2], r, err)
+	}
+	for _, id := range []string{ids[0], "../vendor_rules", "latest"} {
+		if _, err := Load(dir, id); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Load(%q) error = %v, want ErrNotFound", id, err)
+		}
+	}
+	if _, err := Save(dir, Run{RunID: "../escape"}, Keep); err == nil {
+		t.Error("Save accepted a malformed run ID")
+	}
+}
+
+func TestIDsMissingDir(t *testing.T) {
+	if ids, err := IDs(t.TempDir() + "/none"); err != nil || len(ids) != 0 {
+		t.Errorf("IDs(missing) = %v, %v; want empty", ids, err)
+	}
+}
@@ -0,0 +1,117 @@
+package scores
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"longevity-ranker/internal/storage"
+)
+
+// Filename is the quality score table path, relative to the repo root. It is
+// maintained outside this tool (exported from Labdoor, ConsumerLab, or kept
+// by hand); nothing here writes it.
+var Filename = filepath.Join(storage.DataDir, "quality_scores.csv")
+
+// columns are the recognized header names (case-insensitive, any order).
+// brand and score are required.
+var columns = []string{"brand", "product", "score", "source"}
+
+// Score is one row: a 0–100 quality score for a brand's products, or for a
+// single product when Product is set.
+type Score struct {
+	Brand   string // Vendor name as configured, matched case-insensitively
+	Product string // Product handle, or a substring of the product title; "" = every product of the brand
+	Score   float64
+	Source  string // e.g. "Labdoor", "ConsumerLab"
+}
+
+// Table holds every score row in file order.
+type Table []Score
+
+// Load reads a score CSV. A missing file yields an empty table. Any invalid
+// row fails the whole file with its line number.
+func Load(path string) (Table, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Table{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse decodes score CSV data with a header row.
+func Parse(data []byte) (Table, error) {
+	r := csv.NewReader(strings.NewReader(string(data)))
+	r.TrimLeadingSpace = true
+	r.FieldsPerRecord = -1
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return Table{}, nil
+	}
+
+	col := make(map[string]int)
+	for i, h := range rows[0] {
+		col[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, required := range []string{"brand", "score"} {
+		if _, ok := col[required]; !ok {
+			return nil, fmt.Errorf("missing %q column (want %s)", required, strings.Join(columns, ", "))
+		}
+	}
+	field := func(row []string, name string) string {
+		if i, ok := col[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	table := Table{}
+	for n, row := range rows[1:] {
+		line := n + 2
+		s := Score{Brand: field(row, "brand"), Product: field(row, "product"), Source: field(row, "source")}
+		if s.Brand == "" {
+			return nil, fmt.Errorf("line %d: empty brand", line)
+		}
+		s.Score, err = strconv.ParseFloat(field(row, "score"), 64)
+		if err != nil || s.Score <= 0 || s.Score > 100 {
+			return nil, fmt.Errorf("line %d: score %q is not a number in (0, 100]", line, field(row, "score"))
+		}
+		table = append(table, s)
+	}
+	return table, nil
+}
+
+// Lookup returns the score for a product: a row naming the product (its
+// handle exactly, or a case-insensitive substring of its title) beats a
+// brand-wide row. Among rows of the same kind the last in the file wins, so
+// appending a newer export overrides an older one. ok is false when the brand
+// has no row that applies.
+func (t Table) Lookup(vendorName, handle, title string) (s Score, ok bool) {
+	lowerTitle := strings.ToLower(title)
+	var brandWide Score
+	brandOK := false
+	for _, row := range t {
+		if !strings.EqualFold(row.Brand, vendorName) {
+			continue
+		}
+		switch {
+		case row.Product == "":
+			brandWide, brandOK = row, true
+		case row.Product == handle || strings.Contains(lowerTitle, strings.ToLower(row.Product)):
+			s, ok = row, true
+		}
+	}
+	if ok {
+		return s, true
+	}
+	return brandWide, brandOK
+}
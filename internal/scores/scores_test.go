@@ -0,0 +1,53 @@
+package scores
+
+import (
+	"strings"
+	"testing"
+)
+
+const sample = `Brand,Product,Score,Source
+Nutricost,,72,Labdoor
+nutricost,nutricost-creatine,91,Labdoor
+Nutricost,TMG,80,ConsumerLab
+Nutricost,TMG,85,ConsumerLab
+`
+
+func TestLookup(t *testing.T) {
+	table, err := Parse([]byte(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		vendor, handle, title string
+		want                  float64
+		ok                    bool
+	}{
+		{"Nutricost", "nutricost-creatine", "Creatine Monohydrate", 91, true},
+		// Title substring, later row wins
+		{"Nutricost", "betaine", "Betaine Anhydrous (TMG) Powder", 85, true},
+		// Brand-wide fallback, brand matched case-insensitively
+		{"NUTRICOST", "nutricost-nmn", "NMN", 72, true},
+		{"ProHealth", "nmn", "NMN", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := table.Lookup(tt.vendor, tt.handle, tt.title)
+		if ok != tt.ok || got.Score != tt.want {
+			t.Errorf("Lookup(%q, %q) = %v, %v; want %v, %v", tt.vendor, tt.handle, got.Score, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := map[string]string{
+		"product,score\nnmn,80\n":   `missing "brand" column`,
+		"brand,score\n,80\n":        "line 2: empty brand",
+		"brand,score\nAcme,120\n":   `line 2: score "120"`,
+		"brand,score\nAcme,great\n": `line 2: score "great"`,
+	}
+	for input, want := range tests {
+		if _, err := Parse([]byte(input)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Parse(%q) error = %v, want %q", input, err, want)
+		}
+	}
+}
@@ -0,0 +1,348 @@
+package scraper
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"longevity-ranker/internal/models"
+)
+
+// Environment variables holding the Product Advertising API 5.0
+// credentials of an Amazon Associates account. With all three set, amazon
+// vendors are priced through the API instead of their product pages.
+const (
+	PAAPIAccessKeyEnv  = "AMAZON_PAAPI_ACCESS_KEY"
+	PAAPISecretKeyEnv  = "AMAZON_PAAPI_SECRET_KEY"
+	PAAPIPartnerTagEnv = "AMAZON_PAAPI_PARTNER_TAG"
+)
+
+// paapiBatch is the most ItemIds one GetItems request takes.
+const paapiBatch = 10
+
+// paapiRegions maps an Amazon marketplace host to its PA-API host and AWS
+// region (https://webservices.amazon.com/paapi5/documentation/common-request-parameters.html).
+var paapiRegions = map[string][2]string{
+	"www.amazon.com":    {"webservices.amazon.com", "us-east-1"},
+	"www.amazon.ca":     {"webservices.amazon.ca", "us-east-1"},
+	"www.amazon.com.mx": {"webservices.amazon.com.mx", "us-east-1"},
+	"www.amazon.co.uk":  {"webservices.amazon.co.uk", "eu-west-1"},
+	"www.amazon.de":     {"webservices.amazon.de", "eu-west-1"},
+	"www.amazon.fr":     {"webservices.amazon.fr", "eu-west-1"},
+	"www.amazon.it":     {"webservices.amazon.it", "eu-west-1"},
+	"www.amazon.es":     {"webservices.amazon.es", "eu-west-1"},
+	"www.amazon.in":     {"webservices.amazon.in", "eu-west-1"},
+	"www.amazon.co.jp":  {"webservices.amazon.co.jp", "us-west-2"},
+}
+
+// Amazon product page markup. The buy box price is the first a-offscreen
+// price of the core price block; the list price is the struck-through one.
+var (
+	reAmazonTitle     = regexp.MustCompile(`(?s)<span[^>]*id="productTitle"[^>]*>(.*?)</span>`)
+	reAmazonCorePrice = regexp.MustCompile(`(?s)id="corePrice(?:Display_desktop)?_feature_div".*?<span class="a-offscreen">([^<]+)</span>`)
+	reAmazonListPrice = regexp.MustCompile(`(?s)data-a-strike="true"[^>]*>\s*<span class="a-offscreen">([^<]+)</span>`)
+	reAmazonStock     = regexp.MustCompile(`(?s)<div[^>]*id="availability"[^>]*>(.*?)</div>`)
+	reAmazonImage     = regexp.MustCompile(`<img[^>]*\bid="landingImage"[^>]*>`)
+	reAmazonHiRes     = regexp.MustCompile(`\b(?:data-old-hires|src)="([^"]+)"`)
+	reAmazonBullets   = regexp.MustCompile(`(?s)<div[^>]*id="feature-bullets"[^>]*>(.*?)</ul>`)
+)
+
+// FetchAmazonProducts prices the vendor's ASINs on the Amazon marketplace
+// of vendor.URL (e.g. https://www.amazon.com), as one one-variant product
+// per ASIN whose handle is its /dp/ URL, so the ranking shows the Amazon
+// listing of a SKU next to the brand's own store. With PA-API credentials
+// (see PAAPIAccessKeyEnv) it calls GetItems; otherwise, and with
+// DiscoverOnly, it fetches each product page like the page-per-product
+// scrapers.
+func FetchAmazonProducts(vendor models.Vendor) ([]models.Product, error) {
+	marketplace, err := url.Parse(vendor.URL)
+	if err != nil || marketplace.Host == "" {
+		return nil, fmt.Errorf("invalid Amazon marketplace URL %q", vendor.URL)
+	}
+	if len(vendor.ASINs) == 0 {
+		return nil, fmt.Errorf("no asins to price")
+	}
+
+	access, secret, tag := os.Getenv(PAAPIAccessKeyEnv), os.Getenv(PAAPISecretKeyEnv), os.Getenv(PAAPIPartnerTagEnv)
+	if access != "" && secret != "" && tag != "" && !DiscoverOnly {
+		fmt.Printf("🔍 Pricing %d ASIN(s) for %s (PA-API)...\n", len(vendor.ASINs), vendor.Name)
+		products, err := fetchPAAPIProducts(vendor, marketplace, paapiCredentials{access, secret, tag}, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("PA-API: %s", strings.ReplaceAll(err.Error(), secret, "REDACTED"))
+		}
+		return products, nil
+	}
+
+	fmt.Printf("🔍 Crawling %d ASIN(s) for %s (Amazon product pages)...\n", len(vendor.ASINs), vendor.Name)
+	links := make(map[string]bool, len(vendor.ASINs))
+	for _, asin := range vendor.ASINs {
+		links[amazonURL(marketplace, asin)] = true
+	}
+	return crawlPages(vendor, links, parseAmazonPage), nil
+}
+
+// amazonURL is the canonical product page of an ASIN on the marketplace,
+// the product's handle.
+func amazonURL(marketplace *url.URL, asin string) string {
+	return marketplace.Scheme + "://" + marketplace.Host + "/dp/" + asin
+}
+
+// parseAmazonPage reads the product of an Amazon /dp/ page: title, buy box
+// price, struck-through list price, stock, main image and feature bullets
+// (the description the analyzer reads doses from). A page without a buy box
+// price (no new offer, or a robot check) yields nothing.
+func parseAmazonPage(page, link string) []models.Product {
+	if strings.Contains(page, "/errors/validateCaptcha") {
+		fmt.Printf("   ⚠️ Amazon served a robot check for %s; set %s to use PA-API\n", link, PAAPIAccessKeyEnv)
+		return nil
+	}
+	m := reAmazonTitle.FindStringSubmatch(page)
+	if m == nil {
+		return nil
+	}
+	title := strings.TrimSpace(html.UnescapeString(m[1]))
+	m = reAmazonCorePrice.FindStringSubmatch(page)
+	if m == nil {
+		return nil
+	}
+	price, ok := parseDisplayedPrice(m[1])
+	if !ok {
+		return nil
+	}
+
+	variant := models.Variant{Price: fmt.Sprintf("%.2f", price), Title: "Default Title", Available: true}
+	if m := reAmazonListPrice.FindStringSubmatch(page); m != nil {
+		if list, ok := parseDisplayedPrice(m[1]); ok && list > price {
+			variant.CompareAtPrice = fmt.Sprintf("%.2f", list)
+		}
+	}
+	if m := reAmazonStock.FindStringSubmatch(page); m != nil {
+		stock := strings.ToLower(reHTMLTag.ReplaceAllString(m[1], ""))
+		variant.Available = !strings.Contains(stock, "unavailable") && !strings.Contains(stock, "out of stock")
+	}
+
+	p := models.Product{
+		ID:       link[strings.LastIndex(link, "/")+1:],
+		Title:    title,
+		Handle:   link,
+		Variants: []models.Variant{variant},
+	}
+	// The high-resolution image when the tag names one, else its src
+	if img := reAmazonImage.FindString(page); img != "" {
+		for _, m := range reAmazonHiRes.FindAllStringSubmatch(img, -1) {
+			if p.ImageURL = m[1]; strings.HasPrefix(m[0], "data-old-hires") {
+				break
+			}
+		}
+	}
+	if m := reAmazonBullets.FindStringSubmatch(page); m != nil {
+		p.BodyHTML = strings.Join(strings.Fields(html.UnescapeString(reHTMLTag.ReplaceAllString(m[1], " "))), " ")
+	}
+	return []models.Product{p}
+}
+
+// paapiCredentials sign PA-API requests. The secret never leaves the
+// signature.
+type paapiCredentials struct {
+	accessKey, secretKey, partnerTag string
+}
+
+// paapiResponse is the part of a GetItems response the ranking uses.
+type paapiResponse struct {
+	ItemsResult struct {
+		Items []struct {
+			ASIN     string `json:"ASIN"`
+			ItemInfo struct {
+				Title struct {
+					DisplayValue string `json:"DisplayValue"`
+				} `json:"Title"`
+				Features struct {
+					DisplayValues []string `json:"DisplayValues"`
+				} `json:"Features"`
+			} `json:"ItemInfo"`
+			Images struct {
+				Primary struct {
+					Large struct {
+						URL string `json:"URL"`
+					} `json:"Large"`
+				} `json:"Primary"`
+			} `json:"Images"`
+			Offers struct {
+				Listings []struct {
+					Price struct {
+						Amount   float64 `json:"Amount"`
+						Currency string  `json:"Currency"`
+					} `json:"Price"`
+					SavingBasis struct {
+						Amount float64 `json:"Amount"`
+					} `json:"SavingBasis"`
+					Availability struct {
+						Type string `json:"Type"`
+					} `json:"Availability"`
+				} `json:"Listings"`
+			} `json:"Offers"`
+		} `json:"Items"`
+	} `json:"ItemsResult"`
+	Errors []struct {
+		Code    string `json:"Code"`
+		Message string `json:"Message"`
+	} `json:"Errors"`
+}
+
+// paapiResources are the GetItems resources parsePAAPIItems reads.
+var paapiResources = []string{
+	"ItemInfo.Title",
+	"ItemInfo.Features",
+	"Images.Primary.Large",
+	"Offers.Listings.Price",
+	"Offers.Listings.SavingBasis",
+	"Offers.Listings.Availability.Type",
+}
+
+// paapiEndpoint is the GetItems URL of a PA-API host; a variable so tests
+// can point it at a local server.
+var paapiEndpoint = func(host string) string { return "https://" + host + "/paapi5/getitems" }
+
+// fetchPAAPIProducts calls GetItems for the vendor's ASINs, paapiBatch at a
+// time. ASINs the API reports as errors (ItemNotAccessible, invalid) are
+// skipped with a warning; a failed request fails the vendor.
+func fetchPAAPIProducts(vendor models.Vendor, marketplace *url.URL, creds paapiCredentials, now time.Time) ([]models.Product, error) {
+	region, ok := paapiRegions[marketplace.Host]
+	if !ok {
+		return nil, fmt.Errorf("no PA-API region for marketplace %s", marketplace.Host)
+	}
+	var products []models.Product
+	for start := 0; start < len(vendor.ASINs); start += paapiBatch {
+		batch := vendor.ASINs[start:min(start+paapiBatch, len(vendor.ASINs))]
+		body, err := json.Marshal(map[string]any{
+			"ItemIds":     batch,
+			"Resources":   paapiResources,
+			"PartnerTag":  creds.partnerTag,
+			"PartnerType": "Associates",
+			"Marketplace": marketplace.Host,
+		})
+		if err != nil {
+			return nil, err
+		}
+		req, err := newRequest(vendor, http.MethodPost, paapiEndpoint(region[0]), bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Encoding", "amz-1.0")
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+		req.Header.Set("X-Amz-Target", "com.amazon.paapi5.v1.ProductAdvertisingAPIv1.GetItems")
+		signPAAPI(req, body, region[1], creds, now)
+
+		resp, err := do(vendor, req)
+		if err != nil {
+			return nil, err
+		}
+		var decoded paapiResponse
+		err = json.NewDecoder(resp.Body).Decode(&decoded)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("GetItems returned %s: %v", resp.Status, err)
+		}
+		if resp.StatusCode >= 300 {
+			msg := resp.Status
+			if len(decoded.Errors) > 0 {
+				msg = decoded.Errors[0].Code + ": " + decoded.Errors[0].Message
+			}
+			return nil, fmt.Errorf("GetItems: %s", msg)
+		}
+		for _, e := range decoded.Errors {
+			fmt.Printf("   ⚠️ %s: %s\n", e.Code, e.Message)
+		}
+		products = append(products, parsePAAPIItems(decoded, marketplace)...)
+	}
+	fmt.Printf("   -> Priced %d of %d ASIN(s).\n", len(products), len(vendor.ASINs))
+	return products, nil
+}
+
+// parsePAAPIItems turns GetItems results into products like
+// parseAmazonPage's, from each item's first listing (the buy box). Items
+// without a listing are not sold new right now and are skipped.
+func parsePAAPIItems(resp paapiResponse, marketplace *url.URL) []models.Product {
+	var products []models.Product
+	for _, item := range resp.ItemsResult.Items {
+		if len(item.Offers.Listings) == 0 || item.Offers.Listings[0].Price.Amount <= 0 {
+			continue
+		}
+		listing := item.Offers.Listings[0]
+		variant := models.Variant{
+			Price:     fmt.Sprintf("%.2f", listing.Price.Amount),
+			Title:     "Default Title",
+			Available: listing.Availability.Type == "" || listing.Availability.Type == "Now",
+		}
+		if listing.SavingBasis.Amount > listing.Price.Amount {
+			variant.CompareAtPrice = fmt.Sprintf("%.2f", listing.SavingBasis.Amount)
+		}
+		products = append(products, models.Product{
+			ID:       item.ASIN,
+			Title:    item.ItemInfo.Title.DisplayValue,
+			Handle:   amazonURL(marketplace, item.ASIN),
+			BodyHTML: strings.Join(item.ItemInfo.Features.DisplayValues, " "),
+			ImageURL: item.Images.Primary.Large.URL,
+			Currency: listing.Price.Currency,
+			Variants: []models.Variant{variant},
+		})
+	}
+	return products
+}
+
+// signPAAPI adds the AWS Signature Version 4 headers PA-API requires
+// (service ProductAdvertisingAPI), signing the host of req's URL, which the
+// client sends as the Host header.
+func signPAAPI(req *http.Request, body []byte, region string, creds paapiCredentials, now time.Time) {
+	const service = "ProductAdvertisingAPI"
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	signed := []string{"content-encoding", "content-type", "host", "x-amz-date", "x-amz-target"} // Sorted
+	var canonicalHeaders strings.Builder
+	for _, name := range signed {
+		value := req.Header.Get(name)
+		if name == "host" {
+			value = req.URL.Host
+		}
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+	payloadHash := sha256.Sum256(body)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		strings.Join(signed, ";"),
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+creds.secretKey), date)
+	for _, part := range []string{region, service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.accessKey, scope, strings.Join(signed, ";"), signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
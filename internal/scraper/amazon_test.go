@@ -0,0 +1,143 @@
+package scraper
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"longevity-ranker/internal/models"
+)
+
+func TestFetchAmazonProductsPages(t *testing.T) {
+	srv := serveFixtures(t, map[string]string{"/dp/B0CXYZ1234": "amazon_product.html"})
+
+	products, err := FetchAmazonProducts(models.Vendor{
+		Name: "Fixture Amazon", URL: srv.URL, Type: "amazon", ASINs: []string{"B0CXYZ1234", "B0MISSING0"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(products) != 1 {
+		t.Fatalf("products = %d, want the one ASIN with a page: %+v", len(products), products)
+	}
+	p := products[0]
+	if p.ID != "B0CXYZ1234" || p.Handle != srv.URL+"/dp/B0CXYZ1234" ||
+		p.Title != "Example Labs NMN 500mg per Capsule, 60 Capsules & Third-Party Tested" {
+		t.Errorf("product id/handle/title = %q/%q/%q", p.ID, p.Handle, p.Title)
+	}
+	if p.BodyHTML != "500mg NMN per capsule, 60 capsules per bottle 99% purity & third-party tested" {
+		t.Errorf("product.BodyHTML = %q", p.BodyHTML)
+	}
+	if p.ImageURL != "https://m.media-amazon.com/images/I/71large._AC_SL1500_.jpg" {
+		t.Errorf("product image = %q, want the high-resolution one", p.ImageURL)
+	}
+	if len(p.Variants) != 1 {
+		t.Fatalf("variants = %+v, want one", p.Variants)
+	}
+	assertVariant(t, p.Variants[0], models.Variant{Price: "1049.99", CompareAtPrice: "1199.00", Title: "Default Title", Available: true})
+}
+
+func TestParseAmazonPageStock(t *testing.T) {
+	page := `<span id="productTitle">NMN</span>
+<div id="corePrice_feature_div"><span class="a-offscreen">$29.99</span></div>
+<div id="availability"><span>Currently unavailable.</span></div>`
+	products := parseAmazonPage(page, "https://www.amazon.com/dp/B0CXYZ1234")
+	if len(products) != 1 || products[0].Variants[0].Available {
+		t.Errorf("parseAmazonPage(unavailable) = %+v, want one unavailable variant", products)
+	}
+	if got := parseAmazonPage(`<form action="/errors/validateCaptcha">`, "https://www.amazon.com/dp/B0CXYZ1234"); got != nil {
+		t.Errorf("parseAmazonPage(robot check) = %+v, want nothing", got)
+	}
+}
+
+// TestSignPAAPI checks the signature against the AWS SDK's v4 signer for the
+// same request.
+func TestSignPAAPI(t *testing.T) {
+	body := []byte(`{"ItemIds":["B0CXYZ1234"]}`)
+	req, err := http.NewRequest(http.MethodPost, "https://webservices.amazon.com/paapi5/getitems", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Encoding", "amz-1.0")
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("X-Amz-Target", "com.amazon.paapi5.v1.ProductAdvertisingAPIv1.GetItems")
+	signPAAPI(req, body, "us-east-1", paapiCredentials{"AKID", "SECRET", "tag-20"}, time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC))
+
+	want := "AWS4-HMAC-SHA256 Credential=AKID/20261017/us-east-1/ProductAdvertisingAPI/aws4_request, " +
+		"SignedHeaders=content-encoding;content-type;host;x-amz-date;x-amz-target, " +
+		"Signature=df598fbb82a74a667f44e6ee53e006e478e0c73d048ba69d15f95a9fe41664e9"
+	if got := req.Header.Get("Authorization"); got != want || req.Header.Get("X-Amz-Date") != "20261017T120000Z" {
+		t.Errorf("Authorization = %q, want %q", got, want)
+	}
+}
+
+func TestFetchPAAPIProducts(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ItemIds     []string
+			PartnerTag  string
+			Marketplace string
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.PartnerTag != "tag-20" || req.Marketplace != "www.amazon.com" {
+			t.Errorf("GetItems body = %+v, %v", req, err)
+		}
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") {
+			t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+		}
+		mu.Lock()
+		batches = append(batches, req.ItemIds)
+		mu.Unlock()
+		if req.ItemIds[0] != "B000000000" {
+			fmt.Fprint(w, `{"Errors":[{"Code":"ItemNotAccessible","Message":"The ItemId B000000010 is not accessible through the Product Advertising API."}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"ItemsResult":{"Items":[
+			{"ASIN":"B000000000","ItemInfo":{"Title":{"DisplayValue":"NMN 500mg 60 Capsules"},"Features":{"DisplayValues":["500mg per capsule","Third-party tested"]}},
+			 "Images":{"Primary":{"Large":{"URL":"https://m.media-amazon.com/images/I/nmn.jpg"}}},
+			 "Offers":{"Listings":[{"Price":{"Amount":39.95,"Currency":"USD"},"SavingBasis":{"Amount":49.95},"Availability":{"Type":"Now"}}]}},
+			{"ASIN":"B000000001","ItemInfo":{"Title":{"DisplayValue":"NMN Powder 100g"}},
+			 "Offers":{"Listings":[{"Price":{"Amount":89,"Currency":"USD"},"Availability":{"Type":"Backorderable"}}]}},
+			{"ASIN":"B000000002","ItemInfo":{"Title":{"DisplayValue":"No offer"}}}
+		]}}`)
+	}))
+	defer srv.Close()
+	defer func(orig func(string) string) { paapiEndpoint = orig }(paapiEndpoint)
+	paapiEndpoint = func(string) string { return srv.URL + "/paapi5/getitems" }
+
+	vendor := models.Vendor{Name: "Fixture PA-API", URL: "https://www.amazon.com", Type: "amazon"}
+	for i := 0; i < 11; i++ {
+		vendor.ASINs = append(vendor.ASINs, fmt.Sprintf("B0000000%02d", i))
+	}
+	marketplace, _ := url.Parse(vendor.URL)
+	products, err := fetchPAAPIProducts(vendor, marketplace, paapiCredentials{"AKID", "SECRET", "tag-20"}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(batches) != 2 || len(batches[0]) != paapiBatch || len(batches[1]) != 1 {
+		t.Errorf("GetItems batches = %v, want 10 ASINs then 1", batches)
+	}
+	if len(products) != 2 {
+		t.Fatalf("products = %+v, want the two items with a listing", products)
+	}
+	p := products[0]
+	if p.ID != "B000000000" || p.Handle != "https://www.amazon.com/dp/B000000000" || p.Currency != "USD" ||
+		p.BodyHTML != "500mg per capsule Third-party tested" || p.ImageURL != "https://m.media-amazon.com/images/I/nmn.jpg" {
+		t.Errorf("product = %+v", p)
+	}
+	assertVariant(t, p.Variants[0], models.Variant{Price: "39.95", CompareAtPrice: "49.95", Title: "Default Title", Available: true})
+	assertVariant(t, products[1].Variants[0], models.Variant{Price: "89.00", Title: "Default Title", Available: false})
+
+	marketplace, _ = url.Parse("https://www.amazon.com.br")
+	if _, err := fetchPAAPIProducts(vendor, marketplace, paapiCredentials{"AKID", "SECRET", "tag-20"}, time.Now()); err == nil {
+		t.Error("fetchPAAPIProducts(unknown marketplace) = nil error, want one")
+	}
+}
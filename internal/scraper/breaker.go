@@ -0,0 +1,211 @@
+package scraper
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"longevity-ranker/internal/models"
+	"longevity-ranker/internal/runerrors"
+)
+
+// defaultFailureThreshold is the number of consecutive failed requests
+// before the circuit opens, for vendors that leave FailureThreshold unset.
+const defaultFailureThreshold = 5
+
+// defaultMaxRetries is how many times a network error or 5xx response is
+// retried for vendors that leave MaxRetries unset. A negative MaxRetries
+// turns retries off.
+const defaultMaxRetries = 2
+
+// Retry timing. The wait before retry n (from 0) is the vendor's
+// RetryBackoff (retryBackoff when unset) doubled n times and capped at
+// maxRetryBackoff, of which the upper half is random, so a vendor's
+// concurrent requests do not all retry at the same instant. Variables so
+// tests can shorten them.
+var (
+	retryBackoff    = 500 * time.Millisecond
+	maxRetryBackoff = 30 * time.Second
+)
+
+// ErrCircuitOpen is returned for every request to a vendor whose circuit
+// breaker has opened. Scrapers treat it like any other fetch error.
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// breaker counts a vendor's consecutive failed requests. Once the count
+// reaches the vendor's threshold the circuit opens for the rest of the run:
+// a vendor that keeps failing is skipped quickly instead of timing out on
+// every remaining page.
+type breaker struct {
+	mu       sync.Mutex
+	failures int
+	open     bool
+}
+
+var (
+	breakersMu sync.Mutex
+	breakers   = map[string]*breaker{}
+)
+
+func breakerFor(vendorName string) *breaker {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+	b, ok := breakers[vendorName]
+	if !ok {
+		b = &breaker{}
+		breakers[vendorName] = b
+	}
+	return b
+}
+
+func (b *breaker) isOpen() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.open
+}
+
+// record notes a request outcome and reports whether this failure tripped
+// the breaker.
+func (b *breaker) record(ok bool, threshold int) (tripped bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if ok {
+		b.failures = 0
+		return false
+	}
+	b.failures++
+	if !b.open && b.failures >= threshold {
+		b.open = true
+		return true
+	}
+	return false
+}
+
+// failureThreshold returns the vendor's circuit breaker threshold.
+func failureThreshold(vendor models.Vendor) int {
+	if vendor.FailureThreshold > 0 {
+		return vendor.FailureThreshold
+	}
+	return defaultFailureThreshold
+}
+
+// maxRetries returns how many times the vendor's failed requests are retried.
+func maxRetries(vendor models.Vendor) int {
+	switch {
+	case vendor.MaxRetries < 0:
+		return 0
+	case vendor.MaxRetries == 0:
+		return defaultMaxRetries
+	}
+	return vendor.MaxRetries
+}
+
+// retryDelay returns the wait before retry attempt (0 for the first), with
+// jitter. A Retry-After header on the failed response (seconds or HTTP date,
+// as 503s often carry) is honored when it asks for longer, up to
+// maxRetryAfter.
+func retryDelay(vendor models.Vendor, attempt int, resp *http.Response) time.Duration {
+	d := vendor.RetryBackoff
+	if d <= 0 {
+		d = retryBackoff
+	}
+	for i := 0; i < attempt && d < maxRetryBackoff; i++ {
+		d *= 2
+	}
+	d = min(d, maxRetryBackoff)
+	d = d/2 + rand.N(d/2+1)
+	if resp != nil {
+		d = max(d, parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()))
+	}
+	return d
+}
+
+// failed reports whether a request outcome counts against the breaker:
+// network errors, 5xx responses, and 429s that outlasted every retry.
+func failed(resp *http.Response, err error) bool {
+	return err != nil || resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+}
+
+// pageErrors collects every failed request of the run, for data/errors.json.
+var pageErrors runerrors.Log
+
+// PageErrors returns the run's failed requests (network errors, and
+// responses of 400 and above after all retries).
+func PageErrors() []runerrors.Entry {
+	return pageErrors.Entries()
+}
+
+// recordPageError logs the request's final outcome if it failed.
+func recordPageError(vendor models.Vendor, req *http.Request, resp *http.Response, err error) {
+	e := runerrors.Entry{Vendor: vendor.Name, Scope: runerrors.ScopePage, URL: redactAPIKey(vendor, req.URL.String())}
+	switch {
+	case err != nil:
+		e.Message = redactAPIKey(vendor, err.Error())
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) { // The URL is already in the entry
+			e.Message = redactAPIKey(vendor, urlErr.Err.Error())
+		}
+	case resp.StatusCode >= 400:
+		e.Status = resp.StatusCode
+		e.Message = resp.Status
+	default:
+		return
+	}
+	e.Class = runerrors.Classify(err, e.Status)
+	pageErrors.Add(e)
+}
+
+// do is the single request path for all scrapers. It refuses requests once
+// the vendor's circuit is open, requests the host's robots.txt disallows
+// (see checkRobots; logged in PageErrors) and requests past the crawl
+// budget. It retries network errors and 5xx responses up to maxRetries
+// times with exponential backoff (see retryDelay), and feeds the outcome to
+// the breaker. 429 handling happens below, in doThrottled.
+func do(vendor models.Vendor, req *http.Request) (*http.Response, error) {
+	b := breakerFor(vendor.Name)
+	if b.isOpen() {
+		recordMetrics(vendor.Name, func(m *Metrics) { m.Skipped++ })
+		return nil, fmt.Errorf("%w for %s: %s skipped", ErrCircuitOpen, vendor.Name, req.URL)
+	}
+	if err := checkRobots(vendor, req.URL); err != nil {
+		recordMetrics(vendor.Name, func(m *Metrics) { m.Disallowed++ })
+		u := redactAPIKey(vendor, req.URL.String())
+		pageErrors.Add(runerrors.Entry{Vendor: vendor.Name, Scope: runerrors.ScopePage, URL: u, Class: runerrors.ClassDisallowed, Message: err.Error()})
+		return nil, fmt.Errorf("%w: %s skipped", err, u)
+	}
+	if !budgetFor(vendor.Name).spend(vendor.MaxRequests, redactAPIKey(vendor, req.URL.String())) {
+		recordMetrics(vendor.Name, func(m *Metrics) { m.OverBudget++ })
+		return nil, fmt.Errorf("%w for %s (%d requests): %s skipped", ErrBudgetExhausted, vendor.Name, vendor.MaxRequests, redactAPIKey(vendor, req.URL.String()))
+	}
+
+	var (
+		resp *http.Response
+		err  error
+	)
+	for attempt := 0; ; attempt++ {
+		resp, err = doThrottled(vendor, req)
+		retryable := err != nil || resp.StatusCode >= 500
+		if !retryable || attempt >= maxRetries(vendor) {
+			break
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		recordMetrics(vendor.Name, func(m *Metrics) { m.Retries++ })
+		time.Sleep(retryDelay(vendor, attempt, resp))
+	}
+
+	if failed(resp, err) {
+		recordMetrics(vendor.Name, func(m *Metrics) { m.Failures++ })
+	}
+	recordPageError(vendor, req, resp, err)
+	if b.record(!failed(resp, err), failureThreshold(vendor)) {
+		recordMetrics(vendor.Name, func(m *Metrics) { m.Tripped = true })
+		fmt.Printf("   ⛔ %s: %d consecutive failed requests, skipping the rest of this vendor.\n", vendor.Name, failureThreshold(vendor))
+	}
+	return resp, err
+}
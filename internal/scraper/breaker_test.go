@@ -0,0 +1,147 @@
+package scraper
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"longevity-ranker/internal/models"
+	"longevity-ranker/internal/runerrors"
+)
+
+func TestRetriesServerErrors(t *testing.T) {
+	defer func(d time.Duration) { retryBackoff = d }(retryBackoff)
+	retryBackoff = time.Millisecond
+
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	vendor := models.Vendor{Name: "Flaky Vendor", MaxRetries: 2}
+	body, err := FetchBody(vendor, srv.URL)
+	if err != nil || string(body) != "ok" {
+		t.Fatalf("FetchBody() = %q, %v; want ok after one retry", body, err)
+	}
+	if m := VendorMetrics(vendor.Name); m.Retries != 1 || m.Failures != 0 {
+		t.Errorf("metrics = %+v, want 1 retry and no failures", m)
+	}
+}
+
+func TestRetriesByDefault(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	vendor := models.Vendor{Name: "Unavailable Vendor", RetryBackoff: time.Millisecond}
+	if _, err := FetchBody(vendor, srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 1+defaultMaxRetries {
+		t.Errorf("server saw %d requests, want 1 + %d default retries", requests, defaultMaxRetries)
+	}
+	if m := VendorMetrics(vendor.Name); m.Retries != defaultMaxRetries || m.Failures != 1 {
+		t.Errorf("metrics = %+v, want %d retries and 1 failure", m, defaultMaxRetries)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	defer func(d time.Duration) { maxRetryBackoff = d }(maxRetryBackoff)
+	maxRetryBackoff = 4 * time.Second
+
+	vendor := models.Vendor{RetryBackoff: time.Second}
+	for attempt, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second} {
+		for range 20 {
+			if d := retryDelay(vendor, attempt, nil); d < want/2 || d > want {
+				t.Fatalf("retryDelay(attempt %d) = %v, want within [%v, %v]", attempt, d, want/2, want)
+			}
+		}
+	}
+	if d := retryDelay(models.Vendor{}, 0, nil); d < retryBackoff/2 || d > retryBackoff {
+		t.Errorf("retryDelay(no RetryBackoff) = %v, want around the %v default", d, retryBackoff)
+	}
+
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"10"}}}
+	if d := retryDelay(vendor, 0, resp); d != 10*time.Second {
+		t.Errorf("retryDelay(Retry-After: 10) = %v, want 10s", d)
+	}
+}
+
+func TestCircuitBreakerSkipsFailingVendor(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	vendor := models.Vendor{Name: "Down Vendor", FailureThreshold: 3, MaxRetries: -1}
+	for i := 0; i < 10; i++ {
+		FetchBody(vendor, srv.URL)
+	}
+	if requests != 3 {
+		t.Errorf("server saw %d requests, want 3 before the circuit opened", requests)
+	}
+	if _, err := FetchBody(vendor, srv.URL); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("err = %v, want ErrCircuitOpen", err)
+	}
+	if m := VendorMetrics(vendor.Name); !m.Tripped || m.Failures != 3 || m.Skipped != 8 {
+		t.Errorf("metrics = %+v, want tripped, 3 failures, 8 skipped", m)
+	}
+}
+
+func TestVendorTimeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	vendor := models.Vendor{Name: "Slow Vendor", Timeout: 20 * time.Millisecond, MaxRetries: -1}
+	start := time.Now()
+	if _, err := FetchBody(vendor, srv.URL); err == nil {
+		t.Fatal("FetchBody() succeeded against a server that never answers")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("request took %v, want the 20ms vendor timeout", elapsed)
+	}
+}
+
+func TestPageErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gone" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	vendor := models.Vendor{Name: "Page Error Vendor"}
+	FetchBody(vendor, srv.URL+"/ok")
+	FetchBody(vendor, srv.URL+"/gone")
+
+	var got []runerrors.Entry
+	for _, e := range PageErrors() {
+		if e.Vendor == vendor.Name {
+			got = append(got, e)
+		}
+	}
+	want := runerrors.Entry{Vendor: vendor.Name, Scope: runerrors.ScopePage, URL: srv.URL + "/gone",
+		Status: http.StatusNotFound, Class: runerrors.ClassHTTP, Message: "404 Not Found"}
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("page errors = %+v, want only %+v", got, want)
+	}
+}
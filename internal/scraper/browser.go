@@ -0,0 +1,161 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// BrowserPathEnv names the environment variable holding the Chrome or
+// Chromium binary browser fetches run. Unset, chromedp looks for one on the
+// PATH and in the usual install locations.
+const BrowserPathEnv = "CHROME_PATH"
+
+// browserTimeout bounds one browser fetch when the vendor sets no Timeout:
+// a Cloudflare challenge takes a few seconds to clear on top of the page.
+const browserTimeout = 90 * time.Second
+
+// challengePoll is how often a fetch checks whether the challenge cleared.
+const challengePoll = 500 * time.Millisecond
+
+// pageSource reads what the tab shows. Chrome displays a JSON or text
+// response (Shopify's products.json) as a <pre> whose text is the original
+// body; anything else is returned as the rendered HTML.
+const pageSource = `/json|text\/plain/.test(document.contentType) ? document.body.innerText : document.documentElement.outerHTML`
+
+// The run's headless Chrome, started by the first browser fetch. Every tab
+// shares it, so the clearance cookie Cloudflare sets after one challenge
+// serves the rest of the vendor's pages.
+var (
+	browserMu     sync.Mutex
+	browserCtx    context.Context
+	browserCancel context.CancelFunc
+)
+
+// startBrowser returns the context of the run's browser, starting it on
+// first use.
+func startBrowser() (context.Context, error) {
+	browserMu.Lock()
+	defer browserMu.Unlock()
+	if browserCtx != nil {
+		return browserCtx, nil
+	}
+
+	opts := append(chromedp.DefaultExecAllocatorOptions[:], chromedp.UserAgent(userAgent))
+	if path := os.Getenv(BrowserPathEnv); path != "" {
+		opts = append(opts, chromedp.ExecPath(path))
+	}
+	alloc, cancelAlloc := chromedp.NewExecAllocator(context.Background(), opts...)
+	ctx, cancel := chromedp.NewContext(alloc)
+	// Tabs opened from a context whose browser is not running yet would
+	// each start their own browser
+	if err := chromedp.Run(ctx); err != nil {
+		cancel()
+		cancelAlloc()
+		return nil, fmt.Errorf("could not start headless Chrome (set %s to its binary): %w", BrowserPathEnv, err)
+	}
+	browserCtx = ctx
+	browserCancel = func() { cancel(); cancelAlloc() }
+	return browserCtx, nil
+}
+
+// CloseBrowser stops the headless Chrome, if a browser fetch started one.
+func CloseBrowser() {
+	browserMu.Lock()
+	defer browserMu.Unlock()
+	if browserCancel != nil {
+		browserCancel()
+		browserCtx, browserCancel = nil, nil
+	}
+}
+
+// isChallenge reports whether a page title is Cloudflare's JavaScript
+// challenge, which redirects to the page once the browser passes it. A hard
+// block ("Attention Required!") never clears and is returned as it is.
+func isChallenge(title string) bool {
+	return strings.HasPrefix(title, "Just a moment") || strings.HasPrefix(title, "Checking your browser")
+}
+
+// browserTransport is the http.RoundTripper of Browser vendors (see
+// ClientFor): each GET opens the URL in a tab of the run's headless Chrome,
+// waits out a Cloudflare challenge, and returns what the tab shows. The
+// breaker, budget and 429 handling around it are the same as for plain
+// requests. Other methods are refused, so a vendor with CartPricing keeps
+// its listed prices.
+type browserTransport struct{}
+
+func (browserTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return nil, fmt.Errorf("browser fetch supports GET only, not %s %s", req.Method, req.URL)
+	}
+	browser, err := startBrowser()
+	if err != nil {
+		return nil, err
+	}
+	tab, cancel := chromedp.NewContext(browser)
+	defer cancel()
+	stop := context.AfterFunc(req.Context(), cancel) // The client's Timeout
+	defer stop()
+
+	headers := network.Headers{}
+	for name, values := range req.Header {
+		headers[name] = strings.Join(values, ", ")
+	}
+	nav, err := chromedp.RunResponse(tab,
+		network.Enable(),
+		network.SetExtraHTTPHeaders(headers),
+		chromedp.Navigate(req.URL.String()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("browser fetch %s: %w", req.URL, err)
+	}
+	status := int(nav.Status)
+	header := http.Header{}
+	for name, value := range nav.Headers {
+		header.Set(name, fmt.Sprint(value))
+	}
+
+	for {
+		var title string
+		// The challenge reloads the tab, which can fail a read mid-way
+		if err := chromedp.Run(tab, chromedp.Title(&title)); err == nil && !isChallenge(title) {
+			break
+		}
+		select {
+		case <-tab.Done():
+			return nil, fmt.Errorf("browser fetch %s: Cloudflare challenge did not clear: %w", req.URL, tab.Err())
+		case <-time.After(challengePoll):
+		}
+		// The page behind a cleared challenge is the one asked for
+		status, header = http.StatusOK, http.Header{}
+	}
+
+	var contentType, body string
+	if err := chromedp.Run(tab,
+		chromedp.WaitReady("body", chromedp.ByQuery),
+		chromedp.Evaluate(`document.contentType`, &contentType),
+		chromedp.Evaluate(pageSource, &body),
+	); err != nil {
+		return nil, fmt.Errorf("browser fetch %s: %w", req.URL, err)
+	}
+	header.Set("Content-Type", contentType)
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
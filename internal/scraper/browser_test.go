@@ -0,0 +1,86 @@
+package scraper
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"longevity-ranker/internal/models"
+)
+
+func TestIsChallenge(t *testing.T) {
+	for title, want := range map[string]bool{
+		"Just a moment...":                    true,
+		"Checking your browser before access": true,
+		"Attention Required! | Cloudflare":    false,
+		"Wonderfeel Youngr NMN":               false,
+		"":                                    false,
+	} {
+		if got := isChallenge(title); got != want {
+			t.Errorf("isChallenge(%q) = %v, want %v", title, got, want)
+		}
+	}
+}
+
+func TestBrowserClient(t *testing.T) {
+	c := ClientFor(models.Vendor{Name: "Browser Vendor", Browser: true})
+	if _, ok := c.Transport.(browserTransport); !ok || c.Timeout != browserTimeout {
+		t.Fatalf("ClientFor(Browser) = transport %T, timeout %v; want browserTransport, %v", c.Transport, c.Timeout, browserTimeout)
+	}
+	// Carts need POSTs, which a page load cannot send
+	req, err := newRequest(models.Vendor{}, "POST", "https://example.com/cart/add.js", strings.NewReader("id=1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Transport.RoundTrip(req); err == nil || !strings.Contains(err.Error(), "GET only") {
+		t.Errorf("POST through the browser: err = %v, want a GET-only error", err)
+	}
+}
+
+// TestBrowserFetch runs a real headless Chrome; it is skipped where none
+// is installed.
+func TestBrowserFetch(t *testing.T) {
+	if os.Getenv(BrowserPathEnv) == "" {
+		found := false
+		for _, name := range []string{"google-chrome", "chromium", "chromium-browser", "headless-shell"} {
+			if _, err := exec.LookPath(name); err == nil {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Skip("no Chrome or Chromium installed")
+		}
+	}
+	defer CloseBrowser()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/products.json" {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"products":[{"handle":"nmn","currency":%q}]}`, r.Header.Get("X-Currency"))
+			return
+		}
+		fmt.Fprint(w, `<html><head><title>NMN</title></head><body><h1>NMN 500mg</h1></body></html>`)
+	}))
+	defer srv.Close()
+
+	vendor := models.Vendor{Name: "Browser Fetch Vendor", Browser: true, Headers: map[string]string{"X-Currency": "USD"}}
+	body, err := FetchBody(vendor, srv.URL+"/products.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.TrimSpace(string(body)), `{"products":[{"handle":"nmn","currency":"USD"}]}`; got != want {
+		t.Errorf("JSON body = %q, want %q", got, want)
+	}
+	body, err = FetchBody(vendor, srv.URL+"/nmn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(body), "<h1>NMN 500mg</h1>") {
+		t.Errorf("HTML body = %q, want the rendered page", body)
+	}
+}
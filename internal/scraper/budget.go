@@ -0,0 +1,215 @@
+package scraper
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"longevity-ranker/internal/models"
+	"longevity-ranker/internal/storage"
+)
+
+// ErrBudgetExhausted is returned for every request to a vendor once it has
+// sent vendor.MaxRequests requests this run. Refused URLs are listed by
+// BudgetSkipped.
+var ErrBudgetExhausted = errors.New("crawl budget exhausted")
+
+// budget counts the requests sent to a vendor and the URLs it refused once
+// the vendor's MaxRequests was reached. Retries and 429 re-sends belong to
+// the request they repeat and are not counted.
+type budget struct {
+	mu      sync.Mutex
+	used    int
+	skipped []string
+}
+
+var (
+	budgetsMu sync.Mutex
+	budgets   = map[string]*budget{}
+)
+
+func budgetFor(vendorName string) *budget {
+	budgetsMu.Lock()
+	defer budgetsMu.Unlock()
+	b, ok := budgets[vendorName]
+	if !ok {
+		b = &budget{}
+		budgets[vendorName] = b
+	}
+	return b
+}
+
+// spend takes one request from the budget. Once limit requests were sent
+// (limit 0 means unlimited) it records url as skipped and returns false.
+func (b *budget) spend(limit int, url string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if limit > 0 && b.used >= limit {
+		b.skipped = append(b.skipped, url)
+		return false
+	}
+	b.used++
+	return true
+}
+
+// skip records urls the scraper will not request because the budget is
+// spent.
+func (b *budget) skip(urls ...string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.skipped = append(b.skipped, urls...)
+}
+
+// BudgetSkipped returns the URLs not requested because the vendor's crawl
+// budget was spent, in crawl order (refusals racing in concurrent workers
+// may swap places). Nil when the budget sufficed.
+func BudgetSkipped(vendorName string) []string {
+	b := budgetFor(vendorName)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.skipped...)
+}
+
+// crawlPages fetches the product page links of a page-per-product vendor and
+// parses each one (see fetchPages for concurrency and pacing). Products are
+// kept in sortedLinks order whatever order the pages arrive in, so the saved
+// file is stable. With a crawl budget, product pages already in the vendor's
+// cached data/<vendor>.json go first, so known products stay fresh and new
+// ones fill whatever budget is left; once the budget is spent the remaining
+// links are skipped and their cached products, if any, kept. Parsed pages
+// are recorded in the vendor's checkpoint (see CheckpointDir); with Resume
+// the pages it already holds are not fetched again. With DiscoverOnly the
+// links are recorded in crawl order and nothing is fetched.
+func crawlPages(vendor models.Vendor, links map[string]bool, parse func(html, link string) []models.Product) []models.Product {
+	ordered := sortedLinks(links)
+	var cached map[string][]models.Product
+	if vendor.MaxRequests > 0 {
+		cached = cachedPages(vendor.Name)
+		ordered = knownFirst(ordered, cached)
+	}
+	if DiscoverOnly {
+		recordDiscovered(vendor.Name, ordered)
+		return nil
+	}
+
+	cp := openCheckpoint(vendor)
+	todo := cp.remaining(ordered)
+	if note := cp.resumeNote(ordered, todo); note != "" {
+		fmt.Println(note)
+	}
+	pages, errs := fetchPages(vendor, todo, parse, cp)
+	fetched := make(map[string]int, len(todo))
+	for i, link := range todo {
+		fetched[link] = i
+	}
+
+	var products []models.Product
+	skipped, kept, failed := 0, 0, 0
+	for _, link := range ordered {
+		i, ok := fetched[link]
+		if !ok {
+			products = append(products, cp.products(link)...)
+			continue
+		}
+		if errs[i] != nil && !errors.Is(errs[i], ErrBudgetExhausted) {
+			failed++
+		}
+		if errors.Is(errs[i], ErrBudgetExhausted) {
+			skipped++
+			if len(cached[link]) > 0 {
+				kept++
+			}
+			products = append(products, cached[link]...)
+			continue
+		}
+		products = append(products, pages[i]...)
+	}
+	if skipped > 0 {
+		fmt.Printf("   ⏸️  Crawl budget of %d requests spent: skipped %d product page(s), kept %d from cache.\n",
+			vendor.MaxRequests, skipped, kept)
+	}
+	cp.finish(failed == 0)
+	return products
+}
+
+// fetchPages fetches links with up to vendor.Concurrency requests in flight
+// (at least one) and parses each page; pages[i] and errs[i] belong to
+// links[i]. Requests are paced by the vendor's token bucket at RateLimit
+// (defaultPageRate when unset), so more workers only fill the gaps a slow
+// response leaves. Once a request is refused for the crawl budget, links
+// not yet started are not requested: they are recorded as skipped and get
+// ErrBudgetExhausted too. Each parsed page is recorded in cp, which may be
+// nil.
+func fetchPages(vendor models.Vendor, links []string, parse func(html, link string) []models.Product, cp *checkpoint) ([][]models.Product, []error) {
+	if vendor.RateLimit <= 0 {
+		vendor.RateLimit = defaultPageRate
+	}
+	pages := make([][]models.Product, len(links))
+	errs := make([]error, len(links))
+
+	var spent atomic.Bool
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range max(vendor.Concurrency, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				body, err := FetchBody(vendor, links[i])
+				if err != nil {
+					errs[i] = err
+					if errors.Is(err, ErrBudgetExhausted) {
+						spent.Store(true)
+					}
+					continue
+				}
+				pages[i] = parse(string(body), links[i])
+				cp.record(links[i], pages[i])
+			}
+		}()
+	}
+	sent := 0
+	for ; sent < len(links) && !spent.Load(); sent++ {
+		jobs <- sent
+	}
+	close(jobs)
+	wg.Wait()
+
+	if sent < len(links) {
+		budgetFor(vendor.Name).skip(links[sent:]...)
+		for i := sent; i < len(links); i++ {
+			errs[i] = fmt.Errorf("%w for %s (%d requests): %s skipped", ErrBudgetExhausted, vendor.Name, vendor.MaxRequests, links[i])
+		}
+	}
+	return pages, errs
+}
+
+// knownFirst moves the links that have cached products to the front, keeping
+// the order within both groups.
+func knownFirst(links []string, cached map[string][]models.Product) []string {
+	ordered := make([]string, 0, len(links))
+	var fresh []string
+	for _, link := range links {
+		if len(cached[link]) > 0 {
+			ordered = append(ordered, link)
+		} else {
+			fresh = append(fresh, link)
+		}
+	}
+	return append(ordered, fresh...)
+}
+
+// cachedPages groups the vendor's cached products by handle (their product
+// page URL). Empty when there is no readable cache.
+func cachedPages(vendorName string) map[string][]models.Product {
+	products, err := storage.LoadJSON[[]models.Product](storage.VendorFilename(vendorName))
+	if err != nil {
+		return nil
+	}
+	pages := make(map[string][]models.Product)
+	for _, p := range products {
+		pages[p.Handle] = append(pages[p.Handle], p)
+	}
+	return pages
+}
@@ -0,0 +1,153 @@
+package scraper
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"longevity-ranker/internal/models"
+)
+
+func TestCrawlBudget(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	vendor := models.Vendor{Name: "Budget Vendor", MaxRequests: 2}
+	for _, path := range []string{"/a", "/b", "/c", "/d"} {
+		_, err := FetchBody(vendor, srv.URL+path)
+		if over := errors.Is(err, ErrBudgetExhausted); over != (path == "/c" || path == "/d") {
+			t.Errorf("FetchBody(%s) err = %v", path, err)
+		}
+	}
+	if requests != 2 {
+		t.Errorf("server saw %d requests, want the budget of 2", requests)
+	}
+	if got, want := BudgetSkipped(vendor.Name), []string{srv.URL + "/c", srv.URL + "/d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("BudgetSkipped() = %v, want %v", got, want)
+	}
+	if m := VendorMetrics(vendor.Name); m.OverBudget != 2 || m.Tripped {
+		t.Errorf("metrics = %+v, want 2 over budget and the breaker closed", m)
+	}
+	if got := BudgetSkipped("Unlimited Vendor"); got != nil {
+		t.Errorf("BudgetSkipped(unknown vendor) = %v, want nil", got)
+	}
+}
+
+func TestCrawlBudgetSkipsProductPages(t *testing.T) {
+	srv := serveFixtures(t, map[string]string{
+		"/products/": "magento_category.html",
+		"/pure-nmn":  "magento_product.html",
+	})
+
+	// The category page spends the budget; the product page is skipped
+	vendor := models.Vendor{Name: "Budget Magento", URL: srv.URL + "/products/", Type: "magento", MaxRequests: 1}
+	products, err := FetchMagentoProducts(vendor)
+	if err != nil || len(products) != 0 {
+		t.Errorf("FetchMagentoProducts() = %d products, %v; want none within a budget of 1", len(products), err)
+	}
+	if got, want := BudgetSkipped(vendor.Name), []string{srv.URL + "/pure-nmn"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("BudgetSkipped() = %v, want %v", got, want)
+	}
+}
+
+func TestKnownFirst(t *testing.T) {
+	cached := map[string][]models.Product{
+		"https://shop.test/d": {{ID: "d"}},
+		"https://shop.test/b": {{ID: "b"}},
+	}
+	links := []string{"https://shop.test/a", "https://shop.test/b", "https://shop.test/c", "https://shop.test/d"}
+	want := []string{"https://shop.test/b", "https://shop.test/d", "https://shop.test/a", "https://shop.test/c"}
+	if got := knownFirst(links, cached); !reflect.DeepEqual(got, want) {
+		t.Errorf("knownFirst() = %v, want %v", got, want)
+	}
+}
+
+func TestFetchPagesConcurrently(t *testing.T) {
+	var mu sync.Mutex
+	var inFlight, peak int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		peak = max(peak, inFlight)
+		mu.Unlock()
+		time.Sleep(60 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		w.Write([]byte(r.URL.Path))
+	}))
+	defer srv.Close()
+
+	var links []string
+	for i := range 8 {
+		links = append(links, fmt.Sprintf("%s/p%d", srv.URL, i))
+	}
+	vendor := models.Vendor{Name: "Concurrent Vendor", Concurrency: 3, RateLimit: 1000}
+	pages, errs := fetchPages(vendor, links, func(html, link string) []models.Product {
+		return []models.Product{{ID: html, Handle: link}}
+	}, nil)
+	for i, link := range links {
+		if errs[i] != nil || len(pages[i]) != 1 || pages[i][0].Handle != link || pages[i][0].ID != fmt.Sprintf("/p%d", i) {
+			t.Errorf("page %d = %+v, %v; want the page of %s", i, pages[i], errs[i], link)
+		}
+	}
+	if peak < 2 || peak > 3 {
+		t.Errorf("peak requests in flight = %d, want 2 or 3 with a concurrency of 3", peak)
+	}
+}
+
+func TestFetchPagesDefaultRate(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	var links []string
+	for i := range 5 {
+		links = append(links, fmt.Sprintf("%s/p%d", srv.URL, i))
+	}
+	// Without a RateLimit the bucket holds 3 requests, then refills every 300ms
+	start := time.Now()
+	fetchPages(models.Vendor{Name: "Default Rate", Concurrency: 5}, links, func(html, link string) []models.Product { return nil }, nil)
+	if d := time.Since(start); d < 400*time.Millisecond {
+		t.Errorf("5 pages at the default rate took %v, want about 500ms", d)
+	}
+}
+
+func TestFetchPagesStopsAtBudget(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	var links []string
+	for i := range 6 {
+		links = append(links, fmt.Sprintf("%s/p%d", srv.URL, i))
+	}
+	vendor := models.Vendor{Name: "Concurrent Budget", MaxRequests: 2, Concurrency: 3, RateLimit: 1000}
+	_, errs := fetchPages(vendor, links, func(html, link string) []models.Product { return nil }, nil)
+	over := 0
+	for _, err := range errs {
+		if errors.Is(err, ErrBudgetExhausted) {
+			over++
+		}
+	}
+	if requests.Load() != 2 || over != 4 {
+		t.Errorf("server saw %d requests and %d pages were over budget, want 2 and 4", requests.Load(), over)
+	}
+	if got := BudgetSkipped(vendor.Name); len(got) != 4 {
+		t.Errorf("BudgetSkipped() = %v, want the 4 pages past the budget", got)
+	}
+}
@@ -0,0 +1,83 @@
+package scraper
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+)
+
+// dropParams are query parameters that never select a different product:
+// click IDs, mailing-list and referral tags, storefront search markers, and
+// "variant", which only preselects a variant on the same product page.
+// Parameters starting with "utm_" are dropped too.
+var dropParams = map[string]bool{
+	"gclid": true, "gbraid": true, "wbraid": true, "fbclid": true, "msclkid": true,
+	"mc_cid": true, "mc_eid": true, "_ga": true, "_gl": true, "srsltid": true,
+	"ref": true, "_pos": true, "_sid": true, "_ss": true, "_kx": true,
+	"variant": true,
+}
+
+// canonicalURL normalizes a product link so one page is fetched and analyzed
+// under one URL: HTML entities ("&amp;") decoded, lowercase scheme and host, no fragment, no dropParams, and
+// the remaining query parameters sorted. The trailing slash is kept as
+// linked; canonicalLinks collapses the two forms. Unparseable links are
+// returned unchanged.
+func canonicalURL(link string) string {
+	u, err := url.Parse(html.UnescapeString(link))
+	if err != nil {
+		return link
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	u.Fragment, u.RawFragment = "", ""
+	if u.RawQuery != "" {
+		q := u.Query()
+		for name := range q {
+			if dropParams[strings.ToLower(name)] || strings.HasPrefix(strings.ToLower(name), "utm_") {
+				q.Del(name)
+			}
+		}
+		u.RawQuery = q.Encode()
+	}
+	return u.String()
+}
+
+// canonicalLinks canonicalizes a crawl's product links and collapses those
+// that differ only by a trailing slash, keeping the form without it. It
+// returns the links and how many duplicates were collapsed.
+func canonicalLinks(links map[string]bool) (map[string]bool, int) {
+	byKey := make(map[string]string, len(links))
+	for link := range links {
+		link = canonicalURL(link)
+		key := slashless(link)
+		if kept, ok := byKey[key]; !ok || len(link) < len(kept) {
+			byKey[key] = link
+		}
+	}
+	canonical := make(map[string]bool, len(byKey))
+	for _, link := range byKey {
+		canonical[link] = true
+	}
+	return canonical, len(links) - len(canonical)
+}
+
+// slashless drops a trailing slash from the link's path, except a bare "/".
+func slashless(link string) string {
+	u, err := url.Parse(link)
+	if err != nil || len(u.Path) <= 1 {
+		return link
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	u.RawPath = ""
+	return u.String()
+}
+
+// collapsedNote describes collapsed duplicate links for the crawl's "Found"
+// line; "" when there were none.
+func collapsedNote(collapsed int) string {
+	if collapsed == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%d duplicate URL(s) collapsed)", collapsed)
+}
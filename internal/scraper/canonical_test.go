@@ -0,0 +1,61 @@
+package scraper
+
+import (
+	"testing"
+
+	"longevity-ranker/internal/models"
+)
+
+func TestCanonicalURL(t *testing.T) {
+	tests := []struct{ link, want string }{
+		{"https://shop.test/pure-nmn", "https://shop.test/pure-nmn"},
+		{"https://Shop.TEST/pure-nmn?utm_source=mail&UTM_Campaign=x&gclid=1", "https://shop.test/pure-nmn"},
+		{"https://shop.test/pure-nmn?variant=102", "https://shop.test/pure-nmn"},
+		{"https://shop.test/pure-nmn?size=60&amp;utm_medium=email", "https://shop.test/pure-nmn?size=60"},
+		{"https://shop.test/pure-nmn#reviews", "https://shop.test/pure-nmn"},
+		{"https://shop.test/product/nmn/", "https://shop.test/product/nmn/"},
+		// Parameters that may select the product are kept, sorted
+		{"https://shop.test/index.php?route=product&id=7&fbclid=2", "https://shop.test/index.php?id=7&route=product"},
+	}
+	for _, tt := range tests {
+		if got := canonicalURL(tt.link); got != tt.want {
+			t.Errorf("canonicalURL(%q) = %q, want %q", tt.link, got, tt.want)
+		}
+	}
+}
+
+func TestCanonicalLinks(t *testing.T) {
+	links, collapsed := canonicalLinks(map[string]bool{
+		"https://shop.test/pure-nmn/":                true,
+		"https://shop.test/pure-nmn?ref=home":        true,
+		"https://shop.test/product/nr/":              true,
+		"https://shop.test/product/nr/?_pos=1&_ss=r": true,
+		"https://shop.test/":                         true,
+	})
+	want := []string{"https://shop.test/", "https://shop.test/product/nr/", "https://shop.test/pure-nmn"}
+	if got := sortedLinks(links); len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Errorf("canonicalLinks() = %v, want %v", got, want)
+	}
+	if collapsed != 2 {
+		t.Errorf("collapsed = %d, want 2", collapsed)
+	}
+}
+
+func TestFetchMagentoProductsCanonicalLinks(t *testing.T) {
+	srv := serveFixtures(t, map[string]string{
+		"/products/": "magento_category_tracking.html",
+		"/pure-nmn":  "magento_product.html",
+	})
+
+	// Three links to /pure-nmn (tracking tags, a trailing slash, ?variant=)
+	// are one page: fetched once, its handle without the query string
+	products, err := FetchMagentoProducts(models.Vendor{Name: "Fixture Magento", URL: srv.URL + "/products/", Type: "magento"})
+	if err != nil || len(products) != 1 {
+		t.Fatalf("FetchMagentoProducts() = %d products, %v; want /pure-nmn once", len(products), err)
+	}
+	for _, p := range products {
+		if p.Handle != srv.URL+"/pure-nmn" {
+			t.Errorf("handle = %q, want %q", p.Handle, srv.URL+"/pure-nmn")
+		}
+	}
+}
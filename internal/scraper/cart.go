@@ -0,0 +1,150 @@
+package scraper
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"longevity-ranker/internal/models"
+)
+
+// shopifyCart is the part of Shopify's /cart.js response the simulation
+// reads. Amounts are in cents of the cart currency; TotalPrice includes
+// cart-level discounts, which line prices do not.
+type shopifyCart struct {
+	Currency   string `json:"currency"`
+	TotalPrice int64  `json:"total_price"`
+	Items      []struct {
+		VariantID int64 `json:"variant_id"`
+		Quantity  int   `json:"quantity"`
+	} `json:"items"`
+}
+
+// cartSession replays the cookies the store sets (the cart token) on the
+// requests of one simulation. Vendors with PersistCookies already keep
+// them in their client's jar.
+type cartSession struct {
+	vendor  models.Vendor
+	cookies map[string]*http.Cookie
+}
+
+// send posts form (or GETs when form is nil) to rawURL and returns the
+// response body. 4xx and 5xx responses are errors carrying the status.
+func (s *cartSession) send(rawURL string, form url.Values) ([]byte, int, error) {
+	method, body := "GET", io.Reader(nil)
+	if form != nil {
+		method, body = "POST", strings.NewReader(form.Encode())
+	}
+	req, err := newRequest(s.vendor, method, rawURL, body)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	for _, c := range s.cookies {
+		req.AddCookie(c)
+	}
+
+	resp, err := do(s.vendor, req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	if ClientFor(s.vendor).Jar == nil {
+		for _, c := range resp.Cookies() {
+			s.cookies[c.Name] = c
+		}
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, err
+	}
+	if resp.StatusCode >= 400 {
+		return nil, resp.StatusCode, fmt.Errorf("HTTP %d from %s", resp.StatusCode, rawURL)
+	}
+	return data, resp.StatusCode, nil
+}
+
+// cartPrice empties the cart, adds qty of the variant and returns what the
+// cart charges per unit, formatted like a listed price.
+func (s *cartSession) cartPrice(base *url.URL, variantID string, qty int) (string, int, error) {
+	endpoint := func(path string) string {
+		return (&url.URL{Scheme: base.Scheme, Host: base.Host, Path: marketPath(s.vendor, path)}).String()
+	}
+	if _, status, err := s.send(endpoint("/cart/clear.js"), url.Values{}); err != nil {
+		return "", status, err
+	}
+	add := url.Values{"id": {variantID}, "quantity": {strconv.Itoa(qty)}}
+	if _, status, err := s.send(endpoint("/cart/add.js"), add); err != nil {
+		return "", status, err
+	}
+	body, status, err := s.send(endpoint("/cart.js"), nil)
+	if err != nil {
+		return "", status, err
+	}
+
+	var cart shopifyCart
+	if err := json.Unmarshal(body, &cart); err != nil {
+		return "", status, fmt.Errorf("invalid cart: %v", err)
+	}
+	want := s.vendor.Currency
+	if want == "" {
+		want = "USD"
+	}
+	if cart.Currency != "" && !strings.EqualFold(cart.Currency, want) {
+		return "", status, fmt.Errorf("cart is in %s, vendor prices are in %s", cart.Currency, want)
+	}
+	id, _ := strconv.ParseInt(variantID, 10, 64)
+	if len(cart.Items) != 1 || cart.Items[0].VariantID != id || cart.Items[0].Quantity != qty {
+		return "", status, fmt.Errorf("cart does not hold exactly %d × variant %s", qty, variantID)
+	}
+	return strconv.FormatFloat(float64(cart.TotalPrice)/100/float64(qty), 'f', 2, 64), status, nil
+}
+
+// simulateShopifyCarts sets CartPrice on every available variant of
+// products that has a store ID: the variant alone in an emptied cart, at its
+// minimum order quantity. A variant the store refuses to add (HTTP 422:
+// sold out, quantity limits) is skipped; any other failure ends the
+// simulation for the vendor, leaving the listed prices in place.
+func simulateShopifyCarts(vendor models.Vendor, products []models.Product) {
+	base, err := url.Parse(vendor.URL)
+	if err != nil {
+		return
+	}
+	s := &cartSession{vendor: vendor, cookies: map[string]*http.Cookie{}}
+	simulated, discounted := 0, 0
+	for i := range products {
+		for j := range products[i].Variants {
+			v := &products[i].Variants[j]
+			if !v.Available || v.ID == "" {
+				continue
+			}
+			price, status, err := s.cartPrice(base, v.ID, max(v.MinOrderQty, 1))
+			if status == http.StatusUnprocessableEntity {
+				continue
+			}
+			if err != nil {
+				if !errors.Is(err, ErrBudgetExhausted) && !errors.Is(err, ErrCircuitOpen) {
+					fmt.Printf("   ⚠️  Cart simulation failed for %s (%s): %v\n", products[i].Title, v.Title, err)
+				}
+				fmt.Printf("   🛒 %s: cart prices for %d variant(s), %d below the listed price; stopped early.\n", vendor.Name, simulated, discounted)
+				return
+			}
+			v.CartPrice = price
+			simulated++
+			if listed, err := strconv.ParseFloat(v.Price, 64); err == nil {
+				if cart, _ := strconv.ParseFloat(price, 64); cart < listed {
+					discounted++
+				}
+			}
+		}
+	}
+	fmt.Printf("   🛒 %s: cart prices for %d variant(s), %d below the listed price.\n", vendor.Name, simulated, discounted)
+}
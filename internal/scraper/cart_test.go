@@ -0,0 +1,127 @@
+package scraper
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"longevity-ranker/internal/models"
+)
+
+func TestShopifyCartPricing(t *testing.T) {
+	// A store whose cart takes 15% off orders of two or more units, keeps
+	// carts by cookie and refuses variant 3 (sold out)
+	var mu sync.Mutex
+	carts := map[string]map[int64]int{} // cart token → variant ID → quantity
+	var posts []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.URL.Path == "/products.json" {
+			if r.URL.Query().Get("page") != "1" {
+				w.Write([]byte(`{"products": []}`))
+				return
+			}
+			w.Write([]byte(`{"products": [{"id": 10, "title": "NMN 500mg", "handle": "nmn", "variants": [
+				{"id": 1, "title": "1 Bottle", "price": "40.00", "available": true},
+				{"id": 2, "title": "2 Bottles", "price": "40.00", "available": true},
+				{"id": 3, "title": "3 Bottles", "price": "38.00", "available": true},
+				{"id": 4, "title": "6 Bottles", "price": "35.00", "available": false}
+			]}]}`))
+			return
+		}
+
+		token := ""
+		if c, err := r.Cookie("cart"); err == nil {
+			token = c.Value
+		} else {
+			token = strconv.Itoa(len(carts) + 1)
+			carts[token] = map[int64]int{}
+			http.SetCookie(w, &http.Cookie{Name: "cart", Value: token, Path: "/"})
+		}
+		cart := carts[token]
+		if r.Method == http.MethodPost {
+			posts = append(posts, r.URL.Path)
+		}
+		switch r.URL.Path {
+		case "/cart/clear.js":
+			clear(cart)
+			w.Write([]byte(`{"items": []}`))
+		case "/cart/add.js":
+			id, _ := strconv.ParseInt(r.FormValue("id"), 10, 64)
+			if id == 3 {
+				http.Error(w, `{"status": 422, "description": "sold out"}`, http.StatusUnprocessableEntity)
+				return
+			}
+			qty, _ := strconv.Atoi(r.FormValue("quantity"))
+			cart[id] += qty
+			w.Write([]byte(`{}`))
+		case "/cart.js":
+			total, items := 0.0, ""
+			for id, qty := range cart {
+				total += 4000 * float64(qty)
+				if items != "" {
+					items += ","
+				}
+				items += fmt.Sprintf(`{"variant_id": %d, "quantity": %d}`, id, qty)
+				if qty >= 2 {
+					total *= 0.85
+				}
+			}
+			fmt.Fprintf(w, `{"currency": "USD", "total_price": %.0f, "items": [%s]}`, total, items)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	vendor := models.Vendor{Name: "Cart Shop", URL: srv.URL + "/products.json", Type: "shopify", CartPricing: true}
+	products, err := FetchShopifyProducts(vendor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(products) != 1 || len(products[0].Variants) != 4 {
+		t.Fatalf("products = %+v, want one product with 4 variants", products)
+	}
+
+	// Variant 2's minimum order of two reaches the discount tier
+	products[0].Variants[1].MinOrderQty = 2
+	simulateShopifyCarts(vendor, products)
+
+	var got []string
+	for _, v := range products[0].Variants {
+		got = append(got, v.CartPrice)
+	}
+	want := []string{"40.00", "34.00", "", ""}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("cart prices = %q, want %q (sold-out and unavailable variants unpriced)", got, want)
+	}
+	if len(carts) != 2 {
+		t.Errorf("carts = %d, want one per simulation (cookie replayed)", len(carts))
+	}
+	// Two simulations × three available variants × clear and add
+	if len(posts) != 12 {
+		t.Errorf("POSTs = %d (%v), want 12", len(posts), posts)
+	}
+}
+
+func TestShopifyCartPricingStopsOnMissingCart(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	products := []models.Product{{Title: "NMN", Variants: []models.Variant{
+		{ID: "1", Price: "40.00", Available: true},
+		{ID: "2", Price: "70.00", Available: true},
+	}}}
+	simulateShopifyCarts(models.Vendor{Name: "No Cart Shop", URL: srv.URL + "/products.json"}, products)
+	if requests != 1 || products[0].Variants[0].CartPrice != "" {
+		t.Errorf("requests = %d, cart price %q; want one failed request and listed prices kept", requests, products[0].Variants[0].CartPrice)
+	}
+}
@@ -0,0 +1,155 @@
+package scraper
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"longevity-ranker/internal/models"
+	"longevity-ranker/internal/storage"
+)
+
+// CheckpointDir holds one file per vendor recording the product pages its
+// crawl has parsed so far, so an interrupted crawl can be resumed. "" (the
+// default) disables checkpoints; cmd/main.go sets it for live scrapes.
+var CheckpointDir = ""
+
+// Resume makes crawlPages start from the vendor's checkpoint, fetching
+// only the pages it does not record. cmd/main.go sets it from -resume.
+var Resume = false
+
+// checkpointEvery is how many newly parsed pages are recorded between
+// writes of the checkpoint file: a crash loses at most that many pages.
+const checkpointEvery = 10
+
+// maxCheckpointAge is the oldest checkpoint -resume continues: the prices
+// of pages parsed a day ago are not this run's, so an older one is started
+// over.
+const maxCheckpointAge = 24 * time.Hour
+
+// checkpoint is a vendor's crawl progress: the products of each product
+// page parsed, keyed by link. A page that yielded nothing is recorded too,
+// so it is not fetched again.
+type checkpoint struct {
+	Vendor  string                      `json:"vendor"`
+	Started time.Time                   `json:"started"`
+	Pages   map[string][]models.Product `json:"pages"`
+
+	mu      sync.Mutex
+	path    string
+	unsaved int
+}
+
+// checkpointPath is the vendor's checkpoint file, named like its
+// data/<vendor>.json.
+func checkpointPath(vendorName string) string {
+	return filepath.Join(CheckpointDir, filepath.Base(storage.VendorFilename(vendorName)))
+}
+
+// openCheckpoint returns the vendor's checkpoint, nil when checkpoints are
+// off. With Resume it continues the saved one when it is at most
+// maxCheckpointAge old; otherwise, or when none can be read, it starts
+// empty and replaces the file at the first write.
+func openCheckpoint(vendor models.Vendor) *checkpoint {
+	if CheckpointDir == "" {
+		return nil
+	}
+	path := checkpointPath(vendor.Name)
+	if Resume {
+		cp, err := storage.LoadJSON[*checkpoint](path)
+		switch {
+		case err == nil && cp != nil && cp.Vendor == vendor.Name && time.Since(cp.Started) > maxCheckpointAge:
+			fmt.Printf("   ⚠️  Not resuming %s: its checkpoint of %s is older than %.0fh; starting over.\n",
+				vendor.Name, cp.Started.Format("2006-01-02 15:04 UTC"), maxCheckpointAge.Hours())
+		case err == nil && cp != nil && cp.Vendor == vendor.Name:
+			cp.path = path
+			if cp.Pages == nil {
+				cp.Pages = make(map[string][]models.Product)
+			}
+			return cp
+		case !os.IsNotExist(err):
+			fmt.Printf("   ⚠️  Could not resume %s from %s (%v); starting over.\n", vendor.Name, path, err)
+		}
+	}
+	return &checkpoint{Vendor: vendor.Name, Started: time.Now().UTC(), Pages: make(map[string][]models.Product), path: path}
+}
+
+// remaining returns the links the checkpoint does not record, in order.
+func (cp *checkpoint) remaining(links []string) []string {
+	if cp == nil {
+		return links
+	}
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	var todo []string
+	for _, link := range links {
+		if _, done := cp.Pages[link]; !done {
+			todo = append(todo, link)
+		}
+	}
+	return todo
+}
+
+// products returns the recorded products of link.
+func (cp *checkpoint) products(link string) []models.Product {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	return cp.Pages[link]
+}
+
+// record adds a parsed page, writing the file every checkpointEvery pages.
+// Safe for concurrent use; a nil checkpoint records nothing.
+func (cp *checkpoint) record(link string, products []models.Product) {
+	if cp == nil {
+		return
+	}
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	cp.Pages[link] = products
+	if cp.unsaved++; cp.unsaved >= checkpointEvery {
+		cp.save()
+	}
+}
+
+// finish ends the crawl: a complete one deletes the checkpoint, one with
+// failed pages writes it so -resume can retry just those.
+func (cp *checkpoint) finish(complete bool) {
+	if cp == nil {
+		return
+	}
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	if complete {
+		os.Remove(cp.path)
+		return
+	}
+	cp.save()
+}
+
+// save writes the checkpoint through a temporary file, so a crash mid-write
+// leaves the previous one intact. Called with cp.mu held. A failed write
+// only costs a resumed run the pages since the last one, so it is not
+// reported.
+func (cp *checkpoint) save() {
+	cp.unsaved = 0
+	if err := os.MkdirAll(filepath.Dir(cp.path), 0755); err != nil {
+		return
+	}
+	tmp := cp.path + ".tmp"
+	if storage.SaveJSON(tmp, cp) != nil {
+		return
+	}
+	os.Rename(tmp, cp.path)
+}
+
+// resumeNote is the line printed when a crawl resumes, or "" when it
+// starts fresh.
+func (cp *checkpoint) resumeNote(links, todo []string) string {
+	if cp == nil || len(todo) == len(links) {
+		return ""
+	}
+	return fmt.Sprintf("   ↩️  Resuming %s from its checkpoint of %s (checkpoints older than %.0fh start over): %d of %d product page(s) already done.",
+		cp.Vendor, cp.Started.Format("2006-01-02 15:04 UTC"), maxCheckpointAge.Hours(), len(links)-len(todo), len(links))
+}
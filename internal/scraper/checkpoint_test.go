@@ -0,0 +1,96 @@
+package scraper
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"longevity-ranker/internal/models"
+	"longevity-ranker/internal/storage"
+)
+
+func TestCrawlPagesResumesFromCheckpoint(t *testing.T) {
+	defer func(dir string, resume bool) { CheckpointDir, Resume = dir, resume }(CheckpointDir, Resume)
+	CheckpointDir, Resume = t.TempDir(), false
+
+	var mu sync.Mutex
+	var fetched []string
+	down := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/p") {
+			http.NotFound(w, r)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		fetched = append(fetched, r.URL.Path)
+		if down && r.URL.Path == "/p3" {
+			panic(http.ErrAbortHandler) // Connection dropped
+		}
+		w.Write([]byte(r.URL.Path))
+	}))
+	defer srv.Close()
+
+	links := map[string]bool{}
+	for i := range 5 {
+		links[fmt.Sprintf("%s/p%d", srv.URL, i)] = true
+	}
+	vendor := models.Vendor{Name: "Checkpoint Vendor", MaxRetries: -1, RateLimit: 1000}
+	parse := func(html, link string) []models.Product { return []models.Product{{ID: html, Handle: link}} }
+
+	if got := crawlPages(vendor, links, parse); len(got) != 4 {
+		t.Fatalf("first crawl = %d products, want 4 (p3 failed)", len(got))
+	}
+	if _, err := os.Stat(checkpointPath(vendor.Name)); err != nil {
+		t.Fatalf("checkpoint after a failed page: %v", err)
+	}
+
+	// The resumed crawl fetches only the failed page
+	Resume, down, fetched = true, false, nil
+	got := crawlPages(vendor, links, parse)
+	var ids []string
+	for _, p := range got {
+		ids = append(ids, p.ID)
+	}
+	if strings.Join(ids, ",") != "/p0,/p1,/p2,/p3,/p4" {
+		t.Errorf("resumed crawl = %v, want p0 to p4 in order", ids)
+	}
+	if len(fetched) != 1 || fetched[0] != "/p3" {
+		t.Errorf("resumed crawl fetched %v, want only /p3", fetched)
+	}
+	if _, err := os.Stat(checkpointPath(vendor.Name)); !os.IsNotExist(err) {
+		t.Errorf("checkpoint after a complete crawl: %v, want it deleted", err)
+	}
+}
+
+func TestCrawlPagesIgnoresStaleCheckpoint(t *testing.T) {
+	defer func(dir string, resume bool) { CheckpointDir, Resume = dir, resume }(CheckpointDir, Resume)
+	CheckpointDir, Resume = t.TempDir(), true
+
+	var fetched atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched.Add(1)
+		w.Write([]byte(r.URL.Path))
+	}))
+	defer srv.Close()
+
+	vendor := models.Vendor{Name: "Stale Checkpoint", RateLimit: 1000}
+	links := map[string]bool{srv.URL + "/p0": true, srv.URL + "/p1": true}
+	stale := &checkpoint{Vendor: vendor.Name, Started: time.Now().Add(-maxCheckpointAge - time.Hour), Pages: map[string][]models.Product{
+		srv.URL + "/p0": {{ID: "old"}},
+	}}
+	if err := storage.SaveJSON(checkpointPath(vendor.Name), stale); err != nil {
+		t.Fatal(err)
+	}
+
+	got := crawlPages(vendor, links, func(html, link string) []models.Product { return []models.Product{{ID: html}} })
+	if fetched.Load() != 2 || len(got) != 2 || got[0].ID != "/p0" {
+		t.Errorf("crawl over a stale checkpoint fetched %d pages and got %+v, want both pages fetched again", fetched.Load(), got)
+	}
+}
@@ -1,36 +1,274 @@
 package scraper
 
 import (
+	"fmt"
 	"io"
+	"math/rand/v2"
 	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"slices"
+	"sync"
 	"time"
+
+	"longevity-ranker/internal/models"
 )
 
 const userAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
 
-// DefaultClient is a shared HTTP client used by all scrapers.
+// userAgentPool is the built-in pool of RotateUserAgent vendors: current
+// desktop browsers on the major platforms.
+var userAgentPool = []string{
+	userAgent,
+	"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:121.0) Gecko/20100101 Firefox/121.0",
+	"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.2 Safari/605.1.15",
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.0.0",
+	"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+}
+
+// DefaultClient is a shared HTTP client used by all scrapers. Its transport
+// honors the HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables.
 var DefaultClient = &http.Client{Timeout: 30 * time.Second}
 
-// NewRequest creates a GET request with the standard User-Agent header.
-func NewRequest(url string) (*http.Request, error) {
-	req, err := http.NewRequest("GET", url, nil)
+// vendorClients holds one client per vendor that needs its own: a cookie jar
+// (PersistCookies), so cookies a vendor sets (consent, currency, session) are
+// replayed on every later request to it during the run, a custom Timeout, a
+// proxy (ProxyEnv), or the headless browser (Browser). Vendors scrape
+// concurrently.
+var (
+	vendorClientsMu sync.Mutex
+	vendorClients   = map[string]*http.Client{}
+)
+
+// ClientFor returns the HTTP client to use for vendor: DefaultClient, or the
+// vendor's own client when PersistCookies, Timeout, ProxyEnv or Browser is
+// set. A Browser vendor's client fetches through headless Chrome (see
+// browserTransport), allowing browserTimeout per request by default.
+func ClientFor(vendor models.Vendor) *http.Client {
+	if !vendor.PersistCookies && vendor.Timeout <= 0 && vendor.ProxyEnv == "" && !vendor.Browser {
+		return DefaultClient
+	}
+	vendorClientsMu.Lock()
+	defer vendorClientsMu.Unlock()
+	if c, ok := vendorClients[vendor.Name]; ok {
+		return c
+	}
+	c := &http.Client{Timeout: DefaultClient.Timeout, Transport: DefaultClient.Transport}
+	if vendor.ProxyEnv != "" {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.Proxy = proxyFrom(vendor.ProxyEnv)
+		c.Transport = t
+	}
+	if vendor.Browser {
+		c.Transport, c.Timeout = browserTransport{}, browserTimeout
+	}
+	if vendor.Timeout > 0 {
+		c.Timeout = vendor.Timeout
+	}
+	if vendor.PersistCookies {
+		c.Jar, _ = cookiejar.New(nil) // never fails with nil options
+	}
+	vendorClients[vendor.Name] = c
+	return c
+}
+
+// proxyFrom returns a Proxy function reading the proxy URL from the
+// environment variable env on every request. An unset or malformed value
+// fails the request rather than sending it without the proxy.
+func proxyFrom(env string) func(*http.Request) (*url.URL, error) {
+	return func(*http.Request) (*url.URL, error) {
+		raw := os.Getenv(env)
+		if raw == "" {
+			return nil, fmt.Errorf("proxy: $%s is not set", env)
+		}
+		u, err := url.Parse(raw)
+		if err != nil || u.Host == "" || !slices.Contains([]string{"http", "https", "socks5", "socks5h"}, u.Scheme) {
+			return nil, fmt.Errorf("proxy: $%s is not an http(s):// or socks5:// URL", env)
+		}
+		return u, nil
+	}
+}
+
+// uaRotation is a vendor's place in its User-Agent pool.
+type uaRotation struct {
+	mu   sync.Mutex
+	pool []string
+	i    int
+}
+
+var (
+	rotationsMu sync.Mutex
+	rotations   = map[string]*uaRotation{}
+)
+
+// rotationFor returns the vendor's User-Agent rotation, starting at a
+// random member of its pool, or nil when the vendor does not rotate.
+func rotationFor(vendor models.Vendor) *uaRotation {
+	if len(vendor.UserAgents) == 0 && !vendor.RotateUserAgent {
+		return nil
+	}
+	rotationsMu.Lock()
+	defer rotationsMu.Unlock()
+	r, ok := rotations[vendor.Name]
+	if !ok {
+		pool := vendor.UserAgents
+		if len(pool) == 0 {
+			pool = userAgentPool
+		}
+		r = &uaRotation{pool: pool, i: rand.IntN(len(pool))}
+		rotations[vendor.Name] = r
+	}
+	return r
+}
+
+// userAgentFor returns the User-Agent the vendor's requests carry: the same
+// one for the whole run, so it matches the vendor's cookies, unless a 403
+// moved the vendor on (see rotateUserAgent).
+func userAgentFor(vendor models.Vendor) string {
+	r := rotationFor(vendor)
+	if r == nil {
+		return userAgent
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.pool[r.i]
+}
+
+// rotateUserAgent moves a rotating vendor to the next User-Agent of its
+// pool after a 403, and reports whether it did.
+func rotateUserAgent(vendor models.Vendor) bool {
+	r := rotationFor(vendor)
+	if r == nil || len(r.pool) < 2 {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.i = (r.i + 1) % len(r.pool)
+	return true
+}
+
+// NewRequest creates a GET request with the vendor's User-Agent (see
+// userAgentFor), then applies the vendor's configured Headers (which may
+// replace the User-Agent) and Cookies, and the localization cookie of its
+// Market's country unless Cookies sets one.
+func NewRequest(vendor models.Vendor, url string) (*http.Request, error) {
+	return newRequest(vendor, "GET", url, nil)
+}
+
+// newRequest is NewRequest for any method. A body from strings.Reader or
+// bytes.Reader can be resent, so POSTs are retried like GETs (see
+// doThrottled).
+func newRequest(vendor models.Vendor, method, url string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(method, url, body)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("User-Agent", userAgentFor(vendor))
+	for k, v := range vendor.Headers {
+		req.Header.Set(k, v)
+	}
+	for name, value := range vendor.Cookies {
+		req.AddCookie(&http.Cookie{Name: name, Value: value})
+	}
+	if country := marketCountry(vendor.Market); country != "" && vendor.Cookies[marketCookie] == "" {
+		req.AddCookie(&http.Cookie{Name: marketCookie, Value: country})
+	}
 	return req, nil
 }
 
-// FetchBody performs a GET request and returns the response body bytes.
-func FetchBody(url string) ([]byte, error) {
-	req, err := NewRequest(url)
+// FetchBody performs a GET request for vendor and returns the response body
+// bytes. 429 responses are retried (see do); one that persists is an error.
+// With a CacheDir, a page cached with an ETag or Last-Modified is requested
+// conditionally and a 304 answer returns the cached body.
+func FetchBody(vendor models.Vendor, url string) ([]byte, error) {
+	req, err := NewRequest(vendor, url)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := DefaultClient.Do(req)
+	entry, cached := loadCached(vendor, url)
+	if cached {
+		entry.conditional(req)
+	}
+	resp, err := do(vendor, req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
-	return io.ReadAll(resp.Body)
-}
\ No newline at end of file
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return nil, fmt.Errorf("still throttled (HTTP 429) after %d retries: %s", maxThrottleRetries, url)
+	case resp.StatusCode == http.StatusNotModified && cached:
+		recordMetrics(vendor.Name, func(m *Metrics) { m.NotModified++ })
+		return entry.Body, nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err == nil && resp.StatusCode == http.StatusOK {
+		storeCached(vendor, url, resp.Header, body)
+	}
+	return body, err
+}
+
+// maxParallelFetches caps how many of one vendor's entry URLs (collections,
+// category pages) are fetched at once. Requests still share the vendor's
+// breaker, rate limit and concurrency cap, and the host's 429 limiter.
+const maxParallelFetches = 4
+
+// fetchAll calls fetch for every URL, at most maxParallelFetches at a time,
+// and returns the results and errors in urls order.
+func fetchAll[T any](urls []string, fetch func(string) (T, error)) ([]T, []error) {
+	results := make([]T, len(urls))
+	errs := make([]error, len(urls))
+	sem := make(chan struct{}, maxParallelFetches)
+	var wg sync.WaitGroup
+	for i, u := range urls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i], errs[i] = fetch(u)
+		}()
+	}
+	wg.Wait()
+	return results, errs
+}
+
+// entryURLs is the vendor URL followed by its extra Collections, each once.
+func entryURLs(vendor models.Vendor) []string {
+	urls := []string{vendor.URL}
+	for _, u := range vendor.Collections {
+		if !slices.Contains(urls, u) {
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+// entryPage is one fetched listing page of a page-per-product vendor.
+type entryPage struct {
+	URL  *url.URL // Relative product links resolve against it
+	HTML string
+}
+
+// fetchEntryPages fetches the vendor's entry URLs (see entryURLs) in
+// parallel and returns the pages that loaded, in order. A failed vendor URL
+// fails the vendor; a failed extra entry is skipped (do records it in
+// PageErrors).
+func fetchEntryPages(vendor models.Vendor) ([]entryPage, error) {
+	urls := entryURLs(vendor)
+	bodies, errs := fetchAll(urls, func(u string) ([]byte, error) { return FetchBody(vendor, u) })
+	if errs[0] != nil {
+		return nil, errs[0]
+	}
+	var pages []entryPage
+	for i, body := range bodies {
+		pageURL, err := url.Parse(urls[i])
+		if err != nil || errs[i] != nil {
+			continue
+		}
+		pages = append(pages, entryPage{URL: pageURL, HTML: string(body)})
+	}
+	return pages, nil
+}
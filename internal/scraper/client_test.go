@@ -0,0 +1,104 @@
+package scraper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"longevity-ranker/internal/models"
+)
+
+func TestVendorHeadersAndCookies(t *testing.T) {
+	var gotCurrency, gotConsent, gotSession string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotCurrency = r.Header.Get("X-Currency")
+		gotConsent, gotSession = "", ""
+		if c, err := r.Cookie("consent"); err == nil {
+			gotConsent = c.Value
+		}
+		if c, err := r.Cookie("session"); err == nil {
+			gotSession = c.Value
+		}
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc", Path: "/"})
+	}))
+	defer srv.Close()
+
+	vendor := models.Vendor{
+		Name:    "Cookie Vendor",
+		Headers: map[string]string{"X-Currency": "USD"},
+		Cookies: map[string]string{"consent": "yes"},
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := FetchBody(vendor, srv.URL); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if gotCurrency != "USD" || gotConsent != "yes" {
+		t.Errorf("header/cookie = %q/%q, want USD/yes", gotCurrency, gotConsent)
+	}
+	if gotSession != "" {
+		t.Errorf("session cookie replayed without PersistCookies: %q", gotSession)
+	}
+
+	vendor.PersistCookies = true
+	for i := 0; i < 2; i++ {
+		if _, err := FetchBody(vendor, srv.URL); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if gotSession != "abc" || gotConsent != "yes" {
+		t.Errorf("with PersistCookies: session/consent = %q/%q, want abc/yes", gotSession, gotConsent)
+	}
+}
+
+func TestUserAgentRotation(t *testing.T) {
+	var agents []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		agents = append(agents, r.Header.Get("User-Agent"))
+		if len(agents) == 2 {
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer srv.Close()
+
+	pool := []string{"agent-a", "agent-b"}
+	vendor := models.Vendor{Name: "Rotating Vendor", UserAgents: pool, MaxRetries: -1}
+	for i := 0; i < 3; i++ {
+		FetchBody(vendor, srv.URL)
+	}
+	if !slices.Contains(pool, agents[0]) || agents[1] != agents[0] || agents[2] == agents[1] || !slices.Contains(pool, agents[2]) {
+		t.Errorf("User-Agents = %q, want one from the pool kept until the 403, then the other", agents)
+	}
+	if m := VendorMetrics(vendor.Name); m.Rotated != 1 {
+		t.Errorf("Rotated = %d, want 1", m.Rotated)
+	}
+
+	plain := models.Vendor{Name: "Plain Vendor"}
+	if got := userAgentFor(plain); got != userAgent {
+		t.Errorf("userAgentFor(no rotation) = %q, want the default", got)
+	}
+	if got := userAgentFor(models.Vendor{Name: "Pool Vendor", RotateUserAgent: true}); !slices.Contains(userAgentPool, got) {
+		t.Errorf("userAgentFor(RotateUserAgent) = %q, want one of the built-in pool", got)
+	}
+}
+
+func TestVendorProxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String() // A proxy receives the absolute URL
+		w.Write([]byte("via proxy"))
+	}))
+	defer proxy.Close()
+
+	vendor := models.Vendor{Name: "Proxied Vendor", ProxyEnv: "TEST_VENDOR_PROXY", MaxRetries: -1}
+	t.Setenv("TEST_VENDOR_PROXY", "")
+	if _, err := FetchBody(vendor, "http://shop.invalid/products.json"); err == nil {
+		t.Error("FetchBody() with the proxy variable unset succeeded, want an error instead of a direct request")
+	}
+	t.Setenv("TEST_VENDOR_PROXY", proxy.URL)
+	body, err := FetchBody(vendor, "http://shop.invalid/products.json")
+	if err != nil || string(body) != "via proxy" || proxied != "http://shop.invalid/products.json" {
+		t.Errorf("FetchBody() = %q, %v (proxy saw %q); want the page through the proxy", body, err, proxied)
+	}
+}
@@ -0,0 +1,116 @@
+package scraper
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"longevity-ranker/internal/models"
+)
+
+// csvColumns are the recognized header names (case-insensitive). name and
+// price are required; rows leave the others empty when unknown.
+var csvColumns = []string{"name", "price", "mg", "count", "grams", "url"}
+
+var reNonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+// FetchCSVProducts reads a spreadsheet export (vendor.URL is a local path or
+// an http(s) URL) with a header row naming the columns name, price, mg,
+// count, grams and url, in any order. Each row is one variant. Rows sharing
+// a url (or, without one, a name) become variants of a single product.
+//
+// The analyzer reads mass from text, so the numeric columns are rendered
+// into the variant title: mg and count as "500mg 60 Capsules", grams as
+// "250g". url becomes the handle, as with the other full-URL backends.
+func FetchCSVProducts(vendor models.Vendor) ([]models.Product, error) {
+	data, err := readSource(vendor)
+	if err != nil {
+		return nil, fmt.Errorf("reading CSV %q: %v", vendor.URL, err)
+	}
+	products, err := parseCSVProducts(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing CSV %q: %v", vendor.URL, err)
+	}
+	fmt.Printf("📄 Loaded %d CSV products for %s\n", len(products), vendor.Name)
+	return products, nil
+}
+
+func parseCSVProducts(data []byte) ([]models.Product, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.TrimLeadingSpace = true
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("missing header row")
+	}
+
+	col := make(map[string]int)
+	for i, h := range rows[0] {
+		col[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, required := range csvColumns[:2] {
+		if _, ok := col[required]; !ok {
+			return nil, fmt.Errorf("missing %q column (want %s)", required, strings.Join(csvColumns, ", "))
+		}
+	}
+	field := func(row []string, name string) string {
+		if i, ok := col[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	var products []models.Product
+	byHandle := make(map[string]int)
+	for n, row := range rows[1:] {
+		line := n + 2
+		name := field(row, "name")
+		price := strings.TrimPrefix(field(row, "price"), "$")
+		if name == "" {
+			return nil, fmt.Errorf("line %d: empty name", line)
+		}
+		if p, err := strconv.ParseFloat(price, 64); err != nil || p <= 0 {
+			return nil, fmt.Errorf("line %d: invalid price %q", line, field(row, "price"))
+		}
+
+		var parts []string
+		for _, c := range []struct{ column, unit string }{{"mg", "mg"}, {"count", " Capsules"}, {"grams", "g"}} {
+			raw := field(row, c.column)
+			if raw == "" {
+				continue
+			}
+			// The analyzer's regexes read whole numbers only
+			if v, err := strconv.Atoi(raw); err != nil || v <= 0 {
+				return nil, fmt.Errorf("line %d: %s %q is not a positive whole number", line, c.column, raw)
+			}
+			parts = append(parts, raw+c.unit)
+		}
+		variantTitle := strings.Join(parts, " ")
+		if variantTitle == "" {
+			variantTitle = "Default Title"
+		}
+
+		handle := field(row, "url")
+		if handle == "" {
+			handle = strings.Trim(reNonSlug.ReplaceAllString(strings.ToLower(name), "-"), "-")
+		}
+		variant := models.Variant{Price: price, Title: variantTitle, Available: true}
+		if i, ok := byHandle[handle]; ok {
+			products[i].Variants = append(products[i].Variants, variant)
+			continue
+		}
+		byHandle[handle] = len(products)
+		products = append(products, models.Product{
+			ID:       strconv.Itoa(line),
+			Title:    name,
+			Handle:   handle,
+			Variants: []models.Variant{variant},
+		})
+	}
+	return products, nil
+}
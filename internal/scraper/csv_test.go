@@ -0,0 +1,57 @@
+package scraper
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"longevity-ranker/internal/models"
+)
+
+func TestFetchCSVProducts(t *testing.T) {
+	products, err := FetchCSVProducts(models.Vendor{Name: "Group Buy", URL: filepath.Join("testdata", "group_buy.csv"), Type: "csv"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Rows sharing a url are one product; rows without one get a slug handle
+	want := []struct {
+		title, handle string
+		variants      []models.Variant
+	}{
+		{"NMN Group Buy Capsules", "https://example.com/nmn-caps", []models.Variant{
+			{Price: "39.00", Title: "500mg 60 Capsules", Available: true},
+			{Price: "69.00", Title: "500mg 120 Capsules", Available: true},
+		}},
+		{"Bulk NMN Powder", "bulk-nmn-powder", []models.Variant{{Price: "120", Title: "250g", Available: true}}},
+		{"Mystery Tincture", "mystery-tincture", []models.Variant{{Price: "25", Title: "Default Title", Available: true}}},
+	}
+	if len(products) != len(want) {
+		t.Fatalf("products = %d, want %d: %+v", len(products), len(want), products)
+	}
+	for i, w := range want {
+		p := products[i]
+		if p.Title != w.title || p.Handle != w.handle || len(p.Variants) != len(w.variants) {
+			t.Errorf("product[%d] = %q %q with %d variants, want %q %q with %d",
+				i, p.Title, p.Handle, len(p.Variants), w.title, w.handle, len(w.variants))
+			continue
+		}
+		for j, v := range w.variants {
+			assertVariant(t, p.Variants[j], v)
+		}
+	}
+}
+
+func TestParseCSVProductsErrors(t *testing.T) {
+	cases := map[string]string{
+		"price,mg\n10,500\n":           `missing "name" column`,
+		"name,price\nNMN,free\n":       `line 2: invalid price "free"`,
+		"name,price,mg\nNMN,10,12.5\n": `line 2: mg "12.5" is not a positive whole number`,
+		"name,price\n,10\n":            "line 2: empty name",
+	}
+	for input, want := range cases {
+		if _, err := parseCSVProducts([]byte(input)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("parseCSVProducts(%q) error = %v, want %q", input, err, want)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package scraper
+
+import (
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"longevity-ranker/internal/models"
+)
+
+// Currency sources reported by InferCurrency, most reliable first.
+const (
+	CurrencyFromURL   = "url query"     // A currency parameter on the vendor URL (?currency=USD)
+	CurrencyFromPages = "product pages" // LD+JSON priceCurrency or Magento product:price:currency
+	CurrencyFromShop  = "shopify meta"  // The Shopify store's /meta.json
+	CurrencyFromTLD   = "domain"        // A country-code TLD (.co.uk → GBP)
+)
+
+// tldCurrencies maps country-code TLDs to their currency. Generic TLDs
+// (.com, .org, .co) say nothing about it.
+var tldCurrencies = map[string]string{
+	"uk": "GBP", "de": "EUR", "fr": "EUR", "es": "EUR", "it": "EUR", "nl": "EUR",
+	"be": "EUR", "at": "EUR", "ie": "EUR", "pt": "EUR", "fi": "EUR", "eu": "EUR",
+	"ca": "CAD", "au": "AUD", "nz": "NZD", "ch": "CHF", "se": "SEK", "dk": "DKK",
+	"no": "NOK", "jp": "JPY", "us": "USD",
+}
+
+// rePageCurrency matches a Magento page's price currency meta tag or an
+// LD+JSON priceCurrency.
+var rePageCurrency = regexp.MustCompile(`(?:property="product:price:currency" content="|"priceCurrency"\s*:\s*")([A-Za-z]{3})"`)
+
+// pageCurrency returns the upper-cased currency a product page states, or "".
+func pageCurrency(html string) string {
+	if m := rePageCurrency.FindStringSubmatch(html); m != nil {
+		return strings.ToUpper(m[1])
+	}
+	return ""
+}
+
+// InferCurrency guesses the ISO 4217 code of a vendor's prices from, in
+// order: a currency parameter on its URL, the currency most of its products
+// report, a Shopify store's /meta.json, and a country-code TLD. It returns
+// "" when none of them says.
+func InferCurrency(vendor models.Vendor, products []models.Product) (currency, source string) {
+	u, err := url.Parse(vendor.URL)
+	if err != nil {
+		return "", ""
+	}
+	if c := u.Query().Get("currency"); len(c) == 3 {
+		return strings.ToUpper(c), CurrencyFromURL
+	}
+
+	counts := map[string]int{}
+	for _, p := range products {
+		if p.Currency != "" {
+			counts[p.Currency]++
+		}
+	}
+	if len(counts) > 0 {
+		codes := make([]string, 0, len(counts))
+		for c := range counts {
+			codes = append(codes, c)
+		}
+		sort.Slice(codes, func(i, j int) bool {
+			if counts[codes[i]] != counts[codes[j]] {
+				return counts[codes[i]] > counts[codes[j]]
+			}
+			return codes[i] < codes[j]
+		})
+		return codes[0], CurrencyFromPages
+	}
+
+	if vendor.Type == "shopify" {
+		if body, err := FetchBody(vendor, u.Scheme+"://"+u.Host+"/meta.json"); err == nil {
+			var meta struct {
+				Currency string `json:"currency"`
+			}
+			if json.Unmarshal(body, &meta) == nil && len(meta.Currency) == 3 {
+				return strings.ToUpper(meta.Currency), CurrencyFromShop
+			}
+		}
+	}
+
+	host := u.Hostname()
+	if c, ok := tldCurrencies[host[strings.LastIndex(host, ".")+1:]]; ok {
+		return c, CurrencyFromTLD
+	}
+	return "", ""
+}
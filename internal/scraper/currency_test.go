@@ -0,0 +1,52 @@
+package scraper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"longevity-ranker/internal/models"
+)
+
+func TestInferCurrency(t *testing.T) {
+	shop := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/meta.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"name": "Shop", "currency": "cad"}`))
+	}))
+	defer shop.Close()
+
+	eur := []models.Product{{Currency: "EUR"}, {Currency: "EUR"}, {Currency: "GBP"}, {}}
+	tests := []struct {
+		vendor     models.Vendor
+		products   []models.Product
+		want, from string
+	}{
+		{models.Vendor{URL: "https://shop.co.uk/products.json?currency=usd"}, eur, "USD", CurrencyFromURL},
+		{models.Vendor{URL: "https://shop.co.uk/shop/"}, eur, "EUR", CurrencyFromPages},
+		{models.Vendor{URL: shop.URL + "/collections/all/products.json", Type: "shopify"}, nil, "CAD", CurrencyFromShop},
+		{models.Vendor{URL: "https://shop.co.uk/shop/", Type: "magento"}, []models.Product{{}}, "GBP", CurrencyFromTLD},
+		{models.Vendor{URL: "https://shop.com/shop/", Type: "magento"}, nil, "", ""},
+	}
+	for _, tt := range tests {
+		got, from := InferCurrency(tt.vendor, tt.products)
+		if got != tt.want || from != tt.from {
+			t.Errorf("InferCurrency(%s) = %q from %q, want %q from %q", tt.vendor.URL, got, from, tt.want, tt.from)
+		}
+	}
+}
+
+func TestPageCurrency(t *testing.T) {
+	tests := map[string]string{
+		`<meta property="product:price:currency" content="eur"/>`: "EUR",
+		`{"@type": "Offer", "priceCurrency": "GBP", "price": 10}`: "GBP",
+		`<meta property="og:type" content="product"/>`:            "",
+	}
+	for html, want := range tests {
+		if got := pageCurrency(html); got != want {
+			t.Errorf("pageCurrency(%s) = %q, want %q", html, got, want)
+		}
+	}
+}
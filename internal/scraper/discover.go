@@ -0,0 +1,53 @@
+package scraper
+
+import (
+	"errors"
+	"sync"
+
+	"longevity-ranker/internal/models"
+)
+
+// DiscoverOnly stops every scraper after its link-extraction phase:
+// crawlPages records the product page links it was handed instead of
+// fetching them, and Shopify records the products.json URLs it would
+// paginate. Entry pages, sitemaps and /collections.json are still fetched.
+// cmd/main.go sets it from -discover.
+var DiscoverOnly = false
+
+// discoverTypes are the vendor types with a link-extraction phase.
+var discoverTypes = map[string]bool{
+	"shopify": true, "magento": true, "html-ldjson": true, "amazon": true, "generic-html": true,
+}
+
+var (
+	discoveredMu sync.Mutex
+	discovered   = map[string][]string{}
+)
+
+// recordDiscovered sets the links the vendor's scraper would crawl.
+func recordDiscovered(vendorName string, links []string) {
+	discoveredMu.Lock()
+	defer discoveredMu.Unlock()
+	discovered[vendorName] = append([]string(nil), links...)
+}
+
+// DiscoverLinks runs the vendor's scraper with DiscoverOnly set and returns
+// the URLs it would crawl, in crawl order. ok is false for vendor types
+// whose entry pages are the products (csv, priceapi, iherb, mock).
+func DiscoverLinks(vendor models.Vendor) (links []string, ok bool, err error) {
+	if !discoverTypes[vendor.Type] {
+		return nil, false, nil
+	}
+	if !DiscoverOnly {
+		return nil, true, errors.New("DiscoverOnly is not set")
+	}
+	discoveredMu.Lock()
+	delete(discovered, vendor.Name)
+	discoveredMu.Unlock()
+	if _, err := FetchProducts(vendor); err != nil {
+		return nil, true, err
+	}
+	discoveredMu.Lock()
+	defer discoveredMu.Unlock()
+	return discovered[vendor.Name], true, nil
+}
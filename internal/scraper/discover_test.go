@@ -0,0 +1,48 @@
+package scraper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"longevity-ranker/internal/models"
+)
+
+func TestDiscoverLinks(t *testing.T) {
+	defer func(on bool) { DiscoverOnly = on }(DiscoverOnly)
+	DiscoverOnly = true
+
+	category, err := os.ReadFile(filepath.Join("testdata", "magento_category.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var productFetches int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/products/" {
+			w.Write(category)
+			return
+		}
+		productFetches++
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	links, ok, err := DiscoverLinks(models.Vendor{Name: "Discover Magento", URL: srv.URL + "/products/", Type: "magento"})
+	if err != nil || !ok {
+		t.Fatalf("DiscoverLinks() ok = %v, err = %v", ok, err)
+	}
+	if want := []string{srv.URL + "/pure-nmn"}; !reflect.DeepEqual(links, want) {
+		t.Errorf("DiscoverLinks() = %v, want %v", links, want)
+	}
+	if productFetches != 0 {
+		t.Errorf("server saw %d product page request(s), want none", productFetches)
+	}
+
+	// A vendor type whose entry page is the product list has nothing to discover
+	if _, ok, err := DiscoverLinks(models.Vendor{Name: "Discover CSV", URL: "prices.csv", Type: "csv"}); ok || err != nil {
+		t.Errorf("DiscoverLinks(csv) ok = %v, err = %v; want false, nil", ok, err)
+	}
+}
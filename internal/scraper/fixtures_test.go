@@ -0,0 +1,52 @@
+package scraper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"longevity-ranker/internal/models"
+)
+
+// serveFixtures starts a server that answers each path in routes with the
+// contents of the named file under testdata/. A route with a query
+// ("/c/nmn?p=2") beats the bare path. Unknown paths return 404.
+func serveFixtures(t *testing.T, routes map[string]string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name, ok := routes[r.URL.RequestURI()]
+		if !ok {
+			name, ok = routes[r.URL.Path]
+		}
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Errorf("reading fixture %s: %v", name, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Write(data)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// sortProducts orders scraped products by ID so assertions do not depend on
+// map iteration order inside the scrapers.
+func sortProducts(products []models.Product) {
+	sort.Slice(products, func(i, j int) bool { return products[i].ID < products[j].ID })
+}
+
+// assertVariant checks the single-variant invariants shared by all backends.
+func assertVariant(t *testing.T, got models.Variant, want models.Variant) {
+	t.Helper()
+	if got != want {
+		t.Errorf("variant = %+v, want %+v", got, want)
+	}
+}
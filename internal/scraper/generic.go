@@ -0,0 +1,366 @@
+package scraper
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"longevity-ranker/internal/models"
+)
+
+// Generic HTML markup: start and end tags, and the attributes of a start
+// tag (double-, single- or unquoted values).
+var (
+	reGenericTag  = regexp.MustCompile(`<(/?)([a-zA-Z][a-zA-Z0-9-]*)\b([^>]*)>`)
+	reGenericAttr = regexp.MustCompile(`([a-zA-Z_:][-a-zA-Z0-9_:.]*)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+)
+
+// voidElements never have an end tag.
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "param": true, "source": true, "track": true, "wbr": true,
+}
+
+// FetchGenericProducts scrapes a store on none of the supported platforms
+// from the product pages its config lists: vendor.URL and ProductPages.
+// Each page yields whatever products parseGenericPage finds; the rest of
+// the store is not crawled.
+func FetchGenericProducts(vendor models.Vendor) ([]models.Product, error) {
+	links := map[string]bool{vendor.URL: true}
+	for _, link := range vendor.ProductPages {
+		links[link] = true
+	}
+	fmt.Printf("🔍 Crawling %d product page(s) for %s (generic HTML)...\n", len(links), vendor.Name)
+	return crawlPages(vendor, links, parseGenericPage), nil
+}
+
+// parseGenericPage reads a product page with the first markup that yields
+// a priced product: LD+JSON (as html-ldjson vendors), schema.org microdata,
+// then Open Graph product tags. Open Graph also fills in the image,
+// description and currency microdata leaves out. A page with none of them
+// yields nothing.
+func parseGenericPage(page, link string) []models.Product {
+	if products := parseLdJsonProductPage(page, link); len(products) > 0 {
+		return products
+	}
+	og := openGraph(page)
+	products := parseMicrodata(page, link)
+	if len(products) == 0 {
+		if p, ok := openGraphProduct(og, link); ok {
+			products = []models.Product{p}
+		}
+	}
+	for i := range products {
+		p := &products[i]
+		if p.ImageURL == "" {
+			p.ImageURL = og["og:image"]
+		}
+		if p.BodyHTML == "" {
+			p.BodyHTML = og["og:description"]
+		}
+		if p.Currency == "" {
+			p.Currency = strings.ToUpper(ogValue(og, "price:currency"))
+		}
+		p.ImageURL = resolveLink(link, p.ImageURL)
+	}
+	return products
+}
+
+// mdItem is one schema.org microdata item (an itemscope element).
+type mdItem struct {
+	typ    string            // itemtype, e.g. https://schema.org/Product
+	prop   string            // The item's own itemprop in its parent; "" at the top level
+	parent int               // Index of the enclosing item; -1 at the top level
+	props  map[string]string // First value of each property
+}
+
+// mdElement is an open element while walking the page.
+type mdElement struct {
+	name  string
+	item  int    // Index of the item it opens; -1 when it is not an itemscope
+	prop  string // itemprop valued by the element's text, read at its end tag
+	owner int    // Item the text property belongs to
+	start int    // Offset of the element's content
+}
+
+// parseMicrodata returns the page's top-level schema.org Product items,
+// one product each with a variant per priced offer. Offers nested in an
+// AggregateOffer count; an AggregateOffer without any is priced at its
+// lowPrice. Properties of nested items other than offers (brand, review,
+// seller) are ignored, so their names do not leak into the product's.
+func parseMicrodata(page, link string) []models.Product {
+	items := microdataItems(page)
+	var products []models.Product
+	for i, item := range items {
+		if item.prop != "" || !isSchemaType(item.typ, "Product") {
+			continue
+		}
+		var offers []mdItem
+		for _, o := range childItems(items, i, "offers") {
+			nested := childItems(items, o, "offers")
+			if len(nested) == 0 {
+				offers = append(offers, items[o])
+			}
+			for _, n := range nested {
+				offers = append(offers, items[n])
+			}
+		}
+
+		p := models.Product{
+			ID:       item.props["name"],
+			Title:    item.props["name"],
+			Handle:   link,
+			BodyHTML: item.props["description"],
+			ImageURL: item.props["image"],
+		}
+		for _, o := range offers {
+			price, ok := genericPrice(o.props["price"])
+			if !ok {
+				price, ok = genericPrice(o.props["lowPrice"])
+			}
+			if !ok {
+				continue
+			}
+			title := o.props["name"]
+			if title == "" {
+				title = "Default Title"
+			}
+			if p.Currency == "" {
+				p.Currency = strings.ToUpper(o.props["priceCurrency"])
+			}
+			p.Variants = append(p.Variants, models.Variant{Price: price, Title: title, Available: genericAvailable(o.props["availability"])})
+		}
+		if p.Title != "" && len(p.Variants) > 0 {
+			products = append(products, p)
+		}
+	}
+	return products
+}
+
+// microdataItems walks the page's tags, skipping scripts and styles, and
+// collects its microdata items in document order. A property's value is the
+// element's content attribute, else its href or src for links, images and
+// other void elements, else its text. Unclosed elements end with the
+// enclosing element's end tag.
+func microdataItems(page string) []mdItem {
+	var items []mdItem
+	var open []mdElement
+	current := func() int {
+		for i := len(open) - 1; i >= 0; i-- {
+			if open[i].item >= 0 {
+				return open[i].item
+			}
+		}
+		return -1
+	}
+	set := func(item int, props, value string) {
+		if item < 0 {
+			return
+		}
+		for _, prop := range strings.Fields(props) {
+			if _, ok := items[item].props[prop]; !ok {
+				items[item].props[prop] = value
+			}
+		}
+	}
+
+	skipTo := 0
+	for _, m := range reGenericTag.FindAllStringSubmatchIndex(page, -1) {
+		if m[0] < skipTo {
+			continue
+		}
+		name := strings.ToLower(page[m[4]:m[5]])
+		if name == "script" || name == "style" {
+			if end := strings.Index(strings.ToLower(page[m[1]:]), "</"+name); end >= 0 {
+				skipTo = m[1] + end
+			}
+		}
+		if page[m[2]:m[3]] == "/" {
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i].name != name {
+					continue
+				}
+				for _, e := range open[i:] {
+					if e.prop != "" {
+						set(e.owner, e.prop, genericText(page[e.start:m[0]]))
+					}
+				}
+				open = open[:i]
+				break
+			}
+			continue
+		}
+
+		attrs := htmlAttrs(page[m[6]:m[7]])
+		prop, hasProp := attrs["itemprop"]
+		_, scoped := attrs["itemscope"]
+		void := voidElements[name] || strings.HasSuffix(page[m[6]:m[7]], "/")
+		e := mdElement{name: name, item: -1, start: m[1]}
+		switch {
+		case scoped:
+			items = append(items, mdItem{typ: attrs["itemtype"], prop: prop, parent: current(), props: map[string]string{}})
+			e.item = len(items) - 1
+		case hasProp:
+			if v, ok := attrs["content"]; ok {
+				set(current(), prop, strings.TrimSpace(v))
+			} else if v, ok := firstAttr(attrs, "href", "src"); ok && (void || name == "a") {
+				set(current(), prop, strings.TrimSpace(v))
+			} else if !void {
+				e.prop, e.owner = prop, current()
+			}
+		}
+		if !void {
+			open = append(open, e)
+		}
+	}
+	return items
+}
+
+// childItems returns the indexes of the items directly inside items[parent]
+// as its prop property.
+func childItems(items []mdItem, parent int, prop string) []int {
+	var children []int
+	for i, item := range items {
+		if item.parent == parent && item.prop == prop {
+			children = append(children, i)
+		}
+	}
+	return children
+}
+
+// isSchemaType reports whether an itemtype is the schema.org type name
+// (http or https, trailing slash or not).
+func isSchemaType(itemtype, name string) bool {
+	for _, t := range strings.Fields(itemtype) {
+		t = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(t, "https://"), "http://"), "/")
+		if strings.EqualFold(t, "schema.org/"+name) {
+			return true
+		}
+	}
+	return false
+}
+
+// openGraph returns the page's <meta property|name content> pairs, keyed
+// by the lower-cased property, first occurrence winning.
+func openGraph(page string) map[string]string {
+	og := map[string]string{}
+	for _, m := range reGenericTag.FindAllStringSubmatch(page, -1) {
+		if m[1] != "" || !strings.EqualFold(m[2], "meta") {
+			continue
+		}
+		attrs := htmlAttrs(m[3])
+		key, ok := firstAttr(attrs, "property", "name")
+		content, hasContent := attrs["content"]
+		if !ok || !hasContent {
+			continue
+		}
+		if key = strings.ToLower(key); og[key] == "" {
+			og[key] = strings.TrimSpace(content)
+		}
+	}
+	return og
+}
+
+// ogValue returns a product tag by its suffix, under the product: prefix
+// of the Open Graph product spec or the og: one some stores use.
+func ogValue(og map[string]string, suffix string) string {
+	if v := og["product:"+suffix]; v != "" {
+		return v
+	}
+	return og["og:"+suffix]
+}
+
+// openGraphProduct builds a one-variant product from Open Graph tags; ok
+// is false without a title and a price.
+func openGraphProduct(og map[string]string, link string) (models.Product, bool) {
+	title := og["og:title"]
+	price, ok := genericPrice(ogValue(og, "price:amount"))
+	if title == "" || !ok {
+		return models.Product{}, false
+	}
+	return models.Product{
+		ID:       title,
+		Title:    title,
+		Handle:   link,
+		Variants: []models.Variant{{Price: price, Title: "Default Title", Available: genericAvailable(ogValue(og, "availability"))}},
+	}, true
+}
+
+// htmlAttrs returns a start tag's attributes by lower-cased name, values
+// unescaped. Attributes without a value (itemscope) map to "".
+func htmlAttrs(s string) map[string]string {
+	attrs := map[string]string{}
+	for _, m := range reGenericAttr.FindAllStringSubmatch(s, -1) {
+		attrs[strings.ToLower(m[1])] = html.UnescapeString(m[2] + m[3] + m[4])
+	}
+	for _, f := range strings.Fields(reGenericAttr.ReplaceAllString(s, " ")) {
+		if f = strings.ToLower(strings.TrimSuffix(f, "/")); f != "" {
+			if _, ok := attrs[f]; !ok {
+				attrs[f] = ""
+			}
+		}
+	}
+	return attrs
+}
+
+// firstAttr returns the first of names the attributes have.
+func firstAttr(attrs map[string]string, names ...string) (string, bool) {
+	for _, name := range names {
+		if v, ok := attrs[name]; ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// genericText is an element's content as plain text: tags dropped,
+// entities unescaped, whitespace collapsed.
+func genericText(s string) string {
+	return strings.Join(strings.Fields(html.UnescapeString(reHTMLTag.ReplaceAllString(s, " "))), " ")
+}
+
+// genericPrice normalizes a machine-readable ("29.9") or displayed
+// ("$1,299.00", "29,99 €") price to two decimals.
+func genericPrice(s string) (string, bool) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	ok := err == nil && v > 0
+	if err != nil {
+		v, ok = parseDisplayedPrice(s)
+	}
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("%.2f", v), true
+}
+
+// genericAvailable reads a schema.org availability URL or an Open Graph
+// availability value. Only an explicit out-of-stock value is unavailable;
+// a page that does not say is assumed in stock.
+func genericAvailable(s string) bool {
+	s = strings.ToLower(strings.NewReplacer(" ", "", "_", "", "-", "").Replace(s))
+	for _, out := range []string{"outofstock", "soldout", "discontinued"} {
+		if strings.Contains(s, out) {
+			return false
+		}
+	}
+	return s != "oos"
+}
+
+// resolveLink makes a relative URL found on the page at base absolute.
+func resolveLink(base, ref string) string {
+	if ref == "" {
+		return ""
+	}
+	b, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+	u, err := b.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return u.String()
+}
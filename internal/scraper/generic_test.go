@@ -0,0 +1,66 @@
+package scraper
+
+import (
+	"testing"
+
+	"longevity-ranker/internal/models"
+)
+
+func TestFetchGenericProductsContract(t *testing.T) {
+	srv := serveFixtures(t, map[string]string{
+		"/products/nmn-powder": "generic_microdata.html",
+		"/products/tmg":        "generic_opengraph.html",
+		"/products/ldjson":     "ldjson_product.html",
+		"/about":               "iherb_category.html",
+	})
+	vendor := models.Vendor{
+		Name: "Fixture Generic", URL: srv.URL + "/products/nmn-powder", Type: "generic-html",
+		ProductPages: []string{srv.URL + "/products/tmg", srv.URL + "/products/ldjson", srv.URL + "/about"},
+	}
+	products, err := FetchProducts(vendor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sortProducts(products)
+	if len(products) != 3 {
+		t.Fatalf("products = %d, want 3 (the page without product markup yields none): %+v", len(products), products)
+	}
+
+	nmn := products[0]
+	if nmn.Title != "Pure NMN Powder" || nmn.Handle != srv.URL+"/products/nmn-powder" || nmn.Currency != "USD" {
+		t.Errorf("microdata product title/handle/currency = %q/%q/%q", nmn.Title, nmn.Handle, nmn.Currency)
+	}
+	if nmn.BodyHTML != "99% pure NMN. 50 g per jar & 500 mg per scoop." {
+		t.Errorf("microdata BodyHTML = %q", nmn.BodyHTML)
+	}
+	if nmn.ImageURL != srv.URL+"/media/nmn-powder.jpg" {
+		t.Errorf("microdata ImageURL = %q, want the resolved og:image", nmn.ImageURL)
+	}
+	if len(nmn.Variants) != 2 {
+		t.Fatalf("microdata variants = %+v, want 2", nmn.Variants)
+	}
+	assertVariant(t, nmn.Variants[0], models.Variant{Price: "39.90", Title: "50 g jar", Available: true})
+	assertVariant(t, nmn.Variants[1], models.Variant{Price: "1069.00", Title: "100 g jar", Available: false})
+
+	tmg := products[1]
+	if tmg.Title != "TMG 500mg, 120 Capsules" || tmg.Currency != "EUR" || tmg.ImageURL != "https://cdn.example.com/tmg.jpg" {
+		t.Errorf("Open Graph product = %+v", tmg)
+	}
+	if tmg.BodyHTML != "500 mg trimethylglycine per capsule, 120 capsules." || len(tmg.Variants) != 1 {
+		t.Fatalf("Open Graph product = %+v", tmg)
+	}
+	assertVariant(t, tmg.Variants[0], models.Variant{Price: "19.50", Title: "Default Title", Available: false})
+
+	if ld := products[2]; ld.Title != "Wonderfeel NMN Capsuls™ 1000 mg" || ld.Variants[0].Price != "58.00" {
+		t.Errorf("LD+JSON product = %+v", ld)
+	}
+}
+
+func TestGenericPageWatchlist(t *testing.T) {
+	srv := serveFixtures(t, map[string]string{"/products/tmg": "generic_opengraph.html"})
+	vendor := models.Vendor{Name: "Fixture Generic Pages", URL: srv.URL + "/products/nmn-powder", Type: "generic-html"}
+	pages, ok, err := FetchProductPages(vendor, []string{srv.URL + "/products/tmg"})
+	if !ok || err != nil || len(pages) != 1 || pages[0].Handle != srv.URL+"/products/tmg" {
+		t.Errorf("FetchProductPages() = %+v, %v, %v; want the one watched page", pages, ok, err)
+	}
+}
@@ -0,0 +1,77 @@
+package scraper
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"longevity-ranker/internal/models"
+	"longevity-ranker/internal/storage"
+)
+
+// CacheDir is the HTTP response cache of FetchBody: one file per vendor and
+// URL holding the last 200 response's body with its ETag and Last-Modified
+// validators, so an unchanged page is answered 304 and not downloaded again.
+// "" (the default) disables the cache; cmd/main.go sets it from -http-cache.
+var CacheDir = ""
+
+// cacheEntry is one cached response.
+type cacheEntry struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	Body         []byte `json:"body"`
+}
+
+// cachePath names the entry of url by a hash of the vendor and URL: the same
+// URL may render differently under another vendor's headers and cookies.
+func cachePath(vendor models.Vendor, url string) string {
+	sum := sha256.Sum256([]byte(vendor.Name + "\n" + url))
+	return filepath.Join(CacheDir, hex.EncodeToString(sum[:16])+".json")
+}
+
+// cacheable reports whether the vendor's responses go through the cache.
+// Browser vendors do not: Chrome sends its own conditional headers and hands
+// back rendered pages. Nor do vendors with an API key, whose URL may carry
+// the key in its query and would write it to disk (and to the CI cache).
+func cacheable(vendor models.Vendor) bool {
+	return CacheDir != "" && !vendor.Browser && vendor.APIKeyEnv == ""
+}
+
+// loadCached returns the vendor's cached response for url, if any.
+func loadCached(vendor models.Vendor, url string) (cacheEntry, bool) {
+	if !cacheable(vendor) {
+		return cacheEntry{}, false
+	}
+	entry, err := storage.LoadJSON[cacheEntry](cachePath(vendor, url))
+	if err != nil || entry.URL != url || (entry.ETag == "" && entry.LastModified == "") {
+		return cacheEntry{}, false
+	}
+	return entry, true
+}
+
+// conditional makes req ask for the page only if it changed since entry.
+func (entry cacheEntry) conditional(req *http.Request) {
+	if entry.ETag != "" {
+		req.Header.Set("If-None-Match", entry.ETag)
+	}
+	if entry.LastModified != "" {
+		req.Header.Set("If-Modified-Since", entry.LastModified)
+	}
+}
+
+// storeCached saves a 200 response's body when it carries a validator to
+// revalidate it with next time. A failed write only costs the next run a
+// full download, so it is not reported.
+func storeCached(vendor models.Vendor, url string, header http.Header, body []byte) {
+	entry := cacheEntry{URL: url, ETag: header.Get("ETag"), LastModified: header.Get("Last-Modified"), Body: body}
+	if !cacheable(vendor) || (entry.ETag == "" && entry.LastModified == "") {
+		return
+	}
+	if err := os.MkdirAll(CacheDir, 0755); err != nil {
+		return
+	}
+	storage.SaveJSON(cachePath(vendor, url), entry)
+}
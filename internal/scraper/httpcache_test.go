@@ -0,0 +1,113 @@
+package scraper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"longevity-ranker/internal/models"
+)
+
+func TestFetchBodyRevalidatesCachedPages(t *testing.T) {
+	defer func(dir string) { CacheDir = dir }(CacheDir)
+	CacheDir = t.TempDir()
+
+	body := "<html>v1</html>"
+	var full, notModified int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/etag":
+			if r.Header.Get("If-None-Match") == `"v1"` && body == "<html>v1</html>" {
+				notModified++
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"v1"`)
+		case "/modified":
+			if r.Header.Get("If-Modified-Since") == "Mon, 12 Oct 2026 08:00:00 GMT" {
+				notModified++
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("Last-Modified", "Mon, 12 Oct 2026 08:00:00 GMT")
+		}
+		full++
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	vendor := models.Vendor{Name: "Cached Vendor"}
+	for _, path := range []string{"/etag", "/modified", "/etag", "/modified"} {
+		if got, err := FetchBody(vendor, srv.URL+path); err != nil || string(got) != body {
+			t.Fatalf("FetchBody(%s) = %q, %v; want %q", path, got, err, body)
+		}
+	}
+	if full != 2 || notModified != 2 {
+		t.Errorf("server sent %d full and %d 304 responses, want 2 and 2", full, notModified)
+	}
+	if m := VendorMetrics(vendor.Name); m.NotModified != 2 {
+		t.Errorf("metrics = %+v, want 2 not modified", m)
+	}
+
+	// A changed page is downloaded again and replaces the cached copy
+	body = "<html>v2</html>"
+	if got, err := FetchBody(vendor, srv.URL+"/etag"); err != nil || string(got) != body || full != 3 {
+		t.Errorf("FetchBody(changed) = %q, %v after %d full responses; want the new page", got, err, full)
+	}
+
+	// Pages without validators and other vendors' pages are not cached
+	FetchBody(vendor, srv.URL+"/plain")
+	FetchBody(models.Vendor{Name: "Other Vendor"}, srv.URL+"/modified")
+	if full != 5 {
+		t.Errorf("server sent %d full responses, want 5", full)
+	}
+	if entries, _ := os.ReadDir(CacheDir); len(entries) != 3 {
+		t.Errorf("cache holds %d entries, want 3 (two for Cached Vendor, one for Other Vendor)", len(entries))
+	}
+}
+
+func TestFetchBodySkipsCacheForAPIKeys(t *testing.T) {
+	defer func(dir string) { CacheDir = dir }(CacheDir)
+	CacheDir = t.TempDir()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`{"offers": []}`))
+	}))
+	defer srv.Close()
+	t.Setenv("TEST_CACHED_KEY", "s3cret-key")
+
+	vendor := models.Vendor{Name: "Keyed API", URL: srv.URL + "/offers", Type: "priceapi", APIKeyEnv: "TEST_CACHED_KEY", APIKeyParam: "key"}
+	if _, err := FetchPriceAPIProducts(vendor); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := os.ReadDir(CacheDir)
+	for _, e := range entries {
+		if data, _ := os.ReadFile(filepath.Join(CacheDir, e.Name())); strings.Contains(string(data), "s3cret-key") {
+			t.Errorf("cache file %s holds the API key", e.Name())
+		}
+	}
+	if len(entries) != 0 {
+		t.Errorf("cache holds %d entries, want none for a vendor with an API key", len(entries))
+	}
+}
+
+func TestFetchBodyWithoutCache(t *testing.T) {
+	var conditional bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conditional = conditional || r.Header.Get("If-None-Match") != ""
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	vendor := models.Vendor{Name: "Uncached Vendor"}
+	FetchBody(vendor, srv.URL)
+	FetchBody(vendor, srv.URL)
+	if conditional {
+		t.Error("sent a conditional request with CacheDir unset")
+	}
+}
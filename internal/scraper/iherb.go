@@ -0,0 +1,162 @@
+package scraper
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"longevity-ranker/internal/models"
+)
+
+// iherbMaxPages caps the listing pages crawled per category, so a
+// mistyped category URL (the whole store) does not run for hours.
+const iherbMaxPages = 20
+
+// iHerb category page markup. Each product cell is a div carrying the
+// product ID and brand in data-ga-* attributes; the price block shows the
+// current price first and a struck-through list price ("price-olp") on sale.
+var (
+	reIherbCell      = regexp.MustCompile(`<div[^>]*\bdata-ga-product-id="(\d+)"[^>]*>`)
+	reIherbBrand     = regexp.MustCompile(`\bdata-ga-brand-name="([^"]*)"`)
+	reIherbOutStock  = regexp.MustCompile(`(?i)\bdata-ga-is-out-of-stock="true"`)
+	reIherbLink      = regexp.MustCompile(`<a[^>]*\bclass="[^"]*\bproduct-link\b[^"]*"[^>]*>`)
+	reIherbHref      = regexp.MustCompile(`\bhref="([^"]+)"`)
+	reIherbTitle     = regexp.MustCompile(`\btitle="([^"]+)"`)
+	reIherbImage     = regexp.MustCompile(`<img[^>]*\b(?:data-src|src)="(https?://[^"]+)"`)
+	reIherbPrice     = regexp.MustCompile(`(?s)<span[^>]*\bclass="price\b[^"]*"[^>]*>\s*(?:<bdi>)?([^<]+)`)
+	reIherbListPrice = regexp.MustCompile(`(?s)<span[^>]*\bclass="price-olp\b[^"]*"[^>]*>\s*(?:<bdi>)?([^<]+)`)
+	reIherbPageLink  = regexp.MustCompile(`href="[^"]*[?&]p=(\d+)[^"]*"`)
+)
+
+// FetchIherbProducts crawls the iHerb category pages at vendor.URL and
+// Collections (e.g. https://www.iherb.com/c/nmn), following their ?p=
+// pagination, and reads every product from its listing cell: one
+// one-variant product per iHerb product ID, whose handle is its /pr/ URL.
+// Product.Brand holds the manufacturer and the title drops its leading
+// "Brand, ", so the ranking attributes each listing to its brand (see
+// models.Product.Brand) rather than to iHerb. A product listed in several
+// categories is kept once.
+func FetchIherbProducts(vendor models.Vendor) ([]models.Product, error) {
+	fmt.Printf("🔍 Crawling %s (iHerb categories)...\n", vendor.Name)
+	pages, err := fetchEntryPages(vendor)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var products []models.Product
+	add := func(page entryPage) {
+		for _, p := range parseIherbListing(page.HTML, page.URL) {
+			if !seen[p.ID] {
+				seen[p.ID] = true
+				products = append(products, p)
+			}
+		}
+	}
+	for _, page := range pages {
+		add(page)
+		for _, link := range iherbPageLinks(page) {
+			body, err := FetchBody(vendor, link.String())
+			if err != nil {
+				continue // Recorded in PageErrors; the other pages still count
+			}
+			add(entryPage{URL: link, HTML: string(body)})
+		}
+	}
+	fmt.Printf("   -> Found %d iHerb products.\n", len(products))
+	return products, nil
+}
+
+// iherbPageLinks returns the URLs of a category's pages after the first,
+// up to the highest page its pagination links to (at most iherbMaxPages).
+func iherbPageLinks(page entryPage) []*url.URL {
+	last := 1
+	for _, m := range reIherbPageLink.FindAllStringSubmatch(page.HTML, -1) {
+		if n, err := strconv.Atoi(m[1]); err == nil && n > last {
+			last = n
+		}
+	}
+	last = min(last, iherbMaxPages)
+
+	var links []*url.URL
+	for n := 2; n <= last; n++ {
+		u := *page.URL
+		q := u.Query()
+		q.Set("p", strconv.Itoa(n))
+		u.RawQuery = q.Encode()
+		links = append(links, &u)
+	}
+	return links
+}
+
+// parseIherbListing reads the product cells of one category page. Cells
+// without a product link or a readable price are skipped.
+func parseIherbListing(page string, pageURL *url.URL) []models.Product {
+	cells := reIherbCell.FindAllStringSubmatchIndex(page, -1)
+	var products []models.Product
+	for i, c := range cells {
+		end := len(page)
+		if i+1 < len(cells) {
+			end = cells[i+1][0]
+		}
+		if p, ok := parseIherbCell(page[c[0]:end], page[c[2]:c[3]], pageURL); ok {
+			products = append(products, p)
+		}
+	}
+	return products
+}
+
+// parseIherbCell reads one product cell: brand, title and link from the
+// product link, price, list price, stock and image.
+func parseIherbCell(cell, id string, pageURL *url.URL) (models.Product, bool) {
+	tag := reIherbLink.FindString(cell)
+	href := reIherbHref.FindStringSubmatch(tag)
+	title := reIherbTitle.FindStringSubmatch(tag)
+	if href == nil || title == nil {
+		return models.Product{}, false
+	}
+	link, err := pageURL.Parse(html.UnescapeString(href[1]))
+	if err != nil {
+		return models.Product{}, false
+	}
+	m := reIherbPrice.FindStringSubmatch(cell)
+	if m == nil {
+		return models.Product{}, false
+	}
+	price, ok := parseDisplayedPrice(m[1])
+	if !ok {
+		return models.Product{}, false
+	}
+
+	variant := models.Variant{
+		Price:     fmt.Sprintf("%.2f", price),
+		Title:     "Default Title",
+		Available: !reIherbOutStock.MatchString(cell),
+	}
+	if m := reIherbListPrice.FindStringSubmatch(cell); m != nil {
+		if list, ok := parseDisplayedPrice(m[1]); ok && list > price {
+			variant.CompareAtPrice = fmt.Sprintf("%.2f", list)
+		}
+	}
+
+	p := models.Product{
+		ID:       id,
+		Title:    strings.TrimSpace(html.UnescapeString(title[1])),
+		Handle:   link.String(),
+		Variants: []models.Variant{variant},
+	}
+	if m := reIherbBrand.FindStringSubmatch(cell); m != nil {
+		p.Brand = strings.TrimSpace(html.UnescapeString(m[1]))
+	}
+	// "Doctor's Best, NMN, 150 mg, 30 Veggie Caps" → "NMN, 150 mg, 30 Veggie Caps"
+	if rest, ok := strings.CutPrefix(p.Title, p.Brand+","); ok && p.Brand != "" {
+		p.Title = strings.TrimSpace(rest)
+	}
+	if m := reIherbImage.FindStringSubmatch(cell); m != nil {
+		p.ImageURL = m[1]
+	}
+	return p, true
+}
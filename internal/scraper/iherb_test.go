@@ -0,0 +1,53 @@
+package scraper
+
+import (
+	"testing"
+
+	"longevity-ranker/internal/models"
+)
+
+func TestFetchIherbProducts(t *testing.T) {
+	srv := serveFixtures(t, map[string]string{
+		"/c/nmn":     "iherb_category.html",
+		"/c/nmn?p=2": "iherb_category_p2.html",
+		"/c/tmg":     "iherb_category.html", // Its ?p=2 is missing: skipped
+	})
+
+	products, err := FetchIherbProducts(models.Vendor{
+		Name: "iHerb", URL: srv.URL + "/c/nmn", Type: "iherb", Collections: []string{srv.URL + "/c/tmg"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Each product once, whatever the pages and categories listing it; the
+	// cell without a price is skipped
+	if len(products) != 3 {
+		t.Fatalf("products = %d, want 3: %+v", len(products), products)
+	}
+	sortProducts(products)
+
+	p := products[0]
+	if p.ID != "103997" || p.Brand != "Doctor's Best" || p.Title != "NMN, 150 mg, 30 Veggie Caps" ||
+		p.Handle != "https://www.iherb.com/pr/doctor-s-best-nmn-150-mg-30-veggie-caps/103997" {
+		t.Errorf("product id/brand/title/handle = %q/%q/%q/%q", p.ID, p.Brand, p.Title, p.Handle)
+	}
+	if p.ImageURL != "https://cloudinary.images-iherb.com/image/upload/f_auto,q_auto:eco/images/drb/drb00512/l/1.jpg" {
+		t.Errorf("product image = %q", p.ImageURL)
+	}
+	assertVariant(t, p.Variants[0], models.Variant{Price: "19.99", CompareAtPrice: "24.99", Title: "Default Title", Available: true})
+
+	// A relative link resolves against the category page; the lazy image's
+	// data-src beats its placeholder
+	p = products[1]
+	if p.Brand != "California Gold Nutrition" || p.Title != "TMG, 500 mg, 90 Veggie Capsules" ||
+		p.Handle != srv.URL+"/pr/california-gold-nutrition-tmg-500-mg-90-veggie-capsules/117250" ||
+		p.ImageURL != "https://cloudinary.images-iherb.com/image/upload/images/cgn/cgn02018/l/2.jpg" {
+		t.Errorf("product = %+v", p)
+	}
+	assertVariant(t, p.Variants[0], models.Variant{Price: "14.00", Title: "Default Title", Available: false})
+
+	if p = products[2]; p.ID != "120331" || p.Brand != "Life Extension" {
+		t.Errorf("page 2 product = %+v", p)
+	}
+	assertVariant(t, p.Variants[0], models.Variant{Price: "39.95", Title: "Default Title", Available: true})
+}
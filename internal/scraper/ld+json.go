@@ -5,12 +5,14 @@ import (
 	"fmt"
 	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
-	"time"
 
 	"longevity-ranker/internal/models"
 )
 
+var reLdSchema = regexp.MustCompile(`(?s)<script type="application/ld\+json"[^>]*>(.*?)</script>`)
+
 type LdJsonGraph struct {
 	Graph []LdNode `json:"@graph"`
 }
@@ -31,9 +33,28 @@ type LdVariant struct {
 }
 
 type LdOffer struct {
-	Price         interface{} `json:"price"`
-	PriceCurrency string      `json:"priceCurrency"`
-	Availability  string      `json:"availability"`
+	Price              interface{}     `json:"price"`
+	PriceCurrency      string          `json:"priceCurrency"`
+	Availability       string          `json:"availability"`
+	PriceSpecification json.RawMessage `json:"priceSpecification,omitempty"` // One object or a list
+}
+
+// LdPriceSpec is one priceSpecification entry. Only UnitPriceSpecification
+// entries with a mass referenceQuantity ("100 GRM") are used.
+type LdPriceSpec struct {
+	Type              interface{} `json:"@type"`
+	Price             interface{} `json:"price"`
+	ReferenceQuantity struct {
+		Value    interface{} `json:"value"`
+		UnitCode string      `json:"unitCode"`
+		UnitText string      `json:"unitText"`
+	} `json:"referenceQuantity"`
+}
+
+// unitGrams maps UN/CEFACT unit codes and common unit texts to grams.
+var unitGrams = map[string]float64{
+	"GRM": 1, "KGM": 1000, "MGM": 0.001,
+	"g": 1, "kg": 1000, "mg": 0.001,
 }
 
 func FetchLdJsonProducts(vendor models.Vendor) ([]models.Product, error) {
@@ -44,97 +65,136 @@ func FetchLdJsonProducts(vendor models.Vendor) ([]models.Product, error) {
 		return nil, fmt.Errorf("invalid vendor URL: %v", err)
 	}
 
-	shopBody, err := FetchBody(vendor.URL)
+	shopPages, err := fetchEntryPages(vendor)
 	if err != nil {
 		return nil, err
 	}
 
 	reProductLink := regexp.MustCompile(`href="([^"]*?)"`)
-	matches := reProductLink.FindAllStringSubmatch(string(shopBody), -1)
-
 	uniqueLinks := make(map[string]bool)
-	for _, m := range matches {
-		relURL, err := url.Parse(m[1])
-		if err != nil {
-			continue
-		}
-		absURL := baseURL.ResolveReference(relURL)
-		if absURL.Host == baseURL.Host && strings.Contains(absURL.Path, "/product/") {
-			uniqueLinks[absURL.String()] = true
+	for _, page := range shopPages {
+		for _, m := range reProductLink.FindAllStringSubmatch(page.HTML, -1) {
+			relURL, err := url.Parse(m[1])
+			if err != nil {
+				continue
+			}
+			absURL := page.URL.ResolveReference(relURL)
+			if absURL.Host == baseURL.Host && isLdJsonProductPath(absURL.Path) {
+				uniqueLinks[absURL.String()] = true
+			}
 		}
 	}
 
-	fmt.Printf("   -> Found %d unique product pages.\n", len(uniqueLinks))
+	discoverSitemap(vendor, uniqueLinks)
+	uniqueLinks, collapsed := canonicalLinks(uniqueLinks)
+	fmt.Printf("   -> Found %d unique product pages%s.\n", len(uniqueLinks), collapsedNote(collapsed))
 
-	var products []models.Product
+	return crawlPages(vendor, uniqueLinks, parseLdJsonProductPage), nil
+}
 
-	for link := range uniqueLinks {
-		time.Sleep(300 * time.Millisecond)
+// isLdJsonProductPath reports whether a link path is a product page
+// (WooCommerce's /product/<slug>/).
+func isLdJsonProductPath(path string) bool {
+	return strings.Contains(path, "/product/")
+}
 
-		pageBody, err := FetchBody(link)
-		if err != nil {
+// parseLdJsonProductPage extracts the Product nodes (and their variants) from
+// a product page's schema.org LD+JSON @graph.
+func parseLdJsonProductPage(html, link string) []models.Product {
+	var products []models.Product
+	for _, match := range reLdSchema.FindAllStringSubmatch(html, -1) {
+		var graph LdJsonGraph
+		if err := json.Unmarshal([]byte(match[1]), &graph); err != nil {
 			continue
 		}
 
-		reSchema := regexp.MustCompile(`(?s)<script type="application/ld\+json"[^>]*>(.*?)</script>`)
-		schemaMatches := reSchema.FindAllStringSubmatch(string(pageBody), -1)
-
-		for _, match := range schemaMatches {
-			var graph LdJsonGraph
-			if err := json.Unmarshal([]byte(match[1]), &graph); err != nil {
+		for _, node := range graph.Graph {
+			if !isProductType(node.Type) {
 				continue
 			}
 
-			for _, node := range graph.Graph {
-				if !isProductType(node.Type) {
-					continue
-				}
+			imgURL := extractImageURL(node.Image)
 
-				imgURL := extractImageURL(node.Image)
-
-				if len(node.HasVariant) > 0 {
-					for _, v := range node.HasVariant {
-						desc := v.Description
-						if desc == "" {
-							desc = node.Description
-						}
-
-						products = append(products, models.Product{
-							ID:       v.Name,
-							Title:    v.Name,
-							Handle:   link,
-							BodyHTML: desc,
-							ImageURL: imgURL,
-							Variants: []models.Variant{
-								{
-									Price:     fmt.Sprintf("%v", v.Offers.Price),
-									Title:     v.Name,
-									Available: strings.Contains(v.Offers.Availability, "InStock"),
-								},
-							},
-						})
+			if len(node.HasVariant) > 0 {
+				for _, v := range node.HasVariant {
+					desc := v.Description
+					if desc == "" {
+						desc = node.Description
 					}
-				} else if node.Offers != nil {
+
 					products = append(products, models.Product{
-						ID:       node.Name,
-						Title:    node.Name,
+						ID:       v.Name,
+						Title:    v.Name,
 						Handle:   link,
-						BodyHTML: node.Description,
+						BodyHTML: desc,
 						ImageURL: imgURL,
+						Currency: strings.ToUpper(v.Offers.PriceCurrency),
 						Variants: []models.Variant{
 							{
-								Price:     fmt.Sprintf("%v", node.Offers.Price),
-								Title:     node.Name,
-								Available: strings.Contains(node.Offers.Availability, "InStock"),
+								Price:     fmt.Sprintf("%v", v.Offers.Price),
+								Title:     v.Name,
+								Available: strings.Contains(v.Offers.Availability, "InStock"),
+								UnitPrice: unitPricePerGram(v.Offers.PriceSpecification),
 							},
 						},
 					})
 				}
+			} else if node.Offers != nil {
+				products = append(products, models.Product{
+					ID:       node.Name,
+					Title:    node.Name,
+					Handle:   link,
+					BodyHTML: node.Description,
+					ImageURL: imgURL,
+					Currency: strings.ToUpper(node.Offers.PriceCurrency),
+					Variants: []models.Variant{
+						{
+							Price:     fmt.Sprintf("%v", node.Offers.Price),
+							Title:     node.Name,
+							Available: strings.Contains(node.Offers.Availability, "InStock"),
+							UnitPrice: unitPricePerGram(node.Offers.PriceSpecification),
+						},
+					},
+				})
 			}
 		}
 	}
+	return products
+}
 
-	return products, nil
+// unitPricePerGram returns the price per gram stated by an offer's
+// UnitPriceSpecification, or 0 when it has none with a mass reference
+// quantity (a missing value means 1 unit).
+func unitPricePerGram(raw json.RawMessage) float64 {
+	var specs []LdPriceSpec
+	if json.Unmarshal(raw, &specs) != nil {
+		var spec LdPriceSpec
+		if json.Unmarshal(raw, &spec) != nil {
+			return 0
+		}
+		specs = []LdPriceSpec{spec}
+	}
+	for _, spec := range specs {
+		if !hasLdType(spec.Type, "UnitPriceSpecification") {
+			continue
+		}
+		grams, ok := unitGrams[spec.ReferenceQuantity.UnitCode]
+		if !ok {
+			grams, ok = unitGrams[strings.ToLower(strings.TrimSpace(spec.ReferenceQuantity.UnitText))]
+		}
+		price, err := strconv.ParseFloat(fmt.Sprint(spec.Price), 64)
+		if !ok || err != nil || price <= 0 {
+			continue
+		}
+		quantity := 1.0
+		if spec.ReferenceQuantity.Value != nil {
+			if quantity, err = strconv.ParseFloat(fmt.Sprint(spec.ReferenceQuantity.Value), 64); err != nil || quantity <= 0 {
+				continue
+			}
+		}
+		return price / (quantity * grams)
+	}
+	return 0
 }
 
 // extractImageURL handles the polymorphic image field (string or []string).
@@ -151,12 +211,17 @@ func extractImageURL(img interface{}) string {
 }
 
 func isProductType(t interface{}) bool {
+	return hasLdType(t, "Product") || hasLdType(t, "ProductGroup")
+}
+
+// hasLdType reports whether a polymorphic @type (string or []string) is name.
+func hasLdType(t interface{}, name string) bool {
 	if s, ok := t.(string); ok {
-		return s == "Product" || s == "ProductGroup"
+		return s == name
 	}
 	if arr, ok := t.([]interface{}); ok {
 		for _, v := range arr {
-			if s, ok := v.(string); ok && (s == "Product" || s == "ProductGroup") {
+			if s, ok := v.(string); ok && s == name {
 				return true
 			}
 		}
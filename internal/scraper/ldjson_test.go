@@ -0,0 +1,95 @@
+package scraper
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"longevity-ranker/internal/models"
+)
+
+func TestFetchLdJsonProductsContract(t *testing.T) {
+	srv := serveFixtures(t, map[string]string{
+		"/shop/":                           "ldjson_shop.html",
+		"/product/wonderfeel-youngr-nmn/":  "ldjson_product_group.html",
+		"/product/wonderfeel-nmn-capsuls/": "ldjson_product.html",
+	})
+
+	products, err := FetchLdJsonProducts(models.Vendor{Name: "Fixture LD", URL: srv.URL + "/shop/", Type: "html-ldjson"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sortProducts(products)
+
+	want := []struct {
+		id, handle, body, image string
+		variant                 models.Variant
+	}{
+		{
+			id:      "Wonderfeel NMN Capsuls™ 1000 mg",
+			handle:  srv.URL + "/product/wonderfeel-nmn-capsuls/",
+			body:    "1000mg per serving, 60 capsules.",
+			image:   "https://getwonderfeel.com/capsuls.png",
+			variant: models.Variant{Price: "58.00", Title: "Wonderfeel NMN Capsuls™ 1000 mg", Available: true},
+		},
+		{
+			id:      "Wonderfeel Youngr™ NMN - 1 bottle",
+			handle:  srv.URL + "/product/wonderfeel-youngr-nmn/",
+			body:    "900mg NMN per serving, 60 capsules, 2 capsules per serving.",
+			image:   "https://getwonderfeel.com/youngr.png",
+			variant: models.Variant{Price: "88.00", Title: "Wonderfeel Youngr™ NMN - 1 bottle", Available: true},
+		},
+		{
+			id:      "Wonderfeel Youngr™ NMN - 3 bottles",
+			handle:  srv.URL + "/product/wonderfeel-youngr-nmn/",
+			body:    "Three bottles, 180 capsules.",
+			image:   "https://getwonderfeel.com/youngr.png",
+			variant: models.Variant{Price: "237", Title: "Wonderfeel Youngr™ NMN - 3 bottles", Available: false},
+		},
+	}
+	if len(products) != len(want) {
+		t.Fatalf("products = %d, want %d: %+v", len(products), len(want), products)
+	}
+	for i, w := range want {
+		p := products[i]
+		if p.ID != w.id || p.Title != w.id || p.Handle != w.handle {
+			t.Errorf("product[%d] id/title/handle = %q/%q/%q", i, p.ID, p.Title, p.Handle)
+		}
+		if p.BodyHTML != w.body {
+			t.Errorf("product[%d].BodyHTML = %q, want %q", i, p.BodyHTML, w.body)
+		}
+		if p.ImageURL != w.image {
+			t.Errorf("product[%d].ImageURL = %q, want %q", i, p.ImageURL, w.image)
+		}
+		if p.Currency != "USD" {
+			t.Errorf("product[%d].Currency = %q, want USD", i, p.Currency)
+		}
+		assertVariant(t, p.Variants[0], w.variant)
+	}
+}
+
+func TestUnitPricePerGram(t *testing.T) {
+	tests := []struct {
+		name, raw string
+		want      float64
+	}{
+		{"per 100 g", `{"@type": "UnitPriceSpecification", "price": 12.5, "referenceQuantity": {"value": 100, "unitCode": "GRM"}}`, 0.125},
+		{"per kg in a list", `[{"@type": "PriceSpecification", "price": 99}, {"@type": "UnitPriceSpecification", "price": "80.00", "referenceQuantity": {"value": "1", "unitCode": "KGM"}}]`, 0.08},
+		{"unit text, no value", `{"@type": "UnitPriceSpecification", "price": 0.2, "referenceQuantity": {"unitText": "g"}}`, 0.2},
+		{"per capsule", `{"@type": "UnitPriceSpecification", "price": 0.5, "referenceQuantity": {"value": 1, "unitCode": "C62"}}`, 0},
+		{"no specification", ``, 0},
+	}
+	for _, tt := range tests {
+		if got := unitPricePerGram(json.RawMessage(tt.raw)); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: unitPricePerGram() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	html := `<script type="application/ld+json">{"@graph": [{"@type": "Product", "name": "NMN Powder 50g",
+		"offers": {"price": "30.00", "availability": "InStock",
+			"priceSpecification": {"@type": "UnitPriceSpecification", "price": "60.00", "referenceQuantity": {"value": 100, "unitCode": "GRM"}}}}]}</script>`
+	products := parseLdJsonProductPage(html, "https://shop.test/product/nmn/")
+	if len(products) != 1 || products[0].Variants[0].UnitPrice != 0.6 {
+		t.Errorf("parseLdJsonProductPage() = %+v, want one variant at 0.60/g", products)
+	}
+}
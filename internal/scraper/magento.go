@@ -3,12 +3,13 @@ package scraper
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
 	"net/url"
 	"regexp"
 	"slices"
+	"sort"
 	"strconv"
 	"strings"
-	"time"
 
 	"longevity-ranker/internal/models"
 )
@@ -25,6 +26,7 @@ var (
 	reDescDiv     = regexp.MustCompile(`class="product attribute description"[^>]*>.*?<div class="value"[^>]*>(.*?)</div>`)
 	reItemProp    = regexp.MustCompile(`<meta itemprop="image" content="([^"]*?)"`)
 	reOgImage     = regexp.MustCompile(`<meta property="og:image" content="([^"]*?)"`)
+	reMinAllowed  = regexp.MustCompile(`minAllowed(?:"|&quot;)\s*:\s*"?(\d+)`)
 )
 
 // --- Magento JSON Structures ---
@@ -38,7 +40,7 @@ type MagentoInit struct {
 }
 
 type MagentoJsonConfig struct {
-	Attributes   map[string]MagentoAttribute   `json:"attributes"`
+	Attributes   map[string]MagentoAttribute    `json:"attributes"`
 	OptionPrices map[string]MagentoOptionPrice  `json:"optionPrices"`
 	Salable      map[string]map[string][]string `json:"salable"`
 	Images       map[string][]MagentoImage      `json:"images"`
@@ -63,6 +65,9 @@ type MagentoOption struct {
 }
 
 type MagentoOptionPrice struct {
+	OldPrice struct {
+		Amount float64 `json:"amount"`
+	} `json:"oldPrice"`
 	FinalPrice struct {
 		Amount float64 `json:"amount"`
 	} `json:"finalPrice"`
@@ -74,7 +79,7 @@ type DnaBulkInit struct {
 	BulkOptions struct {
 		BulkConfig struct {
 			BulkBuyConfig map[string]DnaTierInfo `json:"bulkBuyConfig"`
-			DnaIdToSku    map[string]string       `json:"dnaIdToSku"`
+			DnaIdToSku    map[string]string      `json:"dnaIdToSku"`
 		} `json:"bulkBuyConfig"`
 	} `json:"DoNotAge_BulkBuy/js/catalog/product/view/bulkbuy-options"`
 }
@@ -86,35 +91,26 @@ type DnaTierInfo struct {
 
 // --- Scraper Logic ---
 
+// FetchMagentoProducts collects the product links of the vendor's category
+// page and any extra Collections (fetched in parallel), then parses each
+// product page once (see crawlPages for the order).
 func FetchMagentoProducts(vendor models.Vendor) ([]models.Product, error) {
 	fmt.Printf("🔍 Crawling %s (Magento)...\n", vendor.Name)
 
-	baseURL, err := url.Parse(vendor.URL)
-	if err != nil {
-		return nil, err
-	}
-
-	shopBody, err := FetchBody(vendor.URL)
+	shopPages, err := fetchEntryPages(vendor)
 	if err != nil {
 		return nil, err
 	}
 
-	uniqueLinks := extractProductLinks(string(shopBody), baseURL)
-	fmt.Printf("   -> Found %d potential products.\n", len(uniqueLinks))
-
-	var products []models.Product
-	for link := range uniqueLinks {
-		time.Sleep(300 * time.Millisecond)
-
-		pageBody, err := FetchBody(link)
-		if err != nil {
-			continue
-		}
-
-		products = append(products, parseMagentoProductPage(string(pageBody), link)...)
+	uniqueLinks := make(map[string]bool)
+	for _, page := range shopPages {
+		maps.Copy(uniqueLinks, extractProductLinks(page.HTML, page.URL))
 	}
+	discoverSitemap(vendor, uniqueLinks)
+	uniqueLinks, collapsed := canonicalLinks(uniqueLinks)
+	fmt.Printf("   -> Found %d potential products%s.\n", len(uniqueLinks), collapsedNote(collapsed))
 
-	return products, nil
+	return crawlPages(vendor, uniqueLinks, parseMagentoProductPage), nil
 }
 
 // extractProductLinks finds all product URLs on the category page.
@@ -132,7 +128,20 @@ func extractProductLinks(html string, baseURL *url.URL) map[string]bool {
 	return uniqueLinks
 }
 
-// parseMagentoProductPage processes a single product page HTML.
+// sortedLinks returns the link set in order, so product pages are fetched
+// and saved in the same order on every run.
+func sortedLinks(links map[string]bool) []string {
+	sorted := make([]string, 0, len(links))
+	for link := range links {
+		sorted = append(sorted, link)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// parseMagentoProductPage processes a single product page HTML into one
+// product whose variants are the page's one-time options and bulk tiers,
+// ordered by option ID.
 func parseMagentoProductPage(html, link string) []models.Product {
 	title := getCleanTitle(html)
 	context := getSeoContext(html)
@@ -145,7 +154,14 @@ func parseMagentoProductPage(html, link string) []models.Product {
 	}
 
 	oneTimeIDs, checkPurchase := getOneTimePurchaseIDs(stdConfig)
-	return extractVariants(stdConfig, bulkConfig, oneTimeIDs, checkPurchase, title, context, desc, fallbackImg, link)
+	minQty := getMinOrderQty(html)
+	products := extractVariants(stdConfig, bulkConfig, oneTimeIDs, checkPurchase, minQty, title, context, desc, fallbackImg, link)
+	currency := pageCurrency(html)
+	for i := range products {
+		products[i].Currency = currency
+	}
+	slices.SortFunc(products, func(a, b models.Product) int { return strings.Compare(a.ID, b.ID) })
+	return MergeByHandle(products)
 }
 
 // parseMagentoConfigs extracts the JSON blobs from the HTML scripts.
@@ -204,6 +220,7 @@ func extractVariants(
 	bulkConfig DnaBulkInit,
 	oneTimeIDs map[string]bool,
 	checkPurchase bool,
+	minQty int,
 	title, context, desc, fallbackImg, link string,
 ) []models.Product {
 	var products []models.Product
@@ -228,6 +245,10 @@ func extractVariants(
 				isAvailable := checkAvailability(stdConfig, attr.ID, opt.ID, pid)
 				variantImage := resolveImage(stdConfig, pid, fallbackImg)
 				basePrice := priceInfo.FinalPrice.Amount
+				compareAt := ""
+				if old := priceInfo.OldPrice.Amount; old > basePrice {
+					compareAt = fmt.Sprintf("%.2f", old)
+				}
 
 				// Single unit product
 				products = append(products, models.Product{
@@ -238,14 +259,16 @@ func extractVariants(
 					ImageURL: variantImage,
 					Handle:   link,
 					Variants: []models.Variant{{
-						Price:     fmt.Sprintf("%.2f", basePrice),
-						Title:     opt.Label,
-						Available: isAvailable,
+						Price:          fmt.Sprintf("%.2f", basePrice),
+						CompareAtPrice: compareAt,
+						Title:          opt.Label,
+						Available:      isAvailable,
+						MinOrderQty:    packsForMinQty(minQty, 1),
 					}},
 				})
 
 				// Bulk packs
-				products = append(products, extractBulkVariants(bulkConfig, pid, title, context, desc, variantImage, link, opt.Label, isAvailable)...)
+				products = append(products, extractBulkVariants(bulkConfig, pid, title, context, desc, variantImage, link, opt.Label, isAvailable, minQty)...)
 			}
 		}
 	}
@@ -257,6 +280,7 @@ func extractBulkVariants(
 	bulkConfig DnaBulkInit,
 	pid, title, context, desc, img, link, label string,
 	isAvailable bool,
+	minQty int,
 ) []models.Product {
 	sku, ok := bulkConfig.BulkOptions.BulkConfig.DnaIdToSku[pid]
 	if !ok {
@@ -281,9 +305,10 @@ func extractBulkVariants(
 			ImageURL: img,
 			Handle:   link,
 			Variants: []models.Variant{{
-				Price:     fmt.Sprintf("%.2f", unitPrice*float64(qty)),
-				Title:     fmt.Sprintf("%s - %d Pack", label, qty),
-				Available: isAvailable,
+				Price:       fmt.Sprintf("%.2f", unitPrice*float64(qty)),
+				Title:       fmt.Sprintf("%s - %d Pack", label, qty),
+				Available:   isAvailable,
+				MinOrderQty: packsForMinQty(minQty, qty),
 			}},
 		})
 	}
@@ -319,6 +344,26 @@ func resolveImage(config MagentoJsonConfig, pid, fallback string) string {
 	return fallback
 }
 
+// getMinOrderQty reads the cart quantity minimum ("minAllowed" in the qty
+// input's validation rules). Returns 0 when the page sets none.
+func getMinOrderQty(html string) int {
+	if m := reMinAllowed.FindStringSubmatch(html); len(m) > 1 {
+		qty, _ := strconv.Atoi(m[1])
+		return qty
+	}
+	return 0
+}
+
+// packsForMinQty converts a unit minimum into the number of packs of packSize
+// units that must be bought. Returns 0 (no minimum) when one pack suffices.
+func packsForMinQty(minQty, packSize int) int {
+	packs := (minQty + packSize - 1) / packSize
+	if packs <= 1 {
+		return 0
+	}
+	return packs
+}
+
 // --- HTML Extraction Helpers ---
 
 func getCleanTitle(html string) string {
@@ -356,4 +401,4 @@ func getImageFromHTML(html string) string {
 		return m[1]
 	}
 	return ""
-}
\ No newline at end of file
+}
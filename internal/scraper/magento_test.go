@@ -0,0 +1,90 @@
+package scraper
+
+import (
+	"testing"
+
+	"longevity-ranker/internal/models"
+)
+
+func TestFetchMagentoProductsContract(t *testing.T) {
+	srv := serveFixtures(t, map[string]string{
+		"/products/": "magento_category.html",
+		"/pure-nmn":  "magento_product.html",
+	})
+
+	products, err := FetchMagentoProducts(models.Vendor{Name: "Fixture Magento", URL: srv.URL + "/products/", Type: "magento"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(products) != 1 {
+		t.Fatalf("products = %d, want the page as one product: %+v", len(products), products)
+	}
+
+	p := products[0]
+	if p.ID != "101" || p.Title != "Pure NMN" || p.Handle != srv.URL+"/pure-nmn" {
+		t.Errorf("product id/title/handle = %q/%q/%q", p.ID, p.Title, p.Handle)
+	}
+	if p.Context != "Buy Pure NMN 500mg | 60/120 Capsules | DoNotAge" {
+		t.Errorf("product.Context = %q", p.Context)
+	}
+	if p.BodyHTML != "Pure NMN capsules, 500mg per capsule. Third-party tested." {
+		t.Errorf("product.BodyHTML = %q", p.BodyHTML)
+	}
+	// Option 101 has a variant image, which becomes the product's
+	if p.ImageURL != "https://donotage.org/media/pure-nmn-60-full.png" {
+		t.Errorf("product image = %q", p.ImageURL)
+	}
+
+	// One-time options only (subscription IDs 201/202 are dropped), plus the
+	// 3- and 6-pack bulk tiers of option 101, by option ID. Tier "1" is not
+	// a bulk pack. The page's minimum cart quantity of 4 takes two 3-packs
+	// but one 6-pack. Option 102 falls back to og:image.
+	want := []models.Variant{
+		{Price: "39.00", CompareAtPrice: "49.00", Title: "60 Capsules", Available: true, MinOrderQty: 4},
+		{Price: "105.00", Title: "60 Capsules - 3 Pack", Available: true, MinOrderQty: 2},
+		{Price: "192.00", Title: "60 Capsules - 6 Pack", Available: true},
+		{Price: "69.00", Title: "120 Capsules", Available: false, MinOrderQty: 4,
+			ImageURL: "https://donotage.org/media/catalog/product/pure-nmn.png"},
+	}
+	if len(p.Variants) != len(want) {
+		t.Fatalf("variants = %d, want %d: %+v", len(p.Variants), len(want), p.Variants)
+	}
+	for i, w := range want {
+		assertVariant(t, p.Variants[i], w)
+	}
+}
+
+func TestFetchMagentoProductsCollections(t *testing.T) {
+	srv := serveFixtures(t, map[string]string{
+		"/products/": "magento_category.html",
+		"/powders/":  "magento_category.html",
+		"/pure-nmn":  "magento_product.html",
+	})
+
+	// Both category pages list /pure-nmn; it is parsed once
+	products, err := FetchMagentoProducts(models.Vendor{
+		Name: "Fixture Magento", URL: srv.URL + "/products/", Type: "magento",
+		Collections: []string{srv.URL + "/powders/", srv.URL + "/products/"},
+	})
+	if err != nil || len(products) != 1 || len(products[0].Variants) != 4 {
+		t.Errorf("FetchMagentoProducts() = %d products, %v; want /pure-nmn once", len(products), err)
+	}
+}
+
+func TestFetchProductPages(t *testing.T) {
+	srv := serveFixtures(t, map[string]string{
+		"/products/": "magento_category.html",
+		"/pure-nmn":  "magento_product.html",
+	})
+	vendor := models.Vendor{Name: "Fixture Magento", URL: srv.URL + "/products/", Type: "magento"}
+
+	// Only the watched page is fetched, not the category
+	products, ok, err := FetchProductPages(vendor, []string{srv.URL + "/pure-nmn"})
+	if !ok || err != nil || len(products) != 1 || products[0].Handle != srv.URL+"/pure-nmn" {
+		t.Errorf("FetchProductPages() = %d products, %v, %v; want /pure-nmn", len(products), ok, err)
+	}
+
+	if _, ok, _ := FetchProductPages(models.Vendor{Type: "shopify"}, []string{"nmn"}); ok {
+		t.Error("FetchProductPages(shopify) ok = true, want false (fetched whole)")
+	}
+}
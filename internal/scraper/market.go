@@ -0,0 +1,49 @@
+package scraper
+
+import (
+	"net/url"
+	"strings"
+
+	"longevity-ranker/internal/models"
+)
+
+// marketCookie is the cookie a Shopify storefront keeps the shopper's
+// country in; its value picks the market's prices.
+const marketCookie = "localization"
+
+// marketCountry returns the upper-cased country of a Shopify Markets locale
+// ("en-gb" → "GB"), or "" when it names a language only.
+func marketCountry(market string) string {
+	if _, country, ok := strings.Cut(market, "-"); ok {
+		return strings.ToUpper(country)
+	}
+	return ""
+}
+
+// marketPath puts path under the vendor's market subfolder ("/products.json"
+// → "/en-gb/products.json"). A path already in it, or a vendor without a
+// market, is returned unchanged.
+func marketPath(vendor models.Vendor, path string) string {
+	if vendor.Market == "" {
+		return path
+	}
+	prefix := "/" + vendor.Market
+	if path == prefix || strings.HasPrefix(path, prefix+"/") {
+		return path
+	}
+	return prefix + "/" + strings.TrimPrefix(path, "/")
+}
+
+// marketURL is rawURL with its path under the vendor's market subfolder;
+// an unparsable URL is returned unchanged.
+func marketURL(vendor models.Vendor, rawURL string) string {
+	if vendor.Market == "" {
+		return rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	u.Path = marketPath(vendor, u.Path)
+	return u.String()
+}
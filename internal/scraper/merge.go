@@ -0,0 +1,58 @@
+package scraper
+
+import "longevity-ranker/internal/models"
+
+// MergeByHandle folds products that share a handle into one product whose
+// variants are theirs, in order. Magento pages list every size option and
+// bulk tier under its own product ID, so without this one product page
+// becomes several products: the sibling price check sees a single variant,
+// and overrides, review decisions and dedup keyed by handle match several
+// products at once.
+//
+// The merged product keeps the first product's ID, title, description and
+// image; a variant of another product that has no image of its own takes
+// that product's image. Variants repeating a title already merged are
+// dropped, since history keys on the variant title. Products without a
+// handle are kept as they are. Merging is idempotent, so cached files
+// written before it can be passed through again.
+func MergeByHandle(products []models.Product) []models.Product {
+	merged := make([]models.Product, 0, len(products))
+	at := make(map[string]int) // handle → index in merged
+	for _, p := range products {
+		i, seen := at[p.Handle]
+		if p.Handle == "" || !seen {
+			if p.Handle != "" {
+				at[p.Handle] = len(merged)
+			}
+			p.Variants = appendVariants(nil, p, p.ImageURL)
+			merged = append(merged, p)
+			continue
+		}
+		merged[i].Variants = appendVariants(merged[i].Variants, p, merged[i].ImageURL)
+	}
+	return merged
+}
+
+// appendVariants appends p's variants whose titles are not in variants yet,
+// giving them p's image when it differs from the merged product's.
+func appendVariants(variants []models.Variant, p models.Product, productImage string) []models.Variant {
+	for _, v := range p.Variants {
+		if hasVariantTitle(variants, v.Title) {
+			continue
+		}
+		if v.ImageURL == "" && p.ImageURL != productImage {
+			v.ImageURL = p.ImageURL
+		}
+		variants = append(variants, v)
+	}
+	return variants
+}
+
+func hasVariantTitle(variants []models.Variant, title string) bool {
+	for _, v := range variants {
+		if v.Title == title {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package scraper
+
+import (
+	"reflect"
+	"testing"
+
+	"longevity-ranker/internal/models"
+)
+
+func TestMergeByHandle(t *testing.T) {
+	option := func(id, handle, image string, titles ...string) models.Product {
+		p := models.Product{ID: id, Title: "Pure NMN", Handle: handle, ImageURL: image}
+		for _, title := range titles {
+			p.Variants = append(p.Variants, models.Variant{Title: title, Price: "10"})
+		}
+		return p
+	}
+	products := []models.Product{
+		option("101", "pure-nmn", "60.png", "60 Capsules"),
+		option("101-3", "pure-nmn", "60.png", "60 Capsules - 3 Pack"),
+		option("", "", "", "Default Title"),
+		option("102", "pure-nmn", "120.png", "120 Capsules", "60 Capsules"),
+		option("", "", "", "Default Title"),
+		option("301", "pure-tmg", "tmg.png", "250g"),
+	}
+
+	got := MergeByHandle(products)
+	want := []models.Product{
+		{ID: "101", Title: "Pure NMN", Handle: "pure-nmn", ImageURL: "60.png", Variants: []models.Variant{
+			{Title: "60 Capsules", Price: "10"},
+			{Title: "60 Capsules - 3 Pack", Price: "10"},
+			{Title: "120 Capsules", Price: "10", ImageURL: "120.png"},
+		}},
+		option("", "", "", "Default Title"),
+		option("", "", "", "Default Title"),
+		option("301", "pure-tmg", "tmg.png", "250g"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MergeByHandle() =\n%+v\nwant\n%+v", got, want)
+	}
+	if again := MergeByHandle(got); !reflect.DeepEqual(again, want) {
+		t.Errorf("MergeByHandle(merged) = %+v, want it unchanged", again)
+	}
+}
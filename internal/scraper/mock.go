@@ -0,0 +1,37 @@
+package scraper
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"longevity-ranker/internal/models"
+)
+
+// FetchMockProducts serves products from a fixture instead of a storefront.
+// vendor.URL is either a local file path or an http(s) URL (e.g. an httptest
+// server); both must hold a JSON []models.Product, the same schema as the
+// cached data/<vendor>.json files.
+func FetchMockProducts(vendor models.Vendor) ([]models.Product, error) {
+	data, err := readSource(vendor)
+	if err != nil {
+		return nil, fmt.Errorf("reading mock fixture %q: %v", vendor.URL, err)
+	}
+
+	var products []models.Product
+	if err := json.Unmarshal(data, &products); err != nil {
+		return nil, fmt.Errorf("parsing mock fixture %q: %v", vendor.URL, err)
+	}
+	fmt.Printf("🧪 Loaded %d mock products for %s\n", len(products), vendor.Name)
+	return products, nil
+}
+
+// readSource reads vendor.URL from the local filesystem, or over HTTP when it
+// is an http(s) URL. Used by the file-backed vendor types (mock, csv).
+func readSource(vendor models.Vendor) ([]byte, error) {
+	if strings.HasPrefix(vendor.URL, "http://") || strings.HasPrefix(vendor.URL, "https://") {
+		return FetchBody(vendor, vendor.URL)
+	}
+	return os.ReadFile(vendor.URL)
+}
@@ -0,0 +1,32 @@
+package scraper
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// reDisplayedAmount matches the number of a displayed price, with its
+// thousands and decimal separators.
+var reDisplayedAmount = regexp.MustCompile(`\d[\d.,\s]*`)
+
+// parseDisplayedPrice parses a price as a storefront shows it ("$1,299.00",
+// "29,99 €"), for the scrapers that read prices off rendered pages (Amazon,
+// iHerb, generic HTML). A comma or dot followed by exactly two digits at
+// the end is the decimal mark; every other separator groups thousands.
+func parseDisplayedPrice(s string) (float64, bool) {
+	digits := strings.Join(strings.Fields(reDisplayedAmount.FindString(s)), "")
+	if digits == "" {
+		return 0, false
+	}
+	whole, cents := digits, ""
+	if i := strings.LastIndexAny(digits, ".,"); i >= 0 && len(digits)-i == 3 {
+		whole, cents = digits[:i], digits[i+1:]
+	}
+	whole = strings.NewReplacer(",", "", ".", "").Replace(whole)
+	v, err := strconv.ParseFloat(whole+"."+cents, 64)
+	if err != nil || v <= 0 {
+		return 0, false
+	}
+	return v, true
+}
@@ -0,0 +1,22 @@
+package scraper
+
+import "testing"
+
+func TestParseDisplayedPrice(t *testing.T) {
+	for s, want := range map[string]float64{
+		"$29.99":      29.99,
+		"$1,049.99":   1049.99,
+		"29,99 €":     29.99,
+		"1.049,99 €":  1049.99,
+		"£1,049":      1049,
+		"￥2,980":      2980,
+		"1 049,99 zł": 1049.99,
+	} {
+		if got, ok := parseDisplayedPrice(s); !ok || got != want {
+			t.Errorf("parseDisplayedPrice(%q) = %v, %v; want %v", s, got, ok, want)
+		}
+	}
+	if _, ok := parseDisplayedPrice("See price in cart"); ok {
+		t.Error("parseDisplayedPrice(no digits) ok, want false")
+	}
+}
@@ -0,0 +1,222 @@
+package scraper
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"longevity-ranker/internal/models"
+)
+
+// priceAPIParsers decode a price API response body, keyed by
+// Vendor.APIFormat. vendor.URL is passed for formats that take settings
+// (e.g. the Amazon marketplace) from the request.
+var priceAPIParsers = map[string]func(body []byte, endpoint *url.URL) ([]models.Product, error){
+	"":      parseOfferList,
+	"keepa": parseKeepaProducts,
+}
+
+// FetchPriceAPIProducts queries a commercial price API (vendor.URL is the
+// full request URL, including product IDs) and converts its response into
+// products with the parser for vendor.APIFormat. The API key is read from
+// the vendor.APIKeyEnv environment variable.
+func FetchPriceAPIProducts(vendor models.Vendor) ([]models.Product, error) {
+	parse, ok := priceAPIParsers[vendor.APIFormat]
+	if !ok {
+		return nil, fmt.Errorf("unknown price API format %q", vendor.APIFormat)
+	}
+	endpoint, err := url.Parse(vendor.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	key := ""
+	if vendor.APIKeyEnv != "" {
+		if key = os.Getenv(vendor.APIKeyEnv); key == "" {
+			return nil, fmt.Errorf("API key variable %s is not set", vendor.APIKeyEnv)
+		}
+	}
+	if key != "" {
+		if vendor.APIKeyParam != "" {
+			q := endpoint.Query()
+			q.Set(vendor.APIKeyParam, key)
+			endpoint.RawQuery = q.Encode()
+		} else {
+			headers := map[string]string{"Authorization": "Bearer " + key}
+			for k, v := range vendor.Headers {
+				headers[k] = v
+			}
+			vendor.Headers = headers
+		}
+	}
+
+	body, err := FetchBody(vendor, endpoint.String())
+	if err != nil {
+		if key != "" {
+			return nil, fmt.Errorf("%s", redactAPIKey(vendor, err.Error()))
+		}
+		return nil, err
+	}
+	products, err := parse(body, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("parsing price API response: %v", err)
+	}
+	fmt.Printf("💲 Loaded %d price API products for %s\n", len(products), vendor.Name)
+	return products, nil
+}
+
+// redactAPIKey replaces the vendor's API key in s, so errors and the error
+// report never leak it.
+func redactAPIKey(vendor models.Vendor, s string) string {
+	if vendor.APIKeyEnv == "" {
+		return s
+	}
+	if key := os.Getenv(vendor.APIKeyEnv); key != "" {
+		return strings.ReplaceAll(s, key, "REDACTED")
+	}
+	return s
+}
+
+// priceOffer is one entry of the normalized offer list, the format for APIs
+// without a dedicated parser: a small adapter (or the API's own field
+// mapping) returns {"offers": [...]}. Offers sharing a url are variants of
+// one product, as with CSV rows.
+type priceOffer struct {
+	ID        string  `json:"id"`
+	Title     string  `json:"title"`
+	Variant   string  `json:"variant"`
+	URL       string  `json:"url"`
+	Price     float64 `json:"price"`
+	ListPrice float64 `json:"list_price"`
+	Available *bool   `json:"available"` // Missing = in stock
+	ImageURL  string  `json:"image_url"`
+}
+
+func parseOfferList(body []byte, _ *url.URL) ([]models.Product, error) {
+	var resp struct {
+		Offers []priceOffer `json:"offers"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	var products []models.Product
+	byHandle := make(map[string]int)
+	for i, o := range resp.Offers {
+		if o.Title == "" || o.URL == "" {
+			return nil, fmt.Errorf("offer %d: title and url are required", i)
+		}
+		if o.Price <= 0 {
+			continue // No current offer
+		}
+		variant := models.Variant{
+			Price:     fmt.Sprintf("%.2f", o.Price),
+			Title:     o.Variant,
+			Available: o.Available == nil || *o.Available,
+		}
+		if variant.Title == "" {
+			variant.Title = "Default Title"
+		}
+		if o.ListPrice > o.Price {
+			variant.CompareAtPrice = fmt.Sprintf("%.2f", o.ListPrice)
+		}
+		if i, ok := byHandle[o.URL]; ok {
+			products[i].Variants = append(products[i].Variants, variant)
+			continue
+		}
+		id := o.ID
+		if id == "" {
+			id = o.URL
+		}
+		byHandle[o.URL] = len(products)
+		products = append(products, models.Product{
+			ID:       id,
+			Title:    o.Title,
+			Handle:   o.URL,
+			ImageURL: o.ImageURL,
+			Variants: []models.Variant{variant},
+		})
+	}
+	return products, nil
+}
+
+// Keepa's stats.current indexes: prices in cents, -1 when there is no offer.
+const (
+	keepaAmazon    = 0
+	keepaNew       = 1
+	keepaListPrice = 4
+)
+
+// keepaDomains maps Keepa's domain parameter to the Amazon marketplace host.
+var keepaDomains = map[string]string{
+	"1": "www.amazon.com", "2": "www.amazon.co.uk", "3": "www.amazon.de",
+	"4": "www.amazon.fr", "5": "www.amazon.co.jp", "6": "www.amazon.ca",
+	"8": "www.amazon.it", "9": "www.amazon.es", "10": "www.amazon.in",
+	"11": "www.amazon.com.mx",
+}
+
+// parseKeepaProducts reads a Keepa /product response requested with stats
+// (e.g. ?domain=1&asin=B0...,B0...&stats=1). Each ASIN becomes a one-variant
+// product priced at Amazon's own offer, else the lowest new offer. Handles
+// are the product's Amazon URL on the requested marketplace.
+func parseKeepaProducts(body []byte, endpoint *url.URL) ([]models.Product, error) {
+	var resp struct {
+		Products []struct {
+			ASIN      string `json:"asin"`
+			Title     string `json:"title"`
+			ImagesCSV string `json:"imagesCSV"`
+			Stats     *struct {
+				Current []int `json:"current"`
+			} `json:"stats"`
+		} `json:"products"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+	host, ok := keepaDomains[endpoint.Query().Get("domain")]
+	if !ok {
+		host = keepaDomains["1"]
+	}
+
+	var products []models.Product
+	for _, kp := range resp.Products {
+		if kp.ASIN == "" || kp.Title == "" || kp.Stats == nil {
+			continue
+		}
+		current := func(i int) int {
+			if i < len(kp.Stats.Current) {
+				return kp.Stats.Current[i]
+			}
+			return -1
+		}
+		cents := current(keepaAmazon)
+		if cents <= 0 {
+			cents = current(keepaNew)
+		}
+		if cents <= 0 {
+			continue // Not sold new right now
+		}
+		variant := models.Variant{
+			Price:     fmt.Sprintf("%.2f", float64(cents)/100),
+			Title:     "Default Title",
+			Available: true,
+		}
+		if list := current(keepaListPrice); list > cents {
+			variant.CompareAtPrice = fmt.Sprintf("%.2f", float64(list)/100)
+		}
+
+		p := models.Product{
+			ID:       kp.ASIN,
+			Title:    kp.Title,
+			Handle:   "https://" + host + "/dp/" + kp.ASIN,
+			Variants: []models.Variant{variant},
+		}
+		if image, _, _ := strings.Cut(kp.ImagesCSV, ","); image != "" {
+			p.ImageURL = "https://m.media-amazon.com/images/I/" + image
+		}
+		products = append(products, p)
+	}
+	return products, nil
+}
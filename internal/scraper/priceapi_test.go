@@ -0,0 +1,98 @@
+package scraper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"longevity-ranker/internal/models"
+)
+
+func TestFetchPriceAPIKeepa(t *testing.T) {
+	body, err := os.ReadFile(filepath.Join("testdata", "keepa_product.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("key"); got != "secret" {
+			t.Errorf("key = %q, want secret", got)
+		}
+		w.Write(body)
+	}))
+	defer srv.Close()
+	t.Setenv("TEST_KEEPA_KEY", "secret")
+
+	products, err := FetchPriceAPIProducts(models.Vendor{
+		Name:        "Amazon",
+		URL:         srv.URL + "/product?domain=2&asin=B0NMN00001,B0TMG00002,B0OOS00003&stats=1",
+		Type:        "priceapi",
+		APIFormat:   "keepa",
+		APIKeyEnv:   "TEST_KEEPA_KEY",
+		APIKeyParam: "key",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []models.Product{
+		{
+			ID: "B0NMN00001", Title: "NMN 500mg 60 Capsules",
+			Handle:   "https://www.amazon.co.uk/dp/B0NMN00001",
+			ImageURL: "https://m.media-amazon.com/images/I/71nmnMain.jpg",
+			Variants: []models.Variant{{Price: "39.99", CompareAtPrice: "49.99", Title: "Default Title", Available: true}},
+		},
+		{
+			ID: "B0TMG00002", Title: "TMG Powder 250g",
+			Handle:   "https://www.amazon.co.uk/dp/B0TMG00002",
+			Variants: []models.Variant{{Price: "24.50", Title: "Default Title", Available: true}},
+		},
+	}
+	if !reflect.DeepEqual(products, want) {
+		t.Errorf("products =\n%+v\nwant\n%+v", products, want)
+	}
+}
+
+func TestFetchPriceAPIOfferList(t *testing.T) {
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		w.Write([]byte(`{"offers": [
+			{"id": "a1", "title": "NMN Powder", "variant": "100g", "url": "https://shop.example/nmn", "price": 49.5, "list_price": 59},
+			{"id": "a2", "title": "NMN Powder", "variant": "250g", "url": "https://shop.example/nmn", "price": 99, "available": false},
+			{"id": "a3", "title": "No Offer", "url": "https://shop.example/none", "price": 0}
+		]}`))
+	}))
+	defer srv.Close()
+	t.Setenv("TEST_PRICE_API_TOKEN", "tok")
+
+	products, err := FetchPriceAPIProducts(models.Vendor{
+		Name: "Price API", URL: srv.URL, Type: "priceapi", APIKeyEnv: "TEST_PRICE_API_TOKEN",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if auth != "Bearer tok" {
+		t.Errorf("Authorization = %q, want Bearer tok", auth)
+	}
+	want := []models.Product{{
+		ID: "a1", Title: "NMN Powder", Handle: "https://shop.example/nmn",
+		Variants: []models.Variant{
+			{Price: "49.50", CompareAtPrice: "59.00", Title: "100g", Available: true},
+			{Price: "99.00", Title: "250g", Available: false},
+		},
+	}}
+	if !reflect.DeepEqual(products, want) {
+		t.Errorf("products =\n%+v\nwant\n%+v", products, want)
+	}
+}
+
+func TestFetchPriceAPIMissingKey(t *testing.T) {
+	t.Setenv("TEST_UNSET_KEY", "")
+	_, err := FetchPriceAPIProducts(models.Vendor{Name: "Price API", URL: "http://127.0.0.1:1", APIKeyEnv: "TEST_UNSET_KEY"})
+	if err == nil || !strings.Contains(err.Error(), "TEST_UNSET_KEY") {
+		t.Errorf("err = %v, want the missing variable named", err)
+	}
+}
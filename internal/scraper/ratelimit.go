@@ -0,0 +1,78 @@
+package scraper
+
+import (
+	"sync"
+	"time"
+
+	"longevity-ranker/internal/models"
+)
+
+// defaultPageRate is the rate of a product page crawl for a vendor without
+// a RateLimit: one request every 300ms.
+const defaultPageRate = 1 / 0.3
+
+// vendorLimiter holds a vendor's token bucket (Vendor.RateLimit) and its
+// slots for requests in flight (Vendor.Concurrency). Unlike hostLimiter it
+// spans every host the vendor's requests go to: its API, CDN and cart.
+type vendorLimiter struct {
+	mu     sync.Mutex
+	tokens float64   // May go negative: tokens reserved by waiting requests
+	last   time.Time // When tokens was last refilled; zero = full bucket
+	slots  chan struct{}
+}
+
+var (
+	vendorLimitersMu sync.Mutex
+	vendorLimiters   = map[string]*vendorLimiter{}
+)
+
+// vendorLimiterFor returns the vendor's limiter, sized from its config on
+// first use.
+func vendorLimiterFor(vendor models.Vendor) *vendorLimiter {
+	vendorLimitersMu.Lock()
+	defer vendorLimitersMu.Unlock()
+	l, ok := vendorLimiters[vendor.Name]
+	if !ok {
+		l = &vendorLimiter{}
+		if vendor.Concurrency > 0 {
+			l.slots = make(chan struct{}, vendor.Concurrency)
+		}
+		vendorLimiters[vendor.Name] = l
+	}
+	return l
+}
+
+// take blocks until the bucket has a token for one request and spends it.
+// The bucket refills at rate tokens per second and holds max(rate, 1); a
+// rate of 0 or less never blocks.
+func (l *vendorLimiter) take(rate float64) {
+	if rate <= 0 {
+		return
+	}
+	capacity := max(rate, 1)
+	l.mu.Lock()
+	now := time.Now()
+	if l.last.IsZero() {
+		l.tokens = capacity
+	} else {
+		l.tokens = min(capacity, l.tokens+now.Sub(l.last).Seconds()*rate)
+	}
+	l.last = now
+	l.tokens--
+	var wait time.Duration
+	if l.tokens < 0 {
+		wait = time.Duration(-l.tokens / rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+	time.Sleep(wait)
+}
+
+// acquire blocks until fewer than Concurrency of the vendor's requests are
+// in flight and returns the function that frees the slot.
+func (l *vendorLimiter) acquire() func() {
+	if l.slots == nil {
+		return func() {}
+	}
+	l.slots <- struct{}{}
+	return func() { <-l.slots }
+}
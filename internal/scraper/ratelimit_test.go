@@ -0,0 +1,67 @@
+package scraper
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"longevity-ranker/internal/models"
+)
+
+func TestVendorLimiterTake(t *testing.T) {
+	l := &vendorLimiter{}
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		l.take(0) // No limit
+	}
+	if d := time.Since(start); d > 10*time.Millisecond {
+		t.Errorf("take(0) waited %v, want no wait", d)
+	}
+
+	// The bucket starts full: 50 requests go at once, the next 5 at 50/s
+	start = time.Now()
+	for i := 0; i < 55; i++ {
+		l.take(50)
+	}
+	if d := time.Since(start); d < 90*time.Millisecond || d > time.Second {
+		t.Errorf("55 takes at 50/s took %v, want about 100ms", d)
+	}
+}
+
+func TestFetchBodyHonorsConcurrency(t *testing.T) {
+	defer func(min, max time.Duration) { minThrottleInterval, maxThrottleInterval = min, max }(minThrottleInterval, maxThrottleInterval)
+	minThrottleInterval, maxThrottleInterval = time.Millisecond, 4*time.Millisecond
+
+	var mu sync.Mutex
+	var inFlight, peak int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		peak = max(peak, inFlight)
+		mu.Unlock()
+		time.Sleep(30 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	vendor := models.Vendor{Name: "Capped Vendor", Concurrency: 2}
+	var urls []string
+	for i := 0; i < 8; i++ {
+		urls = append(urls, fmt.Sprintf("%s/page/%d", srv.URL, i))
+	}
+	_, errs := fetchAll(urls, func(u string) ([]byte, error) { return FetchBody(vendor, u) })
+	for _, err := range errs {
+		if err != nil {
+			t.Fatalf("FetchBody() error = %v", err)
+		}
+	}
+	if peak > 2 {
+		t.Errorf("peak requests in flight = %d, want at most 2", peak)
+	}
+}
@@ -0,0 +1,239 @@
+package scraper
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"longevity-ranker/internal/models"
+)
+
+// IgnoreRobots turns off the robots.txt checks of do; cmd/main.go sets it
+// from -ignore-robots.
+var IgnoreRobots = false
+
+// ErrDisallowed is returned for a request the host's robots.txt disallows.
+var ErrDisallowed = errors.New("disallowed by robots.txt")
+
+// robotsAgent is the product token matched against robots.txt User-agent
+// lines, so a site can address this tool by name; otherwise the "*" group
+// applies. The User-Agent header itself is a browser's (see userAgent).
+const robotsAgent = "longevity-rank"
+
+// robotsMaxBytes is how much of a robots.txt is read (RFC 9309 asks for at
+// least 500 KiB).
+const robotsMaxBytes = 512 << 10
+
+// maxCrawlDelay caps a host's Crawl-delay, so one absurd value cannot stall
+// the whole crawl. A variable so tests can shorten it.
+var maxCrawlDelay = 30 * time.Second
+
+// robotsRule is one Allow or Disallow line.
+type robotsRule struct {
+	allow   bool
+	pattern string
+}
+
+// robots is what a host's robots.txt asks of this tool.
+type robots struct {
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+// robotsTTL is how long a fetched robots.txt is trusted: a whole run, and
+// a day of a long-running worker's jobs.
+const robotsTTL = 24 * time.Hour
+
+// robotsEntry is a host's robots.txt, fetched once per robotsTTL.
+type robotsEntry struct {
+	once    sync.Once
+	expires time.Time
+	robots  robots
+}
+
+var (
+	robotsMu    sync.Mutex
+	robotsHosts = map[string]*robotsEntry{}
+)
+
+// robotsApply reports whether the vendor's requests are checked against
+// robots.txt. Price API vendors call an API with their own key, and the
+// Wayback Machine serves archived copies; neither is crawling the site.
+func robotsApply(vendor models.Vendor) bool {
+	return !IgnoreRobots && vendor.Type != "priceapi" && vendor.Name != waybackClient.Name
+}
+
+// checkRobots returns ErrDisallowed when the robots.txt of u's host
+// disallows u. The first request to a host fetches its robots.txt and
+// applies its Crawl-delay to the host's limiter.
+func checkRobots(vendor models.Vendor, u *url.URL) error {
+	if !robotsApply(vendor) {
+		return nil
+	}
+	r := robotsFor(vendor, u)
+	if !r.allowed(u) {
+		return ErrDisallowed
+	}
+	return nil
+}
+
+// robotsFor returns the robots.txt of u's scheme and host, fetching it on
+// first use and again once it expires.
+func robotsFor(vendor models.Vendor, u *url.URL) robots {
+	origin := u.Scheme + "://" + u.Host
+	robotsMu.Lock()
+	e, ok := robotsHosts[origin]
+	if !ok || time.Now().After(e.expires) {
+		e = &robotsEntry{expires: time.Now().Add(robotsTTL)}
+		robotsHosts[origin] = e
+	}
+	robotsMu.Unlock()
+	e.once.Do(func() {
+		e.robots = fetchRobots(vendor, origin)
+		if e.robots.crawlDelay > 0 {
+			limiterFor(u.Host).pace(e.robots.crawlDelay)
+		}
+	})
+	return e.robots
+}
+
+// fetchRobots downloads origin's robots.txt with the vendor's headers. Only
+// a 200 answer restricts anything: a missing file (4xx) allows everything,
+// and so does one that cannot be fetched (network error, 5xx), so a flaky
+// robots.txt does not take the vendor down. The request goes through the
+// vendor's client (the plain one for Browser vendors, whose Chrome would
+// render the file as a page) and the host's limiter, but not the vendor's
+// breaker or crawl budget. It does count against the vendor's rate limit
+// and concurrency cap.
+func fetchRobots(vendor models.Vendor, origin string) robots {
+	req, err := NewRequest(vendor, origin+"/robots.txt")
+	if err != nil {
+		return robots{}
+	}
+	client := ClientFor(vendor)
+	if vendor.Browser {
+		client = DefaultClient
+	}
+	vl := vendorLimiterFor(vendor)
+	vl.take(vendor.RateLimit)
+	limiterFor(req.URL.Host).wait()
+	release := vl.acquire()
+	resp, err := client.Do(req)
+	release()
+	if err != nil {
+		return robots{}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return robots{}
+	}
+	return parseRobots(io.LimitReader(resp.Body, robotsMaxBytes))
+}
+
+// parseRobots reads the rules of the groups naming robotsAgent, or of the
+// "*" groups when none does. Consecutive User-agent lines open one group;
+// groups for the same agent are merged. Crawl-delay is in seconds, capped
+// at maxCrawlDelay. Sitemap and unknown lines are ignored.
+func parseRobots(r io.Reader) robots {
+	var named, all robots
+	var agents []string
+	inRules, hasNamed := false, false
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+		if key == "user-agent" {
+			if inRules {
+				agents, inRules = nil, false
+			}
+			agents = append(agents, strings.ToLower(value))
+			continue
+		}
+		if key != "allow" && key != "disallow" && key != "crawl-delay" {
+			continue
+		}
+		inRules = true
+		for _, agent := range agents {
+			var group *robots
+			switch agent {
+			case robotsAgent:
+				group, hasNamed = &named, true
+			case "*":
+				group = &all
+			default:
+				continue
+			}
+			switch key {
+			case "crawl-delay":
+				if secs, err := strconv.ParseFloat(value, 64); err == nil && secs > 0 {
+					group.crawlDelay = min(time.Duration(secs*float64(time.Second)), maxCrawlDelay)
+				}
+			default:
+				if value != "" { // An empty Disallow allows everything
+					group.rules = append(group.rules, robotsRule{allow: key == "allow", pattern: value})
+				}
+			}
+		}
+	}
+	if hasNamed {
+		return named
+	}
+	return all
+}
+
+// allowed applies the longest rule matching u's path and query; Allow wins
+// a tie, and no match allows. /robots.txt itself is always allowed.
+func (r robots) allowed(u *url.URL) bool {
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if path == "/robots.txt" {
+		return true
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	allow, longest := true, -1
+	for _, rule := range r.rules {
+		n := len(rule.pattern)
+		if !robotsMatch(rule.pattern, path) || n < longest || (n == longest && !rule.allow) {
+			continue
+		}
+		allow, longest = rule.allow, n
+	}
+	return allow
+}
+
+// robotsMatch matches a robots.txt path pattern against path: a prefix
+// match where "*" stands for any run of characters and a trailing "$"
+// anchors the end.
+func robotsMatch(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	parts := strings.Split(strings.TrimSuffix(pattern, "$"), "*")
+	rest, ok := strings.CutPrefix(path, parts[0])
+	if !ok {
+		return false
+	}
+	for i, part := range parts[1:] {
+		if anchored && i == len(parts)-2 { // The last part, after a "*", ends the path
+			return strings.HasSuffix(rest, part)
+		}
+		j := strings.Index(rest, part)
+		if j < 0 {
+			return false
+		}
+		rest = rest[j+len(part):]
+	}
+	return !anchored || rest == ""
+}
@@ -0,0 +1,107 @@
+package scraper
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"longevity-ranker/internal/models"
+)
+
+// TestMain runs the package's tests without robots.txt checks: their
+// fixture servers count requests and answer every path. The robots tests
+// turn the checks back on.
+func TestMain(m *testing.M) {
+	IgnoreRobots = true
+	os.Exit(m.Run())
+}
+
+func TestParseRobots(t *testing.T) {
+	const txt = `# Shopify-style robots.txt
+User-agent: *
+Disallow: /cart
+Disallow: /collections/*sort_by*
+Disallow: /*.atom$
+Allow: /cart/help
+Crawl-delay: 2
+
+User-agent: AhrefsBot
+Disallow: /
+
+Sitemap: https://shop.example.com/sitemap.xml
+`
+	r := parseRobots(strings.NewReader(txt))
+	if r.crawlDelay != 2*time.Second {
+		t.Errorf("crawlDelay = %s, want 2s", r.crawlDelay)
+	}
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/products.json?page=2", true},
+		{"/cart/add.js", false},
+		{"/cart/help", true},
+		{"/collections/nmn?sort_by=price", false},
+		{"/collections/nmn/products.json", true},
+		{"/products/nmn.atom", false},
+		{"/products/nmn.atom.json", true},
+		{"/robots.txt", true},
+	}
+	for _, tt := range tests {
+		u, _ := url.Parse("https://shop.example.com" + tt.path)
+		if got := r.allowed(u); got != tt.want {
+			t.Errorf("allowed(%s) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+
+	// A group naming this tool replaces the "*" group, even an empty one.
+	named := parseRobots(strings.NewReader("User-agent: *\nDisallow: /\n\nUser-agent: Longevity-Rank\nDisallow:\n"))
+	if u, _ := url.Parse("https://shop.example.com/products.json"); !named.allowed(u) {
+		t.Error("the longevity-rank group (allow all) should win over the * group")
+	}
+}
+
+func TestFetchBodyRespectsRobots(t *testing.T) {
+	defer func(ignore bool) { IgnoreRobots = ignore }(IgnoreRobots)
+	IgnoreRobots = false
+
+	var robotsFetches, requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			robotsFetches++
+			w.Write([]byte("User-agent: *\nDisallow: /private\nCrawl-delay: 0.01\n"))
+			return
+		}
+		requests++
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	vendor := models.Vendor{Name: "Robots Vendor"}
+	if body, err := FetchBody(vendor, srv.URL+"/products.json"); err != nil || string(body) != "ok" {
+		t.Fatalf("FetchBody(allowed) = %q, %v; want ok", body, err)
+	}
+	if _, err := FetchBody(vendor, srv.URL+"/private/deal"); !errors.Is(err, ErrDisallowed) {
+		t.Errorf("FetchBody(disallowed) error = %v, want ErrDisallowed", err)
+	}
+	if robotsFetches != 1 || requests != 1 {
+		t.Errorf("robots.txt fetched %d time(s), %d page request(s); want 1 and 1", robotsFetches, requests)
+	}
+	if m := VendorMetrics(vendor.Name); m.Disallowed != 1 || m.Requests != 1 {
+		t.Errorf("metrics = %+v, want 1 request and 1 disallowed", m)
+	}
+	u, _ := url.Parse(srv.URL)
+	if floor := limiterFor(u.Host).floor; floor != 10*time.Millisecond {
+		t.Errorf("host floor = %s, want the 10ms Crawl-delay", floor)
+	}
+
+	IgnoreRobots = true
+	if body, err := FetchBody(vendor, srv.URL+"/private/deal"); err != nil || string(body) != "ok" {
+		t.Errorf("FetchBody(disallowed) with IgnoreRobots = %q, %v; want ok", body, err)
+	}
+}
@@ -2,6 +2,8 @@ package scraper
 
 import (
 	"fmt"
+	"os"
+
 	"longevity-ranker/internal/models"
 )
 
@@ -10,9 +12,15 @@ type FetchFunc func(models.Vendor) ([]models.Product, error)
 
 // registry maps vendor type strings to their scraper implementation.
 var registry = map[string]FetchFunc{
-	"shopify":    FetchShopifyProducts,
-	"html-ldjson": FetchLdJsonProducts,
-	"magento":    FetchMagentoProducts,
+	"shopify":      FetchShopifyProducts,
+	"html-ldjson":  FetchLdJsonProducts,
+	"magento":      FetchMagentoProducts,
+	"mock":         FetchMockProducts,
+	"csv":          FetchCSVProducts,
+	"priceapi":     FetchPriceAPIProducts,
+	"amazon":       FetchAmazonProducts,
+	"iherb":        FetchIherbProducts,
+	"generic-html": FetchGenericProducts,
 }
 
 // FetchProducts dispatches to the correct scraper based on vendor.Type.
@@ -22,4 +30,75 @@ func FetchProducts(vendor models.Vendor) ([]models.Product, error) {
 		return nil, fmt.Errorf("unknown vendor scraper type: %s", vendor.Type)
 	}
 	return fn(vendor)
-}
\ No newline at end of file
+}
+
+// priceSources names where each vendor type's prices come from, for
+// models.Attribution.
+var priceSources = map[string]string{
+	"shopify":      "shopify-api",
+	"html-ldjson":  "ld+json",
+	"magento":      "magento-page",
+	"mock":         "mock",
+	"csv":          "csv",
+	"priceapi":     "price-api",
+	"amazon":       "amazon-page",
+	"iherb":        "iherb-listing",
+	"generic-html": "page markup",
+}
+
+// PriceSource names where the vendor's prices come from: its backend's
+// feed or page ("shopify-api"), "amazon-paapi" when PA-API credentials are
+// set, or "manual-json" for a Cloudflare vendor kept by hand (not
+// scraped without -browser).
+func PriceSource(vendor models.Vendor) string {
+	switch {
+	case vendor.Cloudflare && !vendor.Browser:
+		return "manual-json"
+	case vendor.Type == "amazon" && os.Getenv(PAAPIAccessKeyEnv) != "" && os.Getenv(PAAPISecretKeyEnv) != "" && os.Getenv(PAAPIPartnerTagEnv) != "":
+		return "amazon-paapi"
+	case vendor.Type == "priceapi" && vendor.APIFormat != "":
+		return "price-api:" + vendor.APIFormat
+	}
+	if source, ok := priceSources[vendor.Type]; ok {
+		return source
+	}
+	return vendor.Type
+}
+
+// pageParsers maps the page-per-product vendor types to their product page
+// parser. Their handles are the product URLs.
+var pageParsers = map[string]func(html, link string) []models.Product{
+	"magento":      parseMagentoProductPage,
+	"html-ldjson":  parseLdJsonProductPage,
+	"amazon":       parseAmazonPage,
+	"generic-html": parseGenericPage,
+}
+
+// FetchProductPages fetches only the given product pages of a
+// page-per-product vendor instead of crawling its catalog, as concurrently
+// as the crawl (see fetchPages). Pages that fail are skipped, like in the
+// crawl (see PageErrors); it errors only when none could be fetched. ok is
+// false for vendor types without product pages (Shopify, CSV, price APIs),
+// which list their catalog in one feed and are fetched whole.
+func FetchProductPages(vendor models.Vendor, links []string) (products []models.Product, ok bool, err error) {
+	parse, ok := pageParsers[vendor.Type]
+	if !ok {
+		return nil, false, nil
+	}
+	fmt.Printf("🔍 Fetching %d watched product page(s) of %s (%s)...\n", len(links), vendor.Name, vendor.Type)
+
+	pages, errs := fetchPages(vendor, links, parse, nil)
+	fetched := 0
+	for i := range links {
+		if errs[i] != nil {
+			err = errs[i]
+			continue
+		}
+		fetched++
+		products = append(products, pages[i]...)
+	}
+	if fetched == 0 && len(links) > 0 {
+		return nil, true, err
+	}
+	return products, true, nil
+}
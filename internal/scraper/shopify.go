@@ -2,22 +2,31 @@ package scraper
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"net/url"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
+	"unicode"
 
 	"longevity-ranker/internal/models"
+	"longevity-ranker/internal/runerrors"
 )
 
 const maxShopifyPages = 1000
 
+// FetchShopifyProducts crawls the vendor's products.json URL plus any extra
+// Collections and, when DiscoverCollections or DiscoverWords is set, every
+// collection from /collections.json whose handle or title matches one of
+// their keywords. The collections are crawled in parallel and merged in that
+// order; products listed in several collections are kept once, by product ID.
+// With a Market, every URL is put under the market's subfolder. With
+// DiscoverOnly the collection URLs are recorded and none is fetched.
 func FetchShopifyProducts(vendor models.Vendor) ([]models.Product, error) {
-	var finalProducts []models.Product
-	seenIDs := make(map[string]bool)
-	page := 1
-
 	fmt.Printf("🔌 Connecting to %s...\n", vendor.Name)
 
 	baseURL, err := url.Parse(vendor.URL)
@@ -25,6 +34,75 @@ func FetchShopifyProducts(vendor models.Vendor) ([]models.Product, error) {
 		return nil, fmt.Errorf("invalid vendor URL %q: %v", vendor.URL, err)
 	}
 
+	var collectionURLs []string
+	for _, u := range entryURLs(vendor) {
+		if u = marketURL(vendor, u); !slices.Contains(collectionURLs, u) {
+			collectionURLs = append(collectionURLs, u)
+		}
+	}
+	if len(vendor.DiscoverCollections) > 0 || len(vendor.DiscoverWords) > 0 {
+		discovered, err := discoverShopifyCollections(vendor, baseURL)
+		if err != nil {
+			fmt.Printf("   ⚠️  Collection discovery failed for %s: %v\n", vendor.Name, err)
+		}
+		for _, u := range discovered {
+			if !slices.Contains(collectionURLs, u) {
+				collectionURLs = append(collectionURLs, u)
+			}
+		}
+	}
+
+	if DiscoverOnly {
+		recordDiscovered(vendor.Name, collectionURLs)
+		return nil, nil
+	}
+
+	collections, errs := fetchAll(collectionURLs, func(rawURL string) ([]models.Product, error) {
+		return fetchShopifyCollection(vendor, rawURL)
+	})
+	if errs[0] != nil {
+		return nil, errs[0]
+	}
+
+	var finalProducts []models.Product
+	seenIDs := make(map[string]bool)
+	for i, rawURL := range collectionURLs {
+		if errs[i] != nil {
+			continue // Recorded in PageErrors
+		}
+		products := collections[i]
+		dupes := 0
+		for _, p := range products {
+			if seenIDs[p.ID] {
+				dupes++
+				continue
+			}
+			seenIDs[p.ID] = true
+			finalProducts = append(finalProducts, p)
+		}
+		if len(collectionURLs) > 1 {
+			fmt.Printf("   -> %s: %d products (%d already seen)\n", rawURL, len(products), dupes)
+		}
+	}
+
+	if vendor.CartPricing {
+		simulateShopifyCarts(vendor, finalProducts)
+	}
+	return finalProducts, nil
+}
+
+// fetchShopifyCollection paginates one products.json URL until an empty page
+// or a page with no new products.
+func fetchShopifyCollection(vendor models.Vendor, rawURL string) ([]models.Product, error) {
+	var finalProducts []models.Product
+	seenIDs := make(map[string]bool)
+	page := 1
+
+	baseURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid collection URL %q: %v", rawURL, err)
+	}
+
 	for page <= maxShopifyPages {
 		// Build paginated URL preserving any existing query params (e.g. ?currency=USD)
 		q := baseURL.Query()
@@ -33,7 +111,7 @@ func FetchShopifyProducts(vendor models.Vendor) ([]models.Product, error) {
 		baseURL.RawQuery = q.Encode()
 		fetchURL := baseURL.String()
 
-		req, err := NewRequest(fetchURL)
+		req, err := NewRequest(vendor, fetchURL)
 		if err != nil {
 			return nil, fmt.Errorf("failed building request for page %d: %v", page, err)
 		}
@@ -41,71 +119,42 @@ func FetchShopifyProducts(vendor models.Vendor) ([]models.Product, error) {
 		req.Header.Set("Pragma", "no-cache")
 		req.Header.Set("Expires", "0")
 
-		resp, err := DefaultClient.Do(req)
+		resp, err := do(vendor, req)
+		if errors.Is(err, ErrBudgetExhausted) && page > 1 {
+			fmt.Printf("   ⏸️  Crawl budget of %d requests spent on page %d, keeping %d products.\n", vendor.MaxRequests, page, len(finalProducts))
+			break
+		}
 		if err != nil {
-			return nil, fmt.Errorf("failed fetching page %d: %v", page, err)
+			return nil, fmt.Errorf("failed fetching page %d: %w", page, err)
 		}
 		defer resp.Body.Close()
-
-		body, _ := io.ReadAll(resp.Body)
-
-		var rawData struct {
-			Products []struct {
-				ID       int64  `json:"id"`
-				Title    string `json:"title"`
-				Handle   string `json:"handle"`
-				BodyHTML string `json:"body_html"`
-				Images   []struct {
-					Src string `json:"src"`
-				} `json:"images"`
-				Variants []struct {
-					Price     string `json:"price"`
-					Title     string `json:"title"`
-					Available bool   `json:"available"`
-				} `json:"variants"`
-			} `json:"products"`
+		if resp.StatusCode == http.StatusTooManyRequests {
+			fmt.Printf("   ⚠️  Still throttled on page %d after %d retries, keeping %d products.\n", page, maxThrottleRetries, len(finalProducts))
+			break
 		}
 
-		if err := json.Unmarshal(body, &rawData); err != nil {
+		body, _ := io.ReadAll(resp.Body)
+		pageProducts, err := parseShopifyProducts(body)
+		if err != nil {
+			pageErrors.Add(runerrors.Entry{Vendor: vendor.Name, Scope: runerrors.ScopePage, URL: fetchURL,
+				Status: resp.StatusCode, Class: runerrors.ClassParse, Message: err.Error()})
 			break
 		}
-		if len(rawData.Products) == 0 {
+		if len(pageProducts) == 0 {
 			break
 		}
 
 		newOnPage := 0
-		for _, p := range rawData.Products {
-			pid := strconv.FormatInt(p.ID, 10)
-			if seenIDs[pid] {
+		for _, p := range pageProducts {
+			if seenIDs[p.ID] {
 				continue
 			}
-			seenIDs[pid] = true
+			seenIDs[p.ID] = true
 			newOnPage++
-
-			img := ""
-			if len(p.Images) > 0 {
-				img = p.Images[0].Src
-			}
-
-			newProd := models.Product{
-				ID:       pid,
-				Title:    p.Title,
-				Handle:   p.Handle,
-				BodyHTML: p.BodyHTML,
-				ImageURL: img,
-			}
-			for _, v := range p.Variants {
-				newProd.Variants = append(newProd.Variants, models.Variant{
-					Price:     v.Price,
-					Title:     v.Title,
-					Available: v.Available,
-				})
-			}
-
-			finalProducts = append(finalProducts, newProd)
+			finalProducts = append(finalProducts, p)
 		}
 
-		fmt.Printf("   -> Page %d: %d items (%d new)\n", page, len(rawData.Products), newOnPage)
+		fmt.Printf("   -> %s page %d: %d items (%d new)\n", baseURL.Path, page, len(pageProducts), newOnPage)
 
 		if newOnPage == 0 {
 			fmt.Printf("   ⚠️  No new products on page %d, stopping pagination.\n", page)
@@ -115,8 +164,153 @@ func FetchShopifyProducts(vendor models.Vendor) ([]models.Product, error) {
 	}
 
 	if page > maxShopifyPages {
-		fmt.Printf("   ⚠️  Hit max page limit (%d) for %s.\n", maxShopifyPages, vendor.Name)
+		fmt.Printf("   ⚠️  Hit max page limit (%d) for %s.\n", maxShopifyPages, rawURL)
 	}
 
 	return finalProducts, nil
-}
\ No newline at end of file
+}
+
+// parseShopifyProducts converts one products.json page into products. Each
+// variant's image is its featured_image, else the product image that lists
+// the variant in variant_ids.
+func parseShopifyProducts(body []byte) ([]models.Product, error) {
+	var rawData struct {
+		Products []struct {
+			ID       int64  `json:"id"`
+			Title    string `json:"title"`
+			Handle   string `json:"handle"`
+			BodyHTML string `json:"body_html"`
+			Images   []struct {
+				Src        string  `json:"src"`
+				VariantIDs []int64 `json:"variant_ids"`
+			} `json:"images"`
+			Variants []struct {
+				ID             int64  `json:"id"`
+				Price          string `json:"price"`
+				CompareAtPrice string `json:"compare_at_price"`
+				Title          string `json:"title"`
+				Available      bool   `json:"available"`
+				FeaturedImage  *struct {
+					Src string `json:"src"`
+				} `json:"featured_image"`
+			} `json:"variants"`
+		} `json:"products"`
+	}
+	if err := json.Unmarshal(body, &rawData); err != nil {
+		return nil, err
+	}
+
+	products := make([]models.Product, 0, len(rawData.Products))
+	for _, p := range rawData.Products {
+		img := ""
+		if len(p.Images) > 0 {
+			img = p.Images[0].Src
+		}
+
+		variantImages := make(map[int64]string)
+		for _, im := range p.Images {
+			for _, vid := range im.VariantIDs {
+				if _, seen := variantImages[vid]; !seen {
+					variantImages[vid] = im.Src
+				}
+			}
+		}
+
+		newProd := models.Product{
+			ID:       strconv.FormatInt(p.ID, 10),
+			Title:    p.Title,
+			Handle:   p.Handle,
+			BodyHTML: p.BodyHTML,
+			ImageURL: img,
+		}
+		for _, v := range p.Variants {
+			variantImg := variantImages[v.ID]
+			if v.FeaturedImage != nil && v.FeaturedImage.Src != "" {
+				variantImg = v.FeaturedImage.Src
+			}
+			newProd.Variants = append(newProd.Variants, models.Variant{
+				ID:             strconv.FormatInt(v.ID, 10),
+				Price:          v.Price,
+				CompareAtPrice: v.CompareAtPrice,
+				Title:          v.Title,
+				Available:      v.Available,
+				ImageURL:       variantImg,
+			})
+		}
+		products = append(products, newProd)
+	}
+	return products, nil
+}
+
+// containsWords reports whether the words of kw occur in s one after
+// another, words being runs of letters and digits compared
+// case-insensitively: "nad" is in "nad-plus" and "NAD+ Boosters" but not in
+// "canada" or "lemonade".
+func containsWords(s, kw string) bool {
+	words, want := splitWords(s), splitWords(kw)
+	if len(want) == 0 {
+		return false
+	}
+	for i := 0; i+len(want) <= len(words); i++ {
+		if slices.Equal(words[i:i+len(want)], want) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitWords lowercases s and splits it into runs of letters and digits.
+func splitWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// maxCollectionPages caps /collections.json pagination during discovery.
+const maxCollectionPages = 20
+
+// discoverShopifyCollections lists the store's collections via
+// /collections.json and returns the products.json URL of every collection
+// whose handle or title contains one of vendor.DiscoverCollections, or one
+// of vendor.DiscoverWords as whole words (see containsWords). The vendor
+// URL's query string (e.g. ?currency=USD) is carried over, and both paths
+// are under the vendor's market subfolder.
+func discoverShopifyCollections(vendor models.Vendor, baseURL *url.URL) ([]string, error) {
+	var urls []string
+	for page := 1; page <= maxCollectionPages; page++ {
+		listURL := url.URL{Scheme: baseURL.Scheme, Host: baseURL.Host, Path: marketPath(vendor, "/collections.json"),
+			RawQuery: url.Values{"page": {strconv.Itoa(page)}}.Encode()}
+		body, err := FetchBody(vendor, listURL.String())
+		if err != nil {
+			return urls, err
+		}
+
+		var rawData struct {
+			Collections []struct {
+				Title  string `json:"title"`
+				Handle string `json:"handle"`
+			} `json:"collections"`
+		}
+		if err := json.Unmarshal(body, &rawData); err != nil {
+			return urls, fmt.Errorf("parsing collections page %d: %v", page, err)
+		}
+		if len(rawData.Collections) == 0 {
+			break
+		}
+
+		for _, c := range rawData.Collections {
+			identity := strings.ToLower(c.Handle + " " + c.Title)
+			matches := slices.ContainsFunc(vendor.DiscoverCollections, func(kw string) bool {
+				return strings.Contains(identity, strings.ToLower(kw))
+			}) || slices.ContainsFunc(vendor.DiscoverWords, func(kw string) bool {
+				return containsWords(identity, kw)
+			})
+			if matches {
+				u := url.URL{Scheme: baseURL.Scheme, Host: baseURL.Host,
+					Path: marketPath(vendor, "/collections/"+c.Handle+"/products.json"), RawQuery: baseURL.RawQuery}
+				urls = append(urls, u.String())
+			}
+		}
+	}
+	return urls, nil
+}
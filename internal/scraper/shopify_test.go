@@ -0,0 +1,239 @@
+package scraper
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"longevity-ranker/internal/models"
+)
+
+func TestFetchShopifyProductsContract(t *testing.T) {
+	page1, err := os.ReadFile(filepath.Join("testdata", "shopify_products.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if got := r.URL.Query().Get("currency"); got != "USD" {
+			t.Errorf("existing query param dropped: currency=%q", got)
+		}
+		if r.URL.Query().Get("page") == "1" {
+			w.Write(page1)
+			return
+		}
+		w.Write([]byte(`{"products":[]}`))
+	}))
+	defer srv.Close()
+
+	products, err := FetchShopifyProducts(models.Vendor{
+		Name: "Fixture Shopify",
+		URL:  srv.URL + "/collections/nmn/products.json?currency=USD",
+		Type: "shopify",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests != 2 {
+		t.Errorf("requests = %d, want 2 (one page plus the empty terminator)", requests)
+	}
+	if len(products) != 3 {
+		t.Fatalf("products = %d, want 3", len(products))
+	}
+
+	nmn := products[0]
+	if nmn.ID != "2796018139236" || nmn.Handle != "prohealth-nmn-pro-300-enhanced-absorption-30-capsules-ph518" {
+		t.Errorf("product[0] identity = %q/%q", nmn.ID, nmn.Handle)
+	}
+	if nmn.ImageURL != "https://cdn.shopify.com/s/files/1/0001/nmn-pro-300.jpg" {
+		t.Errorf("product[0] image = %q", nmn.ImageURL)
+	}
+	assertVariant(t, nmn.Variants[0], models.Variant{ID: "1", Price: "39.95", CompareAtPrice: "49.95", Title: "Default Title", Available: true})
+
+	pack := products[1]
+	if pack.ImageURL != "" {
+		t.Errorf("product[1] image = %q, want empty", pack.ImageURL)
+	}
+	assertVariant(t, pack.Variants[0], models.Variant{ID: "2", Price: "107.85", Title: "Default Title", Available: false})
+
+	creatine := products[2]
+	if len(creatine.Variants) != 2 {
+		t.Fatalf("product[2] variants = %d, want 2", len(creatine.Variants))
+	}
+	if creatine.ImageURL != "https://cdn.shopify.com/s/files/1/0002/creatine-front.jpg" {
+		t.Errorf("product[2] image = %q, want first image", creatine.ImageURL)
+	}
+	assertVariant(t, creatine.Variants[0], models.Variant{ID: "3", Price: "26.96", Title: "Unflavored / 500 GMS", Available: true,
+		ImageURL: "https://cdn.shopify.com/s/files/1/0002/creatine-500g.jpg"})
+	assertVariant(t, creatine.Variants[1], models.Variant{ID: "4", Price: "44.96", Title: "Unflavored / 1 KG", Available: true,
+		ImageURL: "https://cdn.shopify.com/s/files/1/0002/creatine-1kg.jpg"})
+}
+
+func TestFetchShopifyProductsStopsOnRepeatedPage(t *testing.T) {
+	page, err := os.ReadFile(filepath.Join("testdata", "shopify_products.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A store that ignores ?page= and returns the same products forever
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write(page)
+	}))
+	defer srv.Close()
+
+	products, err := FetchShopifyProducts(models.Vendor{Name: "Fixture Shopify", URL: srv.URL + "/products.json", Type: "shopify"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(products) != 3 || requests != 2 {
+		t.Errorf("products = %d, requests = %d; want 3 products after 2 requests", len(products), requests)
+	}
+}
+
+func TestFetchShopifyProductsMultiCollection(t *testing.T) {
+	page := func(ids ...int) string {
+		var items []string
+		for _, id := range ids {
+			items = append(items, fmt.Sprintf(`{"id": %d, "title": "NMN %d", "handle": "nmn-%d", "variants": []}`, id, id, id))
+		}
+		return `{"products": [` + strings.Join(items, ",") + `]}`
+	}
+	pages := map[string]string{
+		"/collections/nmn/products.json":         page(1, 2),
+		"/collections/tmg/products.json":         page(2, 3),
+		"/collections/resveratrol/products.json": page(3, 4),
+	}
+
+	var mu sync.Mutex
+	var collectionRequests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/collections.json" {
+			if r.URL.Query().Get("page") == "1" {
+				w.Write([]byte(`{"collections": [
+					{"title": "Resveratrol", "handle": "resveratrol"},
+					{"title": "Skin Care", "handle": "skin-care"},
+					{"title": "NMN", "handle": "nmn"}
+				]}`))
+				return
+			}
+			w.Write([]byte(`{"collections": []}`))
+			return
+		}
+		if got := r.URL.Query().Get("currency"); got != "USD" {
+			t.Errorf("%s: currency=%q, want USD carried from the vendor URL", r.URL.Path, got)
+		}
+		if r.URL.Query().Get("page") != "1" {
+			w.Write([]byte(`{"products":[]}`))
+			return
+		}
+		mu.Lock()
+		collectionRequests = append(collectionRequests, r.URL.Path)
+		mu.Unlock()
+		w.Write([]byte(pages[r.URL.Path]))
+	}))
+	defer srv.Close()
+
+	products, err := FetchShopifyProducts(models.Vendor{
+		Name:                "Fixture Shopify",
+		URL:                 srv.URL + "/collections/nmn/products.json?currency=USD",
+		Type:                "shopify",
+		Collections:         []string{srv.URL + "/collections/tmg/products.json?currency=USD"},
+		DiscoverCollections: []string{"nmn", "resveratrol"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []string
+	for _, p := range products {
+		ids = append(ids, p.ID)
+	}
+	if want := []string{"1", "2", "3", "4"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("product IDs = %v, want %v (each once)", ids, want)
+	}
+	// Crawled in parallel, so only the set of collections is fixed
+	sort.Strings(collectionRequests)
+	wantRequests := []string{"/collections/nmn/products.json", "/collections/resveratrol/products.json", "/collections/tmg/products.json"}
+	if !reflect.DeepEqual(collectionRequests, wantRequests) {
+		t.Errorf("collections crawled = %v, want %v", collectionRequests, wantRequests)
+	}
+}
+
+func TestFetchShopifyProductsMarket(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if c, err := r.Cookie("localization"); err != nil || c.Value != "GB" {
+			t.Errorf("%s: localization cookie = %v, %v; want GB", r.URL.Path, c, err)
+		}
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		switch {
+		case r.URL.Path == "/en-gb/collections.json" && r.URL.Query().Get("page") == "1":
+			w.Write([]byte(`{"collections": [{"title": "NMN", "handle": "nmn"}]}`))
+		case r.URL.Path == "/en-gb/collections/nmn/products.json" && r.URL.Query().Get("page") == "1":
+			w.Write([]byte(`{"products": [{"id": 1, "title": "NMN", "handle": "nmn", "variants": [{"id": 10, "price": "40.00", "title": "Default Title", "available": true}]}]}`))
+		case strings.HasSuffix(r.URL.Path, ".json"):
+			w.Write([]byte(`{"products": [], "collections": []}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	products, err := FetchShopifyProducts(models.Vendor{
+		Name:                "Market Shopify",
+		URL:                 srv.URL + "/products.json",
+		Type:                "shopify",
+		Market:              "en-gb",
+		Collections:         []string{srv.URL + "/en-gb/collections/nmn/products.json"}, // Already in the market
+		DiscoverCollections: []string{"nmn"},
+	})
+	if err != nil || len(products) != 1 || products[0].Variants[0].Price != "40.00" {
+		t.Fatalf("FetchShopifyProducts() = %+v, %v; want the market's NMN", products, err)
+	}
+	for _, p := range paths {
+		if !strings.HasPrefix(p, "/en-gb/") && p != "/robots.txt" {
+			t.Errorf("requested %s outside the market subfolder", p)
+		}
+	}
+}
+
+func TestDiscoverShopifyCollectionsWords(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") != "1" {
+			w.Write([]byte(`{"collections": []}`))
+			return
+		}
+		w.Write([]byte(`{"collections": [
+			{"title": "NAD+ Boosters", "handle": "nad-plus"},
+			{"title": "Ships to Canada", "handle": "canada"},
+			{"title": "Lemonade Mixes", "handle": "lemonade"},
+			{"title": "Longevity Stack", "handle": "longevity-stack"}
+		]}`))
+	}))
+	defer srv.Close()
+
+	base, _ := url.Parse(srv.URL)
+	got, err := discoverShopifyCollections(models.Vendor{
+		Name: "Word Shopify", URL: srv.URL, Type: "shopify",
+		DiscoverCollections: []string{"longevity"}, DiscoverWords: []string{"nad"},
+	}, base)
+	want := []string{srv.URL + "/collections/nad-plus/products.json", srv.URL + "/collections/longevity-stack/products.json"}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("discoverShopifyCollections() = %v, %v; want %v", got, err, want)
+	}
+}
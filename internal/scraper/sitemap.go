@@ -0,0 +1,145 @@
+package scraper
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"longevity-ranker/internal/models"
+)
+
+// maxSitemaps bounds the sitemap files one vendor's discovery fetches,
+// the index included.
+const maxSitemaps = 20
+
+// productPaths maps the page-per-product vendor types to the URL paths
+// their sitemap discovery keeps when the vendor sets no SitemapPattern.
+// Magento has none: its products, CMS pages (/about-us) and categories all
+// sit at top-level URL keys, so config.Load requires a SitemapPattern.
+var productPaths = map[string]func(path string) bool{
+	"html-ldjson": isLdJsonProductPath,
+}
+
+// sitemapDoc is either kind of sitemap file: an index listing other
+// sitemaps, or a urlset listing pages.
+type sitemapDoc struct {
+	Sitemaps []string `xml:"sitemap>loc"`
+	URLs     []string `xml:"url>loc"`
+}
+
+// discoverSitemap adds the product pages of the vendor's Sitemap, if it has
+// one, to links, the ones its category pages list. A sitemap that cannot be
+// read is reported and leaves links as they are.
+func discoverSitemap(vendor models.Vendor, links map[string]bool) {
+	if vendor.Sitemap == "" {
+		return
+	}
+	found, err := sitemapLinks(vendor)
+	if err != nil {
+		fmt.Printf("   ⚠️ Sitemap of %s: %v\n", vendor.Name, err)
+		return
+	}
+	added := 0
+	for link := range found {
+		if !links[link] {
+			links[link] = true
+			added++
+		}
+	}
+	fmt.Printf("   -> Sitemap lists %d product pages, %d not on the category pages.\n", len(found), added)
+}
+
+// sitemapLinks fetches the vendor's Sitemap and returns the page URLs on the
+// vendor's host whose path matches SitemapPattern, or the vendor type's own
+// product paths (see productPaths) without one. An index is followed to its
+// sitemaps: only those whose URL names products when any does (WooCommerce's
+// product-sitemap.xml), else all, up to maxSitemaps files. Sitemaps may be
+// gzipped.
+func sitemapLinks(vendor models.Vendor) (map[string]bool, error) {
+	base, err := url.Parse(vendor.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid vendor URL: %v", err)
+	}
+	isProduct := productPaths[vendor.Type]
+	if vendor.SitemapPattern != "" {
+		re, err := regexp.Compile(vendor.SitemapPattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sitemapPattern: %v", err)
+		}
+		isProduct = re.MatchString
+	}
+	if isProduct == nil {
+		return nil, fmt.Errorf("%s vendors need a sitemapPattern", vendor.Type)
+	}
+
+	links := make(map[string]bool)
+	queue, seen := []string{vendor.Sitemap}, map[string]bool{vendor.Sitemap: true}
+	for fetched := 0; len(queue) > 0 && fetched < maxSitemaps; fetched++ {
+		sitemapURL := queue[0]
+		queue = queue[1:]
+		doc, err := fetchSitemap(vendor, sitemapURL)
+		if err != nil {
+			if fetched == 0 {
+				return nil, err
+			}
+			continue // Recorded in PageErrors, or a sub-sitemap that is not XML
+		}
+		for _, loc := range productSitemaps(doc.Sitemaps) {
+			if !seen[loc] {
+				seen[loc] = true
+				queue = append(queue, loc)
+			}
+		}
+		for _, loc := range doc.URLs {
+			u, err := url.Parse(strings.TrimSpace(loc))
+			if err == nil && u.Host == base.Host && isProduct(u.Path) {
+				links[u.String()] = true
+			}
+		}
+	}
+	return links, nil
+}
+
+// productSitemaps returns the sitemaps of an index that name products, or
+// all of them when none does.
+func productSitemaps(locs []string) []string {
+	var products []string
+	for i, loc := range locs {
+		locs[i] = strings.TrimSpace(loc)
+		if strings.Contains(strings.ToLower(locs[i]), "product") {
+			products = append(products, locs[i])
+		}
+	}
+	if len(products) == 0 {
+		return locs
+	}
+	return products
+}
+
+// fetchSitemap fetches and decodes one sitemap file, gunzipping it when the
+// server sent it compressed as-is (sitemap.xml.gz).
+func fetchSitemap(vendor models.Vendor, sitemapURL string) (sitemapDoc, error) {
+	body, err := FetchBody(vendor, sitemapURL)
+	if err != nil {
+		return sitemapDoc{}, err
+	}
+	if bytes.HasPrefix(body, []byte{0x1f, 0x8b}) {
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return sitemapDoc{}, fmt.Errorf("%s: %v", sitemapURL, err)
+		}
+		if body, err = io.ReadAll(zr); err != nil {
+			return sitemapDoc{}, fmt.Errorf("%s: %v", sitemapURL, err)
+		}
+	}
+	var doc sitemapDoc
+	if err := xml.Unmarshal(body, &doc); err != nil {
+		return sitemapDoc{}, fmt.Errorf("%s is not a sitemap: %v", sitemapURL, err)
+	}
+	return doc, nil
+}
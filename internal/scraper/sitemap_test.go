@@ -0,0 +1,112 @@
+package scraper
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"longevity-ranker/internal/models"
+)
+
+// serveSitemaps serves the sitemaps (path → XML with a %[1]s placeholder for
+// the server URL, gzipped when the path ends in .gz) and fixture pages.
+func serveSitemaps(t *testing.T, sitemaps, fixtures map[string]string) *httptest.Server {
+	t.Helper()
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if xml, ok := sitemaps[r.URL.Path]; ok {
+			body := []byte(fmt.Sprintf(xml, srv.URL))
+			if filepath.Ext(r.URL.Path) == ".gz" {
+				var buf bytes.Buffer
+				zw := gzip.NewWriter(&buf)
+				zw.Write(body)
+				zw.Close()
+				body = buf.Bytes()
+			}
+			w.Write(body)
+			return
+		}
+		name, ok := fixtures[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Errorf("reading fixture %s: %v", name, err)
+		}
+		w.Write(data)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestSitemapLinks(t *testing.T) {
+	srv := serveSitemaps(t, map[string]string{
+		"/sitemap.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>%[1]s/page-sitemap.xml</loc></sitemap>
+  <sitemap><loc>%[1]s/product-sitemap.xml.gz</loc></sitemap>
+  <sitemap><loc> %[1]s/product-sitemap2.xml </loc></sitemap>
+</sitemapindex>`,
+		"/page-sitemap.xml": `<urlset><url><loc>%[1]s/product/from-pages/</loc></url></urlset>`,
+		"/product-sitemap.xml.gz": `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>%[1]s/product/nmn-powder/</loc><lastmod>2026-01-01</lastmod></url>
+  <url><loc>%[1]s/product-category/nmn/</loc></url>
+  <url><loc>https://elsewhere.example.com/product/other/</loc></url>
+</urlset>`,
+		"/product-sitemap2.xml": `<urlset><url><loc>%[1]s/product/nmn-capsules/</loc></url></urlset>`,
+	}, nil)
+	vendor := models.Vendor{Name: "Sitemap LD", URL: srv.URL + "/shop/", Type: "html-ldjson", Sitemap: srv.URL + "/sitemap.xml"}
+
+	// Only the product sitemaps are followed; categories and other hosts
+	// are dropped
+	got, err := sitemapLinks(vendor)
+	want := map[string]bool{srv.URL + "/product/nmn-powder/": true, srv.URL + "/product/nmn-capsules/": true}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("sitemapLinks() = %v, %v; want %v", got, err, want)
+	}
+
+	vendor.SitemapPattern = `^/product/nmn-c`
+	got, err = sitemapLinks(vendor)
+	if want := map[string]bool{srv.URL + "/product/nmn-capsules/": true}; err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("sitemapLinks(pattern) = %v, %v; want %v", got, err, want)
+	}
+
+	vendor.Sitemap = srv.URL + "/missing.xml"
+	if _, err := sitemapLinks(vendor); err == nil {
+		t.Error("sitemapLinks(missing sitemap) = nil error, want one")
+	}
+}
+
+func TestFetchMagentoProductsSitemap(t *testing.T) {
+	srv := serveSitemaps(t, map[string]string{
+		"/sitemap.xml": `<urlset>
+  <url><loc>%[1]s/products/</loc></url>
+  <url><loc>%[1]s/about-us</loc></url>
+  <url><loc>%[1]s/pure-nmn</loc></url>
+</urlset>`,
+		"/products/": `<html><body>Page 1 of 2, and no Pure NMN on it</body></html>`,
+	}, map[string]string{"/pure-nmn": "magento_product.html"})
+
+	products, err := FetchMagentoProducts(models.Vendor{
+		Name: "Sitemap Magento", URL: srv.URL + "/products/", Type: "magento", Sitemap: srv.URL + "/sitemap.xml", SitemapPattern: "^/pure-",
+	})
+	if err != nil || len(products) != 1 || products[0].Handle != srv.URL+"/pure-nmn" {
+		t.Errorf("FetchMagentoProducts() = %+v, %v; want /pure-nmn from the sitemap", products, err)
+	}
+
+	// An unreadable sitemap leaves the category pages' links
+	products, err = FetchMagentoProducts(models.Vendor{
+		Name: "Sitemap Magento Missing", URL: srv.URL + "/products/", Type: "magento", Sitemap: srv.URL + "/missing.xml", SitemapPattern: "^/pure-",
+	})
+	if err != nil || len(products) != 0 {
+		t.Errorf("FetchMagentoProducts(missing sitemap) = %d products, %v; want none and no error", len(products), err)
+	}
+}
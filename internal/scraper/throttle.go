@@ -0,0 +1,204 @@
+package scraper
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"longevity-ranker/internal/models"
+)
+
+// maxThrottleRetries is how many times a 429 response is retried.
+const maxThrottleRetries = 4
+
+// Throttling limits. Each 429 doubles the host's request spacing, starting at
+// minThrottleInterval and capped at maxThrottleInterval. Retry-After values
+// above maxRetryAfter are clamped so one hostile header cannot stall the
+// whole crawl. Variables so tests can shorten them.
+var (
+	minThrottleInterval = time.Second
+	maxThrottleInterval = 30 * time.Second
+	maxRetryAfter       = 2 * time.Minute
+)
+
+// hostLimiter spaces requests to a single host. Its interval starts at zero,
+// or at the robots.txt Crawl-delay set with pace, and only grows once the
+// host answers 429; it does not relax again during the run.
+type hostLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	floor    time.Duration
+	next     time.Time
+}
+
+var (
+	limitersMu sync.Mutex
+	limiters   = map[string]*hostLimiter{}
+)
+
+func limiterFor(host string) *hostLimiter {
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+	l, ok := limiters[host]
+	if !ok {
+		l = &hostLimiter{}
+		limiters[host] = l
+	}
+	return l
+}
+
+// wait blocks until the host may be requested again and reserves the slot.
+func (l *hostLimiter) wait() {
+	l.mu.Lock()
+	now := time.Now()
+	start := l.next
+	if start.Before(now) {
+		start = now
+	}
+	l.next = start.Add(max(l.interval, l.floor))
+	l.mu.Unlock()
+	time.Sleep(time.Until(start))
+}
+
+// pace makes the host's spacing at least d.
+func (l *hostLimiter) pace(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.floor = max(l.floor, d)
+}
+
+// backoff doubles the host's spacing and pushes the next slot out by at least
+// retryAfter. It returns how long the caller will wait.
+func (l *hostLimiter) backoff(retryAfter time.Duration) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.interval *= 2
+	if l.interval < minThrottleInterval {
+		l.interval = minThrottleInterval
+	}
+	if l.interval > maxThrottleInterval {
+		l.interval = maxThrottleInterval
+	}
+	delay := retryAfter
+	if delay < l.interval {
+		delay = l.interval
+	}
+	if next := time.Now().Add(delay); next.After(l.next) {
+		l.next = next
+	}
+	return delay
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP
+// date. Missing, unparseable or past values yield 0; large ones are clamped
+// to maxRetryAfter.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	var d time.Duration
+	if secs, err := strconv.Atoi(value); err == nil {
+		d = time.Duration(secs) * time.Second
+	} else if t, err := http.ParseTime(value); err == nil {
+		d = t.Sub(now)
+	}
+	if d < 0 {
+		return 0
+	}
+	if d > maxRetryAfter {
+		return maxRetryAfter
+	}
+	return d
+}
+
+// Metrics counts a vendor's HTTP activity during the run.
+type Metrics struct {
+	Requests   int           // Responses received, including 429s
+	Throttled  int           // 429 responses
+	GaveUp     int           // Requests still throttled after maxThrottleRetries
+	Waited     time.Duration // Total time spent backing off after 429s
+	Retries    int           // Retries after network errors and 5xx responses
+	Failures   int           // Requests that failed after all retries
+	Skipped    int           // Requests refused because the circuit was open
+	Tripped    bool          // The vendor's circuit breaker opened
+	OverBudget int           // Requests refused because the crawl budget was spent
+	Disallowed int           // Requests refused because robots.txt disallows them
+	Rotated    int           // User-Agent changes after a 403 (UserAgents, RotateUserAgent)
+
+	NotModified int // Pages answered 304 and served from CacheDir
+}
+
+var (
+	metricsMu sync.Mutex
+	metrics   = map[string]*Metrics{}
+)
+
+func recordMetrics(vendorName string, update func(m *Metrics)) {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+	m, ok := metrics[vendorName]
+	if !ok {
+		m = &Metrics{}
+		metrics[vendorName] = m
+	}
+	update(m)
+}
+
+// VendorMetrics returns a snapshot of the vendor's scrape metrics.
+func VendorMetrics(vendorName string) Metrics {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+	if m, ok := metrics[vendorName]; ok {
+		return *m
+	}
+	return Metrics{}
+}
+
+// doThrottled sends a GET request through the vendor's client, waiting on
+// the vendor's rate limit, the per-host limiter and a slot under the
+// vendor's Concurrency first. A 429 response backs the host off (honoring
+// Retry-After) and is retried; after maxThrottleRetries the last 429
+// response is returned to the caller.
+func doThrottled(vendor models.Vendor, req *http.Request) (*http.Response, error) {
+	limiter := limiterFor(req.URL.Host)
+	vl := vendorLimiterFor(vendor)
+	for attempt := 0; ; attempt++ {
+		vl.take(vendor.RateLimit)
+		limiter.wait()
+		if req.GetBody != nil { // Every attempt sends a fresh copy of the body
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+		release := vl.acquire()
+		resp, err := ClientFor(vendor).Do(req)
+		release()
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusForbidden && rotateUserAgent(vendor) {
+			recordMetrics(vendor.Name, func(m *Metrics) { m.Rotated++ })
+		}
+		throttled := resp.StatusCode == http.StatusTooManyRequests
+		recordMetrics(vendor.Name, func(m *Metrics) {
+			m.Requests++
+			if throttled {
+				m.Throttled++
+			}
+		})
+		if !throttled {
+			return resp, nil
+		}
+		if attempt == maxThrottleRetries {
+			recordMetrics(vendor.Name, func(m *Metrics) { m.GaveUp++ })
+			return resp, nil
+		}
+
+		resp.Body.Close()
+		delay := limiter.backoff(parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()))
+		recordMetrics(vendor.Name, func(m *Metrics) { m.Waited += delay })
+	}
+}
@@ -0,0 +1,75 @@
+package scraper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"longevity-ranker/internal/models"
+)
+
+func TestFetchBodyRetriesThrottledRequests(t *testing.T) {
+	defer func(min, max time.Duration) { minThrottleInterval, maxThrottleInterval = min, max }(minThrottleInterval, maxThrottleInterval)
+	minThrottleInterval, maxThrottleInterval = time.Millisecond, 4*time.Millisecond
+
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests <= 2 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	vendor := models.Vendor{Name: "Throttled Vendor"}
+	body, err := FetchBody(vendor, srv.URL)
+	if err != nil || string(body) != "ok" {
+		t.Fatalf("FetchBody() = %q, %v; want ok after retries", body, err)
+	}
+	m := VendorMetrics(vendor.Name)
+	if m.Requests != 3 || m.Throttled != 2 || m.GaveUp != 0 || m.Waited <= 0 {
+		t.Errorf("metrics = %+v, want 3 requests, 2 throttled, 0 gave up, some wait", m)
+	}
+}
+
+func TestFetchBodyGivesUpOnPersistentThrottling(t *testing.T) {
+	defer func(min, max time.Duration) { minThrottleInterval, maxThrottleInterval = min, max }(minThrottleInterval, maxThrottleInterval)
+	minThrottleInterval, maxThrottleInterval = time.Millisecond, 2*time.Millisecond
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	vendor := models.Vendor{Name: "Hostile Vendor"}
+	if _, err := FetchBody(vendor, srv.URL); err == nil {
+		t.Fatal("FetchBody() succeeded against a server that always answers 429")
+	}
+	if m := VendorMetrics(vendor.Name); m.Throttled != maxThrottleRetries+1 || m.GaveUp != 1 {
+		t.Errorf("metrics = %+v, want %d throttled and 1 gave up", m, maxThrottleRetries+1)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	cases := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"7", 7 * time.Second},
+		{now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0},
+		{"86400", maxRetryAfter},
+		{"soon", 0},
+	}
+	for _, tc := range cases {
+		if got := parseRetryAfter(tc.value, now); got != tc.want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", tc.value, got, tc.want)
+		}
+	}
+}
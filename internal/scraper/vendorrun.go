@@ -0,0 +1,62 @@
+package scraper
+
+import (
+	"longevity-ranker/internal/runerrors"
+)
+
+// VendorRun is what scraping one vendor left in this process's per-run
+// state: its metrics, the URLs its crawl budget refused and its failed
+// requests. A worker sends it back with the products, so the coordinator
+// reports the vendor as if it had scraped it itself.
+type VendorRun struct {
+	Metrics    Metrics           `json:"metrics"`
+	Skipped    []string          `json:"skipped,omitempty"`
+	PageErrors []runerrors.Entry `json:"page_errors,omitempty"`
+}
+
+// TakeVendorRun returns the vendor's per-run state and forgets it, with its
+// circuit breaker, User-Agent pick and rate limiter, so a long-running
+// worker scrapes the vendor's next job like a fresh run.
+func TakeVendorRun(vendorName string) VendorRun {
+	r := VendorRun{Metrics: VendorMetrics(vendorName), Skipped: BudgetSkipped(vendorName), PageErrors: pageErrors.Take(vendorName)}
+	metricsMu.Lock()
+	delete(metrics, vendorName)
+	metricsMu.Unlock()
+	budgetsMu.Lock()
+	delete(budgets, vendorName)
+	budgetsMu.Unlock()
+	breakersMu.Lock()
+	delete(breakers, vendorName)
+	breakersMu.Unlock()
+	rotationsMu.Lock()
+	delete(rotations, vendorName)
+	rotationsMu.Unlock()
+	vendorLimitersMu.Lock()
+	delete(vendorLimiters, vendorName)
+	vendorLimitersMu.Unlock()
+	return r
+}
+
+// RecordVendorRun adds a vendor run scraped elsewhere to this process's
+// state: VendorMetrics, BudgetSkipped and PageErrors then include it.
+func RecordVendorRun(vendorName string, r VendorRun) {
+	recordMetrics(vendorName, func(m *Metrics) {
+		m.Requests += r.Metrics.Requests
+		m.Throttled += r.Metrics.Throttled
+		m.GaveUp += r.Metrics.GaveUp
+		m.Waited += r.Metrics.Waited
+		m.Retries += r.Metrics.Retries
+		m.Failures += r.Metrics.Failures
+		m.Skipped += r.Metrics.Skipped
+		m.Tripped = m.Tripped || r.Metrics.Tripped
+		m.OverBudget += r.Metrics.OverBudget
+		m.Disallowed += r.Metrics.Disallowed
+		m.Rotated += r.Metrics.Rotated
+		m.NotModified += r.Metrics.NotModified
+	})
+	budgetFor(vendorName).skip(r.Skipped...)
+	for _, e := range r.PageErrors {
+		e.Vendor = vendorName
+		pageErrors.Add(e)
+	}
+}
@@ -0,0 +1,43 @@
+package scraper
+
+import (
+	"testing"
+
+	"longevity-ranker/internal/runerrors"
+)
+
+func TestVendorRunMoves(t *testing.T) {
+	const worker, coordinator = "Worker Vendor", "Coordinator Vendor"
+	recordMetrics(worker, func(m *Metrics) { m.Requests, m.Throttled = 5, 1 })
+	budgetFor(worker).skip("https://shop.example.com/products.json?page=9")
+	pageErrors.Add(runerrors.Entry{Vendor: worker, URL: "https://shop.example.com/x", Message: "HTTP 500"})
+
+	r := TakeVendorRun(worker)
+	if r.Metrics.Requests != 5 || len(r.Skipped) != 1 || len(r.PageErrors) != 1 {
+		t.Fatalf("TakeVendorRun() = %+v", r)
+	}
+	if m := VendorMetrics(worker); m != (Metrics{}) {
+		t.Errorf("metrics after TakeVendorRun = %+v, want none", m)
+	}
+	if again := TakeVendorRun(worker); again.Metrics != (Metrics{}) || len(again.PageErrors) != 0 {
+		t.Errorf("second TakeVendorRun() = %+v, want empty", again)
+	}
+
+	RecordVendorRun(coordinator, r)
+	RecordVendorRun(coordinator, r)
+	if m := VendorMetrics(coordinator); m.Requests != 10 || m.Throttled != 2 {
+		t.Errorf("recorded metrics = %+v, want both runs summed", m)
+	}
+	if skipped := BudgetSkipped(coordinator); len(skipped) != 2 {
+		t.Errorf("BudgetSkipped() = %v, want the URL from each run", skipped)
+	}
+	var moved int
+	for _, e := range PageErrors() {
+		if e.Vendor == coordinator {
+			moved++
+		}
+	}
+	if moved != 2 {
+		t.Errorf("%d page error(s) under %s, want 2", moved, coordinator)
+	}
+}
@@ -0,0 +1,92 @@
+package scraper
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"longevity-ranker/internal/models"
+)
+
+// waybackBase is the Internet Archive endpoint. A variable so tests can point
+// it at a fake archive.
+var waybackBase = "https://web.archive.org"
+
+// waybackClient is the pseudo-vendor archive requests are made as: its own
+// throttle, retry and breaker state, and none of the real vendor's headers
+// or cookies.
+var waybackClient = models.Vendor{Name: "Wayback Machine", MaxRetries: 2}
+
+// Snapshot is one archived capture of a URL.
+type Snapshot struct {
+	Timestamp string // YYYYMMDDhhmmss
+	Original  string // The URL as captured
+}
+
+// Date returns the capture date as YYYY-MM-DD.
+func (s Snapshot) Date() string {
+	if len(s.Timestamp) < 8 {
+		return ""
+	}
+	return s.Timestamp[:4] + "-" + s.Timestamp[4:6] + "-" + s.Timestamp[6:8]
+}
+
+// ListSnapshots asks the Wayback CDX API for successful captures of rawURL
+// between from and to (YYYYMMDD, inclusive), at most one per day and limit in
+// total, oldest first.
+func ListSnapshots(rawURL, from, to string, limit int) ([]Snapshot, error) {
+	q := url.Values{
+		"url":      {rawURL},
+		"output":   {"json"},
+		"fl":       {"timestamp,original"},
+		"filter":   {"statuscode:200"},
+		"collapse": {"timestamp:8"},
+		"from":     {from},
+		"to":       {to},
+		"limit":    {strconv.Itoa(limit)},
+	}
+	body, err := FetchBody(waybackClient, waybackBase+"/cdx/search/cdx?"+q.Encode())
+	if err != nil {
+		return nil, err
+	}
+	if len(body) == 0 {
+		return nil, nil // No captures: the CDX API answers with an empty body
+	}
+
+	var rows [][]string
+	if err := json.Unmarshal(body, &rows); err != nil {
+		return nil, fmt.Errorf("parsing CDX response: %v", err)
+	}
+	var snapshots []Snapshot
+	for i, row := range rows {
+		if i == 0 || len(row) < 2 {
+			continue // Header row
+		}
+		snapshots = append(snapshots, Snapshot{Timestamp: row[0], Original: row[1]})
+	}
+	return snapshots, nil
+}
+
+// FetchSnapshotProducts downloads a capture as originally served (the id_
+// modifier skips the archive's rewriting) and parses it with the page parser
+// of the vendor's type. link is the live product URL used as the handle for
+// page-per-product backends, so archived products key the same as live ones.
+// Only the first page of a paginated Shopify collection is archived.
+func FetchSnapshotProducts(vendor models.Vendor, snap Snapshot, link string) ([]models.Product, error) {
+	body, err := FetchBody(waybackClient, waybackBase+"/web/"+snap.Timestamp+"id_/"+snap.Original)
+	if err != nil {
+		return nil, err
+	}
+	switch vendor.Type {
+	case "shopify":
+		return parseShopifyProducts(body)
+	case "magento":
+		return parseMagentoProductPage(string(body), link), nil
+	case "html-ldjson":
+		return parseLdJsonProductPage(string(body), link), nil
+	case "generic-html":
+		return parseGenericPage(string(body), link), nil
+	}
+	return nil, fmt.Errorf("no archive parser for vendor type %q", vendor.Type)
+}
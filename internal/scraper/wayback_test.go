@@ -0,0 +1,74 @@
+package scraper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"longevity-ranker/internal/models"
+)
+
+func TestWaybackSnapshots(t *testing.T) {
+	catalog, err := os.ReadFile(filepath.Join("testdata", "shopify_products.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const live = "https://shop.example/products.json"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cdx/search/cdx":
+			q := r.URL.Query()
+			if q.Get("url") != live || q.Get("from") != "20260101" || q.Get("to") != "20260331" || q.Get("collapse") != "timestamp:8" {
+				t.Errorf("unexpected CDX query %s", r.URL.RawQuery)
+			}
+			w.Write([]byte(`[["timestamp","original"],["20260105120000","` + live + `"],["20260212083000","` + live + `"]]`))
+		case "/web/20260105120000id_/" + live:
+			w.Write(catalog)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	defer func(base string) { waybackBase = base }(waybackBase)
+	waybackBase = srv.URL
+
+	snaps, err := ListSnapshots(live, "20260101", "20260331", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Snapshot{{"20260105120000", live}, {"20260212083000", live}}
+	if !reflect.DeepEqual(snaps, want) {
+		t.Fatalf("ListSnapshots() = %+v, want %+v", snaps, want)
+	}
+	if got := snaps[0].Date(); got != "2026-01-05" {
+		t.Errorf("Date() = %q, want 2026-01-05", got)
+	}
+
+	vendor := models.Vendor{Name: "Archived Shopify", URL: live, Type: "shopify"}
+	products, err := FetchSnapshotProducts(vendor, snaps[0], live)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(products) != 3 {
+		t.Errorf("products = %d, want 3", len(products))
+	}
+	if _, err := FetchSnapshotProducts(vendor, snaps[1], live); err == nil {
+		t.Error("missing capture: want an error")
+	}
+}
+
+func TestWaybackNoSnapshots(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	defer func(base string) { waybackBase = base }(waybackBase)
+	waybackBase = srv.URL
+
+	snaps, err := ListSnapshots("https://shop.example/products.json", "20260101", "20260331", 10)
+	if err != nil || snaps != nil {
+		t.Errorf("ListSnapshots() = %v, %v; want nil, nil", snaps, err)
+	}
+}
@@ -0,0 +1,56 @@
+package seed
+
+import (
+	"embed"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// files is the seed dataset: the vendor list, vendor rules, supplement
+// registry and the cached products of every vendor that had any, as of the
+// last `go run ./cmd/seed`.
+//
+//go:embed data/*.json
+var files embed.FS
+
+// Dir is where the seed dataset lives in the source tree, relative to the
+// repo root. cmd/seed refreshes it from data/.
+var Dir = filepath.Join("internal", "seed", "data")
+
+// Names returns the seed file names, sorted.
+func Names() []string {
+	entries, err := fs.ReadDir(files, "data")
+	if err != nil {
+		return nil // The pattern above guarantees the directory
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Restore writes every seed file missing from dir and returns the names it
+// wrote, sorted. Existing files are never replaced: local data, however
+// old, is newer than what the binary was built with.
+func Restore(dir string) ([]string, error) {
+	var written []string
+	for _, name := range Names() {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			continue
+		}
+		data, err := files.ReadFile("data/" + name)
+		if err != nil {
+			return written, err
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return written, err
+		}
+		written = append(written, name)
+	}
+	return written, nil
+}
@@ -0,0 +1,36 @@
+package seed
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestRestore(t *testing.T) {
+	names := Names()
+	for _, want := range []string{"vendors.json", "vendor_rules.json", "supplements.json"} {
+		if !slices.Contains(names, want) {
+			t.Errorf("Names() = %v, missing %s", names, want)
+		}
+	}
+
+	dir := t.TempDir()
+	local := filepath.Join(dir, "vendor_rules.json")
+	if err := os.WriteFile(local, []byte(`{"*": {}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	written, err := Restore(dir)
+	if err != nil {
+		t.Fatalf("Restore() = %v", err)
+	}
+	if len(written) != len(names)-1 || slices.Contains(written, "vendor_rules.json") {
+		t.Errorf("Restore() wrote %v, want every seed file but the local vendor_rules.json", written)
+	}
+	if data, _ := os.ReadFile(local); string(data) != `{"*": {}}` {
+		t.Errorf("Restore() replaced local vendor_rules.json with %.40s…", data)
+	}
+	if again, err := Restore(dir); err != nil || len(again) != 0 {
+		t.Errorf("second Restore() = %v, %v; want nothing written", again, err)
+	}
+}
@@ -0,0 +1,105 @@
+package split
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"longevity-ranker/internal/models"
+	"longevity-ranker/internal/storage"
+	"longevity-ranker/internal/taxonomy"
+)
+
+// Dir holds the per-supplement reports and their index, relative to the
+// repo root: next to the full report, so the CI workflow commits them too.
+var Dir = storage.DataDir
+
+// IndexName is the file name of the index within Dir.
+const IndexName = "report_index.json"
+
+// FileName is the file name of one supplement's report, by its name in the
+// registry: report_nmn.json.
+func FileName(key string) string {
+	return "report_" + key + ".json"
+}
+
+// Entry describes one supplement's report file.
+type Entry struct {
+	Supplement string `json:"supplement"` // Supplement name in the registry
+	File       string `json:"file"`       // Name next to the index
+	Entries    int    `json:"entries"`
+	Date       string `json:"date"`   // YYYY-MM-DD of the run that wrote it
+	RunID      string `json:"run_id"` // That run's manifest ID
+}
+
+// Index lists the per-supplement reports in the order they were first
+// written.
+type Index struct {
+	Supplements []Entry `json:"supplements"`
+}
+
+// Save writes the report of each tracked supplement (the ones this run
+// ranked), in registry order, with the entries of report whose Supplement
+// is its name, in report order, and records them in the index. Supplements
+// not tracked keep their file and index entry from the run that last ranked
+// them, so a run limited by -supplements refreshes only its own. It returns
+// the paths it wrote under dir, index last.
+func Save(dir string, report []models.Analysis, tracked taxonomy.Registry, date, runID string) ([]string, error) {
+	groups := make(map[string][]models.Analysis, len(tracked))
+	for _, s := range tracked {
+		groups[s.Name] = []models.Analysis{}
+	}
+	for _, a := range report {
+		if entries, ok := groups[a.Supplement]; ok {
+			groups[a.Supplement] = append(entries, a)
+		}
+	}
+
+	indexPath := filepath.Join(dir, IndexName)
+	index, err := LoadIndex(indexPath)
+	if err != nil {
+		return nil, err
+	}
+	var written []string
+	for _, s := range tracked {
+		entries := groups[s.Name]
+		path := filepath.Join(dir, FileName(s.Name))
+		if err := storage.SaveJSON(path, entries); err != nil {
+			return written, err
+		}
+		written = append(written, path)
+		index = index.set(Entry{Supplement: s.Name, File: FileName(s.Name), Entries: len(entries), Date: date, RunID: runID})
+	}
+	if err := storage.SaveJSON(indexPath, index); err != nil {
+		return written, err
+	}
+	return append(written, indexPath), nil
+}
+
+// LoadIndex reads the index at path. A missing file is an empty index.
+func LoadIndex(path string) (Index, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return Index{Supplements: []Entry{}}, nil
+	}
+	index, err := storage.LoadJSON[Index](path)
+	if err != nil {
+		return Index{}, fmt.Errorf("could not load report index %s: %v", path, err)
+	}
+	if index.Supplements == nil {
+		index.Supplements = []Entry{}
+	}
+	return index, nil
+}
+
+// set replaces the entry of e's supplement in place, or appends e.
+func (index Index) set(e Entry) Index {
+	entries := slices.Clone(index.Supplements)
+	if at := slices.IndexFunc(entries, func(old Entry) bool { return old.Supplement == e.Supplement }); at >= 0 {
+		entries[at] = e
+	} else {
+		entries = append(entries, e)
+	}
+	index.Supplements = entries
+	return index
+}
@@ -0,0 +1,61 @@
+package split
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"longevity-ranker/internal/models"
+	"longevity-ranker/internal/storage"
+	"longevity-ranker/internal/taxonomy"
+)
+
+func TestSave(t *testing.T) {
+	dir := t.TempDir()
+	report := []models.Analysis{
+		{Name: "NMN Powder", Supplement: "nmn", EffectiveCost: 0.4},
+		{Name: "Trimethylglycine", Supplement: "tmg", EffectiveCost: 0.05},
+		{Name: "NAD+ Boost with NMN", Supplement: "nad", EffectiveCost: 0.9},
+		{Name: "NMN Capsules", Supplement: "nmn", EffectiveCost: 1.1},
+		{Name: "Fish Oil", EffectiveCost: 0.02},
+	}
+
+	paths, err := Save(dir, report, taxonomy.Defaults().Select([]string{"tmg", "nmn", "creatine"}), "2026-01-01", "run-1")
+	want := []string{
+		filepath.Join(dir, "report_nmn.json"), filepath.Join(dir, "report_tmg.json"),
+		filepath.Join(dir, "report_creatine.json"), filepath.Join(dir, IndexName),
+	}
+	if err != nil || !reflect.DeepEqual(paths, want) {
+		t.Fatalf("Save() = %v, %v; want %v", paths, err, want)
+	}
+	nmn, err := storage.LoadJSON[[]models.Analysis](filepath.Join(dir, "report_nmn.json"))
+	if err != nil || len(nmn) != 2 || nmn[0].Name != "NMN Powder" || nmn[1].Name != "NMN Capsules" {
+		t.Errorf("report_nmn.json = %+v, %v; want both NMN entries in report order", nmn, err)
+	}
+	creatine, err := storage.LoadJSON[[]models.Analysis](filepath.Join(dir, "report_creatine.json"))
+	if err != nil || creatine == nil || len(creatine) != 0 {
+		t.Errorf("report_creatine.json = %#v, %v; want []", creatine, err)
+	}
+
+	// A later NAD-only run adds NAD and leaves the rest
+	if _, err := Save(dir, report[2:3], taxonomy.Defaults().Select([]string{"nad"}), "2026-01-02", "run-2"); err != nil {
+		t.Fatal(err)
+	}
+	index, err := LoadIndex(filepath.Join(dir, IndexName))
+	wantIndex := Index{Supplements: []Entry{
+		{Supplement: "nmn", File: "report_nmn.json", Entries: 2, Date: "2026-01-01", RunID: "run-1"},
+		{Supplement: "tmg", File: "report_tmg.json", Entries: 1, Date: "2026-01-01", RunID: "run-1"},
+		{Supplement: "creatine", File: "report_creatine.json", Entries: 0, Date: "2026-01-01", RunID: "run-1"},
+		{Supplement: "nad", File: "report_nad.json", Entries: 1, Date: "2026-01-02", RunID: "run-2"},
+	}}
+	if err != nil || !reflect.DeepEqual(index, wantIndex) {
+		t.Errorf("index = %+v, %v; want %+v", index, err, wantIndex)
+	}
+}
+
+func TestLoadIndexMissing(t *testing.T) {
+	index, err := LoadIndex(filepath.Join(t.TempDir(), IndexName))
+	if err != nil || index.Supplements == nil || len(index.Supplements) != 0 {
+		t.Errorf("LoadIndex(missing) = %#v, %v; want an empty index", index, err)
+	}
+}
@@ -0,0 +1,98 @@
+package spread
+
+import (
+	"fmt"
+	"sort"
+
+	"longevity-ranker/internal/models"
+	"longevity-ranker/internal/parser"
+	"longevity-ranker/internal/taxonomy"
+	"longevity-ranker/internal/widget"
+)
+
+// Apply sets Supplement, CostPercentile and CostRatio on every report entry
+// that belongs to a supplement of reg (widget.GroupOf), comparing effective
+// costs within the supplement. The reference pool is the supplement's entries
+// above the fold, so a mis-parsed blend neither sets the "cheapest" nor
+// skews the percentiles; flagged entries are still placed against it. A
+// supplement with only flagged entries uses them all.
+func Apply(report []models.Analysis, reg taxonomy.Registry) {
+	groups := make(map[string][]int)
+	for i := range report {
+		if key := widget.GroupOf(reg, report[i]); key != "" {
+			report[i].Supplement = key
+			groups[key] = append(groups[key], i)
+		}
+	}
+
+	for _, members := range groups {
+		var pool []float64
+		for _, i := range members {
+			if !parser.BelowFold(report[i]) {
+				pool = append(pool, report[i].EffectiveCost)
+			}
+		}
+		if len(pool) == 0 {
+			for _, i := range members {
+				pool = append(pool, report[i].EffectiveCost)
+			}
+		}
+		sort.Float64s(pool)
+
+		for _, i := range members {
+			a := &report[i]
+			if pool[0] > 0 {
+				a.CostRatio = a.EffectiveCost / pool[0]
+			}
+			a.CostPercentile = percentile(pool, a.EffectiveCost, !parser.BelowFold(*a))
+		}
+	}
+}
+
+// Rank sets SupplementRank on every report entry above the fold that
+// belongs to a supplement, numbering each supplement's entries in report
+// order, so the report must be sorted and Apply must have run. When previous
+// (the last run's report) ranked the same entry (vendor, handle, variant and
+// purchase type), it also sets PreviousRank and RankChange.
+func Rank(report, previous []models.Analysis) {
+	before := make(map[string]int, len(previous))
+	for _, a := range previous {
+		if a.SupplementRank > 0 {
+			before[rankKey(a)] = a.SupplementRank
+		}
+	}
+	next := map[string]int{}
+	for i := range report {
+		a := &report[i]
+		a.SupplementRank, a.PreviousRank, a.RankChange = 0, 0, 0
+		if a.Supplement == "" || parser.BelowFold(*a) {
+			continue
+		}
+		next[a.Supplement]++
+		a.SupplementRank = next[a.Supplement]
+		if prev, ok := before[rankKey(*a)]; ok {
+			a.PreviousRank = prev
+			a.RankChange = prev - a.SupplementRank
+		}
+	}
+}
+
+// rankKey identifies an entry across runs.
+func rankKey(a models.Analysis) string {
+	return fmt.Sprintf("%s|%s|%s|%s|%t", a.Supplement, a.Vendor, a.Handle, a.Variant, a.IsSubscription)
+}
+
+// percentile returns the share (0–100) of the sorted pool costing strictly
+// more than cost, so 100 is the cheapest. An entry that is itself in the
+// pool is not compared with itself; alone in it, it scores 100.
+func percentile(pool []float64, cost float64, inPool bool) float64 {
+	others := len(pool)
+	if inPool {
+		others--
+	}
+	if others <= 0 {
+		return 100
+	}
+	dearer := len(pool) - sort.Search(len(pool), func(i int) bool { return pool[i] > cost })
+	return 100 * float64(dearer) / float64(others)
+}
@@ -0,0 +1,90 @@
+package spread
+
+import (
+	"math"
+	"testing"
+
+	"longevity-ranker/internal/models"
+	"longevity-ranker/internal/taxonomy"
+)
+
+func TestApply(t *testing.T) {
+	entry := func(name string, cost float64) models.Analysis {
+		return models.Analysis{Name: name, EffectiveCost: cost, Confidence: 0.75}
+	}
+	report := []models.Analysis{
+		entry("NMN Powder", 0.40),
+		entry("NMN Capsules", 0.80),
+		entry("NMN Liposomal", 0.72),
+		entry("NAD+ Boost with NMN", 2.00), // NAD: named first
+		entry("Fish Oil", 0.01),
+	}
+	flagged := entry("NMN Berry Flavor", 0.10)
+	flagged.NeedsReview = true
+	report = append(report, flagged)
+
+	Apply(report, taxonomy.Defaults())
+
+	tests := []struct {
+		supplement string
+		percentile float64
+		ratio      float64
+	}{
+		{"nmn", 100, 1},
+		{"nmn", 0, 2},
+		{"nmn", 50, 1.8},
+		{"nad", 100, 1},
+		{"", 0, 0},
+		// Below the fold: placed against the trusted pool, never the reference
+		{"nmn", 100, 0.25},
+	}
+	for i, tt := range tests {
+		a := report[i]
+		if a.Supplement != tt.supplement || a.CostPercentile != tt.percentile || math.Abs(a.CostRatio-tt.ratio) > 1e-9 {
+			t.Errorf("%q: supplement/percentile/ratio = %q/%v/%v, want %q/%v/%v", a.Name,
+				a.Supplement, a.CostPercentile, a.CostRatio, tt.supplement, tt.percentile, tt.ratio)
+		}
+	}
+}
+
+func TestRank(t *testing.T) {
+	entry := func(name, handle string, cost float64) models.Analysis {
+		return models.Analysis{Name: name, Vendor: "V", Handle: handle, EffectiveCost: cost, Confidence: 0.75}
+	}
+	previous := []models.Analysis{
+		entry("NMN Powder", "powder", 0.40),
+		entry("NMN Capsules", "caps", 0.80),
+		entry("NMN Tablets", "tabs", 0.90),
+	}
+	Apply(previous, taxonomy.Defaults())
+	Rank(previous, nil)
+
+	sub := entry("NMN Capsules", "caps", 0.30)
+	sub.IsSubscription = true
+	flagged := entry("NMN Blend", "blend", 0.10)
+	flagged.NeedsReview = true
+	report := []models.Analysis{
+		sub,
+		entry("NMN Tablets", "tabs", 0.35),
+		entry("NMN Powder", "powder", 0.40),
+		entry("NAD+ Boost", "nad", 2.00),
+		flagged,
+	}
+	Apply(report, taxonomy.Defaults())
+	Rank(report, previous)
+
+	want := []struct{ rank, previous, change int }{
+		{1, 0, 0}, // The subscription entry is new, not the one-time capsules
+		{2, 3, 1},
+		{3, 1, -2},
+		{1, 0, 0}, // First NAD entry
+		{0, 0, 0}, // Below the fold
+	}
+	for i, w := range want {
+		a := report[i]
+		if a.SupplementRank != w.rank || a.PreviousRank != w.previous || a.RankChange != w.change {
+			t.Errorf("%s: rank/previous/change = %d/%d/%d, want %d/%d/%d", a.Name,
+				a.SupplementRank, a.PreviousRank, a.RankChange, w.rank, w.previous, w.change)
+		}
+	}
+}
@@ -25,6 +25,8 @@ func VendorFilename(vendorName string) string {
 }
 
 // SaveJSON marshals any value to pretty-printed JSON and writes it to path.
+// Struct fields keep their declaration order and map keys are sorted, so
+// rewriting unchanged data produces an identical file; callers sort slices.
 func SaveJSON[T any](path string, data T) error {
 	bytes, err := json.MarshalIndent(data, "", "  ")
 	if err != nil {
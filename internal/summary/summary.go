@@ -0,0 +1,136 @@
+package summary
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"longevity-ranker/internal/manifest"
+	"longevity-ranker/internal/models"
+	"longevity-ranker/internal/parser"
+	"longevity-ranker/internal/storage"
+	"longevity-ranker/internal/taxonomy"
+	"longevity-ranker/internal/widget"
+)
+
+// Filename is the vendor summary path, relative to the repo root: next to
+// the report, so the CI workflow commits it too. Each run overwrites it.
+var Filename = filepath.Join(storage.DataDir, "vendor_summary.json")
+
+// Cheapest is a vendor's lowest True Cost entry for one supplement.
+type Cheapest struct {
+	Supplement    string  `json:"supplement"` // Supplement name in the registry
+	Name          string  `json:"name"`
+	Handle        string  `json:"handle"`
+	Variant       string  `json:"variant,omitempty"`
+	Price         float64 `json:"price"`
+	CostPerGram   float64 `json:"cost_per_gram"`
+	EffectiveCost float64 `json:"effective_cost"`
+}
+
+// Vendor is one vendor's card: what it sells, how cheaply, and how far its
+// data can be trusted.
+type Vendor struct {
+	Vendor         string     `json:"vendor"`
+	Status         string     `json:"status"`   // manifest.Status* of this run
+	Products       int        `json:"products"` // Distinct products in the report
+	Entries        int        `json:"entries"`  // Report entries, subscriptions included
+	Cheapest       []Cheapest `json:"cheapest"` // In registry order
+	AvgCostPerGram float64    `json:"avg_cost_per_gram"`
+	QualityScore   float64    `json:"quality_score"`          // parser.VendorQuality.Score, 0–100
+	LastScraped    string     `json:"last_scraped,omitempty"` // RFC 3339; "" when never scraped live
+}
+
+// Summary is the file's content, vendors by name.
+type Summary struct {
+	Date    string   `json:"date"`
+	RunID   string   `json:"run_id"`
+	Vendors []Vendor `json:"vendors"`
+}
+
+// Build summarizes every vendor of the run (statuses, in any order) from the
+// report and the data quality table, with Cheapest per supplement of reg.
+// Cheapest and AvgCostPerGram count only the one-time entries above the
+// fold (see parser.BelowFold), like the ranking's winners. A vendor scraped
+// live gets now as LastScraped; one loaded from its cache keeps the time
+// previous recorded for it.
+func Build(report []models.Analysis, quality []parser.VendorQuality, statuses []manifest.VendorStatus, reg taxonomy.Registry, previous Summary, now time.Time) []Vendor {
+	byVendor := make(map[string][]models.Analysis)
+	for _, a := range report {
+		byVendor[a.Vendor] = append(byVendor[a.Vendor], a)
+	}
+	scores := make(map[string]float64, len(quality))
+	for _, q := range quality {
+		scores[q.Vendor] = q.Score
+	}
+	lastScraped := make(map[string]string, len(previous.Vendors))
+	for _, v := range previous.Vendors {
+		lastScraped[v.Vendor] = v.LastScraped
+	}
+
+	vendors := make([]Vendor, 0, len(statuses))
+	for _, s := range statuses {
+		v := summarize(s.Vendor, byVendor[s.Vendor], reg)
+		v.Status = s.Status
+		v.QualityScore = scores[s.Vendor]
+		v.LastScraped = lastScraped[s.Vendor]
+		if s.Status == manifest.StatusScraped {
+			v.LastScraped = now.UTC().Format(time.RFC3339)
+		}
+		vendors = append(vendors, v)
+	}
+	slices.SortFunc(vendors, func(a, b Vendor) int { return strings.Compare(a.Vendor, b.Vendor) })
+	return vendors
+}
+
+// summarize counts one vendor's entries and finds its cheapest per
+// supplement.
+func summarize(name string, entries []models.Analysis, reg taxonomy.Registry) Vendor {
+	v := Vendor{Vendor: name, Entries: len(entries), Cheapest: []Cheapest{}}
+	handles := make(map[string]bool)
+	cheapest := make(map[string]models.Analysis)
+	sum, n := 0.0, 0
+	for _, a := range entries {
+		handles[a.Handle] = true
+		if a.IsSubscription || parser.BelowFold(a) {
+			continue
+		}
+		sum += a.CostPerGram
+		n++
+		key := a.Supplement
+		if key == "" {
+			key = widget.GroupOf(reg, a)
+		}
+		if best, ok := cheapest[key]; key != "" && (!ok || a.EffectiveCost < best.EffectiveCost) {
+			cheapest[key] = a
+		}
+	}
+	v.Products = len(handles)
+	if n > 0 {
+		v.AvgCostPerGram = sum / float64(n)
+	}
+	for _, s := range reg {
+		if a, ok := cheapest[s.Name]; ok {
+			v.Cheapest = append(v.Cheapest, Cheapest{
+				Supplement: s.Name, Name: a.Name, Handle: a.Handle, Variant: a.Variant,
+				Price: a.Price, CostPerGram: a.CostPerGram, EffectiveCost: a.EffectiveCost,
+			})
+		}
+	}
+	return v
+}
+
+// Load reads the summary at path. A missing file is an empty summary.
+func Load(path string) (Summary, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return Summary{Vendors: []Vendor{}}, nil
+	}
+	s, err := storage.LoadJSON[Summary](path)
+	if err != nil {
+		return Summary{}, fmt.Errorf("could not load vendor summary %s: %v", path, err)
+	}
+	return s, nil
+}
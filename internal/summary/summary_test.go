@@ -0,0 +1,59 @@
+package summary
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"longevity-ranker/internal/manifest"
+	"longevity-ranker/internal/models"
+	"longevity-ranker/internal/parser"
+	"longevity-ranker/internal/taxonomy"
+)
+
+func TestBuild(t *testing.T) {
+	report := []models.Analysis{
+		{Vendor: "Shop", Name: "NMN Powder", Handle: "nmn-powder", Supplement: "nmn", Price: 40, CostPerGram: 0.4, EffectiveCost: 0.4, Confidence: 1},
+		{Vendor: "Shop", Name: "NMN Powder", Handle: "nmn-powder", Supplement: "nmn", Price: 30, CostPerGram: 0.3, EffectiveCost: 0.3, Confidence: 1, IsSubscription: true},
+		{Vendor: "Shop", Name: "NMN Capsules", Handle: "nmn-caps", Supplement: "nmn", Price: 50, CostPerGram: 1.0, EffectiveCost: 1.1, Confidence: 1},
+		{Vendor: "Shop", Name: "Trimethylglycine", Handle: "tmg", Price: 10, CostPerGram: 0.05, EffectiveCost: 0.05, Confidence: 1}, // Older report: grouped by name
+		{Vendor: "Shop", Name: "NMN Blend", Handle: "nmn-blend", Supplement: "nmn", Price: 5, CostPerGram: 0.01, EffectiveCost: 0.01, Confidence: 1, NeedsReview: true},
+		{Vendor: "Other", Name: "Creatine", Handle: "creatine", Supplement: "creatine", Price: 20, CostPerGram: 0.02, EffectiveCost: 0.02, Confidence: 1},
+	}
+	quality := []parser.VendorQuality{{Vendor: "Shop", Score: 80}, {Vendor: "Other", Score: 95}}
+	statuses := []manifest.VendorStatus{
+		{Vendor: "Shop", Status: manifest.StatusScraped},
+		{Vendor: "Other", Status: manifest.StatusCached},
+		{Vendor: "Down", Status: manifest.StatusFailed},
+	}
+	previous := Summary{Vendors: []Vendor{{Vendor: "Shop", LastScraped: "2026-01-01T08:00:00Z"}, {Vendor: "Other", LastScraped: "2026-01-02T08:00:00Z"}}}
+
+	got := Build(report, quality, statuses, taxonomy.Defaults(), previous, time.Date(2026, 1, 3, 8, 0, 0, 0, time.UTC))
+	want := []Vendor{
+		{Vendor: "Down", Status: manifest.StatusFailed, Cheapest: []Cheapest{}},
+		{
+			Vendor: "Other", Status: manifest.StatusCached, Products: 1, Entries: 1,
+			Cheapest:       []Cheapest{{Supplement: "creatine", Name: "Creatine", Handle: "creatine", Price: 20, CostPerGram: 0.02, EffectiveCost: 0.02}},
+			AvgCostPerGram: 0.02, QualityScore: 95, LastScraped: "2026-01-02T08:00:00Z",
+		},
+		{
+			Vendor: "Shop", Status: manifest.StatusScraped, Products: 4, Entries: 5,
+			Cheapest: []Cheapest{
+				{Supplement: "nmn", Name: "NMN Powder", Handle: "nmn-powder", Price: 40, CostPerGram: 0.4, EffectiveCost: 0.4},
+				{Supplement: "tmg", Name: "Trimethylglycine", Handle: "tmg", Price: 10, CostPerGram: 0.05, EffectiveCost: 0.05},
+			},
+			AvgCostPerGram: (0.4 + 1.0 + 0.05) / 3, QualityScore: 80, LastScraped: "2026-01-03T08:00:00Z",
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Build() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestLoadMissing(t *testing.T) {
+	s, err := Load(filepath.Join(t.TempDir(), "vendor_summary.json"))
+	if err != nil || s.Vendors == nil || len(s.Vendors) != 0 {
+		t.Errorf("Load(missing) = %#v, %v; want an empty summary", s, err)
+	}
+}
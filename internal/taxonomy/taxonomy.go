@@ -0,0 +1,267 @@
+package taxonomy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"longevity-ranker/internal/storage"
+)
+
+// Filename is the supplement registry path, relative to the repo root.
+var Filename = filepath.Join(storage.DataDir, "supplements.json")
+
+// Form is a labeled molecular form (salt, ester, hydrate) and the share of
+// its weight that is the active moiety: molar mass of the active compound
+// over molar mass of the labeled compound. Labels state the compound weight,
+// so 750 mg of creatine HCl is only 587 mg of creatine.
+type Form struct {
+	Keywords []string `json:"keywords"` // Lowercase phrases, hyphens as spaces
+	Label    string   `json:"label"`
+	Fraction float64  `json:"fraction"`
+}
+
+// Supplement is everything the analyzer knows about one compound.
+//
+// Name and Aliases are the lowercase keywords that put a product under the
+// supplement. Purity is the share of the labeled compound weight that is
+// the compound itself (0.98 for a 98% pure powder); 0 means pure. Forms are
+// checked in order, so blends resolve to the form listed first.
+// MinUnitMg/MaxUnitMg bound the mg per capsule or tablet a label can
+// plausibly state, and MinCostPerGram/MaxCostPerGram the retail price per
+// active gram, in the report currency; 0 leaves that side open.
+type Supplement struct {
+	Name           string   `json:"name"`
+	Aliases        []string `json:"aliases,omitempty"`
+	TargetDoseMg   float64  `json:"targetDoseMg,omitempty"`
+	Purity         float64  `json:"purity,omitempty"`
+	Forms          []Form   `json:"forms,omitempty"`
+	MinUnitMg      float64  `json:"minUnitMg,omitempty"`
+	MaxUnitMg      float64  `json:"maxUnitMg,omitempty"`
+	MinCostPerGram float64  `json:"minCostPerGram,omitempty"`
+	MaxCostPerGram float64  `json:"maxCostPerGram,omitempty"`
+}
+
+// Registry is the list of tracked supplements, in display order.
+type Registry []Supplement
+
+// Defaults is the built-in registry, written to Filename when it does not
+// exist yet.
+//
+// Molar masses behind the form fractions: creatine 131.13, creatine
+// monohydrate 149.15, creatine HCl 167.59, creatine nitrate 194.14, malic
+// acid 134.09, citric acid 192.12 (tri-creatine salts carry three
+// creatines), betaine 117.15, betaine HCl 153.61, nicotinamide riboside
+// 255.25, NR chloride 290.70.
+//
+// Pterostilbene is a separate molecule (dimethylated resveratrol), not a
+// resveratrol salt: there is no factor between the two, so its form only
+// labels the product so it is not taken for resveratrol.
+//
+// The cost bounds are wide of every retail price seen so far (bulk NMN
+// powder has not sold below $0.30/g), so they only catch parses off by an
+// order of magnitude.
+func Defaults() Registry {
+	return Registry{
+		{Name: "nmn", TargetDoseMg: 500, MinUnitMg: 50, MaxUnitMg: 1500, MinCostPerGram: 0.30, MaxCostPerGram: 20},
+		{
+			Name: "nad", TargetDoseMg: 300, MinUnitMg: 50, MaxUnitMg: 1500, MinCostPerGram: 0.20, MaxCostPerGram: 20,
+			Forms: []Form{
+				{[]string{"nicotinamide riboside chloride", "nr chloride"}, "NR Chloride", 0.878},
+			},
+		},
+		{
+			Name: "tmg", Aliases: []string{"trimethylglycine"}, TargetDoseMg: 1000, MinUnitMg: 250, MaxUnitMg: 2000, MinCostPerGram: 0.01, MaxCostPerGram: 3,
+			Forms: []Form{
+				{[]string{"betaine hcl", "betaine hydrochloride"}, "Betaine HCl", 0.763},
+			},
+		},
+		{
+			Name: "resveratrol", TargetDoseMg: 500, MinUnitMg: 50, MaxUnitMg: 1500, MinCostPerGram: 0.10, MaxCostPerGram: 10,
+			Forms: []Form{
+				{[]string{"pterostilbene"}, "Pterostilbene", 1},
+			},
+		},
+		{
+			Name: "creatine", TargetDoseMg: 5000, MinUnitMg: 250, MaxUnitMg: 5000, MinCostPerGram: 0.01, MaxCostPerGram: 1,
+			Forms: []Form{
+				{[]string{"creatine hcl", "creatine hydrochloride"}, "Creatine HCl", 0.782},
+				{[]string{"creatine nitrate"}, "Creatine Nitrate", 0.675},
+				{[]string{"creatine malate"}, "Tri-Creatine Malate", 0.746},
+				{[]string{"creatine citrate"}, "Tri-Creatine Citrate", 0.672},
+				{[]string{"creatine monohydrate"}, "Creatine Monohydrate", 0.879},
+			},
+		},
+	}
+}
+
+// Load reads the registry from path. A missing file is created from
+// Defaults, like the vendor list. Keywords are lowercased and trimmed; a
+// keyword may belong to one supplement only, purity and form fractions lie
+// in (0, 1], and neither the unit mg nor the cost range may be inverted.
+func Load(path string) (Registry, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		reg := Defaults()
+		if err := storage.SaveJSON(path, reg); err != nil {
+			return nil, fmt.Errorf("could not write default supplement registry: %v", err)
+		}
+		fmt.Printf("📝 Wrote the default supplement registry to %s\n", path)
+		return reg, nil
+	}
+
+	reg, err := storage.LoadJSON[Registry](path)
+	if err != nil {
+		return nil, fmt.Errorf("could not load supplement registry %s: %v", path, err)
+	}
+	owner := map[string]string{} // keyword → supplement name
+	for i := range reg {
+		s := &reg[i]
+		s.Name = normalize(s.Name)
+		if s.Name == "" {
+			return nil, fmt.Errorf("%s: supplement #%d has no name", path, i+1)
+		}
+		for j := range s.Aliases {
+			s.Aliases[j] = normalize(s.Aliases[j])
+		}
+		for _, kw := range s.Keywords() {
+			if kw == "" {
+				return nil, fmt.Errorf("%s: supplement %q has an empty alias", path, s.Name)
+			}
+			if other, ok := owner[kw]; ok {
+				return nil, fmt.Errorf("%s: keyword %q belongs to both %q and %q", path, kw, other, s.Name)
+			}
+			owner[kw] = s.Name
+		}
+		switch {
+		case s.TargetDoseMg < 0:
+			return nil, fmt.Errorf("%s: supplement %q: negative targetDoseMg", path, s.Name)
+		case s.Purity < 0 || s.Purity > 1:
+			return nil, fmt.Errorf("%s: supplement %q: purity %v is not between 0 and 1", path, s.Name, s.Purity)
+		case s.MinUnitMg < 0 || s.MaxUnitMg < 0 || (s.MaxUnitMg > 0 && s.MinUnitMg > s.MaxUnitMg):
+			return nil, fmt.Errorf("%s: supplement %q: invalid unit range %v–%v mg", path, s.Name, s.MinUnitMg, s.MaxUnitMg)
+		case s.MinCostPerGram < 0 || s.MaxCostPerGram < 0 || (s.MaxCostPerGram > 0 && s.MinCostPerGram > s.MaxCostPerGram):
+			return nil, fmt.Errorf("%s: supplement %q: invalid cost range %v–%v per gram", path, s.Name, s.MinCostPerGram, s.MaxCostPerGram)
+		}
+		for j := range s.Forms {
+			f := &s.Forms[j]
+			if f.Fraction <= 0 || f.Fraction > 1 {
+				return nil, fmt.Errorf("%s: supplement %q: form %q fraction %v is not in (0, 1]", path, s.Name, f.Label, f.Fraction)
+			}
+			for k := range f.Keywords {
+				f.Keywords[k] = normalize(f.Keywords[k])
+			}
+		}
+	}
+	return reg, nil
+}
+
+func normalize(keyword string) string {
+	return strings.ToLower(strings.TrimSpace(keyword))
+}
+
+// Keywords returns the supplement's name followed by its aliases.
+func (s Supplement) Keywords() []string {
+	return append([]string{s.Name}, s.Aliases...)
+}
+
+// Form returns the labeled molecular form found in text (lowercased) and
+// its active fraction. An unrecognized form is taken as the compound
+// itself: ("", 1).
+func (s Supplement) Form(text string) (label string, fraction float64) {
+	text = strings.ReplaceAll(text, "-", " ")
+	for _, f := range s.Forms {
+		for _, kw := range f.Keywords {
+			if strings.Contains(text, kw) {
+				return f.Label, f.Fraction
+			}
+		}
+	}
+	return "", 1
+}
+
+// named reports whether name is the supplement's name or one of its aliases.
+func (s Supplement) named(name string) bool {
+	name = normalize(name)
+	for _, kw := range s.Keywords() {
+		if kw == name {
+			return true
+		}
+	}
+	return false
+}
+
+// PurityFraction returns Purity, or 1 when it is not set.
+func (s Supplement) PurityFraction() float64 {
+	if s.Purity > 0 {
+		return s.Purity
+	}
+	return 1
+}
+
+// PlausibleUnitMg reports whether mg per capsule or tablet lies within the
+// supplement's unit range.
+func (s Supplement) PlausibleUnitMg(mg float64) bool {
+	return mg >= s.MinUnitMg && (s.MaxUnitMg == 0 || mg <= s.MaxUnitMg)
+}
+
+// PlausibleCostPerGram reports whether a price per active gram lies within
+// the supplement's cost range.
+func (s Supplement) PlausibleCostPerGram(cost float64) bool {
+	return cost >= s.MinCostPerGram && (s.MaxCostPerGram == 0 || cost <= s.MaxCostPerGram)
+}
+
+// Names returns the supplement names in registry order.
+func (r Registry) Names() []string {
+	names := make([]string, len(r))
+	for i, s := range r {
+		names[i] = s.Name
+	}
+	return names
+}
+
+// Lookup returns the supplement with the given name or alias.
+func (r Registry) Lookup(name string) (Supplement, bool) {
+	for _, s := range r {
+		if s.named(name) {
+			return s, true
+		}
+	}
+	return Supplement{}, false
+}
+
+// Select returns the supplements named (by name or alias) in names, in
+// registry order and each once. Unknown names are skipped; callers that
+// take names from the user check them with Lookup first.
+func (r Registry) Select(names []string) Registry {
+	var selected Registry
+	for _, s := range r {
+		for _, name := range names {
+			if s.named(name) {
+				selected = append(selected, s)
+				break
+			}
+		}
+	}
+	return selected
+}
+
+// Match returns the supplement whose keyword occurs earliest in identity
+// (lowercased title, context and handle), so "NMN + Resveratrol" is NMN.
+// Keywords starting at the same position go to the longer one.
+func (r Registry) Match(identity string) (Supplement, bool) {
+	var best Supplement
+	bestAt, bestLen := -1, 0
+	for _, s := range r {
+		for _, kw := range s.Keywords() {
+			at := strings.Index(identity, kw)
+			if at < 0 {
+				continue
+			}
+			if bestAt < 0 || at < bestAt || (at == bestAt && len(kw) > bestLen) {
+				best, bestAt, bestLen = s, at, len(kw)
+			}
+		}
+	}
+	return best, bestAt >= 0
+}
@@ -0,0 +1,94 @@
+package taxonomy
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "supplements.json")
+	reg, err := Load(path)
+	if err != nil || !reflect.DeepEqual(reg, Defaults()) {
+		t.Fatalf("Load(missing) = %v, %v; want the defaults", reg.Names(), err)
+	}
+	again, err := Load(path)
+	if err != nil || !reflect.DeepEqual(again, Defaults()) {
+		t.Errorf("Load(written defaults) = %v, %v; want the defaults", again.Names(), err)
+	}
+}
+
+func TestLoadValidation(t *testing.T) {
+	tests := []struct {
+		json    string
+		wantErr bool
+	}{
+		{`[{"name": " NMN ", "aliases": ["Beta-NMN"], "purity": 0.99, "minUnitMg": 50, "minCostPerGram": 0.3}]`, false},
+		{`[{"name": ""}]`, true},
+		{`[{"name": "tmg"}, {"name": "betaine", "aliases": ["TMG"]}]`, true},
+		{`[{"name": "nmn", "purity": 1.5}]`, true},
+		{`[{"name": "nmn", "minUnitMg": 500, "maxUnitMg": 100}]`, true},
+		{`[{"name": "nmn", "minCostPerGram": 5, "maxCostPerGram": 0.3}]`, true},
+		{`[{"name": "nmn", "maxCostPerGram": -1}]`, true},
+		{`[{"name": "creatine", "forms": [{"keywords": ["creatine hcl"], "label": "Creatine HCl", "fraction": 0}]}]`, true},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "supplements.json")
+		if err := os.WriteFile(path, []byte(tt.json), 0644); err != nil {
+			t.Fatal(err)
+		}
+		reg, err := Load(path)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Load(%s) error = %v, wantErr %v", tt.json, err, tt.wantErr)
+		}
+		if err == nil {
+			if _, ok := reg.Lookup("beta-nmn"); !ok {
+				t.Errorf("Load(%s): keywords not normalized: %+v", tt.json, reg)
+			}
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	reg := Defaults()
+	tests := []struct {
+		identity string
+		want     string // "" for no match
+	}{
+		{"nmn + resveratrol capsules", "nmn"},
+		{"resveratrol with nmn", "resveratrol"},
+		{"trimethylglycine powder", "tmg"},
+		{"creatine monohydrate", "creatine"},
+		{"vitamin d3", ""},
+	}
+	for _, tt := range tests {
+		s, ok := reg.Match(tt.identity)
+		if ok != (tt.want != "") || s.Name != tt.want {
+			t.Errorf("Match(%q) = %q, %v; want %q", tt.identity, s.Name, ok, tt.want)
+		}
+	}
+
+	// At the same position the longer keyword wins
+	overlap := Registry{{Name: "nad"}, {Name: "nadh"}}
+	if s, _ := overlap.Match("nadh 50mg"); s.Name != "nadh" {
+		t.Errorf("Match(nadh) = %q, want the longer keyword", s.Name)
+	}
+}
+
+func TestSelect(t *testing.T) {
+	got := Defaults().Select([]string{"creatine", "Trimethylglycine", "tmg", "spermidine"})
+	if want := []string{"tmg", "creatine"}; !reflect.DeepEqual(got.Names(), want) {
+		t.Errorf("Select() = %v, want %v in registry order, each once", got.Names(), want)
+	}
+}
+
+func TestForm(t *testing.T) {
+	creatine, _ := Defaults().Lookup("creatine")
+	if label, fraction := creatine.Form("tri-creatine malate powder"); label != "Tri-Creatine Malate" || fraction != 0.746 {
+		t.Errorf("Form(malate) = %q, %v", label, fraction)
+	}
+	if label, fraction := creatine.Form("creatine powder"); label != "" || fraction != 1 {
+		t.Errorf("Form(unlabeled) = %q, %v; want the compound itself", label, fraction)
+	}
+}
@@ -0,0 +1,93 @@
+package watchlist
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+
+	"longevity-ranker/internal/changes"
+	"longevity-ranker/internal/models"
+	"longevity-ranker/internal/storage"
+)
+
+// Filename is the watchlist path, relative to the repo root.
+var Filename = filepath.Join(storage.DataDir, "watchlist.json")
+
+// Entry watches one product, or a single variant of it when Variant is set.
+type Entry struct {
+	Vendor  string `json:"vendor"`
+	Handle  string `json:"handle"`
+	Variant string `json:"variant,omitempty"` // Variant title; empty = every variant
+	Note    string `json:"note,omitempty"`
+}
+
+// Watchlist is the list of watched products.
+type Watchlist []Entry
+
+// Load reads the watchlist from disk. A missing file yields an empty list.
+func Load(path string) (Watchlist, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return Watchlist{}, nil
+	}
+	return storage.LoadJSON[Watchlist](path)
+}
+
+// Watches reports whether the variant is on the watchlist.
+func (w Watchlist) Watches(vendorName, handle, variant string) bool {
+	for _, e := range w {
+		if e.Vendor == vendorName && e.Handle == handle && (e.Variant == "" || e.Variant == variant) {
+			return true
+		}
+	}
+	return false
+}
+
+// Vendors returns the names of the watched vendors, in list order.
+func (w Watchlist) Vendors() []string {
+	var names []string
+	for _, e := range w {
+		if !slices.Contains(names, e.Vendor) {
+			names = append(names, e.Vendor)
+		}
+	}
+	return names
+}
+
+// Handles returns the vendor's watched handles, in list order.
+func (w Watchlist) Handles(vendorName string) []string {
+	var handles []string
+	for _, e := range w {
+		if e.Vendor == vendorName && !slices.Contains(handles, e.Handle) {
+			handles = append(handles, e.Handle)
+		}
+	}
+	return handles
+}
+
+// Filter narrows a product to its watched variants. ok is false when the
+// product is not watched, or none of its variants are.
+func (w Watchlist) Filter(vendorName string, p models.Product) (models.Product, bool) {
+	var variants []models.Variant
+	for _, v := range p.Variants {
+		if w.Watches(vendorName, p.Handle, v.Title) {
+			variants = append(variants, v)
+		}
+	}
+	if len(variants) == 0 {
+		return p, false
+	}
+	p.Variants = variants
+	return p, true
+}
+
+// BackInStock returns the availability changes that are restocks of watched
+// variants.
+func (w Watchlist) BackInStock(availability []changes.AvailabilityChange) []changes.AvailabilityChange {
+	events := []changes.AvailabilityChange{}
+	for _, c := range availability {
+		if c.Available && w.Watches(c.Vendor, c.Handle, c.Variant) {
+			events = append(events, c)
+		}
+	}
+	return events
+}
@@ -0,0 +1,52 @@
+package watchlist
+
+import (
+	"testing"
+
+	"longevity-ranker/internal/changes"
+	"longevity-ranker/internal/models"
+)
+
+func TestBackInStock(t *testing.T) {
+	w := Watchlist{
+		{Vendor: "Vendor", Handle: "nmn-powder"},
+		{Vendor: "Vendor", Handle: "tmg", Variant: "500g"},
+	}
+	availability := []changes.AvailabilityChange{
+		{Vendor: "Vendor", Handle: "nmn-powder", Variant: "100g", Available: true},
+		{Vendor: "Vendor", Handle: "nmn-powder", Variant: "250g", Available: false}, // sold out
+		{Vendor: "Vendor", Handle: "tmg", Variant: "500g", Available: true},
+		{Vendor: "Vendor", Handle: "tmg", Variant: "1kg", Available: true},        // other variant
+		{Vendor: "Other", Handle: "nmn-powder", Variant: "100g", Available: true}, // other vendor
+	}
+
+	got := w.BackInStock(availability)
+	if len(got) != 2 || got[0].Handle != "nmn-powder" || got[1].Variant != "500g" {
+		t.Errorf("BackInStock() = %+v, want nmn-powder 100g and tmg 500g", got)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	w := Watchlist{
+		{Vendor: "Vendor", Handle: "nmn-powder"},
+		{Vendor: "Vendor", Handle: "tmg", Variant: "500g"},
+		{Vendor: "Other", Handle: "tmg"},
+	}
+	variants := []models.Variant{{Title: "500g"}, {Title: "1kg"}}
+
+	if p, ok := w.Filter("Vendor", models.Product{Handle: "nmn-powder", Variants: variants}); !ok || len(p.Variants) != 2 {
+		t.Errorf("Filter(nmn-powder) = %+v, %v; want both variants", p.Variants, ok)
+	}
+	if p, ok := w.Filter("Vendor", models.Product{Handle: "tmg", Variants: variants}); !ok || len(p.Variants) != 1 || p.Variants[0].Title != "500g" {
+		t.Errorf("Filter(tmg) = %+v, %v; want only 500g", p.Variants, ok)
+	}
+	if _, ok := w.Filter("Vendor", models.Product{Handle: "creatine", Variants: variants}); ok {
+		t.Error("Filter(creatine) ok = true, want false")
+	}
+	if got := w.Vendors(); len(got) != 2 || got[0] != "Vendor" || got[1] != "Other" {
+		t.Errorf("Vendors() = %v, want [Vendor Other]", got)
+	}
+	if got := w.Handles("Vendor"); len(got) != 2 || got[1] != "tmg" {
+		t.Errorf("Handles(Vendor) = %v, want [nmn-powder tmg]", got)
+	}
+}
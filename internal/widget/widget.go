@@ -0,0 +1,169 @@
+package widget
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"longevity-ranker/internal/models"
+	"longevity-ranker/internal/storage"
+	"longevity-ranker/internal/taxonomy"
+)
+
+// Filename is the widget path, relative to the repo root. Each run
+// overwrites it.
+var Filename = filepath.Join(storage.DataDir, "widget.json")
+
+// Size limits. The widget is fetched by third-party pages, so it stays small
+// however large the report grows.
+const (
+	DefaultTop = 5         // Entries per supplement unless -widget-top says otherwise
+	MaxTop     = 10        // Hard cap on entries per supplement
+	MaxBytes   = 16 * 1024 // Hard cap on the encoded file
+	maxNameLen = 60        // Longer names are cut with "…"
+)
+
+// Entry is one ranked product, trimmed to what an embed displays.
+type Entry struct {
+	Name          string  `json:"name"`
+	Vendor        string  `json:"vendor"`
+	Price         float64 `json:"price"`
+	CostPerGram   float64 `json:"cost_per_gram"`
+	EffectiveCost float64 `json:"effective_cost"`
+	URL           string  `json:"url"`
+	ImageURL      string  `json:"image_url,omitempty"`
+}
+
+// Widget is the file's content: the cheapest products per supplement,
+// keyed by the supplement's registry name, best first.
+type Widget struct {
+	Date string             `json:"date"`
+	Top  map[string][]Entry `json:"top"`
+}
+
+// Build picks the top entries per supplement of reg from report, which must
+// be sorted by rank (as analyzeAll returns it). An entry belongs to every
+// supplement whose name or alias its name or handle contains, the same
+// matching the frontend's supplement filter uses. Subscription rows and rows
+// flagged for review are left out, and each product appears once per
+// supplement, at its best-ranked variant. top is clamped to MaxTop.
+func Build(report []models.Analysis, vendors []models.Vendor, reg taxonomy.Registry, date string, top int) Widget {
+	if top > MaxTop {
+		top = MaxTop
+	}
+	base := make(map[string]string, len(vendors))
+	for _, v := range vendors {
+		base[v.Name] = v.URL
+	}
+
+	w := Widget{Date: date, Top: make(map[string][]Entry, len(reg))}
+	for _, s := range reg {
+		entries := []Entry{}
+		seen := make(map[string]bool)
+		for _, a := range report {
+			if len(entries) >= top {
+				break
+			}
+			if a.IsSubscription || a.NeedsReview || a.Unavailable || seen[a.Vendor+"|"+a.Handle] {
+				continue
+			}
+			if !matches(strings.ToLower(a.Name+" "+a.Handle), s.Keywords()) {
+				continue
+			}
+			seen[a.Vendor+"|"+a.Handle] = true
+			entries = append(entries, Entry{
+				Name:          truncate(a.Name, maxNameLen),
+				Vendor:        a.Vendor,
+				Price:         round(a.Price, 2),
+				CostPerGram:   round(a.CostPerGram, 3),
+				EffectiveCost: round(a.EffectiveCost, 3),
+				URL:           ProductURL(base[a.Vendor], a.Handle),
+				ImageURL:      a.ImageURL,
+			})
+		}
+		w.Top[s.Name] = entries
+	}
+	return w
+}
+
+// Marshal encodes w compactly, dropping the last entry of the longest section
+// until the result fits in maxBytes. It fails only if even an empty widget
+// does not fit.
+func Marshal(w Widget, maxBytes int) ([]byte, error) {
+	for {
+		data, err := json.Marshal(w)
+		if err != nil || len(data) <= maxBytes {
+			return data, err
+		}
+		keys := make([]string, 0, len(w.Top))
+		for key := range w.Top {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys) // Ties drop from the first section by name
+		longest := ""
+		for _, key := range keys {
+			if len(w.Top[key]) > len(w.Top[longest]) {
+				longest = key
+			}
+		}
+		if longest == "" {
+			return nil, fmt.Errorf("empty widget is %d bytes, over the %d byte limit", len(data), maxBytes)
+		}
+		w.Top[longest] = w.Top[longest][:len(w.Top[longest])-1]
+	}
+}
+
+// ProductURL returns the storefront link for a product. Handles that are
+// already URLs (Magento, LD+JSON, CSV, price APIs) are used as-is; Shopify
+// handles are joined to the vendor URL's host as /products/<handle>.
+// Example: ("https://nutricost.com/collections/all-items/products.json", "nutricost-nmn")
+// → "https://nutricost.com/products/nutricost-nmn"
+func ProductURL(vendorURL, handle string) string {
+	if strings.HasPrefix(handle, "http://") || strings.HasPrefix(handle, "https://") {
+		return handle
+	}
+	u, err := url.Parse(vendorURL)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host + "/products/" + handle
+}
+
+// GroupOf returns the name of the supplement of reg whose keyword occurs
+// earliest in the entry's lowercased name and handle (see
+// taxonomy.Registry.Match), or "" when none does. Unlike the widget
+// sections, which list a blend under every supplement it contains, this
+// picks one: "NAD+ Boost with NMN" is NAD.
+func GroupOf(reg taxonomy.Registry, a models.Analysis) string {
+	s, ok := reg.Match(strings.ToLower(a.Name + " " + a.Handle))
+	if !ok {
+		return ""
+	}
+	return s.Name
+}
+
+func matches(s string, keywords []string) bool {
+	for _, kw := range keywords {
+		if strings.Contains(s, kw) {
+			return true
+		}
+	}
+	return false
+}
+
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n-1]) + "…"
+}
+
+func round(v float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+	return math.Round(v*p) / p
+}
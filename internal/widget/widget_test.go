@@ -0,0 +1,91 @@
+package widget
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"longevity-ranker/internal/models"
+	"longevity-ranker/internal/taxonomy"
+)
+
+func TestBuild(t *testing.T) {
+	vendors := []models.Vendor{
+		{Name: "Shop", URL: "https://shop.example/collections/all/products.json?currency=USD"},
+		{Name: "Pages", URL: "https://pages.example/products/"},
+	}
+	// Sorted by effective cost, as analyzeAll returns it
+	report := []models.Analysis{
+		{Vendor: "Shop", Name: "NMN Powder (Subscribe & Save)", Handle: "nmn-powder", Price: 40, EffectiveCost: 0.4, IsSubscription: true},
+		{Vendor: "Shop", Name: "NMN Powder", Handle: "nmn-powder", Price: 45.004, CostPerGram: 0.45004, EffectiveCost: 0.45004, ImageURL: "https://cdn.example/nmn.jpg"},
+		{Vendor: "Shop", Name: "NMN Powder", Handle: "nmn-powder", Price: 80, CostPerGram: 0.5, EffectiveCost: 0.5},
+		{Vendor: "Pages", Name: "TMG Blend", Handle: "https://pages.example/tmg-blend", Price: 10, EffectiveCost: 0.6, NeedsReview: true},
+		{Vendor: "Pages", Name: "Trimethylglycine", Handle: "https://pages.example/tmg", Price: 20, CostPerGram: 0.08, EffectiveCost: 0.8},
+		{Vendor: "Pages", Name: "NMN Capsules", Handle: "https://pages.example/nmn-caps", Price: 60, CostPerGram: 1.2, EffectiveCost: 1},
+		{Vendor: "Shop", Name: "Liposomal NMN", Handle: "lipo-nmn", Price: 90, CostPerGram: 3, EffectiveCost: 2},
+	}
+
+	got := Build(report, vendors, taxonomy.Defaults(), "2026-01-02", 2)
+	want := Widget{Date: "2026-01-02", Top: map[string][]Entry{
+		"nmn": {
+			{Name: "NMN Powder", Vendor: "Shop", Price: 45, CostPerGram: 0.45, EffectiveCost: 0.45,
+				URL: "https://shop.example/products/nmn-powder", ImageURL: "https://cdn.example/nmn.jpg"},
+			{Name: "NMN Capsules", Vendor: "Pages", Price: 60, CostPerGram: 1.2, EffectiveCost: 1,
+				URL: "https://pages.example/nmn-caps"},
+		},
+		"nad":         {},
+		"tmg":         {{Name: "Trimethylglycine", Vendor: "Pages", Price: 20, CostPerGram: 0.08, EffectiveCost: 0.8, URL: "https://pages.example/tmg"}},
+		"resveratrol": {},
+		"creatine":    {},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Build() =\n%+v\nwant\n%+v", got, want)
+	}
+
+	// A supplement added to the registry gets its own section
+	reg := append(taxonomy.Defaults(), taxonomy.Supplement{Name: "spermidine"})
+	report = append(report, models.Analysis{Vendor: "Shop", Name: "Spermidine Capsules", Handle: "spermidine", Price: 30, CostPerGram: 3, EffectiveCost: 3})
+	if got := Build(report, vendors, reg, "2026-01-02", 2).Top["spermidine"]; len(got) != 1 || got[0].Name != "Spermidine Capsules" {
+		t.Errorf("spermidine section = %+v, want the spermidine product", got)
+	}
+}
+
+func TestMarshalSizeLimit(t *testing.T) {
+	var report []models.Analysis
+	for i := 0; i < 20; i++ {
+		report = append(report, models.Analysis{
+			Vendor: "Shop", Name: strings.Repeat("Creatine Monohydrate ", 5), Handle: "creatine-" + string(rune('a'+i)),
+			Price: 20, EffectiveCost: float64(i),
+		})
+	}
+	w := Build(report, []models.Vendor{{Name: "Shop", URL: "https://shop.example/products.json"}}, taxonomy.Defaults(), "2026-01-02", 50)
+	if n := len(w.Top["creatine"]); n != MaxTop {
+		t.Fatalf("entries = %d, want MaxTop (%d)", n, MaxTop)
+	}
+	if name := w.Top["creatine"][0].Name; len([]rune(name)) != maxNameLen || !strings.HasSuffix(name, "…") {
+		t.Errorf("name %q not truncated to %d runes", name, maxNameLen)
+	}
+
+	data, err := Marshal(w, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) > 1024 {
+		t.Errorf("encoded %d bytes, over the 1024 limit", len(data))
+	}
+	var decoded Widget
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(decoded.Top["creatine"]); n == 0 || n >= MaxTop {
+		t.Errorf("kept %d entries, want some dropped but not all", n)
+	}
+	if decoded.Top["creatine"][0].EffectiveCost != 0 {
+		t.Error("the cheapest entry was dropped first")
+	}
+
+	if _, err := Marshal(w, 10); err == nil {
+		t.Error("Marshal() under an impossible limit: want an error")
+	}
+}
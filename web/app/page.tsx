@@ -1,6 +1,7 @@
-import { loadReport } from "@/lib/data";
+import { loadReport, loadVendorSummary } from "@/lib/data";
 import type { AnalysisWithVendorInfo } from "@/components/ProductTable";
 import ProductTable from "@/components/ProductTable";
+import VendorCards from "@/components/VendorCards";
 import vendors from "@/lib/vendors";
 
 export const dynamic = "force-static";
@@ -9,6 +10,7 @@ export default function Home() {
   // loadReport() reads data/analysis_report.json and maps snake_case → camelCase.
   // No parsing, regex, or math — the Go backend did all of that.
   const report = loadReport();
+  const vendorSummary = loadVendorSummary();
 
   // Build a lookup map from vendor name to VendorInfo for affiliate links
   const vendorMap = new Map(vendors.map((v) => [v.name, v]));
@@ -99,6 +101,11 @@ export default function Home() {
         <ProductTable analyses={enriched} />
       </section>
 
+      {/* Vendor Cards */}
+      <section className="mx-auto max-w-7xl px-4 pb-10 sm:px-6 lg:px-8">
+        <VendorCards vendors={vendorSummary} />
+      </section>
+
       {/* FAQ */}
       <section className="border-t border-zinc-800 bg-zinc-900/30">
         <div className="mx-auto max-w-3xl px-4 py-12 sm:px-6 lg:px-8">
@@ -0,0 +1,22 @@
+interface CertificationBadgesProps {
+  certifications: string[];
+}
+
+/** Third-party testing marks (NSF, Informed Sport, ConsumerLab) from vendor_rules.json. */
+export default function CertificationBadges({ certifications }: CertificationBadgesProps) {
+  if (certifications.length === 0) return null;
+
+  return (
+    <span className="mt-1 flex flex-wrap gap-1">
+      {certifications.map((c) => (
+        <span
+          key={c}
+          className="rounded bg-sky-500/10 px-1.5 py-0.5 text-[10px] font-medium text-sky-400"
+          title="Third-party tested"
+        >
+          ✓ {c}
+        </span>
+      ))}
+    </span>
+  );
+}
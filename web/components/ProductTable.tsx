@@ -1,11 +1,12 @@
 "use client";
 
-import { useState, useMemo } from "react";
+import { Fragment, useState, useMemo } from "react";
 import type { Analysis } from "@/lib/types";
 import type { VendorInfo } from "@/lib/vendors";
 import { buildProductUrl } from "@/lib/vendors";
 import RankBadge from "./RankBadge";
 import TypeBadge from "./TypeBadge";
+import CertificationBadges from "./CertificationBadges";
 import SupplementFilter, {
   type SupplementFilter as FilterValue,
 } from "./SupplementFilter";
@@ -15,7 +16,7 @@ interface AnalysisWithVendorInfo extends Analysis {
 }
 
 interface ProductTableProps {
-  /** All analyses pre-sorted by effectiveCost ascending, with vendorInfo attached */
+  /** All analyses pre-sorted by rankScore ascending, with vendorInfo attached */
   analyses: AnalysisWithVendorInfo[];
 }
 
@@ -28,10 +29,18 @@ const FILTER_KEYWORDS: Record<string, string[]> = {
   creatine: ["creatine"],
 };
 
+/** Minimum active mg per capsule/tablet; powders and liquids always pass. */
+const CAPSULE_MG_OPTIONS = [0, 250, 500] as const;
+
 function formatCurrency(value: number): string {
   return `$${value.toFixed(2)}`;
 }
 
+/** Checkout price in the vendor's own currency, e.g. "€40.00". */
+function formatNative(value: number, currency: string): string {
+  return new Intl.NumberFormat("en-US", { style: "currency", currency }).format(value);
+}
+
 function formatGrams(value: number): string {
   if (value >= 1) {
     return `${value.toFixed(1)}g`;
@@ -49,6 +58,51 @@ function formatCostPerGram(value: number): string {
   return `$${value.toFixed(2)}/g`;
 }
 
+/** "Creatine HCl · 78% active" — the labeled salt/ester and its active share. */
+function formatActiveForm(item: Analysis): string {
+  if (item.activeFraction <= 0) return item.activeForm;
+  return `${item.activeForm} · ${Math.round(item.activeFraction * 100)}% active`;
+}
+
+const SUPPLEMENT_NAMES: Record<string, string> = {
+  nmn: "NMN",
+  nad: "NAD+",
+  tmg: "TMG",
+  resveratrol: "resveratrol",
+  creatine: "creatine",
+};
+
+/** "1.8× the cheapest NMN · p87" — cost ratio and percentile within the supplement (100 = cheapest). */
+function formatSpread(item: Analysis): string {
+  const name = SUPPLEMENT_NAMES[item.supplement] ?? item.supplement;
+  const ratio = item.costRatio.toFixed(1);
+  const lead = ratio === "1.0" ? `cheapest ${name}` : `${ratio}× the cheapest ${name}`;
+  return `${lead} · p${item.costPercentile.toFixed(0)}`;
+}
+
+/** "Q-adj $0.04/g · Labdoor 72" — effective cost divided by the 0–100 quality score. */
+function formatQualityAdjusted(item: Analysis): string {
+  const source = item.qualitySource ? `${item.qualitySource} ` : "";
+  return `Q-adj ${formatCostPerGram(item.qualityAdjustedCost)} · ${source}${item.qualityScore.toFixed(0)}`;
+}
+
+/** "500 mg" — active per capsule/tablet; "—" for powders and liquids. */
+function formatUnitMg(value: number): string {
+  if (value <= 0) return "—";
+  return `${value.toFixed(0)} mg`;
+}
+
+/** "333 servings · 1.5g scoop" — powders that state their scoop size. */
+function formatServings(item: Analysis): string {
+  return `${item.servingsPerContainer} servings · ${formatGrams(item.scoopMg / 1000)} scoop`;
+}
+
+/** "$1.50/day (3 caps)" — capsule products show the whole units per day. */
+function formatCostPerDay(item: Analysis): string {
+  const perDay = `$${item.costPerDay.toFixed(2)}/day`;
+  return item.unitsPerDay > 0 ? `${perDay} (${item.unitsPerDay} caps)` : perDay;
+}
+
 function ProductImage({ src, alt }: { src: string; alt: string }) {
   if (!src) {
     return (
@@ -69,28 +123,160 @@ function ProductImage({ src, alt }: { src: string; alt: string }) {
   );
 }
 
+/** Marks entries on their supplement's cost-vs-trust Pareto front. */
+function ParetoBadge() {
+  return (
+    <span
+      className="mt-1 inline-block rounded bg-violet-500/10 px-1.5 py-0.5 text-[10px] font-medium text-violet-400"
+      title="Nothing is both cheaper and better trusted"
+    >
+      ◆ Pareto
+    </span>
+  );
+}
+
+/** Recent listed prices as a small line; lowest price at the bottom. */
+function Sparkline({ prices }: { prices: number[] }) {
+  if (prices.length < 2) return null;
+  const low = Math.min(...prices);
+  const high = Math.max(...prices);
+  const width = 64;
+  const height = 16;
+  const points = prices
+    .map((p, i) => {
+      const x = (i / (prices.length - 1)) * width;
+      const y = high > low ? height - ((p - low) / (high - low)) * height : height / 2;
+      return `${x.toFixed(1)},${y.toFixed(1)}`;
+    })
+    .join(" ");
+  return (
+    <svg
+      width={width}
+      height={height}
+      viewBox={`0 0 ${width} ${height}`}
+      className="mt-1 ml-auto block text-zinc-500"
+      role="img"
+      aria-label={`${formatCurrency(low)}–${formatCurrency(high)} over ${prices.length} days`}
+    >
+      <title>{`${formatCurrency(low)}–${formatCurrency(high)} over ${prices.length} days`}</title>
+      <polyline points={points} fill="none" stroke="currentColor" strokeWidth={1.25} />
+    </svg>
+  );
+}
+
+function CautionBadge({ reason }: { reason: string }) {
+  return (
+    <span
+      className="mt-1 ml-1 inline-block rounded bg-amber-500/10 px-1.5 py-0.5 text-[10px] font-medium text-amber-400"
+      title={`${reason}. Flavoring adds weight, so the active amount is less certain.`}
+    >
+      ⚠ Flavored
+    </span>
+  );
+}
+
+function BundleBadge({ item }: { item: Analysis }) {
+  if (item.bundleDearer) {
+    return (
+      <span
+        className="mt-1 ml-1 inline-block rounded bg-red-500/10 px-1.5 py-0.5 text-[10px] font-medium text-red-400"
+        title={`This ${item.packSize}-pack costs ${Math.abs(item.bundleSavingPct).toFixed(1)}% more per gram than buying singles (${formatCurrency(-item.bundleSaving)} more per unit).`}
+      >
+        Bulk costs more
+      </span>
+    );
+  }
+  if (item.bundleSaving <= 0) {
+    return null;
+  }
+  return (
+    <span
+      className="mt-1 ml-1 inline-block rounded bg-emerald-500/10 px-1.5 py-0.5 text-[10px] font-medium text-emerald-400"
+      title={`${formatCurrency(item.bundleSaving)} less per unit than buying ${item.packSize} singles.`}
+    >
+      Saves {item.bundleSavingPct.toFixed(0)}% vs singles
+    </span>
+  );
+}
+
+/** Explanations of the subscription risk tags vendor_rules.json can set. */
+const SUBSCRIPTION_RISKS: Record<string, string> = {
+  "hard-to-cancel": "cancelling takes a call, an email or a chat",
+  "renewal-price-up": "renewals are billed above the advertised subscription price",
+};
+
+function SubscriptionRiskBadge({ risks }: { risks: string[] }) {
+  const reasons = risks.map((r) => SUBSCRIPTION_RISKS[r] ?? r);
+  return (
+    <span
+      className="mt-1 ml-1 inline-block rounded bg-amber-500/10 px-1.5 py-0.5 text-[10px] font-medium text-amber-400"
+      title={`This vendor's subscriptions are known to be a trap: ${reasons.join("; ")}.`}
+    >
+      ⚠ Subscription risk
+    </span>
+  );
+}
+
+function DelistedBadge({ since }: { since: string }) {
+  return (
+    <span
+      className="mt-1 ml-1 inline-block rounded bg-zinc-500/10 px-1.5 py-0.5 text-[10px] font-medium text-zinc-400"
+      title={`Missing from the vendor's listing since ${since}. Shown at its last scraped price until the grace period ends.`}
+    >
+      Possibly delisted
+    </span>
+  );
+}
+
+function UnavailableBadge() {
+  return (
+    <span
+      className="mt-1 ml-1 inline-block rounded bg-zinc-500/10 px-1.5 py-0.5 text-[10px] font-medium text-zinc-400"
+      title="Out of stock at the vendor. Shown so you know what a good price looks like; watch it for a back-in-stock alert."
+    >
+      Out of stock
+    </span>
+  );
+}
+
+const FOLD_NOTE = "Below the fold: flagged for review, low-confidence parse or out of stock. Check before buying.";
+
+/** Mirrors parser.BelowFold: needs review, out of stock, or confidence under the caution level (0.5). */
+function isBelowFold(analysis: Analysis): boolean {
+  return analysis.needsReview || analysis.unavailable || analysis.confidence < 0.5;
+}
+
 function matchesFilter(analysis: AnalysisWithVendorInfo, filter: FilterValue): boolean {
   const keywords = FILTER_KEYWORDS[filter] ?? [];
   const searchStr = (analysis.name + " " + analysis.handle + " " + analysis.vendor).toLowerCase();
   return keywords.some((kw) => searchStr.includes(kw));
 }
 
+/** Mirrors -min-capsule-mg: drops capsules/tablets under minMg, keeps unitless entries. */
+function meetsCapsuleMg(analysis: Analysis, minMg: number): boolean {
+  return analysis.unitMg === 0 || analysis.unitMg >= minMg;
+}
+
 export default function ProductTable({ analyses }: ProductTableProps) {
   const [filter, setFilter] = useState<FilterValue>("nmn");
-  const [sortBy, setSortBy] = useState<"effectiveCost" | "costPerGram" | "price">("effectiveCost");
+  // rankScore is the report's own order (effective cost unless rankWeights is configured)
+  const [sortBy, setSortBy] = useState<"rankScore" | "effectiveCost" | "costPerGram" | "price">("rankScore");
   const [sortAsc, setSortAsc] = useState(true);
+  const [minCapsuleMg, setMinCapsuleMg] = useState<number>(0);
 
   const filtered = useMemo(() => {
-    const items = analyses.filter((a) => matchesFilter(a, filter));
+    const items = analyses.filter((a) => matchesFilter(a, filter) && meetsCapsuleMg(a, minCapsuleMg));
 
     items.sort((a, b) => {
+      // Flagged/low-confidence entries stay below the fold whatever the sort
+      if (isBelowFold(a) !== isBelowFold(b)) return isBelowFold(a) ? 1 : -1;
       const va = a[sortBy];
       const vb = b[sortBy];
       return sortAsc ? va - vb : vb - va;
     });
 
     return items;
-  }, [analyses, filter, sortBy, sortAsc]);
+  }, [analyses, filter, minCapsuleMg, sortBy, sortAsc]);
 
   function handleSort(column: "effectiveCost" | "costPerGram" | "price") {
     if (sortBy === column) {
@@ -106,16 +292,33 @@ export default function ProductTable({ analyses }: ProductTableProps) {
     return <span className="ml-1 text-emerald-400">{sortAsc ? "↑" : "↓"}</span>;
   }
 
-  const bestEffectiveCost = filtered.length > 0 ? filtered[0].effectiveCost : 0;
+  // Highlight the top row(s) while the table is in ranking order
+  const ranking = sortAsc && (sortBy === "rankScore" || sortBy === "effectiveCost");
+  const bestScore = filtered.length > 0 ? filtered[0][sortBy] : 0;
+  const foldIndex = filtered.findIndex(isBelowFold);
 
   return (
     <div className="w-full">
       {/* Filter bar */}
       <div className="mb-6 flex flex-col gap-4 sm:flex-row sm:items-center sm:justify-between">
         <SupplementFilter active={filter} onChange={setFilter} />
-        <p className="text-sm text-zinc-500">
-          {filtered.length} product{filtered.length !== 1 ? "s" : ""} found
-        </p>
+        <div className="flex items-center gap-4">
+          <select
+            value={minCapsuleMg}
+            onChange={(e) => setMinCapsuleMg(Number(e.target.value))}
+            className="rounded-lg bg-zinc-800 px-3 py-1.5 text-sm text-zinc-300 cursor-pointer"
+            aria-label="Minimum mg per capsule"
+          >
+            {CAPSULE_MG_OPTIONS.map((mg) => (
+              <option key={mg} value={mg}>
+                {mg === 0 ? "Any capsule size" : `≥ ${mg} mg/cap`}
+              </option>
+            ))}
+          </select>
+          <p className="text-sm text-zinc-500">
+            {filtered.length} product{filtered.length !== 1 ? "s" : ""} found
+          </p>
+        </div>
       </div>
 
       {filtered.length === 0 && (
@@ -136,6 +339,7 @@ export default function ProductTable({ analyses }: ProductTableProps) {
                 <th className="px-4 py-3">Vendor</th>
                 <th className="px-4 py-3">Product</th>
                 <th className="px-4 py-3 w-24">Type</th>
+                <th className="px-4 py-3 w-20 text-right">mg/Cap</th>
                 <th
                   className="px-4 py-3 w-24 cursor-pointer select-none hover:text-zinc-300 text-right"
                   onClick={() => handleSort("price")}
@@ -178,71 +382,138 @@ export default function ProductTable({ analyses }: ProductTableProps) {
             <tbody>
               {filtered.map((item, idx) => {
                 const rank = idx + 1;
-                const isBest = item.effectiveCost === bestEffectiveCost && sortBy === "effectiveCost" && sortAsc;
+                const isBest = ranking && item[sortBy] === bestScore;
 
                 return (
-                  <tr
-                    key={`${item.vendor}-${item.handle}-${idx}`}
-                    className={`table-row-hover border-b border-zinc-800/50 ${
-                      isBest ? "bg-emerald-950/20" : ""
-                    }`}
-                  >
-                    <td className="px-4 py-3">
-                      <RankBadge rank={rank} />
-                    </td>
-                    <td className="px-4 py-3">
-                      <ProductImage src={item.imageURL} alt={item.name} />
-                    </td>
-                    <td className="px-4 py-3">
-                      <span className="font-medium text-zinc-300">{item.vendor}</span>
-                    </td>
-                    <td className="px-4 py-3">
-                      <span className="text-zinc-200 line-clamp-2" title={item.name}>
-                        {item.name}
-                      </span>
-                    </td>
-                    <td className="px-4 py-3">
-                      <TypeBadge type={item.type} />
-                    </td>
-                    <td className="px-4 py-3 text-right font-mono text-zinc-300">
-                      {formatCurrency(item.price)}
-                    </td>
-                    <td className="px-4 py-3 text-right font-mono text-zinc-400">
-                      {formatGrams(item.activeGrams)}
-                    </td>
-                    <td className="px-4 py-3 text-right font-mono text-zinc-500">
-                      {formatGrossGrams(item.grossGrams)}
-                    </td>
-                    <td className="px-4 py-3 text-right font-mono text-zinc-400">
-                      {formatCostPerGram(item.costPerGram)}
-                    </td>
-                    <td className="px-4 py-3 text-right">
-                      <span
-                        className={`font-mono font-semibold ${
-                          isBest
-                            ? "best-price text-emerald-400"
-                            : "text-zinc-200"
-                        }`}
-                      >
-                        {formatCostPerGram(item.effectiveCost)}
-                      </span>
-                      {item.multiplier > 1 && item.multiplierLabel && (
-                        <span className="block text-[10px] text-zinc-500 mt-0.5">
-                          ({item.multiplier}x {item.multiplierLabel})
+                  <Fragment key={`${item.vendor}-${item.handle}-${idx}`}>
+                    {idx === foldIndex && (
+                      <tr className="border-b border-zinc-800/50">
+                        <td colSpan={12} className="px-4 py-2 text-xs text-amber-500/80">
+                          {FOLD_NOTE}
+                        </td>
+                      </tr>
+                    )}
+                    <tr
+                      className={`table-row-hover border-b border-zinc-800/50 ${
+                        isBest ? "bg-emerald-950/20" : ""
+                      }`}
+                    >
+                      <td className="px-4 py-3">
+                        <RankBadge rank={rank} change={item.rankChange} />
+                      </td>
+                      <td className="px-4 py-3">
+                        <ProductImage src={item.imageURL} alt={item.name} />
+                      </td>
+                      <td className="px-4 py-3">
+                        <span className="font-medium text-zinc-300">{item.brand || item.vendor}</span>
+                        {item.brand && (
+                          <span className="block text-[10px] text-zinc-500 mt-0.5">via {item.vendor}</span>
+                        )}
+                      </td>
+                      <td className="px-4 py-3">
+                        <span className="text-zinc-200 line-clamp-2" title={item.name}>
+                          {item.name}
                         </span>
-                      )}
-                    </td>
-                    <td className="px-4 py-3 text-right">
-                      <a
-                        href={buildProductUrl(item.vendorInfo, item.handle)}
-                        target="_blank"
-                        rel="noopener noreferrer"
-                        className="inline-flex items-center rounded-lg bg-emerald-600/20 px-3 py-1.5 text-xs font-semibold text-emerald-400 transition-all hover:bg-emerald-600/30 hover:text-emerald-300"
-                      >
-                        Buy
-                      </a>
-                    </td>
-                  </tr>
+                        <CertificationBadges certifications={item.certifications} />
+                        {item.paretoOptimal && <ParetoBadge />}
+                        {item.caution && <CautionBadge reason={item.caution} />}
+                        {item.possiblyDelisted && <DelistedBadge since={item.missingSince} />}
+                        {item.unavailable && <UnavailableBadge />}
+                        {item.packSize > 0 && <BundleBadge item={item} />}
+                        {item.subscriptionRisks.length > 0 && <SubscriptionRiskBadge risks={item.subscriptionRisks} />}
+                      </td>
+                      <td className="px-4 py-3">
+                        <TypeBadge type={item.type} />
+                      </td>
+                      <td className="px-4 py-3 text-right font-mono text-zinc-400">
+                        {formatUnitMg(item.unitMg)}
+                      </td>
+                      <td className="px-4 py-3 text-right font-mono text-zinc-300">
+                        {formatCurrency(item.price)}
+                        {item.minOrderQty > 1 && (
+                          <span className="block text-[10px] text-zinc-500 mt-0.5">
+                            min {item.minOrderQty}× = {formatCurrency(item.entryPrice)}
+                          </span>
+                        )}
+                        {item.nativeCurrency && (
+                          <span className="block text-[10px] text-zinc-500 mt-0.5">
+                            {formatNative(item.nativePrice, item.nativeCurrency)} at checkout
+                          </span>
+                        )}
+                        {item.shippingCost > 0 && (
+                          <span className="block text-[10px] text-zinc-500 mt-0.5">
+                            + {formatCurrency(item.shippingCost)} shipping
+                          </span>
+                        )}
+                        {item.subscriptionOptions.map((o) => (
+                          <span key={o.intervalDays} className="block text-[10px] text-zinc-500 mt-0.5">
+                            every {o.intervalDays}d {formatCurrency(o.price)} ({formatCurrency(o.annualCost)}/yr)
+                          </span>
+                        ))}
+                        <Sparkline prices={item.recentPrices} />
+                      </td>
+                      <td className="px-4 py-3 text-right font-mono text-zinc-400">
+                        {formatGrams(item.activeGrams)}
+                        {item.activeForm && (
+                          <span className="block text-[10px] text-zinc-500 mt-0.5">
+                            {formatActiveForm(item)}
+                          </span>
+                        )}
+                      </td>
+                      <td className="px-4 py-3 text-right font-mono text-zinc-500">
+                        {formatGrossGrams(item.grossGrams)}
+                        {item.servingsPerContainer > 0 && (
+                          <span className="block text-[10px] text-zinc-500 mt-0.5">
+                            {formatServings(item)}
+                          </span>
+                        )}
+                      </td>
+                      <td className="px-4 py-3 text-right font-mono text-zinc-400">
+                        {formatCostPerGram(item.costPerGram)}
+                      </td>
+                      <td className="px-4 py-3 text-right">
+                        <span
+                          className={`font-mono font-semibold ${
+                            isBest
+                              ? "best-price text-emerald-400"
+                              : "text-zinc-200"
+                          }`}
+                        >
+                          {formatCostPerGram(item.effectiveCost)}
+                        </span>
+                        {item.multiplier > 1 && item.multiplierLabel && (
+                          <span className="block text-[10px] text-zinc-500 mt-0.5">
+                            ({item.multiplier}x {item.multiplierLabel})
+                          </span>
+                        )}
+                        {item.costPerDay > 0 && (
+                          <span className="block text-[10px] text-zinc-500 mt-0.5">
+                            {formatCostPerDay(item)}
+                          </span>
+                        )}
+                        {item.qualityScore > 0 && (
+                          <span className="block text-[10px] text-zinc-500 mt-0.5">
+                            {formatQualityAdjusted(item)}
+                          </span>
+                        )}
+                        {item.supplement && (
+                          <span className="block text-[10px] text-zinc-500 mt-0.5">
+                            {formatSpread(item)}
+                          </span>
+                        )}
+                      </td>
+                      <td className="px-4 py-3 text-right">
+                        <a
+                          href={buildProductUrl(item.vendorInfo, item.handle)}
+                          target="_blank"
+                          rel="noopener noreferrer"
+                          className="inline-flex items-center rounded-lg bg-emerald-600/20 px-3 py-1.5 text-xs font-semibold text-emerald-400 transition-all hover:bg-emerald-600/30 hover:text-emerald-300"
+                        >
+                          Buy
+                        </a>
+                      </td>
+                    </tr>
+                  </Fragment>
                 );
               })}
             </tbody>
@@ -255,88 +526,146 @@ export default function ProductTable({ analyses }: ProductTableProps) {
         <div className="md:hidden flex flex-col gap-3">
           {filtered.map((item, idx) => {
             const rank = idx + 1;
-            const isBest = item.effectiveCost === bestEffectiveCost && sortBy === "effectiveCost" && sortAsc;
+            const isBest = ranking && item[sortBy] === bestScore;
 
             return (
-              <div
-                key={`mobile-${item.vendor}-${item.handle}-${idx}`}
-                className={`card-shine rounded-xl border p-4 ${
-                  isBest
-                    ? "border-emerald-700/50 bg-emerald-950/20"
-                    : "border-zinc-800 bg-zinc-900/50"
-                }`}
-              >
-                <div className="flex items-start gap-3">
-                  {/* Rank + Image */}
-                  <div className="flex flex-col items-center gap-2">
-                    <RankBadge rank={rank} />
-                    <ProductImage src={item.imageURL} alt={item.name} />
-                  </div>
-
-                  {/* Content */}
-                  <div className="flex-1 min-w-0">
-                    <div className="flex items-center gap-2 flex-wrap">
-                      <span className="text-xs font-medium text-zinc-500">{item.vendor}</span>
-                      <TypeBadge type={item.type} />
+              <Fragment key={`mobile-${item.vendor}-${item.handle}-${idx}`}>
+                {idx === foldIndex && (
+                  <p className="px-1 pt-2 text-xs text-amber-500/80">{FOLD_NOTE}</p>
+                )}
+                <div
+                  className={`card-shine rounded-xl border p-4 ${
+                    isBest
+                      ? "border-emerald-700/50 bg-emerald-950/20"
+                      : "border-zinc-800 bg-zinc-900/50"
+                  }`}
+                >
+                  <div className="flex items-start gap-3">
+                    {/* Rank + Image */}
+                    <div className="flex flex-col items-center gap-2">
+                      <RankBadge rank={rank} change={item.rankChange} />
+                      <ProductImage src={item.imageURL} alt={item.name} />
                     </div>
-                    <p className="mt-1 text-sm font-medium text-zinc-200 line-clamp-2">
-                      {item.name}
-                    </p>
-
-                    {/* Stats row */}
-                    <div className="mt-3 grid grid-cols-2 gap-x-4 gap-y-1 text-xs">
-                      <div>
-                        <span className="text-zinc-500">Price</span>
-                        <p className="font-mono font-medium text-zinc-300">
-                          {formatCurrency(item.price)}
-                        </p>
+
+                    {/* Content */}
+                    <div className="flex-1 min-w-0">
+                      <div className="flex items-center gap-2 flex-wrap">
+                        <span className="text-xs font-medium text-zinc-500">
+                          {item.brand ? `${item.brand} via ${item.vendor}` : item.vendor}
+                        </span>
+                        <TypeBadge type={item.type} />
                       </div>
-                      <div>
-                        <span className="text-zinc-500">Active</span>
-                        <p className="font-mono font-medium text-zinc-400">
-                          {formatGrams(item.activeGrams)}
-                        </p>
-                        {item.grossGrams > 0 && (
-                          <p className="text-[10px] text-zinc-500 mt-0.5">
-                            Gross: {formatGrams(item.grossGrams)}
+                      <p className="mt-1 text-sm font-medium text-zinc-200 line-clamp-2">
+                        {item.name}
+                      </p>
+                      <CertificationBadges certifications={item.certifications} />
+                      {item.paretoOptimal && <ParetoBadge />}
+                      {item.caution && <CautionBadge reason={item.caution} />}
+                      {item.possiblyDelisted && <DelistedBadge since={item.missingSince} />}
+                      {item.unavailable && <UnavailableBadge />}
+                      {item.packSize > 0 && <BundleBadge item={item} />}
+                      {item.subscriptionRisks.length > 0 && <SubscriptionRiskBadge risks={item.subscriptionRisks} />}
+
+                      {/* Stats row */}
+                      <div className="mt-3 grid grid-cols-2 gap-x-4 gap-y-1 text-xs">
+                        <div>
+                          <span className="text-zinc-500">Price</span>
+                          <p className="font-mono font-medium text-zinc-300">
+                            {formatCurrency(item.price)}
                           </p>
-                        )}
-                      </div>
-                      <div>
-                        <span className="text-zinc-500">$/Gram</span>
-                        <p className="font-mono font-medium text-zinc-400">
-                          {formatCostPerGram(item.costPerGram)}
-                        </p>
-                      </div>
-                      <div>
-                        <span className="text-zinc-500">True Cost</span>
-                        <p
-                          className={`font-mono font-semibold ${
-                            isBest ? "text-emerald-400" : "text-zinc-200"
-                          }`}
-                        >
-                          {formatCostPerGram(item.effectiveCost)}
-                        </p>
-                        {item.multiplier > 1 && item.multiplierLabel && (
-                          <span className="text-[10px] text-zinc-500">
-                            ({item.multiplier}x {item.multiplierLabel})
-                          </span>
-                        )}
+                          {item.minOrderQty > 1 && (
+                            <p className="text-[10px] text-zinc-500 mt-0.5">
+                              min {item.minOrderQty}× = {formatCurrency(item.entryPrice)}
+                            </p>
+                          )}
+                          {item.nativeCurrency && (
+                            <p className="text-[10px] text-zinc-500 mt-0.5">
+                              {formatNative(item.nativePrice, item.nativeCurrency)} at checkout
+                            </p>
+                          )}
+                          {item.shippingCost > 0 && (
+                            <p className="text-[10px] text-zinc-500 mt-0.5">
+                              + {formatCurrency(item.shippingCost)} shipping
+                            </p>
+                          )}
+                        </div>
+                        <div>
+                          <span className="text-zinc-500">Active</span>
+                          <p className="font-mono font-medium text-zinc-400">
+                            {formatGrams(item.activeGrams)}
+                          </p>
+                          {item.activeForm && (
+                            <p className="text-[10px] text-zinc-500 mt-0.5">
+                              {formatActiveForm(item)}
+                            </p>
+                          )}
+                          {item.grossGrams > 0 && (
+                            <p className="text-[10px] text-zinc-500 mt-0.5">
+                              Gross: {formatGrams(item.grossGrams)}
+                            </p>
+                          )}
+                          {item.unitMg > 0 && (
+                            <p className="text-[10px] text-zinc-500 mt-0.5">
+                              {formatUnitMg(item.unitMg)}/cap
+                            </p>
+                          )}
+                          {item.servingsPerContainer > 0 && (
+                            <p className="text-[10px] text-zinc-500 mt-0.5">
+                              {formatServings(item)}
+                            </p>
+                          )}
+                        </div>
+                        <div>
+                          <span className="text-zinc-500">$/Gram</span>
+                          <p className="font-mono font-medium text-zinc-400">
+                            {formatCostPerGram(item.costPerGram)}
+                          </p>
+                        </div>
+                        <div>
+                          <span className="text-zinc-500">True Cost</span>
+                          <p
+                            className={`font-mono font-semibold ${
+                              isBest ? "text-emerald-400" : "text-zinc-200"
+                            }`}
+                          >
+                            {formatCostPerGram(item.effectiveCost)}
+                          </p>
+                          {item.multiplier > 1 && item.multiplierLabel && (
+                            <span className="text-[10px] text-zinc-500">
+                              ({item.multiplier}x {item.multiplierLabel})
+                            </span>
+                          )}
+                          {item.costPerDay > 0 && (
+                            <span className="block text-[10px] text-zinc-500">
+                              {formatCostPerDay(item)}
+                            </span>
+                          )}
+                          {item.qualityScore > 0 && (
+                            <span className="block text-[10px] text-zinc-500">
+                              {formatQualityAdjusted(item)}
+                            </span>
+                          )}
+                          {item.supplement && (
+                            <span className="block text-[10px] text-zinc-500">
+                              {formatSpread(item)}
+                            </span>
+                          )}
+                        </div>
                       </div>
                     </div>
                   </div>
-                </div>
 
-                {/* Buy button */}
-                <a
-                  href={buildProductUrl(item.vendorInfo, item.handle)}
-                  target="_blank"
-                  rel="noopener noreferrer"
-                  className="mt-3 flex w-full items-center justify-center rounded-lg bg-emerald-600/20 py-2 text-sm font-semibold text-emerald-400 transition-all hover:bg-emerald-600/30 hover:text-emerald-300"
-                >
-                  View Deal →
-                </a>
-              </div>
+                  {/* Buy button */}
+                  <a
+                    href={buildProductUrl(item.vendorInfo, item.handle)}
+                    target="_blank"
+                    rel="noopener noreferrer"
+                    className="mt-3 flex w-full items-center justify-center rounded-lg bg-emerald-600/20 py-2 text-sm font-semibold text-emerald-400 transition-all hover:bg-emerald-600/30 hover:text-emerald-300"
+                  >
+                    View Deal →
+                  </a>
+                </div>
+              </Fragment>
             );
           })}
         </div>
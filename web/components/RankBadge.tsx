@@ -1,8 +1,32 @@
 interface RankBadgeProps {
   rank: number;
+  /** Places gained (positive) or lost within the supplement since the previous run. */
+  change?: number;
 }
 
-export default function RankBadge({ rank }: RankBadgeProps) {
+/** "▲3" in green or "▼1" in red under the badge; nothing when unchanged. */
+function RankChange({ change }: { change: number }) {
+  if (change === 0) return null;
+  return (
+    <span
+      className={`block text-center text-[10px] font-medium ${change > 0 ? "text-emerald-400" : "text-red-400"}`}
+      title={`${change > 0 ? "Up" : "Down"} ${Math.abs(change)} since the last update`}
+    >
+      {change > 0 ? "▲" : "▼"}
+      {Math.abs(change)}
+    </span>
+  );
+}
+
+export default function RankBadge({ rank, change = 0 }: RankBadgeProps) {
+  if (change !== 0) {
+    return (
+      <span className="inline-flex flex-col items-center">
+        <RankBadge rank={rank} />
+        <RankChange change={change} />
+      </span>
+    );
+  }
   if (rank <= 3) {
     const className =
       rank === 1 ? "rank-1" : rank === 2 ? "rank-2" : "rank-3";
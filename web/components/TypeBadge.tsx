@@ -7,6 +7,7 @@ const TYPE_CONFIG: Record<string, { className: string; label: string }> = {
   Powder: { className: "badge-powder", label: "Powder" },
   Tablets: { className: "badge-tablets", label: "Tablets" },
   Gel: { className: "badge-gel", label: "Gel" },
+  Liquid: { className: "badge-liquid", label: "Liquid" },
   "Multi-Pack": { className: "badge-multipack", label: "Multi-Pack" },
   "Hybrid Bundle": { className: "badge-hybrid", label: "Hybrid" },
   Single: { className: "badge-single", label: "Single" },
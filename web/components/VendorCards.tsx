@@ -0,0 +1,65 @@
+import type { VendorSummary } from "@/lib/types";
+import { SUPPLEMENT_OPTIONS } from "./SupplementFilter";
+
+interface VendorCardsProps {
+  vendors: VendorSummary[];
+}
+
+/** Label of a supplement section key, e.g. "nad" → "NAD+". */
+function supplementLabel(key: string): string {
+  return SUPPLEMENT_OPTIONS.find((opt) => opt.value === key)?.label ?? key.toUpperCase();
+}
+
+/** "2026-01-03T08:00:00Z" → "Jan 3"; "never" when the vendor was never scraped live. */
+function formatScraped(value: string): string {
+  if (!value) return "never";
+  return new Date(value).toLocaleDateString("en-US", { month: "short", day: "numeric", timeZone: "UTC" });
+}
+
+/** Score colour: green from 80, amber from 50, red below. */
+function qualityClass(score: number): string {
+  if (score >= 80) return "text-emerald-400";
+  if (score >= 50) return "text-amber-400";
+  return "text-red-400";
+}
+
+export default function VendorCards({ vendors }: VendorCardsProps) {
+  const shown = vendors.filter((v) => v.products > 0);
+  if (shown.length === 0) return null;
+
+  return (
+    <div>
+      <h2 className="mb-4 text-xl font-bold text-zinc-200">Vendors</h2>
+      <div className="grid gap-4 sm:grid-cols-2 lg:grid-cols-3">
+        {shown.map((v) => (
+          <div key={v.vendor} className="rounded-xl border border-zinc-800 bg-zinc-900/50 p-5">
+            <div className="flex items-baseline justify-between gap-2">
+              <h3 className="text-sm font-semibold text-zinc-200">{v.vendor}</h3>
+              <span className={`text-xs font-semibold ${qualityClass(v.qualityScore)}`} title="Data quality score">
+                {v.qualityScore.toFixed(0)}/100
+              </span>
+            </div>
+            <p className="mt-1 text-xs text-zinc-500">
+              {v.products} product{v.products === 1 ? "" : "s"}
+              {v.avgCostPerGram > 0 && <> &middot; avg ${v.avgCostPerGram.toFixed(2)}/g</>}
+              {" "}&middot; scraped {formatScraped(v.lastScraped)}
+              {v.status === "failed" && <span className="text-red-400"> (last run failed)</span>}
+            </p>
+            {v.cheapest.length > 0 && (
+              <ul className="mt-3 space-y-1 text-xs">
+                {v.cheapest.map((c) => (
+                  <li key={c.supplement} className="flex justify-between gap-2">
+                    <span className="truncate text-zinc-400" title={c.name}>
+                      <span className="font-medium text-zinc-300">{supplementLabel(c.supplement)}</span> {c.name}
+                    </span>
+                    <span className="shrink-0 font-mono text-emerald-400">${c.effectiveCost.toFixed(2)}/g</span>
+                  </li>
+                ))}
+              </ul>
+            )}
+          </div>
+        ))}
+      </div>
+    </div>
+  );
+}
@@ -1,8 +1,11 @@
 /**
  * Data loader — reads the pre-computed analysis report from the Go backend.
  *
- * The ONLY file this module touches is data/analysis_report.json, which is
- * the sole integration point between the Go scraper and the Next.js frontend.
+ * The main file this module touches is data/analysis_report.json, which is
+ * the integration point between the Go scraper and the Next.js frontend,
+ * or its extended variant (data/analysis_report_extended.json, written by
+ * `-extended`) when present: the same entries plus recent_prices. The vendor
+ * cards read data/vendor_summary.json, a per-vendor digest of the same run.
  *
  * Snake_case JSON fields from the Go output are mapped to camelCase here.
  * Everything downstream of this module uses clean camelCase Analysis objects.
@@ -13,14 +16,19 @@
 import fs from "fs";
 import path from "path";
 
-import type { Analysis } from "./types";
+import type { Analysis, VendorSummary } from "./types";
 
 /** Raw shape of each entry in analysis_report.json (Go JSON tags are snake_case). */
 interface RawReportEntry {
   vendor: string;
+  brand?: string;
   name: string;
   handle: string;
+  variant?: string;
   price: number;
+  price_source?: string;
+  possibly_delisted?: boolean;
+  missing_since?: string;
   active_grams: number;
   gross_grams: number;
   cost_per_gram: number;
@@ -30,8 +38,71 @@ interface RawReportEntry {
   type: string;
   image_url: string;
   is_subscription: boolean;
+  unavailable?: boolean;
   needs_review: boolean;
   review_reason?: string;
+  review_detail?: string;
+  caution?: string;
+  confidence: number;
+  compare_at_price?: number;
+  discount_pct?: number;
+  perpetual_sale?: boolean;
+  min_order_qty?: number;
+  entry_price?: number;
+  native_price?: number;
+  native_currency?: string;
+  cost_per_day?: number;
+  units_per_day?: number;
+  unit_mg?: number;
+  scoop_mg?: number;
+  servings_per_container?: number;
+  active_form?: string;
+  active_fraction?: number;
+  certifications?: string[];
+  quality_multiplier?: number;
+  quality_score?: number;
+  quality_source?: string;
+  quality_adjusted_cost?: number;
+  shipping_cost?: number;
+  rank_score?: number;
+  pareto_optimal?: boolean;
+  supplement?: string;
+  cost_percentile?: number;
+  cost_ratio?: number;
+  supplement_rank?: number;
+  previous_rank?: number;
+  rank_change?: number;
+  pack_size?: number;
+  bundle_saving?: number;
+  bundle_saving_pct?: number;
+  bundle_dearer?: boolean;
+  subscription_options?: {
+    interval_days: number;
+    price: number;
+    annual_cost: number;
+  }[];
+  subscription_risks?: string[];
+  recent_prices?: number[];
+}
+
+/** Raw shape of each vendor in vendor_summary.json. */
+interface RawVendorSummary {
+  vendor: string;
+  status: string;
+  products: number;
+  entries: number;
+  cheapest: {
+    supplement: string;
+    name: string;
+    handle: string;
+    variant?: string;
+    price: number;
+    cost_per_gram: number;
+    effective_cost: number;
+  }[];
+  avg_cost_per_gram: number;
+  quality_score: number;
+  last_scraped?: string;
 }
 
 /** Absolute path to the /data directory at the repo root. */
@@ -44,9 +115,14 @@ const DATA_DIR = path.resolve(process.cwd(), "..", "data");
 function mapEntry(raw: RawReportEntry): Analysis {
   return {
     vendor: raw.vendor,
+    brand: raw.brand ?? "",
     name: raw.name,
     handle: raw.handle,
+    variant: raw.variant ?? "",
     price: raw.price,
+    priceSource: raw.price_source ?? "",
+    possiblyDelisted: raw.possibly_delisted ?? false,
+    missingSince: raw.missing_since ?? "",
     activeGrams: raw.active_grams,
     grossGrams: raw.gross_grams,
     costPerGram: raw.cost_per_gram,
@@ -56,8 +132,51 @@ function mapEntry(raw: RawReportEntry): Analysis {
     type: raw.type,
     imageURL: raw.image_url,
     isSubscription: raw.is_subscription,
+    unavailable: raw.unavailable ?? false,
     needsReview: raw.needs_review,
     reviewReason: raw.review_reason ?? "",
+    reviewDetail: raw.review_detail ?? "",
+    caution: raw.caution ?? "",
+    confidence: raw.confidence,
+    compareAtPrice: raw.compare_at_price ?? 0,
+    discountPct: raw.discount_pct ?? 0,
+    perpetualSale: raw.perpetual_sale ?? false,
+    minOrderQty: raw.min_order_qty ?? 0,
+    entryPrice: raw.entry_price ?? raw.price,
+    nativePrice: raw.native_price ?? 0,
+    nativeCurrency: raw.native_currency ?? "",
+    costPerDay: raw.cost_per_day ?? 0,
+    unitsPerDay: raw.units_per_day ?? 0,
+    unitMg: raw.unit_mg ?? 0,
+    scoopMg: raw.scoop_mg ?? 0,
+    servingsPerContainer: raw.servings_per_container ?? 0,
+    activeForm: raw.active_form ?? "",
+    activeFraction: raw.active_fraction ?? 0,
+    certifications: raw.certifications ?? [],
+    qualityMultiplier: raw.quality_multiplier ?? 0,
+    qualityScore: raw.quality_score ?? 0,
+    qualitySource: raw.quality_source ?? "",
+    qualityAdjustedCost: raw.quality_adjusted_cost ?? 0,
+    shippingCost: raw.shipping_cost ?? 0,
+    rankScore: raw.rank_score ?? raw.effective_cost,
+    paretoOptimal: raw.pareto_optimal ?? false,
+    supplement: raw.supplement ?? "",
+    costPercentile: raw.cost_percentile ?? 0,
+    costRatio: raw.cost_ratio ?? 0,
+    supplementRank: raw.supplement_rank ?? 0,
+    previousRank: raw.previous_rank ?? 0,
+    rankChange: raw.rank_change ?? 0,
+    packSize: raw.pack_size ?? 0,
+    bundleSaving: raw.bundle_saving ?? 0,
+    bundleSavingPct: raw.bundle_saving_pct ?? 0,
+    bundleDearer: raw.bundle_dearer ?? false,
+    subscriptionOptions: (raw.subscription_options ?? []).map((o) => ({
+      intervalDays: o.interval_days,
+      price: o.price,
+      annualCost: o.annual_cost,
+    })),
+    subscriptionRisks: raw.subscription_risks ?? [],
+    recentPrices: raw.recent_prices ?? [],
   };
 }
 
@@ -66,10 +185,12 @@ function mapEntry(raw: RawReportEntry): Analysis {
  * Returns an array of Analysis entries sorted by effectiveCost ascending
  * (the Go backend already sorts, but order is preserved).
  *
+ * Prefers the extended report, which adds recent prices for sparklines.
  * Returns an empty array if the file is missing or malformed.
  */
 export function loadReport(): Analysis[] {
-  const filePath = path.join(DATA_DIR, "analysis_report.json");
+  const extended = path.join(DATA_DIR, "analysis_report_extended.json");
+  const filePath = fs.existsSync(extended) ? extended : path.join(DATA_DIR, "analysis_report.json");
   try {
     const raw = fs.readFileSync(filePath, "utf-8");
     const parsed: unknown = JSON.parse(raw);
@@ -81,4 +202,37 @@ export function loadReport(): Analysis[] {
     // File missing or malformed — return empty so the build doesn't crash
     return [];
   }
-}
\ No newline at end of file
+}
+/**
+ * Load the per-vendor summary (data/vendor_summary.json), vendors by name.
+ * Returns an empty array if the file is missing or malformed.
+ */
+export function loadVendorSummary(): VendorSummary[] {
+  try {
+    const raw = fs.readFileSync(path.join(DATA_DIR, "vendor_summary.json"), "utf-8");
+    const parsed = JSON.parse(raw) as { vendors?: RawVendorSummary[] };
+    if (!Array.isArray(parsed.vendors)) {
+      return [];
+    }
+    return parsed.vendors.map((v) => ({
+      vendor: v.vendor,
+      status: v.status,
+      products: v.products,
+      entries: v.entries,
+      cheapest: (v.cheapest ?? []).map((c) => ({
+        supplement: c.supplement,
+        name: c.name,
+        handle: c.handle,
+        variant: c.variant ?? "",
+        price: c.price,
+        costPerGram: c.cost_per_gram,
+        effectiveCost: c.effective_cost,
+      })),
+      avgCostPerGram: v.avg_cost_per_gram,
+      qualityScore: v.quality_score,
+      lastScraped: v.last_scraped ?? "",
+    }));
+  } catch {
+    return [];
+  }
+}
@@ -1,3 +1,11 @@
+/** Price of one delivery interval on a subscription entry. */
+export interface SubscriptionOption {
+  intervalDays: number;
+  price: number;
+  /** price × 365 / intervalDays */
+  annualCost: number;
+}
+
 /**
  * Analysis row — the component-facing type used by all frontend code.
  *
@@ -10,9 +18,19 @@
  */
 export interface Analysis {
   vendor: string;
+  /** Manufacturer when vendor is a multi-brand retailer (iHerb); empty otherwise. */
+  brand: string;
   name: string;
   handle: string;
+  /** Source variant title, e.g. "Unflavored / 1 KG"; "" if unknown */
+  variant: string;
   price: number;
+  /** "cart" when price is what a simulated cart charges (tiered cart discounts); "" for the listed price. */
+  priceSource: string;
+  /** Missing from the vendor's latest scrapes; kept at its last scraped price for the grace period. */
+  possiblyDelisted: boolean;
+  /** Date (YYYY-MM-DD) of the first scrape that missed it; "" when listed. */
+  missingSince: string;
   activeGrams: number;
   grossGrams: number;
   costPerGram: number;
@@ -22,6 +40,111 @@ export interface Analysis {
   type: string;
   imageURL: string;
   isSubscription: boolean;
+  /** Out of stock; only in reports run with -include-unavailable. Ranked below the fold. */
+  unavailable: boolean;
   needsReview: boolean;
   reviewReason: string;
-}
\ No newline at end of file
+  /** The flag's live figures, e.g. "$0.20/g, NMN expects $0.30–$20.00/g"; "" when none. */
+  reviewDetail: string;
+  /** Caution keyword match (a flavor), e.g. "Detected caution keyword: berry"; "" when none. Still ranked. */
+  caution: string;
+  confidence: number;
+  compareAtPrice: number;
+  discountPct: number;
+  perpetualSale: boolean;
+  /** Minimum units per order; 0 when the vendor sets none. */
+  minOrderQty: number;
+  /** Real minimum spend: price × minOrderQty (equals price without a minimum). */
+  entryPrice: number;
+  /** Checkout price in the vendor's currency; 0 when it is the report currency (USD). */
+  nativePrice: number;
+  /** ISO 4217 code of nativePrice (e.g. "EUR"); "" for USD vendors. */
+  nativeCurrency: string;
+  /** Cost of the daily target dose; 0 when the supplement has no target. */
+  costPerDay: number;
+  /** Whole capsules/tablets per day (dose rounded up); 0 for powders. */
+  unitsPerDay: number;
+  /** Active mg per capsule/tablet; 0 for powders and liquids. */
+  unitMg: number;
+  /** Powder per labeled scoop, in mg; 0 when the description states none. */
+  scoopMg: number;
+  /** Scoops in the container; 0 when the scoop size is unknown. */
+  servingsPerContainer: number;
+  /** Labeled molecular form (e.g. "Creatine HCl"); empty when not recognized. */
+  activeForm: string;
+  /** Share of the labeled weight that is the active moiety, already applied to activeGrams; 0 when 1. */
+  activeFraction: number;
+  /** Third-party testing marks (e.g. "NSF Certified for Sport"); empty when untested. */
+  certifications: string[];
+  /** Quality multiplier earned by certifications, already folded into effectiveCost; 0 when none. */
+  qualityMultiplier: number;
+  /** External 0–100 quality score (Labdoor, ConsumerLab); 0 when unscored. */
+  qualityScore: number;
+  qualitySource: string;
+  /** effectiveCost / (qualityScore / 100); 0 when unscored. */
+  qualityAdjustedCost: number;
+  /** Shipping fee for one minimum order; 0 when free or unknown. */
+  shippingCost: number;
+  /** Ranking formula score, lower ranks higher; equals effectiveCost unless rankWeights is configured. */
+  rankScore: number;
+  /** On its supplement's Pareto front: nothing is both cheaper and better trusted. */
+  paretoOptimal: boolean;
+  /** Supplement section ("nmn", "nad", "tmg", "resveratrol", "creatine"); empty when none matches. */
+  supplement: string;
+  /** Share (0–100) of the supplement's trusted entries costing more; 100 = cheapest. */
+  costPercentile: number;
+  /** effectiveCost ÷ the supplement's cheapest trusted effectiveCost. */
+  costRatio: number;
+  /** Place among the supplement's entries above the fold (1 = best); 0 when unranked. */
+  supplementRank: number;
+  /** Place in the previous run's report; 0 when it was not ranked there. */
+  previousRank: number;
+  /** Places gained (positive) or lost since the previous run. */
+  rankChange: number;
+  /** Packs in a multi-pack or bulk tier; 0 for a single unit. */
+  packSize: number;
+  /** Saving per unit versus buying the single unit, and its share of the single's cost per gram; negative when the bundle costs more. 0 without a single to compare. */
+  bundleSaving: number;
+  bundleSavingPct: number;
+  /** The bundle costs more per gram than buying singles. */
+  bundleDearer: boolean;
+  /** Per-interval pricing on subscription entries; empty otherwise. */
+  subscriptionOptions: SubscriptionOption[];
+  /** The vendor's subscription risk tags ("hard-to-cancel", "renewal-price-up") on subscription entries; empty otherwise. */
+  subscriptionRisks: string[];
+  /** Last daily listed prices (USD, oldest first) from the extended report; empty without it. */
+  recentPrices: number[];
+}
+/** A vendor's cheapest trusted one-time entry for one supplement. */
+export interface VendorCheapest {
+  /** Supplement section ("nmn", "nad", "tmg", "resveratrol", "creatine"). */
+  supplement: string;
+  name: string;
+  handle: string;
+  variant: string;
+  price: number;
+  costPerGram: number;
+  effectiveCost: number;
+}
+
+/**
+ * Vendor card — one vendor's row of data/vendor_summary.json, mapped to
+ * camelCase in lib/data.ts like Analysis.
+ */
+export interface VendorSummary {
+  vendor: string;
+  /** "scraped", "cached" or "failed" in the run that wrote the summary. */
+  status: string;
+  /** Distinct products in the report. */
+  products: number;
+  /** Report entries, subscriptions included. */
+  entries: number;
+  /** In supplement section order; empty when nothing ranks above the fold. */
+  cheapest: VendorCheapest[];
+  /** Mean $/g of the trusted one-time entries; 0 when there are none. */
+  avgCostPerGram: number;
+  /** Data quality score, 0–100. */
+  qualityScore: number;
+  /** ISO 8601 time of the last live scrape; "" when never scraped live. */
+  lastScraped: string;
+}